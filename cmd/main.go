@@ -1,18 +1,29 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"vietick-backend/internal/config"
 	"vietick-backend/internal/handler"
 	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
 	"vietick-backend/internal/repository"
+	"vietick-backend/internal/search"
 	"vietick-backend/internal/service"
 	"vietick-backend/pkg/database"
 	"vietick-backend/pkg/email"
+	"vietick-backend/pkg/encryption"
 	"vietick-backend/pkg/jwt"
+	"vietick-backend/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -70,25 +81,101 @@ func main() {
 	commentRepo := repository.NewCommentRepository(db)
 	followRepo := repository.NewFollowRepository(db)
 	verificationRepo := repository.NewVerificationRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	contentRuleRepo := repository.NewContentRuleRepository(db)
+	mediaRepo := repository.NewMediaRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	organizationRepo := repository.NewOrganizationRepository(db)
+	draftRepo := repository.NewDraftRepository(db)
+	exportRepo := repository.NewExportRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	pollRepo := repository.NewPollRepository(db)
+	linkPreviewRepo := repository.NewLinkPreviewRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+
+	// Initialize media storage
+	mediaStorage, err := storage.New(&cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+	log.Printf("Media storage initialized (%s)", cfg.Storage.Driver)
+
+	// Identity documents are kept apart and encrypted at rest
+	documentAEAD, err := encryption.LoadDocumentAEAD(&cfg.Storage, &cfg.Encryption)
+	if err != nil {
+		log.Fatalf("Failed to initialize document encryption: %v", err)
+	}
+	privateStorage, err := storage.NewPrivate(&cfg.Storage, documentAEAD)
+	if err != nil {
+		log.Fatalf("Failed to initialize private storage: %v", err)
+	}
+
+	// Encrypted database fields (ID numbers, names on documents) use the PII keyring
+	piiKeyring, err := encryption.LoadKeyring(&cfg.Encryption)
+	if err != nil {
+		log.Fatalf("Failed to initialize PII encryption: %v", err)
+	}
+	encryption.SetDefault(piiKeyring)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, authRepo, jwtManager, emailService)
-	userService := service.NewUserService(userRepo, followRepo)
-	postService := service.NewPostService(postRepo)
-	commentService := service.NewCommentService(commentRepo)
-	followService := service.NewFollowService(followRepo)
-	verificationService := service.NewVerificationService(verificationRepo, userRepo, emailService)
+	searchService := service.NewSearchService(func() search.Engine { return search.NewMemoryEngine() }, postRepo, userRepo, followRepo)
+	authService := service.NewAuthService(userRepo, authRepo, jwtManager, emailService, searchService, &cfg.Account)
+	mediaProcessor := service.NewMediaProcessor(mediaRepo, mediaStorage, privateStorage, cfg.Storage.ImageWorkers, 1000)
+	mediaService := service.NewMediaService(mediaRepo, mediaStorage, privateStorage, mediaProcessor, &cfg.Storage)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, postRepo, &cfg.Analytics)
+	userService := service.NewUserService(userRepo, followRepo, searchService, mediaService, analyticsService)
+	contentPolicyService := service.NewContentPolicyService(contentRuleRepo, reportRepo)
+	notificationService := service.NewNotificationService(notificationRepo)
+	pollService := service.NewPollService(pollRepo, postRepo, notificationService)
+	linkPreviewService := service.NewLinkPreviewService(linkPreviewRepo, &cfg.LinkPreview, 1000)
+	postService := service.NewPostService(postRepo, contentPolicyService, searchService, mediaService, pollService, linkPreviewService, analyticsService, &cfg.Edit, &cfg.Retention)
+	commentService := service.NewCommentService(commentRepo, contentPolicyService, &cfg.Edit, &cfg.Retention)
+	followService := service.NewFollowService(followRepo, searchService, analyticsService)
+	permissionService := service.NewPermissionService(permissionRepo, userRepo)
+	verificationService := service.NewVerificationService(verificationRepo, userRepo, organizationRepo, emailService, searchService, mediaService, permissionService, piiKeyring, &cfg.Verification)
+	organizationService := service.NewOrganizationService(organizationRepo, userRepo, jwtManager, emailService, searchService, mediaService)
+	suspensionService := service.NewSuspensionService(userRepo, authRepo, reportRepo, authService, emailService, searchService)
+	moderationService := service.NewModerationService(reportRepo, postRepo, commentRepo, userRepo, notificationService, suspensionService, searchService, &cfg.Retention)
+	exportService := service.NewExportService(exportRepo, userRepo, postRepo, commentRepo, followRepo, pollRepo, authRepo, verificationService, mediaService, pollService, emailService, privateStorage, cfg.Storage.SigningSecret, &cfg.Export)
+	accountService := service.NewAccountService(userRepo, authRepo, postRepo, commentRepo, followRepo, draftRepo, bookmarkRepo, pollRepo, notificationRepo, organizationRepo, authService, verificationService, mediaService, searchService, exportService, emailService, &cfg.Account)
+	draftService := service.NewDraftService(draftRepo, userRepo, organizationRepo, postService, contentPolicyService, mediaService, notificationService)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo, postRepo, mediaService, pollService, linkPreviewService)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, accountService)
 	postHandler := handler.NewPostHandler(postService)
 	commentHandler := handler.NewCommentHandler(commentService)
 	followHandler := handler.NewFollowHandler(followService)
 	verificationHandler := handler.NewVerificationHandler(verificationService)
+	moderationHandler := handler.NewModerationHandler(moderationService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	suspensionHandler := handler.NewSuspensionHandler(suspensionService)
+	contentRuleHandler := handler.NewContentRuleHandler(contentPolicyService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	mediaHandler := handler.NewMediaHandler(mediaService)
+	permissionHandler := handler.NewPermissionHandler(permissionService)
+	organizationHandler := handler.NewOrganizationHandler(organizationService)
+	draftHandler := handler.NewDraftHandler(draftService)
+	exportHandler := handler.NewExportHandler(exportService)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkService)
+	pollHandler := handler.NewPollHandler(pollService)
 
 	// Setup router
-	router := setupRouter(cfg, authService, userService, authHandler, userHandler, postHandler, commentHandler, followHandler, verificationHandler)
+	router := setupRouter(cfg, authService, userService, permissionService, organizationService, authHandler, userHandler, postHandler, commentHandler, followHandler, verificationHandler, moderationHandler, notificationHandler, suspensionHandler, contentRuleHandler, searchHandler, mediaHandler, permissionHandler, organizationHandler, draftHandler, exportHandler, bookmarkHandler, pollHandler)
+
+	// Build the search index in the background; searches use the database until it is ready.
+	// Rebuilt periodically to pick up changes made through other instances.
+	go func() {
+		ticker := time.NewTicker(15 * time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if err := searchService.Rebuild(); err != nil {
+				log.Printf("Failed to build search index: %v", err)
+			}
+		}
+	}()
 
 	// Start cleanup routine for expired tokens
 	go func() {
@@ -101,25 +188,221 @@ func main() {
 		}
 	}()
 
+	// Periodically reload content rules so edits made on other instances are picked up
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := contentPolicyService.Reload(); err != nil {
+				log.Printf("Failed to reload content rules: %v", err)
+			}
+		}
+	}()
+
+	// Remove presigned uploads that were never completed
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := mediaService.CleanupPending(); err != nil {
+				log.Printf("Failed to cleanup pending media: %v", err)
+			}
+		}
+	}()
+
+	// Delete identity documents once their retention period after the decision is over
+	go func() {
+		ticker := time.NewTicker(6 * time.Hour)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := verificationService.PurgeDocuments(cfg.Verification.DocumentRetentionDays); err != nil {
+				log.Printf("Failed to purge verification documents: %v", err)
+			}
+		}
+	}()
+
+	// Remind users to renew verifications about to expire and remove lapsed blue ticks
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := verificationService.ExpireVerifications(); err != nil {
+				log.Printf("Failed to expire verifications: %v", err)
+			}
+		}
+	}()
+
+	// Assign review requests left without a reviewer (none available, reviewer removed)
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := verificationService.AssignPending(); err != nil {
+				log.Printf("Failed to assign verifications: %v", err)
+			}
+		}
+	}()
+
+	// Permanently delete posts and comments whose retention period after deletion is over
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := postService.PurgeDeleted(); err != nil {
+				log.Printf("Failed to purge deleted posts: %v", err)
+			}
+			if _, err := commentService.PurgeDeleted(); err != nil {
+				log.Printf("Failed to purge deleted comments: %v", err)
+			}
+		}
+	}()
+
+	// Erase deleted accounts in batches and release usernames after their cooldown
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := accountService.EraseDeleted(); err != nil {
+				log.Printf("Failed to erase deleted accounts: %v", err)
+			}
+		}
+	}()
+
+	// Build requested data archives, resuming those interrupted by a restart, and delete
+	// archives whose download link has expired
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := exportService.ProcessDue(); err != nil {
+				log.Printf("Failed to build data exports: %v", err)
+			}
+			if _, err := exportService.CleanupExpired(); err != nil {
+				log.Printf("Failed to cleanup expired data exports: %v", err)
+			}
+		}
+	}()
+
+	// Publish scheduled posts, including those missed while the server was down
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := draftService.PublishDue(); err != nil {
+				log.Printf("Failed to publish scheduled posts: %v", err)
+			}
+		}
+	}()
+
+	// Close polls whose closing time has passed and notify their author and voters
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := pollService.CloseDue(); err != nil {
+				log.Printf("Failed to close polls: %v", err)
+			}
+		}
+	}()
+
+	// Start the image pipeline and pick up images left unprocessed (restart, full queue)
+	mediaProcessor.Start()
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := mediaProcessor.Sweep(); err != nil {
+				log.Printf("Failed to sweep unprocessed media: %v", err)
+			}
+		}
+	}()
+
+	// Start the link preview workers and pick up links left unfetched (restart, full queue)
+	linkPreviewService.Start()
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := linkPreviewService.Sweep(); err != nil {
+				log.Printf("Failed to sweep link previews: %v", err)
+			}
+		}
+	}()
+
+	// Write buffered impressions and views
+	go func() {
+		ticker := time.NewTicker(analyticsService.FlushInterval())
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := analyticsService.Flush(); err != nil {
+				log.Printf("Failed to flush post analytics: %v", err)
+			}
+		}
+	}()
+
+	// Sum yesterday's follows, unfollows and follower engagement once it is over
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := analyticsService.ComputeFollowerStats(); err != nil {
+				log.Printf("Failed to compute follower stats: %v", err)
+			}
+		}
+	}()
+
 	// Start server
 	serverAddr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
 	log.Printf("🚀 VietTick Backend Server starting on %s", serverAddr)
 
-	if err := router.Run(serverAddr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{Addr: serverAddr, Handler: router}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for SIGINT/SIGTERM, let in-flight requests finish, then write what is still buffered
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down server gracefully: %v", err)
 	}
+	if _, err := analyticsService.Flush(); err != nil {
+		log.Printf("Failed to flush post analytics: %v", err)
+	}
+	log.Println("Server stopped")
 }
 
 func setupRouter(
 	cfg *config.Config,
 	authService *service.AuthService,
 	userService *service.UserService,
+	permissionService *service.PermissionService,
+	organizationService *service.OrganizationService,
 	authHandler *handler.AuthHandler,
 	userHandler *handler.UserHandler,
 	postHandler *handler.PostHandler,
 	commentHandler *handler.CommentHandler,
 	followHandler *handler.FollowHandler,
 	verificationHandler *handler.VerificationHandler,
+	moderationHandler *handler.ModerationHandler,
+	notificationHandler *handler.NotificationHandler,
+	suspensionHandler *handler.SuspensionHandler,
+	contentRuleHandler *handler.ContentRuleHandler,
+	searchHandler *handler.SearchHandler,
+	mediaHandler *handler.MediaHandler,
+	permissionHandler *handler.PermissionHandler,
+	organizationHandler *handler.OrganizationHandler,
+	draftHandler *handler.DraftHandler,
+	exportHandler *handler.ExportHandler,
+	bookmarkHandler *handler.BookmarkHandler,
+	pollHandler *handler.PollHandler,
 ) *gin.Engine {
 	// Set Gin mode
 	gin.SetMode(gin.ReleaseMode)
@@ -148,6 +431,11 @@ func setupRouter(
 		})
 	})
 
+	// Files kept on local disk are served by the API unless a full public URL is configured
+	if cfg.Storage.Driver != "s3" && strings.HasPrefix(cfg.Storage.PublicBaseURL, "/") {
+		router.Static(cfg.Storage.PublicBaseURL, cfg.Storage.LocalPath)
+	}
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -169,6 +457,13 @@ func setupRouter(
 			public.GET("/users/check-email", userHandler.CheckEmailAvailability)
 			public.GET("/verification/requirements", verificationHandler.GetVerificationRequirements)
 
+			// Direct upload target, authorized by the signature in the URL
+			public.PUT("/media/:id/upload", mediaHandler.ReceiveUpload)
+			// Identity documents, authorized by the signed link handed out to the reviewer
+			public.GET("/verification/documents/:media_id", verificationHandler.ServeVerificationDocument)
+			// Data archives, authorized by the signed link emailed to the user
+			public.GET("/exports/:id/download", exportHandler.DownloadExport)
+
 			// Search routes
 			searchGroup := public.Group("/search")
 			{
@@ -183,12 +478,18 @@ func setupRouter(
 		protected.Use(middleware.AuthMiddleware(authService))
 		protected.Use(middleware.ApiRateLimitMiddleware())
 		{
+			// Members act on behalf of an organization on these routes (X-Acting-As header or
+			// an organization token), with at least the given role
+			actAsEditor := middleware.ActingAsMiddleware(organizationService, model.OrganizationRoleEditor)
+			actAsAdmin := middleware.ActingAsMiddleware(organizationService, model.OrganizationRoleAdmin)
+			actAsOwner := middleware.ActingAsMiddleware(organizationService, model.OrganizationRoleOwner)
+
 			// Auth routes (authenticated)
 			authGroup := protected.Group("/auth")
 			{
 				authGroup.POST("/logout", authHandler.Logout)
 				authGroup.POST("/logout-all", authHandler.LogoutAll)
-				authGroup.POST("/resend-verification", authHandler.ResendEmailVerification)
+				authGroup.POST("/resend-verification", actAsAdmin, authHandler.ResendEmailVerification)
 				authGroup.POST("/change-password", authHandler.ChangePassword)
 				authGroup.GET("/me", authHandler.GetProfile)
 				authGroup.GET("/check", authHandler.CheckToken)
@@ -197,10 +498,17 @@ func setupRouter(
 			// User routes
 			userGroup := protected.Group("/users")
 			{
-				userGroup.GET("/me", userHandler.GetCurrentProfile)
-				userGroup.PUT("/me", userHandler.UpdateProfile)
-				userGroup.PUT("/me/username", userHandler.UpdateUsername)
-				userGroup.PUT("/me/email", userHandler.UpdateEmail)
+				userGroup.GET("/me", actAsEditor, userHandler.GetCurrentProfile)
+				userGroup.GET("/me/dashboard", actAsEditor, userHandler.GetDashboard)
+				userGroup.GET("/me/follower-analytics", actAsEditor, followHandler.GetFollowerAnalytics)
+				userGroup.PUT("/me", actAsAdmin, userHandler.UpdateProfile)
+				userGroup.PUT("/me/username", actAsOwner, userHandler.UpdateUsername)
+				userGroup.PUT("/me/email", actAsOwner, userHandler.UpdateEmail)
+				userGroup.POST("/me/deactivate", userHandler.DeactivateAccount)
+				userGroup.DELETE("/me", userHandler.DeleteAccount)
+				userGroup.POST("/me/export", exportHandler.RequestExport)
+				userGroup.GET("/me/exports", exportHandler.GetExports)
+				userGroup.GET("/me/exports/:id", exportHandler.GetExport)
 				userGroup.GET("/recommended", userHandler.GetRecommendedUsers)
 				userGroup.GET("/search", userHandler.SearchUsers)
 				userGroup.GET("/:id", userHandler.GetProfile)
@@ -208,9 +516,9 @@ func setupRouter(
 				userGroup.GET("/username/:username", userHandler.GetProfileByUsername)
 
 				// Follow routes
-				userGroup.POST("/:id/follow", followHandler.Follow)
-				userGroup.POST("/:id/unfollow", followHandler.Unfollow)
-				userGroup.POST("/:id/toggle-follow", followHandler.ToggleFollow)
+				userGroup.POST("/:id/follow", actAsAdmin, followHandler.Follow)
+				userGroup.POST("/:id/unfollow", actAsAdmin, followHandler.Unfollow)
+				userGroup.POST("/:id/toggle-follow", actAsAdmin, followHandler.ToggleFollow)
 				userGroup.GET("/:id/follow-status", followHandler.GetFollowStatus)
 				userGroup.GET("/:id/followers", followHandler.GetFollowers)
 				userGroup.GET("/:id/following", followHandler.GetFollowing)
@@ -222,6 +530,7 @@ func setupRouter(
 
 			// Follow routes (bulk operations)
 			followGroup := protected.Group("/follows")
+			followGroup.Use(actAsAdmin)
 			{
 				followGroup.POST("/bulk-follow", followHandler.BulkFollow)
 				followGroup.POST("/bulk-unfollow", followHandler.BulkUnfollow)
@@ -229,18 +538,42 @@ func setupRouter(
 
 			// Post routes
 			postGroup := protected.Group("/posts")
+			postGroup.Use(actAsEditor)
 			{
 				postGroup.POST("", postHandler.CreatePost)
+
+				// Drafts and scheduled posts, registered before /:id
+				draftGroup := postGroup.Group("/drafts")
+				{
+					draftGroup.GET("", draftHandler.GetDrafts)
+					draftGroup.POST("", draftHandler.CreateDraft)
+					draftGroup.GET("/:id", draftHandler.GetDraft)
+					draftGroup.PUT("/:id", draftHandler.UpdateDraft)
+					draftGroup.DELETE("/:id", draftHandler.DeleteDraft)
+					draftGroup.POST("/:id/schedule", draftHandler.ScheduleDraft)
+					draftGroup.POST("/:id/cancel", draftHandler.CancelSchedule)
+					draftGroup.POST("/:id/publish", draftHandler.PublishDraft)
+				}
 				postGroup.GET("/feed", postHandler.GetFeed)
 				postGroup.GET("/explore", postHandler.GetExplorePosts)
 				postGroup.GET("/search", postHandler.SearchPosts)
+				postGroup.GET("/deleted", postHandler.GetDeletedPosts)
 				postGroup.GET("/:id", postHandler.GetPost)
 				postGroup.PUT("/:id", postHandler.UpdatePost)
 				postGroup.DELETE("/:id", postHandler.DeletePost)
 				postGroup.GET("/:id/stats", postHandler.GetPostStats)
+				postGroup.GET("/:id/analytics", postHandler.GetPostAnalytics)
+				postGroup.GET("/:id/history", postHandler.GetPostHistory)
+				postGroup.POST("/:id/restore", postHandler.RestorePost)
 				postGroup.POST("/:id/like", postHandler.LikePost)
 				postGroup.POST("/:id/unlike", postHandler.UnlikePost)
 				postGroup.POST("/:id/toggle-like", postHandler.ToggleLike)
+				postGroup.GET("/:id/reactions", postHandler.GetReactions)
+				postGroup.POST("/:id/reactions", postHandler.React)
+				postGroup.DELETE("/:id/reactions", postHandler.RemoveReaction)
+				postGroup.GET("/:id/poll", pollHandler.GetPoll)
+				postGroup.POST("/:id/poll/vote", pollHandler.Vote)
+				postGroup.DELETE("/:id/poll/vote", pollHandler.RemoveVote)
 				postGroup.GET("/user/:user_id", postHandler.GetUserPosts)
 
 				// Comment routes
@@ -254,44 +587,157 @@ func setupRouter(
 				}
 			}
 
+			// Bookmarks are personal: they are never saved on behalf of an organization
+			protected.POST("/posts/:id/bookmark", bookmarkHandler.BookmarkPost)
+			protected.DELETE("/posts/:id/bookmark", bookmarkHandler.RemoveBookmark)
+			bookmarkGroup := protected.Group("/bookmarks")
+			{
+				bookmarkGroup.GET("", bookmarkHandler.GetBookmarks)
+				bookmarkGroup.GET("/collections", bookmarkHandler.GetCollections)
+				bookmarkGroup.POST("/collections", bookmarkHandler.CreateCollection)
+				bookmarkGroup.PUT("/collections/:id", bookmarkHandler.RenameCollection)
+				bookmarkGroup.DELETE("/collections/:id", bookmarkHandler.DeleteCollection)
+			}
+
 			// Comment routes
 			commentGroup := protected.Group("/comments")
+			commentGroup.Use(actAsEditor)
 			{
+				commentGroup.GET("/deleted", commentHandler.GetDeletedComments)
 				commentGroup.GET("/:id", commentHandler.GetComment)
 				commentGroup.PUT("/:id", commentHandler.UpdateComment)
 				commentGroup.DELETE("/:id", commentHandler.DeleteComment)
 				commentGroup.GET("/:id/stats", commentHandler.GetCommentStats)
+				commentGroup.GET("/:id/history", commentHandler.GetCommentHistory)
+				commentGroup.POST("/:id/restore", commentHandler.RestoreComment)
 				commentGroup.POST("/:id/like", commentHandler.LikeComment)
 				commentGroup.POST("/:id/unlike", commentHandler.UnlikeComment)
 				commentGroup.POST("/:id/toggle-like", commentHandler.ToggleLike)
+				commentGroup.GET("/:id/reactions", commentHandler.GetReactions)
+				commentGroup.POST("/:id/reactions", commentHandler.React)
+				commentGroup.DELETE("/:id/reactions", commentHandler.RemoveReaction)
 			}
 
 			// Verification routes
 			verificationGroup := protected.Group("/verification")
 			{
-				verificationGroup.GET("/me", verificationHandler.GetUserVerification)
-				verificationGroup.GET("/can-submit", verificationHandler.CanSubmitVerification)
+				verificationGroup.GET("/me", actAsAdmin, verificationHandler.GetUserVerification)
+				verificationGroup.GET("/me/history", actAsAdmin, verificationHandler.GetVerificationHistory)
+				verificationGroup.GET("/can-submit", actAsAdmin, verificationHandler.CanSubmitVerification)
 				verificationGroup.GET("/verified-users", verificationHandler.GetVerifiedUsers)
 
-				// Routes requiring email verification
+				// Routes requiring email verification (of the organization when acting as one)
 				emailVerified := verificationGroup.Group("")
-				emailVerified.Use(middleware.RequireEmailVerificationMiddleware(userService))
+				emailVerified.Use(actAsAdmin, middleware.RequireEmailVerificationMiddleware(userService))
 				{
 					emailVerified.POST("/submit", verificationHandler.SubmitIdentityVerification)
 				}
 
+				// Review queue, for reviewers
+				reviewRoutes := verificationGroup.Group("")
+				reviewRoutes.Use(middleware.RequirePermission(permissionService, model.PermissionReviewVerifications))
+				{
+					reviewRoutes.GET("/pending", verificationHandler.GetPendingVerifications)
+					reviewRoutes.GET("/:id", verificationHandler.GetVerification)
+					reviewRoutes.POST("/:id/claim", verificationHandler.ClaimVerification)
+					reviewRoutes.DELETE("/:id/claim", verificationHandler.ReleaseVerification)
+					reviewRoutes.POST("/:id/review", verificationHandler.ReviewVerification)
+				}
+
 				// Admin routes
 				adminRoutes := verificationGroup.Group("")
 				adminRoutes.Use(middleware.AdminMiddleware(userService))
 				{
-					adminRoutes.GET("/pending", verificationHandler.GetPendingVerifications)
 					adminRoutes.GET("/all", verificationHandler.GetAllVerifications)
 					adminRoutes.GET("/stats", verificationHandler.GetVerificationStats)
-					adminRoutes.GET("/:id", verificationHandler.GetVerification)
-					adminRoutes.POST("/:id/review", verificationHandler.ReviewVerification)
+					adminRoutes.POST("/:id/revoke", verificationHandler.RevokeVerification)
+					adminRoutes.PUT("/users/:user_id/category", verificationHandler.UpdateVerificationCategory)
 					adminRoutes.DELETE("/:id", verificationHandler.DeleteVerification)
+					adminRoutes.GET("/:id/document-access", verificationHandler.GetVerificationDocumentAccess)
+				}
+
+				// Identity documents, for reviewers granted access
+				documentRoutes := verificationGroup.Group("")
+				documentRoutes.Use(middleware.RequirePermission(permissionService, model.PermissionViewIdentityDocuments))
+				{
+					documentRoutes.GET("/:id/documents", verificationHandler.GetVerificationDocuments)
 				}
 			}
+
+			// Organization accounts and their members
+			organizationGroup := protected.Group("/organizations")
+			{
+				organizationGroup.GET("", organizationHandler.GetMyOrganizations)
+				organizationGroup.POST("", middleware.RequireEmailVerificationMiddleware(userService), organizationHandler.CreateOrganization)
+				organizationGroup.GET("/:id/members", organizationHandler.GetMembers)
+				organizationGroup.POST("/:id/members", organizationHandler.AddMember)
+				organizationGroup.PUT("/:id/members/:user_id", organizationHandler.UpdateMember)
+				organizationGroup.DELETE("/:id/members/:user_id", organizationHandler.RemoveMember)
+				organizationGroup.POST("/:id/token", organizationHandler.IssueToken)
+			}
+
+			// Report routes
+			protected.POST("/reports", moderationHandler.CreateReport)
+
+			// Moderation routes (admin only)
+			moderationGroup := protected.Group("/moderation")
+			moderationGroup.Use(middleware.AdminMiddleware(userService))
+			{
+				moderationGroup.GET("/reports/pending", moderationHandler.GetPendingReports)
+				moderationGroup.GET("/reports", moderationHandler.GetAllReports)
+				moderationGroup.GET("/reports/:id", moderationHandler.GetReport)
+				moderationGroup.POST("/reports/:id/action", moderationHandler.TakeAction)
+				moderationGroup.GET("/stats", moderationHandler.GetModerationStats)
+				moderationGroup.GET("/actions", moderationHandler.GetAuditTrail)
+
+				// Deleted posts and comments kept until they are purged
+				moderationGroup.GET("/deleted/posts", moderationHandler.GetDeletedPosts)
+				moderationGroup.GET("/deleted/posts/:id", moderationHandler.GetDeletedPost)
+				moderationGroup.POST("/deleted/posts/:id/restore", moderationHandler.RestorePost)
+				moderationGroup.GET("/deleted/comments", moderationHandler.GetDeletedComments)
+				moderationGroup.GET("/deleted/comments/:id", moderationHandler.GetDeletedComment)
+				moderationGroup.POST("/deleted/comments/:id/restore", moderationHandler.RestoreComment)
+
+				// Automatic content rules
+				moderationGroup.GET("/rules", contentRuleHandler.GetRules)
+				moderationGroup.POST("/rules", contentRuleHandler.CreateRule)
+				moderationGroup.POST("/rules/test", contentRuleHandler.TestContent)
+				moderationGroup.GET("/rules/:id", contentRuleHandler.GetRule)
+				moderationGroup.PUT("/rules/:id", contentRuleHandler.UpdateRule)
+				moderationGroup.DELETE("/rules/:id", contentRuleHandler.DeleteRule)
+			}
+
+			// Account administration routes (admin only)
+			adminGroup := protected.Group("/admin")
+			adminGroup.Use(middleware.AdminMiddleware(userService))
+			{
+				adminGroup.GET("/users/suspended", suspensionHandler.GetSuspendedUsers)
+				adminGroup.POST("/users/:id/suspend", suspensionHandler.SuspendUser)
+				adminGroup.POST("/users/:id/unsuspend", suspensionHandler.UnsuspendUser)
+				adminGroup.GET("/users/:id/permissions", permissionHandler.GetUserPermissions)
+				adminGroup.PUT("/users/:id/permissions/:permission", permissionHandler.GrantPermission)
+				adminGroup.DELETE("/users/:id/permissions/:permission", permissionHandler.RevokePermission)
+			}
+
+			// Media routes
+			mediaGroup := protected.Group("/media")
+			mediaGroup.Use(actAsEditor)
+			{
+				mediaGroup.POST("", mediaHandler.Upload)
+				mediaGroup.POST("/presign", mediaHandler.Presign)
+				mediaGroup.POST("/:id/complete", mediaHandler.Complete)
+				mediaGroup.GET("/:id", mediaHandler.GetMedia)
+				mediaGroup.DELETE("/:id", mediaHandler.DeleteMedia)
+			}
+
+			// Notification routes
+			notificationGroup := protected.Group("/notifications")
+			notificationGroup.Use(actAsEditor)
+			{
+				notificationGroup.GET("", notificationHandler.GetNotifications)
+				notificationGroup.POST("/read-all", notificationHandler.MarkAllAsRead)
+				notificationGroup.POST("/:id/read", notificationHandler.MarkAsRead)
+			}
 		}
 
 		// Optional auth routes (can work with or without authentication)
@@ -300,6 +746,7 @@ func setupRouter(
 		{
 			// These routes can provide different data based on authentication status
 			// For example, showing follow status if authenticated
+			optionalAuth.GET("/search/autocomplete", searchHandler.Autocomplete)
 		}
 	}
 
@@ -0,0 +1,117 @@
+// Command migrate-legacy-documents moves identity documents submitted before documents
+// moved to private storage.
+//
+// Each document still referenced by its public URL is copied to the encrypted private
+// storage as a new media, the request is pointed to it and the public file is deleted.
+// Reviewers only get signed links to private documents, so run it once when deploying.
+// It can be run again: requests already moved are skipped.
+//
+//	go run ./cmd/migrate-legacy-documents [-batch 100] [-dry-run]
+package main
+
+import (
+	"flag"
+	"log"
+
+	"vietick-backend/internal/config"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/service"
+	"vietick-backend/pkg/database"
+	"vietick-backend/pkg/encryption"
+	"vietick-backend/pkg/storage"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 100, "requests per batch")
+	dryRun := flag.Bool("dry-run", false, "only count the documents that would be moved")
+	flag.Parse()
+
+	cfg := config.Load()
+	db, err := database.NewConnection(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	publicStorage, err := storage.New(&cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+	documentAEAD, err := encryption.LoadDocumentAEAD(&cfg.Storage, &cfg.Encryption)
+	if err != nil {
+		log.Fatalf("Failed to initialize document encryption: %v", err)
+	}
+	privateStorage, err := storage.NewPrivate(&cfg.Storage, documentAEAD)
+	if err != nil {
+		log.Fatalf("Failed to initialize private storage: %v", err)
+	}
+
+	verificationRepo := repository.NewVerificationRepository(db)
+	// Imported documents are processed by the server's sweep, no processor runs here
+	mediaService := service.NewMediaService(repository.NewMediaRepository(db), publicStorage, privateStorage, nil, &cfg.Storage)
+
+	var scanned, moved, missing int
+	lastID := ""
+	for {
+		verifications, err := verificationRepo.GetWithLegacyDocuments(lastID, *batchSize)
+		if err != nil {
+			log.Fatalf("Failed to read verifications: %v", err)
+		}
+		if len(verifications) == 0 {
+			break
+		}
+		lastID = verifications[len(verifications)-1].ID
+
+		for _, verification := range verifications {
+			scanned++
+			for side, publicURL := range legacyDocuments(&verification) {
+				if *dryRun {
+					moved++
+					continue
+				}
+
+				media, err := mediaService.ImportPublicDocument(verification.UserID, publicURL)
+				if err != nil {
+					// Nothing to move: the request keeps its URL and is reported again next run
+					log.Printf("Skipping %s document of verification %s: %v", side, verification.ID, err)
+					missing++
+					continue
+				}
+				if err := verificationRepo.SetDocumentMedia(verification.ID, side, media.ID); err != nil {
+					mediaService.Purge([]string{media.ID})
+					log.Fatalf("Failed to update verification %s: %v", verification.ID, err)
+				}
+				if err := mediaService.DeletePublicFile(publicURL); err != nil {
+					log.Fatalf("Moved the %s document of verification %s but failed to delete %s: %v",
+						side, verification.ID, publicURL, err)
+				}
+				moved++
+			}
+		}
+		log.Printf("%d requests scanned, %d documents moved", scanned, moved)
+	}
+
+	if *dryRun {
+		log.Printf("Dry run: %d documents of %d requests would be moved", moved, scanned)
+		return
+	}
+	log.Printf("Done: %d documents of %d requests moved, %d could not be read", moved, scanned, missing)
+}
+
+// legacyDocuments returns the public URLs of the request keyed by side, leaving out sides
+// that already have a private media
+func legacyDocuments(verification *model.IdentityVerification) map[string]string {
+	documents := make(map[string]string)
+	mediaIDs := verification.DocumentMediaIDs()
+	add := func(side, publicURL string) {
+		if _, ok := mediaIDs[side]; ok || publicURL == "" {
+			return
+		}
+		documents[side] = publicURL
+	}
+	add(model.VerificationDocumentFront, verification.FrontImageURL)
+	add(model.VerificationDocumentSelfie, verification.SelfieImageURL)
+	if verification.BackImageURL != nil {
+		add(model.VerificationDocumentBack, *verification.BackImageURL)
+	}
+	return documents
+}
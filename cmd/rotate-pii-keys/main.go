@@ -0,0 +1,117 @@
+// Command rotate-pii-keys re-encrypts PII columns with the current key version.
+//
+// Values wrapped with an older master key get their data key re-wrapped, values written
+// before encryption was enabled are encrypted, and blind indexes are recomputed (so the
+// command also applies a new PII_BLIND_INDEX_KEY). Once it has finished, older key
+// versions can be removed from PII_ENCRYPTION_KEYS.
+//
+//	go run ./cmd/rotate-pii-keys [-batch 500] [-dry-run]
+package main
+
+import (
+	"flag"
+	"log"
+
+	"vietick-backend/internal/config"
+	"vietick-backend/internal/service"
+	"vietick-backend/pkg/database"
+	"vietick-backend/pkg/encryption"
+)
+
+type verificationRow struct {
+	ID           string
+	FullName     string
+	IDNumber     string
+	IDNumberHash *string
+}
+
+func main() {
+	batchSize := flag.Int("batch", 500, "rows per batch")
+	dryRun := flag.Bool("dry-run", false, "only count the rows that would change")
+	flag.Parse()
+
+	cfg := config.Load()
+	db, err := database.NewConnection(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	keyring, err := encryption.LoadKeyring(&cfg.Encryption)
+	if err != nil {
+		log.Fatalf("Failed to initialize PII encryption: %v", err)
+	}
+	log.Printf("Re-encrypting identity_verifications with key version %d", keyring.CurrentVersion())
+
+	var scanned, updated int
+	lastID := ""
+	for {
+		var rows []verificationRow
+		err := db.Table("identity_verifications").
+			Select("id, full_name, id_number, id_number_hash").
+			Where("id > ?", lastID).Order("id ASC").Limit(*batchSize).
+			Scan(&rows).Error
+		if err != nil {
+			log.Fatalf("Failed to read verifications: %v", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		lastID = rows[len(rows)-1].ID
+
+		for _, row := range rows {
+			scanned++
+			changes, err := rotateRow(keyring, &row)
+			if err != nil {
+				log.Fatalf("Failed to re-encrypt verification %s: %v", row.ID, err)
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			updated++
+			if *dryRun {
+				continue
+			}
+			if err := db.Table("identity_verifications").Where("id = ?", row.ID).Updates(changes).Error; err != nil {
+				log.Fatalf("Failed to update verification %s: %v", row.ID, err)
+			}
+		}
+		log.Printf("%d rows scanned, %d to update", scanned, updated)
+	}
+
+	if *dryRun {
+		log.Printf("Dry run: %d of %d rows would be updated", updated, scanned)
+		return
+	}
+	log.Printf("Done: %d of %d rows updated", updated, scanned)
+}
+
+// rotateRow returns the columns of the row that need to be rewritten
+func rotateRow(keyring *encryption.Keyring, row *verificationRow) (map[string]interface{}, error) {
+	changes := make(map[string]interface{})
+
+	fullName, changed, err := keyring.Rotate(row.FullName)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		changes["full_name"] = fullName
+	}
+
+	idNumber, changed, err := keyring.Rotate(row.IDNumber)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		changes["id_number"] = idNumber
+	}
+
+	plainIDNumber, err := keyring.DecryptString(row.IDNumber)
+	if err != nil {
+		return nil, err
+	}
+	hash := service.IDNumberIndex(keyring, plainIDNumber)
+	if row.IDNumberHash == nil || *row.IDNumberHash != hash {
+		changes["id_number_hash"] = hash
+	}
+
+	return changes, nil
+}
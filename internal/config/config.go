@@ -11,12 +11,21 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Email    EmailConfig
-	CORS     CORSConfig
-	Redis    RedisConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	Email        EmailConfig
+	CORS         CORSConfig
+	Redis        RedisConfig
+	Storage      StorageConfig
+	Verification VerificationConfig
+	Encryption   EncryptionConfig
+	Edit         EditConfig
+	Retention    RetentionConfig
+	Account      AccountConfig
+	Export       ExportConfig
+	LinkPreview  LinkPreviewConfig
+	Analytics    AnalyticsConfig
 }
 
 type ServerConfig struct {
@@ -59,6 +68,109 @@ type RedisConfig struct {
 	DB       int
 }
 
+type StorageConfig struct {
+	Driver         string // local hoặc s3
+	LocalPath      string
+	PublicBaseURL  string
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+	// Ký URL upload trực tiếp lên API khi backend không hỗ trợ presign
+	SigningSecret string
+	MaxImageBytes int64
+	MaxDocBytes   int64
+	ImageWorkers  int
+	// Giấy tờ định danh: lưu riêng, mã hoá AES-256-GCM, không có URL công khai
+	PrivateLocalPath      string
+	S3PrivateBucket       string
+	DocumentEncryptionKey string // base64, 32 bytes
+}
+
+// Mã hoá các cột chứa thông tin cá nhân (số giấy tờ, họ tên trên giấy tờ)
+type EncryptionConfig struct {
+	PIIKeys              string // "1:base64,2:base64"
+	PIICurrentKeyVersion int    // 0: phiên bản lớn nhất
+	BlindIndexKey        string // base64
+	// Chỉ dùng khi phát triển: cho phép chạy bằng các khoá cố định khi thiếu khoá thật
+	AllowDevelopmentKeys bool
+}
+
+type VerificationConfig struct {
+	// Số ngày giữ giấy tờ sau khi yêu cầu được duyệt hoặc từ chối
+	DocumentRetentionDays int
+	// Tự động từ chối khi số giấy tờ hoặc ảnh trùng khớp chính xác với tài khoản đã được duyệt
+	AutoRejectDuplicates bool
+	// Số ngày phải chờ trước khi gửi lại yêu cầu sau khi bị từ chối hoặc thu hồi
+	ResubmitCooldownDays int
+	// Thời hạn của dấu tích (tháng) theo loại giấy tờ; loại không có trong map thì không hết hạn
+	ValidityMonths map[string]int
+	// Nhắc xác minh lại bao nhiêu ngày trước khi hết hạn
+	ExpiryReminderDays int
+	// Thời gian giữ quyền xét duyệt một yêu cầu sau khi nhận (phút)
+	ClaimLeaseMinutes int
+}
+
+// Thời gian được phép sửa bài viết và bình luận sau khi đăng (phút, 0: không giới hạn)
+type EditConfig struct {
+	PostWindowMinutes int
+	// Bài viết có từ EngagementThreshold reaction và bình luận trở lên chỉ được sửa
+	// trong EngagedPostWindowMinutes
+	EngagedPostWindowMinutes int
+	EngagementThreshold      int
+	CommentWindowMinutes     int
+}
+
+// Bài viết và bình luận đã xoá
+type RetentionConfig struct {
+	// Số ngày tác giả có thể khôi phục
+	RestoreWindowDays int
+	// Số ngày giữ lại cho kiểm duyệt trước khi xoá hẳn
+	DeletedContentDays int
+}
+
+// Vô hiệu hoá và xoá tài khoản
+type AccountConfig struct {
+	// Số ngày đăng nhập lại để mở lại tài khoản đã vô hiệu hoá; sau đó tài khoản bị xoá
+	ReactivationDays int
+	// Số ngày username của tài khoản đã xoá được giữ trước khi người khác có thể dùng
+	UsernameCooldownDays int
+	// Số giây mỗi instance giữ trạng thái tài khoản trong bộ nhớ; 0 là đọc database mỗi request
+	StatusCacheSeconds int
+}
+
+// Tải xuống dữ liệu cá nhân
+type ExportConfig struct {
+	// Số giờ phải chờ giữa hai lần yêu cầu
+	CooldownHours int
+	// Số giờ link tải xuống còn hiệu lực; sau đó file bị xoá
+	LinkTTLHours int
+	// Địa chỉ API dùng trong link gửi qua email
+	PublicBaseURL string
+}
+
+// Xem trước link trong bài viết (OpenGraph)
+type LinkPreviewConfig struct {
+	Workers int
+	// Thời gian tối đa cho một lần tải trang, kể cả redirect (giây)
+	TimeoutSeconds int
+	MaxRedirects   int
+	// Chỉ đọc tối đa bấy nhiêu byte đầu của trang
+	MaxBodyBytes int64
+	// Số giờ giữ kết quả trước khi tải lại trang khi có bài viết mới dẫn link
+	CacheTTLHours int
+}
+
+// Lượt hiển thị và lượt xem bài viết
+type AnalyticsConfig struct {
+	// Một người chỉ được tính một lần cho mỗi bài viết trong khoảng thời gian này (phút)
+	DedupWindowMinutes int
+	// Lượt hiển thị và lượt xem được gom trong bộ nhớ và ghi vào cơ sở dữ liệu sau mỗi khoảng này (giây)
+	FlushIntervalSeconds int
+}
+
 func Load() *Config {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
@@ -69,6 +181,35 @@ func Load() *Config {
 	accessExpiryHour, _ := strconv.Atoi(getEnv("JWT_ACCESS_EXPIRY_HOUR", "24"))
 	refreshExpiryDay, _ := strconv.Atoi(getEnv("JWT_REFRESH_EXPIRY_DAY", "7"))
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	s3PathStyle, _ := strconv.ParseBool(getEnv("S3_USE_PATH_STYLE", "false"))
+	maxImageBytes, _ := strconv.ParseInt(getEnv("MEDIA_MAX_IMAGE_BYTES", "10485760"), 10, 64)
+	maxDocBytes, _ := strconv.ParseInt(getEnv("MEDIA_MAX_DOCUMENT_BYTES", "15728640"), 10, 64)
+	imageWorkers, _ := strconv.Atoi(getEnv("MEDIA_IMAGE_WORKERS", "2"))
+	documentRetentionDays, _ := strconv.Atoi(getEnv("VERIFICATION_DOCUMENT_RETENTION_DAYS", "30"))
+	autoRejectDuplicates, _ := strconv.ParseBool(getEnv("VERIFICATION_AUTO_REJECT_DUPLICATES", "false"))
+	resubmitCooldownDays, _ := strconv.Atoi(getEnv("VERIFICATION_RESUBMIT_COOLDOWN_DAYS", "7"))
+	expiryReminderDays, _ := strconv.Atoi(getEnv("VERIFICATION_EXPIRY_REMINDER_DAYS", "30"))
+	claimLeaseMinutes, _ := strconv.Atoi(getEnv("VERIFICATION_CLAIM_LEASE_MINUTES", "15"))
+	piiCurrentKeyVersion, _ := strconv.Atoi(getEnv("PII_ENCRYPTION_CURRENT_KEY_VERSION", "0"))
+	allowDevelopmentKeys, _ := strconv.ParseBool(getEnv("ALLOW_DEVELOPMENT_KEYS", "false"))
+	postEditWindowMinutes, _ := strconv.Atoi(getEnv("POST_EDIT_WINDOW_MINUTES", "60"))
+	engagedPostEditWindowMinutes, _ := strconv.Atoi(getEnv("POST_EDIT_ENGAGED_WINDOW_MINUTES", "5"))
+	editEngagementThreshold, _ := strconv.Atoi(getEnv("POST_EDIT_ENGAGEMENT_THRESHOLD", "20"))
+	commentEditWindowMinutes, _ := strconv.Atoi(getEnv("COMMENT_EDIT_WINDOW_MINUTES", "15"))
+	restoreWindowDays, _ := strconv.Atoi(getEnv("DELETED_CONTENT_RESTORE_DAYS", "30"))
+	deletedContentDays, _ := strconv.Atoi(getEnv("DELETED_CONTENT_RETENTION_DAYS", "90"))
+	reactivationDays, _ := strconv.Atoi(getEnv("ACCOUNT_REACTIVATION_DAYS", "30"))
+	usernameCooldownDays, _ := strconv.Atoi(getEnv("DELETED_USERNAME_COOLDOWN_DAYS", "30"))
+	statusCacheSeconds, _ := strconv.Atoi(getEnv("ACCOUNT_STATUS_CACHE_SECONDS", "5"))
+	exportCooldownHours, _ := strconv.Atoi(getEnv("DATA_EXPORT_COOLDOWN_HOURS", "24"))
+	exportLinkTTLHours, _ := strconv.Atoi(getEnv("DATA_EXPORT_LINK_TTL_HOURS", "72"))
+	linkPreviewWorkers, _ := strconv.Atoi(getEnv("LINK_PREVIEW_WORKERS", "2"))
+	linkPreviewTimeoutSeconds, _ := strconv.Atoi(getEnv("LINK_PREVIEW_TIMEOUT_SECONDS", "5"))
+	linkPreviewMaxRedirects, _ := strconv.Atoi(getEnv("LINK_PREVIEW_MAX_REDIRECTS", "3"))
+	linkPreviewMaxBytes, _ := strconv.ParseInt(getEnv("LINK_PREVIEW_MAX_BYTES", "524288"), 10, 64)
+	linkPreviewCacheTTLHours, _ := strconv.Atoi(getEnv("LINK_PREVIEW_CACHE_TTL_HOURS", "168"))
+	analyticsDedupWindowMinutes, _ := strconv.Atoi(getEnv("ANALYTICS_DEDUP_WINDOW_MINUTES", "30"))
+	analyticsFlushIntervalSeconds, _ := strconv.Atoi(getEnv("ANALYTICS_FLUSH_INTERVAL_SECONDS", "30"))
 
 	return &Config{
 		Server: ServerConfig{
@@ -115,6 +256,70 @@ func Load() *Config {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       redisDB,
 		},
+		Storage: StorageConfig{
+			Driver:         getEnv("STORAGE_DRIVER", "local"),
+			LocalPath:      getEnv("STORAGE_LOCAL_PATH", "./uploads"),
+			PublicBaseURL:  getEnv("STORAGE_PUBLIC_BASE_URL", "/uploads"),
+			S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+			S3Region:       getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:       getEnv("S3_BUCKET", ""),
+			S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+			S3UsePathStyle: s3PathStyle,
+			SigningSecret:  getEnv("STORAGE_SIGNING_SECRET", "your-super-secret-storage-signing-key"),
+			MaxImageBytes:  maxImageBytes,
+			MaxDocBytes:    maxDocBytes,
+			ImageWorkers:   imageWorkers,
+
+			PrivateLocalPath:      getEnv("STORAGE_PRIVATE_LOCAL_PATH", "./private-uploads"),
+			S3PrivateBucket:       getEnv("S3_PRIVATE_BUCKET", getEnv("S3_BUCKET", "")),
+			DocumentEncryptionKey: getEnv("DOCUMENT_ENCRYPTION_KEY", ""),
+		},
+		Verification: VerificationConfig{
+			DocumentRetentionDays: documentRetentionDays,
+			AutoRejectDuplicates:  autoRejectDuplicates,
+			ResubmitCooldownDays:  resubmitCooldownDays,
+			ValidityMonths:        getEnvAsIntMap("VERIFICATION_VALIDITY_MONTHS", "passport:24,driver_license:24"),
+			ExpiryReminderDays:    expiryReminderDays,
+			ClaimLeaseMinutes:     claimLeaseMinutes,
+		},
+		Encryption: EncryptionConfig{
+			PIIKeys:              getEnv("PII_ENCRYPTION_KEYS", ""),
+			PIICurrentKeyVersion: piiCurrentKeyVersion,
+			BlindIndexKey:        getEnv("PII_BLIND_INDEX_KEY", ""),
+			AllowDevelopmentKeys: allowDevelopmentKeys,
+		},
+		Edit: EditConfig{
+			PostWindowMinutes:        postEditWindowMinutes,
+			EngagedPostWindowMinutes: engagedPostEditWindowMinutes,
+			EngagementThreshold:      editEngagementThreshold,
+			CommentWindowMinutes:     commentEditWindowMinutes,
+		},
+		Retention: RetentionConfig{
+			RestoreWindowDays:  restoreWindowDays,
+			DeletedContentDays: deletedContentDays,
+		},
+		Account: AccountConfig{
+			ReactivationDays:     reactivationDays,
+			UsernameCooldownDays: usernameCooldownDays,
+			StatusCacheSeconds:   statusCacheSeconds,
+		},
+		Export: ExportConfig{
+			CooldownHours: exportCooldownHours,
+			LinkTTLHours:  exportLinkTTLHours,
+			PublicBaseURL: getEnv("API_PUBLIC_BASE_URL", "https://api.vietick.com"),
+		},
+		LinkPreview: LinkPreviewConfig{
+			Workers:        linkPreviewWorkers,
+			TimeoutSeconds: linkPreviewTimeoutSeconds,
+			MaxRedirects:   linkPreviewMaxRedirects,
+			MaxBodyBytes:   linkPreviewMaxBytes,
+			CacheTTLHours:  linkPreviewCacheTTLHours,
+		},
+		Analytics: AnalyticsConfig{
+			DedupWindowMinutes:   analyticsDedupWindowMinutes,
+			FlushIntervalSeconds: analyticsFlushIntervalSeconds,
+		},
 	}
 }
 
@@ -133,6 +338,24 @@ func getEnvAsSlice(key string, defaultVal []string, separator string) []string {
 	return strings.Split(valStr, separator)
 }
 
+// getEnvAsIntMap parses "key:value,key:value" pairs, skipping malformed entries
+func getEnvAsIntMap(key, defaultVal string) map[string]int {
+	result := make(map[string]int)
+	for _, pair := range strings.Split(getEnv(key, defaultVal), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("Ignoring invalid %s entry: %q", key, pair)
+			continue
+		}
+		result[name] = n
+	}
+	return result
+}
+
 func (c *Config) GetRedisOptions() *redis.Options {
 	return &redis.Options{
 		Addr:     c.Redis.Addr,
@@ -0,0 +1,127 @@
+package contentpolicy
+
+// contentpolicy – bộ lọc nội dung tự động chạy trước khi lưu bài viết và bình luận.
+// Mỗi loại quy tắc đăng ký một Factory; thêm loại mới chỉ cần gọi Register.
+
+import (
+	"fmt"
+	"sync"
+
+	"vietick-backend/internal/model"
+)
+
+// Content is the text being checked together with who wrote it
+type Content struct {
+	AuthorID string
+	Text     string
+
+	// folded is the diacritic-insensitive, lowercased form of Text
+	folded string
+}
+
+// Rule is a single compiled check. Match returns a short human-readable reason when the content violates it.
+type Rule interface {
+	Match(c *Content) (bool, string)
+}
+
+// Factory compiles an admin-defined rule into a Rule, rejecting invalid definitions
+type Factory func(rule *model.ContentRule, history *History) (Rule, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[model.ContentRuleType]Factory{}
+)
+
+// Register makes a rule type available to the pipeline
+func Register(ruleType model.ContentRuleType, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[ruleType] = factory
+}
+
+// Compile builds a single rule, used to validate definitions before they are saved
+func Compile(rule *model.ContentRule, history *History) (Rule, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[rule.Type]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported rule type: %s", rule.Type)
+	}
+	return factory(rule, history)
+}
+
+// Higher wins when several rules match the same content
+var actionSeverity = map[model.ContentRuleAction]int{
+	model.ContentRuleShadowHide: 1,
+	model.ContentRuleHold:       2,
+	model.ContentRuleReject:     3,
+}
+
+type compiledRule struct {
+	def  model.ContentRule
+	rule Rule
+}
+
+// Pipeline evaluates content against every active rule
+type Pipeline struct {
+	rules   []compiledRule
+	history *History
+	// Content is only remembered while a repeated-content rule is active
+	recordHistory bool
+}
+
+// NewPipeline compiles the given rules. Rules that fail to compile are skipped and reported.
+func NewPipeline(rules []model.ContentRule, history *History) (*Pipeline, []error) {
+	p := &Pipeline{history: history}
+	var errs []error
+	for i := range rules {
+		if !rules[i].IsActive {
+			continue
+		}
+		compiled, err := Compile(&rules[i], history)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", rules[i].ID, err))
+			continue
+		}
+		p.rules = append(p.rules, compiledRule{def: rules[i], rule: compiled})
+		if rules[i].Type == model.ContentRuleRepeatedContent {
+			p.recordHistory = true
+		}
+	}
+	return p, errs
+}
+
+// Len returns the number of active compiled rules
+func (p *Pipeline) Len() int {
+	return len(p.rules)
+}
+
+// Evaluate runs every rule and returns the most severe decision, or nil when the content is allowed
+func (p *Pipeline) Evaluate(authorID, text string) *model.ContentDecision {
+	c := &Content{AuthorID: authorID, Text: text}
+	c.folded = fold(text)
+
+	var decision *model.ContentDecision
+	for _, r := range p.rules {
+		matched, reason := r.rule.Match(c)
+		if !matched {
+			continue
+		}
+		if decision == nil || actionSeverity[r.def.Action] > actionSeverity[decision.Action] {
+			decision = &model.ContentDecision{
+				Action:   r.def.Action,
+				RuleID:   r.def.ID,
+				RuleType: r.def.Type,
+				Reason:   reason,
+			}
+		}
+	}
+	return decision
+}
+
+// Record remembers accepted content for the repeated-content heuristic
+func (p *Pipeline) Record(authorID, text string) {
+	if p.history != nil && p.recordHistory && authorID != "" {
+		p.history.Add(authorID, fold(text))
+	}
+}
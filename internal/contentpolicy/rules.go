@@ -0,0 +1,242 @@
+package contentpolicy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
+)
+
+func init() {
+	Register(model.ContentRuleKeyword, newKeywordRule)
+	Register(model.ContentRuleRegex, newRegexRule)
+	Register(model.ContentRuleDomain, newDomainRule)
+	Register(model.ContentRuleRepeatedContent, newRepeatedContentRule)
+	Register(model.ContentRuleLinkDensity, newLinkDensityRule)
+}
+
+var (
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+	urlRegex        = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+)
+
+// fold normalises text for matching: no diacritics, lowercase, single spaces
+func fold(text string) string {
+	return strings.TrimSpace(whitespaceRegex.ReplaceAllString(utils.FoldVietnamese(text), " "))
+}
+
+// ExtractURLs returns every http(s):// or www. link in the text
+func ExtractURLs(text string) []string {
+	return urlRegex.FindAllString(text, -1)
+}
+
+// keyword: a comma-separated list of banned words or phrases, matched on word boundaries
+// ignoring case and Vietnamese diacritics ("đánh bạc" also matches "danh bac")
+type keywordRule struct {
+	pattern *regexp.Regexp
+}
+
+func newKeywordRule(rule *model.ContentRule, _ *History) (Rule, error) {
+	var alternatives []string
+	for _, word := range strings.Split(rule.Pattern, ",") {
+		word = fold(word)
+		if word == "" {
+			continue
+		}
+		alternatives = append(alternatives, regexp.QuoteMeta(word))
+	}
+	if len(alternatives) == 0 {
+		return nil, fmt.Errorf("validation failed: keyword rule needs at least one word")
+	}
+	pattern, err := regexp.Compile(`(?:^|[^\pL\pN])(?:` + strings.Join(alternatives, "|") + `)(?:$|[^\pL\pN])`)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return &keywordRule{pattern: pattern}, nil
+}
+
+func (r *keywordRule) Match(c *Content) (bool, string) {
+	if match := r.pattern.FindString(c.folded); match != "" {
+		return true, fmt.Sprintf("contains banned word %q", strings.Trim(match, " \t\n.,!?;:\"'()"))
+	}
+	return false, ""
+}
+
+// regex: an RE2 expression evaluated against both the original and the folded text
+type regexRule struct {
+	pattern *regexp.Regexp
+}
+
+func newRegexRule(rule *model.ContentRule, _ *History) (Rule, error) {
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return nil, fmt.Errorf("validation failed: regex rule needs a pattern")
+	}
+	pattern, err := regexp.Compile("(?i)" + rule.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: invalid regular expression: %w", err)
+	}
+	return &regexRule{pattern: pattern}, nil
+}
+
+func (r *regexRule) Match(c *Content) (bool, string) {
+	if r.pattern.MatchString(c.Text) || r.pattern.MatchString(c.folded) {
+		return true, "matches a blocked pattern"
+	}
+	return false, ""
+}
+
+// domain: a comma-separated blocklist of hosts. Subdomains are blocked too, and the
+// domain is caught with or without a scheme ("spam.vn", "www.spam.vn/x", "https://a.spam.vn").
+type domainRule struct {
+	pattern *regexp.Regexp
+}
+
+func newDomainRule(rule *model.ContentRule, _ *History) (Rule, error) {
+	var alternatives []string
+	for _, domain := range strings.Split(rule.Pattern, ",") {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+		domain = strings.TrimPrefix(strings.TrimSuffix(domain, "/"), "www.")
+		if domain == "" {
+			continue
+		}
+		if !strings.Contains(domain, ".") || strings.ContainsAny(domain, "/ ") {
+			return nil, fmt.Errorf("validation failed: invalid domain %q", domain)
+		}
+		alternatives = append(alternatives, regexp.QuoteMeta(domain))
+	}
+	if len(alternatives) == 0 {
+		return nil, fmt.Errorf("validation failed: domain rule needs at least one domain")
+	}
+	pattern, err := regexp.Compile(`(?i)(?:^|[^a-z0-9.-])(?:[a-z0-9-]+\.)*(` + strings.Join(alternatives, "|") + `)(?:$|[^a-z0-9-])`)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return &domainRule{pattern: pattern}, nil
+}
+
+func (r *domainRule) Match(c *Content) (bool, string) {
+	if m := r.pattern.FindStringSubmatch(c.Text); m != nil {
+		return true, fmt.Sprintf("links to blocked domain %s", strings.ToLower(m[1]))
+	}
+	return false, ""
+}
+
+// repeated_content: the same author posting the same text Threshold times within WindowSeconds
+type repeatedContentRule struct {
+	history   *History
+	threshold int
+	window    time.Duration
+}
+
+func newRepeatedContentRule(rule *model.ContentRule, history *History) (Rule, error) {
+	if history == nil {
+		return nil, fmt.Errorf("repeated content rule needs a history")
+	}
+	threshold := 3
+	if rule.Threshold != nil {
+		threshold = int(*rule.Threshold)
+	}
+	if threshold < 2 {
+		return nil, fmt.Errorf("validation failed: repeated content threshold must be at least 2")
+	}
+	window := time.Hour
+	if rule.WindowSeconds != nil {
+		window = time.Duration(*rule.WindowSeconds) * time.Second
+	}
+	if window > maxHistoryAge {
+		return nil, fmt.Errorf("validation failed: repeated content window cannot exceed %d seconds", int(maxHistoryAge.Seconds()))
+	}
+	return &repeatedContentRule{history: history, threshold: threshold, window: window}, nil
+}
+
+func (r *repeatedContentRule) Match(c *Content) (bool, string) {
+	// The current submission counts towards the threshold
+	previous := r.history.Count(c.AuthorID, c.folded, r.window)
+	if previous+1 >= r.threshold {
+		return true, fmt.Sprintf("same content posted %d times in %s", previous+1, r.window)
+	}
+	return false, ""
+}
+
+// link_density: at least two links making up Threshold (0-1) or more of the words
+type linkDensityRule struct {
+	threshold float64
+}
+
+func newLinkDensityRule(rule *model.ContentRule, _ *History) (Rule, error) {
+	threshold := 0.5
+	if rule.Threshold != nil {
+		threshold = *rule.Threshold
+	}
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("validation failed: link density threshold must be between 0 and 1")
+	}
+	return &linkDensityRule{threshold: threshold}, nil
+}
+
+func (r *linkDensityRule) Match(c *Content) (bool, string) {
+	links := len(ExtractURLs(c.Text))
+	if links < 2 {
+		return false, ""
+	}
+	words := len(strings.Fields(c.Text))
+	density := float64(links) / float64(words)
+	if density >= r.threshold {
+		return true, fmt.Sprintf("too many links (%d of %d words)", links, words)
+	}
+	return false, ""
+}
+
+// HistoryStore persists recent submissions, so that every instance sees the same history
+type HistoryStore interface {
+	RecordSubmission(authorID, fingerprint string, at time.Time) error
+	CountSubmissions(authorID, fingerprint string, since time.Time) (int64, error)
+	PruneSubmissions(before time.Time) error
+}
+
+// History keeps recent submissions per author for the repeated-content heuristic. Texts are
+// stored as a hash of their folded form.
+type History struct {
+	store HistoryStore
+}
+
+const maxHistoryAge = 24 * time.Hour
+
+func NewHistory(store HistoryStore) *History {
+	return &History{store: store}
+}
+
+func fingerprint(folded string) string {
+	sum := sha256.Sum256([]byte(folded))
+	return hex.EncodeToString(sum[:])
+}
+
+func (h *History) Add(authorID, folded string) {
+	if err := h.store.RecordSubmission(authorID, fingerprint(folded), time.Now()); err != nil {
+		fmt.Printf("Failed to record content history: %v\n", err)
+	}
+}
+
+// Count returns how many times the author submitted the text within the window. When the
+// history cannot be read the content is let through rather than held.
+func (h *History) Count(authorID, folded string, window time.Duration) int {
+	count, err := h.store.CountSubmissions(authorID, fingerprint(folded), time.Now().Add(-window))
+	if err != nil {
+		fmt.Printf("Failed to read content history: %v\n", err)
+		return 0
+	}
+	return int(count)
+}
+
+// Prune drops entries older than the longest supported window
+func (h *History) Prune() {
+	if err := h.store.PruneSubmissions(time.Now().Add(-maxHistoryAge)); err != nil {
+		fmt.Printf("Failed to prune content history: %v\n", err)
+	}
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/service"
+	"vietick-backend/internal/utils"
+)
+
+type BookmarkHandler struct {
+	bookmarkService *service.BookmarkService
+}
+
+func NewBookmarkHandler(bookmarkService *service.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkService: bookmarkService,
+	}
+}
+
+// BookmarkPost godoc
+// @Summary Bookmark a post
+// @Description Save a post for later, optionally in a collection. Bookmarking a saved post again moves it to the given collection. Bookmarks are private and the author is not notified.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param request body model.BookmarkPostRequest false "Collection"
+// @Success 200 {object} model.Bookmark
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/bookmark [post]
+func (h *BookmarkHandler) BookmarkPost(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// The body is optional: without it the post is saved as unsorted
+	var req model.BookmarkPostRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			middleware.HandleError(c, err)
+			return
+		}
+	}
+
+	bookmark, err := h.bookmarkService.BookmarkPost(userID, c.Param("id"), &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// RemoveBookmark godoc
+// @Summary Remove a bookmark
+// @Description Remove a post from the current user's bookmarks
+// @Tags bookmarks
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/bookmark [delete]
+func (h *BookmarkHandler) RemoveBookmark(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.bookmarkService.RemoveBookmark(userID, c.Param("id")); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bookmark removed successfully"})
+}
+
+// GetBookmarks godoc
+// @Summary List my bookmarks
+// @Description List the current user's bookmarks, newest first. Posts that were deleted or are no longer visible are left out.
+// @Tags bookmarks
+// @Produce json
+// @Param collection_id query string false "Only bookmarks in this collection"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.BookmarksResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /bookmarks [get]
+func (h *BookmarkHandler) GetBookmarks(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var filter model.BookmarksFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.bookmarkService.GetBookmarks(userID, &filter, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCollections godoc
+// @Summary List my bookmark collections
+// @Description List the current user's bookmark collections by name, with the number of bookmarks in each
+// @Tags bookmarks
+// @Produce json
+// @Success 200 {array} model.BookmarkCollection
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /bookmarks/collections [get]
+func (h *BookmarkHandler) GetCollections(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	collections, err := h.bookmarkService.GetCollections(userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+// CreateCollection godoc
+// @Summary Create a bookmark collection
+// @Description Create a named collection to sort bookmarks into
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param request body model.SaveBookmarkCollectionRequest true "Collection"
+// @Success 201 {object} model.BookmarkCollection
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /bookmarks/collections [post]
+func (h *BookmarkHandler) CreateCollection(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.SaveBookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	collection, err := h.bookmarkService.CreateCollection(userID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// RenameCollection godoc
+// @Summary Rename a bookmark collection
+// @Description Rename one of the current user's bookmark collections
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body model.SaveBookmarkCollectionRequest true "Collection"
+// @Success 200 {object} model.BookmarkCollection
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /bookmarks/collections/{id} [put]
+func (h *BookmarkHandler) RenameCollection(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.SaveBookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	collection, err := h.bookmarkService.RenameCollection(userID, c.Param("id"), &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection godoc
+// @Summary Delete a bookmark collection
+// @Description Delete one of the current user's bookmark collections. Its bookmarks are kept as unsorted bookmarks.
+// @Tags bookmarks
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /bookmarks/collections/{id} [delete]
+func (h *BookmarkHandler) DeleteCollection(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.bookmarkService.DeleteCollection(userID, c.Param("id")); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
@@ -57,7 +57,7 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.commentService.CreateComment(userID, postID, &req)
+	comment, err := h.commentService.CreateComment(userID, postID, middleware.GetActingUserID(c), &req)
 	if err != nil {
 		middleware.HandleError(c, err)
 		return
@@ -97,6 +97,26 @@ func (h *CommentHandler) GetComment(c *gin.Context) {
 	c.JSON(http.StatusOK, comment)
 }
 
+// GetCommentHistory godoc
+// @Summary Get comment edit history
+// @Description Get every version of a comment, the current one first
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} model.CommentHistoryResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /comments/{id}/history [get]
+func (h *CommentHandler) GetCommentHistory(c *gin.Context) {
+	history, err := h.commentService.GetCommentHistory(c.Param("id"), middleware.GetUserIDPtr(c))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // GetPostComments godoc
 // @Summary Get post comments
 // @Description Get comments for a post
@@ -213,7 +233,7 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	err := h.commentService.DeleteComment(commentID, userID)
+	err := h.commentService.DeleteComment(commentID, userID, middleware.GetActingUserID(c))
 	if err != nil {
 		middleware.HandleError(c, err)
 		return
@@ -224,6 +244,68 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	})
 }
 
+// GetDeletedComments godoc
+// @Summary List my deleted comments
+// @Description List the comments the current user deleted and can still restore, most recently deleted first
+// @Tags comments
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.DeletedCommentsResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /comments/deleted [get]
+func (h *CommentHandler) GetDeletedComments(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.commentService.GetDeletedComments(userID, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreComment godoc
+// @Summary Restore a deleted comment
+// @Description Restore a comment the current user deleted. The post must still exist; comments removed by a moderator cannot be restored.
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} model.Comment
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /comments/{id}/restore [post]
+func (h *CommentHandler) RestoreComment(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	comment, err := h.commentService.RestoreComment(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
 // LikeComment godoc
 // @Summary Like a comment
 // @Description Like a comment
@@ -352,6 +434,105 @@ func (h *CommentHandler) ToggleLike(c *gin.Context) {
 	})
 }
 
+// React godoc
+// @Summary React to a comment
+// @Description Set the current user's reaction to a comment: like, love, haha, wow, sad or angry. Reacting again with another type replaces the previous reaction.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param request body model.ReactRequest true "Reaction"
+// @Success 200 {object} model.ReactionResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /comments/{id}/reactions [post]
+func (h *CommentHandler) React(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.ReactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.commentService.React(c.Param("id"), userID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveReaction godoc
+// @Summary Remove my reaction
+// @Description Remove the current user's reaction to a comment, whatever its type
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} model.ReactionResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /comments/{id}/reactions [delete]
+func (h *CommentHandler) RemoveReaction(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	response, err := h.commentService.RemoveReaction(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetReactions godoc
+// @Summary List reactions
+// @Description List who reacted to a comment, newest first, with the number of reactions of each type
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param type query string false "Only this reaction" Enums(like,love,haha,wow,sad,angry)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.ReactionsResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /comments/{id}/reactions [get]
+func (h *CommentHandler) GetReactions(c *gin.Context) {
+	var filter model.ReactionsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.commentService.GetReactions(c.Param("id"), middleware.GetUserIDPtr(c), &filter, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // GetCommentStats godoc
 // @Summary Get comment statistics
 // @Description Get statistics for a comment
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/service"
+)
+
+type ContentRuleHandler struct {
+	contentPolicyService *service.ContentPolicyService
+}
+
+func NewContentRuleHandler(contentPolicyService *service.ContentPolicyService) *ContentRuleHandler {
+	return &ContentRuleHandler{
+		contentPolicyService: contentPolicyService,
+	}
+}
+
+// GetRules godoc
+// @Summary Get content rules
+// @Description Get every automatic moderation rule, active or not (admin only)
+// @Tags moderation
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/rules [get]
+func (h *ContentRuleHandler) GetRules(c *gin.Context) {
+	rules, err := h.contentPolicyService.GetRules()
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// GetRule godoc
+// @Summary Get a content rule
+// @Description Get a single automatic moderation rule (admin only)
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} model.ContentRule
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/rules/{id} [get]
+func (h *ContentRuleHandler) GetRule(c *gin.Context) {
+	rule, err := h.contentPolicyService.GetRule(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRule godoc
+// @Summary Create a content rule
+// @Description Add a keyword, regex, domain, repeated content or link density rule. It applies immediately. (admin only)
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param request body model.CreateContentRuleRequest true "Rule data"
+// @Success 201 {object} model.ContentRule
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/rules [post]
+func (h *ContentRuleHandler) CreateRule(c *gin.Context) {
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.CreateContentRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	rule, err := h.contentPolicyService.CreateRule(adminID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule godoc
+// @Summary Update a content rule
+// @Description Change a rule's pattern, thresholds, action or active flag. It applies immediately. (admin only)
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body model.UpdateContentRuleRequest true "Rule data"
+// @Success 200 {object} model.ContentRule
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/rules/{id} [put]
+func (h *ContentRuleHandler) UpdateRule(c *gin.Context) {
+	var req model.UpdateContentRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	rule, err := h.contentPolicyService.UpdateRule(c.Param("id"), &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule godoc
+// @Summary Delete a content rule
+// @Description Remove an automatic moderation rule (admin only)
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/rules/{id} [delete]
+func (h *ContentRuleHandler) DeleteRule(c *gin.Context) {
+	if err := h.contentPolicyService.DeleteRule(c.Param("id")); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Content rule deleted successfully"})
+}
+
+// TestContent godoc
+// @Summary Test content against the rules
+// @Description Show what the active rules would do with a piece of text, without saving anything (admin only)
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param request body model.TestContentRequest true "Content to test"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/rules/test [post]
+func (h *ContentRuleHandler) TestContent(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req model.TestContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	decision := h.contentPolicyService.TestContent(adminID, req.Content)
+	c.JSON(http.StatusOK, gin.H{
+		"allowed":  decision == nil || decision.Action != model.ContentRuleReject,
+		"decision": decision,
+	})
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/service"
+	"vietick-backend/internal/utils"
+)
+
+type DraftHandler struct {
+	draftService *service.DraftService
+}
+
+func NewDraftHandler(draftService *service.DraftService) *DraftHandler {
+	return &DraftHandler{
+		draftService: draftService,
+	}
+}
+
+// CreateDraft godoc
+// @Summary Save a draft
+// @Description Save a post without publishing it. With publish_at the draft is scheduled and published automatically at that time.
+// @Tags drafts
+// @Accept json
+// @Produce json
+// @Param request body model.SaveDraftRequest true "Draft data"
+// @Success 201 {object} model.PostDraft
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/drafts [post]
+func (h *DraftHandler) CreateDraft(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	draft, err := h.draftService.CreateDraft(userID, middleware.GetActingUserID(c), &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, draft)
+}
+
+// GetDrafts godoc
+// @Summary List my drafts
+// @Description List drafts and scheduled posts of the current user, scheduled ones first by publication time. Published drafts are only listed with status=published.
+// @Tags drafts
+// @Produce json
+// @Param status query string false "Status filter" Enums(draft,scheduled,publishing,published,failed)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.PostDraftsResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/drafts [get]
+func (h *DraftHandler) GetDrafts(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var filter model.DraftsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.draftService.GetDrafts(userID, &filter, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDraft godoc
+// @Summary Get a draft
+// @Description Get one of the current user's drafts
+// @Tags drafts
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 200 {object} model.PostDraft
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/drafts/{id} [get]
+func (h *DraftHandler) GetDraft(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	draft, err := h.draftService.GetDraft(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// UpdateDraft godoc
+// @Summary Update a draft
+// @Description Replace the content of a draft. Setting publish_at schedules it, leaving it out unschedules it.
+// @Tags drafts
+// @Accept json
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Param request body model.SaveDraftRequest true "Draft data"
+// @Success 200 {object} model.PostDraft
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/drafts/{id} [put]
+func (h *DraftHandler) UpdateDraft(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	draft, err := h.draftService.UpdateDraft(c.Param("id"), userID, middleware.GetActingUserID(c), &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// ScheduleDraft godoc
+// @Summary Schedule a draft
+// @Description Schedule a draft for publication, or move a scheduled or failed one to a new time
+// @Tags drafts
+// @Accept json
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Param request body model.ScheduleDraftRequest true "Publication time"
+// @Success 200 {object} model.PostDraft
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/drafts/{id}/schedule [post]
+func (h *DraftHandler) ScheduleDraft(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.ScheduleDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	draft, err := h.draftService.ScheduleDraft(c.Param("id"), userID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// CancelSchedule godoc
+// @Summary Unschedule a draft
+// @Description Turn a scheduled draft back into a plain draft
+// @Tags drafts
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 200 {object} model.PostDraft
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/drafts/{id}/cancel [post]
+func (h *DraftHandler) CancelSchedule(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	draft, err := h.draftService.CancelSchedule(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// PublishDraft godoc
+// @Summary Publish a draft now
+// @Description Publish a draft right away. The post gets the draft's ID.
+// @Tags drafts
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 201 {object} model.Post
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/drafts/{id}/publish [post]
+func (h *DraftHandler) PublishDraft(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	post, err := h.draftService.PublishDraft(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, post)
+}
+
+// DeleteDraft godoc
+// @Summary Discard a draft
+// @Description Delete a draft or cancel and delete a scheduled post
+// @Tags drafts
+// @Produce json
+// @Param id path string true "Draft ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/drafts/{id} [delete]
+func (h *DraftHandler) DeleteDraft(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.draftService.DeleteDraft(c.Param("id"), userID); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Draft deleted successfully"})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/service"
+	"vietick-backend/internal/utils"
+)
+
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RequestExport godoc
+// @Summary Request a copy of my data
+// @Description Start building a ZIP archive of the current user's profile, posts with images, comments, likes, followers, following, sessions and verification history. A download link is emailed when it is ready. One export at a time, with a cooldown between requests.
+// @Tags users
+// @Produce json
+// @Success 202 {object} model.DataExport
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 429 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /users/me/export [post]
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	export, err := h.exportService.RequestExport(userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, export)
+}
+
+// GetExports godoc
+// @Summary List my data exports
+// @Description List the data exports of the current user, newest first. Ready exports include a signed download_url.
+// @Tags users
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.DataExportsResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /users/me/exports [get]
+func (h *ExportHandler) GetExports(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.exportService.GetExports(userID, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetExport godoc
+// @Summary Get a data export
+// @Description Get the status of a data export of the current user
+// @Tags users
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} model.DataExport
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /users/me/exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	export, err := h.exportService.GetExport(userID, c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+// DownloadExport godoc
+// @Summary Download a data archive
+// @Description Download the ZIP archive of a data export with the signed link from the email or the export status
+// @Tags users
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Param expires query string true "Link expiry (unix time)"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Router /exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	export, reader, err := h.exportService.OpenDownload(c.Param("id"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Cache-Control", "no-store")
+	c.DataFromReader(http.StatusOK, *export.SizeBytes, "application/zip", reader, map[string]string{
+		"Content-Disposition":    fmt.Sprintf(`attachment; filename="vietick-data-%s.zip"`, export.CreatedAt.UTC().Format("2006-01-02")),
+		"X-Content-Type-Options": "nosniff",
+		"Referrer-Policy":        "no-referrer",
+	})
+}
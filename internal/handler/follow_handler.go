@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
 	"vietick-backend/internal/service"
 	"vietick-backend/internal/utils"
 )
@@ -387,6 +388,40 @@ func (h *FollowHandler) GetFollowStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetFollowerAnalytics godoc
+// @Summary Get my follower analytics
+// @Description Daily follower growth (follows, unfollows, followers and verified followers), churn, the share of verified followers and the followers who reacted to and commented on my posts the most. Computed every night: days run up to yesterday (UTC), 30 by default and at most 365.
+// @Tags follows
+// @Produce json
+// @Param from query string false "First day, YYYY-MM-DD (UTC)"
+// @Param to query string false "Last day, YYYY-MM-DD (UTC)"
+// @Success 200 {object} model.FollowerAnalytics
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /users/me/follower-analytics [get]
+func (h *FollowHandler) GetFollowerAnalytics(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var filter model.FollowerAnalyticsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	analytics, err := h.followService.GetFollowerAnalytics(userID, &filter)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
 // BulkFollow godoc
 // @Summary Bulk follow users
 // @Description Follow multiple users at once
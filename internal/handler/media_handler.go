@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/service"
+)
+
+// Room for the multipart envelope around the file itself
+const multipartOverhead = 1 << 20
+
+type MediaHandler struct {
+	mediaService *service.MediaService
+}
+
+func NewMediaHandler(mediaService *service.MediaService) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+	}
+}
+
+// Upload godoc
+// @Summary Upload media
+// @Description Upload an image (or a PDF for identity documents) as multipart form data. The file type is detected from its content. Use the returned ID in posts, profiles and verification requests.
+// @Tags media
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File"
+// @Param purpose formData string true "post, avatar or identity_document"
+// @Success 201 {object} model.Media
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 413 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /media [post]
+func (h *MediaHandler) Upload(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// The purpose is only known once the form is parsed, so cap the body at the largest limit;
+	// the service then applies the limit for the purpose
+	maxSize := h.mediaService.MaxUploadSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if c.Request.ContentLength > maxSize+multipartOverhead {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File is larger than %d bytes", maxSize)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+		return
+	}
+	purpose := model.MediaPurpose(c.PostForm("purpose"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+	defer file.Close()
+
+	media, err := h.mediaService.Upload(userID, purpose, file, fileHeader.Size)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+// Presign godoc
+// @Summary Start a direct upload
+// @Description Reserve a media ID and get a URL to PUT the file to. Call complete once the upload has finished.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param request body model.PresignMediaRequest true "Upload data"
+// @Success 201 {object} model.PresignMediaResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /media/presign [post]
+func (h *MediaHandler) Presign(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.PresignMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.mediaService.Presign(userID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ReceiveUpload godoc
+// @Summary Upload the body of a direct upload
+// @Description Target of upload URLs signed by the API when storage does not support presigned URLs. Authorized by the signature in the URL.
+// @Tags media
+// @Accept application/octet-stream
+// @Param id path string true "Media ID"
+// @Param expires query string true "Expiry (unix seconds)"
+// @Param signature query string true "Signature"
+// @Success 204
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Router /media/{id}/upload [put]
+func (h *MediaHandler) ReceiveUpload(c *gin.Context) {
+	if c.Request.ContentLength <= 0 {
+		c.JSON(http.StatusLengthRequired, gin.H{"error": "Content-Length is required"})
+		return
+	}
+
+	err := h.mediaService.ReceiveUpload(c.Param("id"), c.Query("expires"), c.Query("signature"),
+		c.Request.Body, c.Request.ContentLength)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Complete godoc
+// @Summary Finish a direct upload
+// @Description Check the uploaded file (size and detected type) and make the media usable
+// @Tags media
+// @Produce json
+// @Param id path string true "Media ID"
+// @Success 200 {object} model.Media
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /media/{id}/complete [post]
+func (h *MediaHandler) Complete(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	media, err := h.mediaService.Complete(userID, c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, media)
+}
+
+// GetMedia godoc
+// @Summary Get media
+// @Description Get one of your own uploads
+// @Tags media
+// @Produce json
+// @Param id path string true "Media ID"
+// @Success 200 {object} model.Media
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /media/{id} [get]
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	media, err := h.mediaService.GetMedia(userID, c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, media)
+}
+
+// DeleteMedia godoc
+// @Summary Delete media
+// @Description Delete one of your own uploads that is not attached to a post, profile or verification request
+// @Tags media
+// @Produce json
+// @Param id path string true "Media ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /media/{id} [delete]
+func (h *MediaHandler) DeleteMedia(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.mediaService.DeleteMedia(userID, c.Param("id")); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Media deleted successfully"})
+}
@@ -0,0 +1,433 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/service"
+	"vietick-backend/internal/utils"
+)
+
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// CreateReport godoc
+// @Summary Report content
+// @Description Report a post, comment, user or message for moderator review
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param request body model.CreateReportRequest true "Report data"
+// @Success 201 {object} model.Report
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /reports [post]
+func (h *ModerationHandler) CreateReport(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	report, err := h.moderationService.CreateReport(userID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// GetPendingReports godoc
+// @Summary Get moderation queue
+// @Description Get pending reports, oldest first (admin only)
+// @Tags moderation
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.ReportsResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/reports/pending [get]
+func (h *ModerationHandler) GetPendingReports(c *gin.Context) {
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.moderationService.GetPendingReports(&pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAllReports godoc
+// @Summary Get all reports
+// @Description Get all reports with optional status and target filters (admin only)
+// @Tags moderation
+// @Produce json
+// @Param status query string false "Status filter" Enums(pending,actioned,dismissed)
+// @Param target_type query string false "Target filter" Enums(post,comment,user,message)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.ReportsResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/reports [get]
+func (h *ModerationHandler) GetAllReports(c *gin.Context) {
+	var status *model.ReportStatus
+	if statusStr := c.Query("status"); statusStr != "" {
+		s := model.ReportStatus(statusStr)
+		status = &s
+	}
+
+	var targetType *model.ReportTarget
+	if targetStr := c.Query("target_type"); targetStr != "" {
+		t := model.ReportTarget(targetStr)
+		targetType = &t
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.moderationService.GetAllReports(status, targetType, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetReport godoc
+// @Summary Get report by ID
+// @Description Get report details by ID (admin only)
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Report ID"
+// @Success 200 {object} model.Report
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/reports/{id} [get]
+func (h *ModerationHandler) GetReport(c *gin.Context) {
+	reportID := c.Param("id")
+	if reportID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+
+	report, err := h.moderationService.GetReport(reportID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// TakeAction godoc
+// @Summary Act on a report
+// @Description Hide, delete, warn, suspend or dismiss (admin only)
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param request body model.ModerationActionRequest true "Moderator action"
+// @Success 200 {object} model.Report
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/reports/{id}/action [post]
+func (h *ModerationHandler) TakeAction(c *gin.Context) {
+	moderatorID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	reportID := c.Param("id")
+	if reportID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+
+	var req model.ModerationActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	report, err := h.moderationService.TakeAction(reportID, moderatorID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetDeletedPosts godoc
+// @Summary List deleted posts
+// @Description List deleted posts kept until they are purged, most recently deleted first (admin only)
+// @Tags moderation
+// @Produce json
+// @Param source query string false "Who deleted them" Enums(author,moderation)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.DeletedPostsResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/deleted/posts [get]
+func (h *ModerationHandler) GetDeletedPosts(c *gin.Context) {
+	var filter model.DeletedContentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.moderationService.GetDeletedPosts(&filter, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDeletedPost godoc
+// @Summary Get a deleted post
+// @Description Get a deleted post that has not been purged yet (admin only)
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} model.DeletedPost
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/deleted/posts/{id} [get]
+func (h *ModerationHandler) GetDeletedPost(c *gin.Context) {
+	post, err := h.moderationService.GetDeletedPost(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, post)
+}
+
+// RestorePost godoc
+// @Summary Restore a deleted post
+// @Description Restore a post deleted by its author or a moderator, until it is purged (admin only)
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} model.Post
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/deleted/posts/{id}/restore [post]
+func (h *ModerationHandler) RestorePost(c *gin.Context) {
+	moderatorID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	post, err := h.moderationService.RestorePost(c.Param("id"), moderatorID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, post)
+}
+
+// GetDeletedComments godoc
+// @Summary List deleted comments
+// @Description List deleted comments kept until they are purged, most recently deleted first (admin only)
+// @Tags moderation
+// @Produce json
+// @Param source query string false "Who deleted them" Enums(author,moderation)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.DeletedCommentsResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/deleted/comments [get]
+func (h *ModerationHandler) GetDeletedComments(c *gin.Context) {
+	var filter model.DeletedContentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.moderationService.GetDeletedComments(&filter, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDeletedComment godoc
+// @Summary Get a deleted comment
+// @Description Get a deleted comment that has not been purged yet (admin only)
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} model.DeletedComment
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/deleted/comments/{id} [get]
+func (h *ModerationHandler) GetDeletedComment(c *gin.Context) {
+	comment, err := h.moderationService.GetDeletedComment(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// RestoreComment godoc
+// @Summary Restore a deleted comment
+// @Description Restore a comment deleted by its author or a moderator, until it is purged. Its post must not be deleted (admin only).
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} model.Comment
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/deleted/comments/{id}/restore [post]
+func (h *ModerationHandler) RestoreComment(c *gin.Context) {
+	moderatorID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	comment, err := h.moderationService.RestoreComment(c.Param("id"), moderatorID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// GetModerationStats godoc
+// @Summary Get moderation statistics
+// @Description Get report queue statistics (admin only)
+// @Tags moderation
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/stats [get]
+func (h *ModerationHandler) GetModerationStats(c *gin.Context) {
+	stats, err := h.moderationService.GetModerationStats()
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetAuditTrail godoc
+// @Summary Get moderation audit trail
+// @Description Get moderator actions, optionally for one target (admin only)
+// @Tags moderation
+// @Produce json
+// @Param target_type query string false "Target filter" Enums(post,comment,user,message)
+// @Param target_id query string false "Target ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.ModerationActionsResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /moderation/actions [get]
+func (h *ModerationHandler) GetAuditTrail(c *gin.Context) {
+	var targetType *model.ReportTarget
+	if targetStr := c.Query("target_type"); targetStr != "" {
+		t := model.ReportTarget(targetStr)
+		targetType = &t
+	}
+
+	var targetID *string
+	if id := c.Query("target_id"); id != "" {
+		targetID = &id
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.moderationService.GetActions(targetType, targetID, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
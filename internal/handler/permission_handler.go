@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/service"
+)
+
+type PermissionHandler struct {
+	permissionService *service.PermissionService
+}
+
+func NewPermissionHandler(permissionService *service.PermissionService) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
+
+// GetUserPermissions godoc
+// @Summary List a user's permissions
+// @Description List the permissions granted to a user (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} model.UserPermissionsResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/permissions [get]
+func (h *PermissionHandler) GetUserPermissions(c *gin.Context) {
+	response, err := h.permissionService.GetUserPermissions(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GrantPermission godoc
+// @Summary Grant a permission
+// @Description Grant a permission such as verification.documents.view to a user (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param permission path string true "Permission"
+// @Success 200 {object} model.UserPermissionsResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/permissions/{permission} [put]
+func (h *PermissionHandler) GrantPermission(c *gin.Context) {
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	permission := model.Permission(c.Param("permission"))
+	response, err := h.permissionService.GrantPermission(adminID, c.Param("id"), permission)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokePermission godoc
+// @Summary Revoke a permission
+// @Description Revoke a permission from a user (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param permission path string true "Permission"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/permissions/{permission} [delete]
+func (h *PermissionHandler) RevokePermission(c *gin.Context) {
+	permission := model.Permission(c.Param("permission"))
+	if err := h.permissionService.RevokePermission(c.Param("id"), permission); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Permission revoked successfully",
+	})
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/service"
+)
+
+type PollHandler struct {
+	pollService *service.PollService
+}
+
+func NewPollHandler(pollService *service.PollService) *PollHandler {
+	return &PollHandler{
+		pollService: pollService,
+	}
+}
+
+// GetPoll godoc
+// @Summary Get the poll of a post
+// @Description Get the poll attached to a post, with the current user's vote. While a poll with hidden results is open, vote counts are 0 and results_hidden is true.
+// @Tags polls
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} model.Poll
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/poll [get]
+func (h *PollHandler) GetPoll(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	poll, err := h.pollService.GetPoll(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+// Vote godoc
+// @Summary Vote in a poll
+// @Description Vote for one option, or several if the poll allows multiple choices. Voting again replaces the previous vote. Votes are accepted until the poll closes.
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param request body model.PollVoteRequest true "Chosen options"
+// @Success 200 {object} model.Poll
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/poll/vote [post]
+func (h *PollHandler) Vote(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.PollVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	poll, err := h.pollService.Vote(c.Param("id"), userID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
+
+// RemoveVote godoc
+// @Summary Remove my vote from a poll
+// @Description Withdraw the current user's vote while the poll is open
+// @Tags polls
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} model.Poll
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/poll/vote [delete]
+func (h *PollHandler) RemoveVote(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	poll, err := h.pollService.RemoveVote(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, poll)
+}
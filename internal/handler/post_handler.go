@@ -45,7 +45,7 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 		return
 	}
 
-	post, err := h.postService.CreatePost(userID, &req)
+	post, err := h.postService.CreatePost(userID, middleware.GetActingUserID(c), &req)
 	if err != nil {
 		middleware.HandleError(c, err)
 		return
@@ -99,6 +99,26 @@ func (h *PostHandler) GetPost(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// GetPostHistory godoc
+// @Summary Get post edit history
+// @Description Get every version of a post (content, images and hashtags), the current one first
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} model.PostHistoryResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/history [get]
+func (h *PostHandler) GetPostHistory(c *gin.Context) {
+	history, err := h.postService.GetPostHistory(c.Param("id"), middleware.GetUserIDPtr(c))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // UpdatePost godoc
 // @Summary Update a post
 // @Description Update a post (only by owner)
@@ -144,7 +164,7 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 
 // DeletePost godoc
 // @Summary Delete a post
-// @Description Delete a post (only by owner)
+// @Description Delete a post (only by owner). It can be restored for a limited time.
 // @Tags posts
 // @Produce json
 // @Param id path string true "Post ID"
@@ -168,7 +188,7 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 		return
 	}
 
-	err := h.postService.DeletePost(postID, userID)
+	err := h.postService.DeletePost(postID, userID, middleware.GetActingUserID(c))
 	if err != nil {
 		middleware.HandleError(c, err)
 		return
@@ -177,6 +197,68 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully"})
 }
 
+// GetDeletedPosts godoc
+// @Summary List my deleted posts
+// @Description List the posts the current user deleted and can still restore, most recently deleted first
+// @Tags posts
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.DeletedPostsResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/deleted [get]
+func (h *PostHandler) GetDeletedPosts(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.postService.GetDeletedPosts(userID, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RestorePost godoc
+// @Summary Restore a deleted post
+// @Description Restore a post the current user deleted, with its likes, comments and hashtags. Posts removed by a moderator cannot be restored.
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} model.Post
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/restore [post]
+func (h *PostHandler) RestorePost(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	post, err := h.postService.RestorePost(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, post)
+}
+
 // GetFeed godoc
 // @Summary Get user feed
 // @Description Get posts from followed users
@@ -370,6 +452,105 @@ func (h *PostHandler) ToggleLike(c *gin.Context) {
 	})
 }
 
+// React godoc
+// @Summary React to a post
+// @Description Set the current user's reaction to a post: like, love, haha, wow, sad or angry. Reacting again with another type replaces the previous reaction.
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param request body model.ReactRequest true "Reaction"
+// @Success 200 {object} model.ReactionResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/reactions [post]
+func (h *PostHandler) React(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.ReactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.postService.React(c.Param("id"), userID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveReaction godoc
+// @Summary Remove my reaction
+// @Description Remove the current user's reaction to a post, whatever its type
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Success 200 {object} model.ReactionResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/reactions [delete]
+func (h *PostHandler) RemoveReaction(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	response, err := h.postService.RemoveReaction(c.Param("id"), userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetReactions godoc
+// @Summary List reactions
+// @Description List who reacted to a post, newest first, with the number of reactions of each type
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param type query string false "Only this reaction" Enums(like,love,haha,wow,sad,angry)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.ReactionsResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/reactions [get]
+func (h *PostHandler) GetReactions(c *gin.Context) {
+	var filter model.ReactionsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.postService.GetReactions(c.Param("id"), middleware.GetUserIDPtr(c), &filter, &pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // GetExplorePosts godoc
 // @Summary Get explore posts
 // @Description Get posts for exploration/discovery
@@ -427,12 +608,50 @@ func (h *PostHandler) GetPostStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetPostAnalytics godoc
+// @Summary Get post analytics
+// @Description Impressions and views (each viewer counted once per window, split between followers of the author and others), reactions and comments of a post over time. Only the author can see them. Hourly series cover up to 7 days (default the last 2), daily series up to 90 (default the last 30). Impressions and views appear after a short delay.
+// @Tags posts
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param interval query string false "hour or day" default(day)
+// @Param from query string false "First day, YYYY-MM-DD (UTC)"
+// @Param to query string false "Last day, YYYY-MM-DD (UTC)"
+// @Success 200 {object} model.PostAnalytics
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /posts/{id}/analytics [get]
+func (h *PostHandler) GetPostAnalytics(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var filter model.PostAnalyticsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	analytics, err := h.postService.GetPostAnalytics(c.Param("id"), userID, &filter)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
 // SearchPosts godoc
 // @Summary Search posts
-// @Description Search posts by content, hashtag, username, full_name
+// @Description Full-text search ranked by relevance, ignoring Vietnamese diacritics. Supports "exact phrases", #hashtag, from:username, since:YYYY-MM-DD, until:YYYY-MM-DD and has:image
 // @Tags search
 // @Produce json
-// @Param query query string true "Search keyword"
+// @Param query query string true "Search query"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} model.PostsResponse
@@ -447,7 +666,7 @@ func (h *PostHandler) SearchPosts(c *gin.Context) {
 	pageSize := utils.GetQueryInt(c, "page_size", 20)
 	resp, err := h.postService.SearchPosts(query, page, pageSize)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		middleware.HandleError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
@@ -455,7 +674,7 @@ func (h *PostHandler) SearchPosts(c *gin.Context) {
 
 // SearchHashtags godoc
 // @Summary Search hashtags
-// @Description Search hashtags by name
+// @Description Search hashtags by name, ignoring diacritics (exact, then prefix, then substring matches)
 // @Tags search
 // @Produce json
 // @Param query query string true "Search keyword"
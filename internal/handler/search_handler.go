@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/service"
+	"vietick-backend/internal/utils"
+)
+
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Autocomplete godoc
+// @Summary Typeahead suggestions
+// @Description Prefix suggestions for users (by username or name, never email) and hashtags, ignoring diacritics. Users are ranked by followers, verified status and, when signed in, the viewer's follows.
+// @Tags search
+// @Produce json
+// @Param query query string true "Prefix typed so far"
+// @Param type query string false "users, hashtags or all" default(all)
+// @Param limit query int false "Suggestions per type (max 20)" default(10)
+// @Success 200 {object} model.AutocompleteResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Router /search/autocomplete [get]
+func (h *SearchHandler) Autocomplete(c *gin.Context) {
+	query := c.Query("query")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing query parameter"})
+		return
+	}
+
+	kind := c.DefaultQuery("type", "all")
+	if kind != "all" && kind != "users" && kind != "hashtags" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type, expected users, hashtags or all"})
+		return
+	}
+
+	limit := utils.GetQueryInt(c, "limit", 10)
+	viewerID := middleware.GetUserIDPtr(c)
+
+	c.JSON(http.StatusOK, h.searchService.Autocomplete(query, kind, viewerID, limit))
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/middleware"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/service"
+	"vietick-backend/internal/utils"
+)
+
+type SuspensionHandler struct {
+	suspensionService *service.SuspensionService
+}
+
+func NewSuspensionHandler(suspensionService *service.SuspensionService) *SuspensionHandler {
+	return &SuspensionHandler{
+		suspensionService: suspensionService,
+	}
+}
+
+// SuspendUser godoc
+// @Summary Suspend or ban a user
+// @Description Suspend a user for a duration or ban them permanently (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body model.SuspendUserRequest true "Suspension data"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/suspend [post]
+func (h *SuspensionHandler) SuspendUser(c *gin.Context) {
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := c.Param("id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req model.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	user, err := h.suspensionService.SuspendUser(adminID, userID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "User suspended successfully",
+		"user_id":         user.ID,
+		"account_status":  user.AccountStatus,
+		"status_reason":   user.StatusReason,
+		"suspended_until": user.SuspendedUntil,
+	})
+}
+
+// UnsuspendUser godoc
+// @Summary Lift a suspension or ban
+// @Description Restore a suspended or banned user to active (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/unsuspend [post]
+func (h *SuspensionHandler) UnsuspendUser(c *gin.Context) {
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID := c.Param("id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := h.suspensionService.UnsuspendUser(adminID, userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "User reinstated successfully",
+		"user_id":        user.ID,
+		"account_status": user.AccountStatus,
+	})
+}
+
+// GetSuspendedUsers godoc
+// @Summary Get suspended users
+// @Description Get currently suspended or banned users (admin only)
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} model.SuspendedUsersResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/suspended [get]
+func (h *SuspensionHandler) GetSuspendedUsers(c *gin.Context) {
+	var pagination utils.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	response, err := h.suspensionService.GetSuspendedUsers(&pagination)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
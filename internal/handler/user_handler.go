@@ -12,12 +12,14 @@ import (
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService    *service.UserService
+	accountService *service.AccountService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, accountService *service.AccountService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:    userService,
+		accountService: accountService,
 	}
 }
 
@@ -138,7 +140,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 // SearchUsers godoc
 // @Summary Search users
-// @Description Search users by username, full_name, email
+// @Description Search users by username, full name and bio, ranked by relevance
 // @Tags search
 // @Produce json
 // @Param query query string true "Search keyword"
@@ -195,6 +197,39 @@ func (h *UserHandler) GetUserStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetDashboard godoc
+// @Summary Get my dashboard
+// @Description The statistics of the current account with the reach of its posts over the last days: daily impressions and views split between followers and others, reactions, comments, new followers and the posts with the most impressions
+// @Tags users
+// @Produce json
+// @Param days query int false "Number of days, today included (1-90)" default(28)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /users/me/dashboard [get]
+func (h *UserHandler) GetDashboard(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var filter model.DashboardFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	dashboard, err := h.userService.GetDashboard(userID, filter.Days)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
 // CheckUsernameAvailability godoc
 // @Summary Check username availability
 // @Description Check if a username is available
@@ -377,3 +412,73 @@ func (h *UserHandler) UpdateEmail(c *gin.Context) {
 		"email":   req.Email,
 	})
 }
+
+// DeactivateAccount godoc
+// @Summary Deactivate my account
+// @Description Hide the account, its profile and posts and sign out everywhere. Logging in again before reactivate_before reopens it; after that the account is deleted.
+// @Tags users
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /users/me/deactivate [post]
+func (h *UserHandler) DeactivateAccount(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	reactivateBefore, err := h.accountService.Deactivate(userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Account deactivated. Log in again to reactivate it.",
+		"reactivate_before": reactivateBefore,
+	})
+}
+
+// DeleteAccount godoc
+// @Summary Delete my account
+// @Description Permanently delete the account after confirming the password. The account disappears at once; posts, comments, likes, follows and identity documents are erased in the background and a confirmation email is sent when done.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteAccountRequest true "Current password"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /users/me [delete]
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req model.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	if err := h.accountService.Delete(userID, &req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Account deleted. Your data is being erased.",
+	})
+}
@@ -22,7 +22,7 @@ func NewVerificationHandler(verificationService *service.VerificationService) *V
 
 // SubmitIdentityVerification godoc
 // @Summary Submit identity verification
-// @Description Submit identity verification for blue tick
+// @Description Submit identity verification for blue tick. Organization admins submit for the organization with the X-Acting-As header.
 // @Tags verification
 // @Accept json
 // @Produce json
@@ -46,7 +46,13 @@ func (h *VerificationHandler) SubmitIdentityVerification(c *gin.Context) {
 		return
 	}
 
-	verification, err := h.verificationService.SubmitIdentityVerification(userID, &req)
+	// Members of an organization submit on its behalf (X-Acting-As)
+	submittedBy := userID
+	if actingUserID := middleware.GetActingUserID(c); actingUserID != nil {
+		submittedBy = *actingUserID
+	}
+
+	verification, err := h.verificationService.SubmitIdentityVerification(userID, submittedBy, &req)
 	if err != nil {
 		middleware.HandleError(c, err)
 		return
@@ -81,9 +87,34 @@ func (h *VerificationHandler) GetUserVerification(c *gin.Context) {
 	c.JSON(http.StatusOK, verification)
 }
 
+// GetVerificationHistory godoc
+// @Summary Get user verification history
+// @Description Get every verification request of the current user with its status changes (submitted, approved, rejected, revoked, expired) and when a new request can be submitted
+// @Tags verification
+// @Produce json
+// @Success 200 {object} model.VerificationHistoryResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /verification/me/history [get]
+func (h *VerificationHandler) GetVerificationHistory(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	history, err := h.verificationService.GetUserHistory(userID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // GetVerification godoc
 // @Summary Get verification by ID
-// @Description Get verification details by ID (admin only)
+// @Description Get verification details by ID with duplicate matches, status history and reviews (reviewers with verification.review)
 // @Tags verification
 // @Produce json
 // @Param id path string true "Verification ID"
@@ -112,9 +143,10 @@ func (h *VerificationHandler) GetVerification(c *gin.Context) {
 
 // GetPendingVerifications godoc
 // @Summary Get pending verifications
-// @Description Get all pending verification requests (admin only)
+// @Description Get the review queue, oldest first (reviewers with verification.review)
 // @Tags verification
 // @Produce json
+// @Param assigned_to_me query bool false "Only requests assigned to the current reviewer"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} model.IdentityVerificationsResponse
@@ -129,7 +161,13 @@ func (h *VerificationHandler) GetPendingVerifications(c *gin.Context) {
 		return
 	}
 
-	response, err := h.verificationService.GetPendingVerifications(&pagination)
+	var assignedTo *string
+	if c.Query("assigned_to_me") == "true" {
+		userID, _ := middleware.GetUserID(c)
+		assignedTo = &userID
+	}
+
+	response, err := h.verificationService.GetPendingVerifications(assignedTo, &pagination)
 	if err != nil {
 		middleware.HandleError(c, err)
 		return
@@ -143,7 +181,7 @@ func (h *VerificationHandler) GetPendingVerifications(c *gin.Context) {
 // @Description Get all verification requests with optional status filter (admin only)
 // @Tags verification
 // @Produce json
-// @Param status query string false "Status filter" Enums(pending,approved,rejected)
+// @Param status query string false "Status filter" Enums(pending,approved,rejected,revoked,expired)
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} model.IdentityVerificationsResponse
@@ -176,7 +214,7 @@ func (h *VerificationHandler) GetAllVerifications(c *gin.Context) {
 
 // ReviewVerification godoc
 // @Summary Review verification request
-// @Description Approve or reject a verification request (admin only)
+// @Description Approve or reject a verification request. Takes the reviewer's lease on the request; high-risk requests stay pending after the first approval until a second reviewer approves them.
 // @Tags verification
 // @Accept json
 // @Produce json
@@ -217,9 +255,187 @@ func (h *VerificationHandler) ReviewVerification(c *gin.Context) {
 	c.JSON(http.StatusOK, verification)
 }
 
+// ClaimVerification godoc
+// @Summary Claim verification request
+// @Description Take a lease on a pending request so that no other reviewer works on it; claiming again renews the lease
+// @Tags verification
+// @Produce json
+// @Param id path string true "Verification ID"
+// @Success 200 {object} model.IdentityVerification
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /verification/{id}/claim [post]
+func (h *VerificationHandler) ClaimVerification(c *gin.Context) {
+	reviewerID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	verification, err := h.verificationService.ClaimVerification(c.Param("id"), reviewerID)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// ReleaseVerification godoc
+// @Summary Release verification request
+// @Description Give up the lease on a request so that another reviewer can take it
+// @Tags verification
+// @Produce json
+// @Param id path string true "Verification ID"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /verification/{id}/claim [delete]
+func (h *VerificationHandler) ReleaseVerification(c *gin.Context) {
+	reviewerID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.verificationService.ReleaseVerification(c.Param("id"), reviewerID); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Verification released successfully",
+	})
+}
+
+// RevokeVerification godoc
+// @Summary Revoke verification
+// @Description Remove the blue tick granted by an approved verification request; the user is emailed the reason (admin only)
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param id path string true "Verification ID"
+// @Param request body model.RevokeIdentityVerificationRequest true "Revocation reason"
+// @Success 200 {object} model.IdentityVerification
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /verification/{id}/revoke [post]
+func (h *VerificationHandler) RevokeVerification(c *gin.Context) {
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req model.RevokeIdentityVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	verification, err := h.verificationService.RevokeVerification(c.Param("id"), adminID, &req)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// GetVerificationDocuments godoc
+// @Summary Get signed links to verification documents
+// @Description Get short-lived URLs to the identity documents of a request. Requires the verification.documents.view permission; every link handed out is logged.
+// @Tags verification
+// @Produce json
+// @Param id path string true "Verification ID"
+// @Success 200 {object} model.VerificationDocumentsResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /verification/{id}/documents [get]
+func (h *VerificationHandler) GetVerificationDocuments(c *gin.Context) {
+	viewerID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	response, err := h.verificationService.GetDocumentLinks(c.Param("id"), viewerID, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// ServeVerificationDocument godoc
+// @Summary Open a verification document
+// @Description Stream a decrypted identity document through a signed URL from /verification/{id}/documents. Each view is logged.
+// @Tags verification
+// @Produce octet-stream
+// @Param media_id path string true "Media ID"
+// @Param viewer query string true "Viewer ID the URL was issued to"
+// @Param expires query string true "Expiry (unix seconds)"
+// @Param signature query string true "Signature"
+// @Success 200 {file} binary
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Router /verification/documents/{media_id} [get]
+func (h *VerificationHandler) ServeVerificationDocument(c *gin.Context) {
+	media, reader, err := h.verificationService.OpenDocument(c.Param("media_id"), c.Query("viewer"),
+		c.Query("expires"), c.Query("signature"), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Cache-Control", "no-store")
+	c.DataFromReader(http.StatusOK, media.Size, media.ContentType, reader, map[string]string{
+		"Content-Disposition":    "inline",
+		"X-Content-Type-Options": "nosniff",
+		"Referrer-Policy":        "no-referrer",
+	})
+}
+
+// GetVerificationDocumentAccess godoc
+// @Summary Get document access log
+// @Description List who requested or opened the documents of a verification request (admin only)
+// @Tags verification
+// @Produce json
+// @Param id path string true "Verification ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /verification/{id}/document-access [get]
+func (h *VerificationHandler) GetVerificationDocumentAccess(c *gin.Context) {
+	accesses, err := h.verificationService.GetDocumentAccessLog(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"verification_id": c.Param("id"),
+		"accesses":        accesses,
+	})
+}
+
 // GetVerificationStats godoc
 // @Summary Get verification statistics
-// @Description Get verification statistics (admin only)
+// @Description Get verification statistics with SLA metrics: time to first review, backlog age percentiles and per-reviewer throughput (admin only)
 // @Tags verification
 // @Produce json
 // @Success 200 {object} map[string]interface{}
@@ -298,22 +514,32 @@ func (h *VerificationHandler) DeleteVerification(c *gin.Context) {
 
 // GetVerifiedUsers godoc
 // @Summary Get verified users
-// @Description Get a list of verified users
+// @Description Directory of verified accounts with the viewer's follow status, filterable by category and searchable by username or name
 // @Tags verification
 // @Produce json
+// @Param category query string false "Verification category" Enums(journalist,government,brand,creator)
+// @Param q query string false "Search username or full name"
+// @Param sort query string false "Sort order" Enums(followers,verified_at) default(followers)
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} model.FollowersResponse
+// @Failure 400 {object} middleware.ErrorResponse
 // @Security BearerAuth
 // @Router /verification/verified-users [get]
 func (h *VerificationHandler) GetVerifiedUsers(c *gin.Context) {
+	var filter model.VerifiedUsersFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
 	var pagination utils.PaginationParams
 	if err := c.ShouldBindQuery(&pagination); err != nil {
 		middleware.HandleError(c, err)
 		return
 	}
 
-	response, err := h.verificationService.GetVerifiedUsers(&pagination)
+	response, err := h.verificationService.GetVerifiedUsers(&filter, middleware.GetUserIDPtr(c), &pagination)
 	if err != nil {
 		middleware.HandleError(c, err)
 		return
@@ -322,6 +548,37 @@ func (h *VerificationHandler) GetVerifiedUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// UpdateVerificationCategory godoc
+// @Summary Set verification category
+// @Description Set or clear the directory category (journalist, government, brand, creator) of a verified user (admin only)
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body model.UpdateVerificationCategoryRequest true "Category, null to clear"
+// @Success 200 {object} model.UserProfile
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security BearerAuth
+// @Router /verification/users/{user_id}/category [put]
+func (h *VerificationHandler) UpdateVerificationCategory(c *gin.Context) {
+	var req model.UpdateVerificationCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	profile, err := h.verificationService.SetVerificationCategory(c.Param("user_id"), req.Category)
+	if err != nil {
+		middleware.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
 // GetVerificationRequirements godoc
 // @Summary Get verification requirements
 // @Description Get the requirements for identity verification
@@ -341,6 +598,19 @@ func (h *VerificationHandler) GetVerificationRequirements(c *gin.Context) {
 			"passport",
 			"driver_license",
 		},
+		"organization_documents": []string{
+			"Business registration certificate (front)",
+			"Photo of the submitting member holding the certificate",
+		},
+		"accepted_organization_id_types": []string{
+			"business_registration",
+		},
+		"categories": []string{
+			"journalist",
+			"government",
+			"brand",
+			"creator",
+		},
 		"image_requirements": map[string]string{
 			"format":     "JPEG, PNG",
 			"max_size":   "5MB",
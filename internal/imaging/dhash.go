@@ -0,0 +1,29 @@
+package imaging
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// DHash is a 64-bit perceptual "difference hash": the image is shrunk to 9x8 grey pixels
+// and each bit tells whether a pixel is brighter than its right neighbour. Re-encoded,
+// resized or slightly edited copies of an image have hashes a few bits apart.
+func DHash(img image.Image) uint64 {
+	// Fit filters the whole picture down first; bilinear is enough for the last step
+	src := Fit(img, 256)
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.BiLinear.Scale(small, small.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		row := small.Pix[y*small.Stride:]
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if row[x] < row[x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
@@ -0,0 +1,218 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// StripMetadata removes EXIF (including GPS), XMP, IPTC and text comments from an encoded
+// image without re-encoding the pixels. Color profiles are kept.
+func StripMetadata(data []byte, format string) ([]byte, error) {
+	switch format {
+	case "jpeg":
+		return stripJPEG(data)
+	case "png":
+		return stripPNG(data)
+	case "webp":
+		return stripWebP(data)
+	default:
+		return nil, fmt.Errorf("unsupported image format: %s", format)
+	}
+}
+
+// Orientation returns the EXIF orientation (1-8) of the image, 1 when there is none
+func Orientation(data []byte, format string) int {
+	var exif []byte
+	switch format {
+	case "jpeg":
+		exif = jpegExif(data)
+	case "webp":
+		exif = webpChunk(data, "EXIF")
+	}
+	if exif == nil {
+		return 1
+	}
+	exif = bytes.TrimPrefix(exif, []byte("Exif\x00\x00"))
+	return tiffOrientation(exif)
+}
+
+// JPEG markers kept when stripping: APP0 (JFIF), APP2 (ICC profile), APP14 (Adobe color transform)
+var keptJPEGMarkers = map[byte]bool{0xE0: true, 0xE2: true, 0xEE: true}
+
+// jpegSegments calls fn for every marker segment before the image data and returns the
+// offset of the start-of-scan segment
+func jpegSegments(data []byte, fn func(marker byte, segment []byte)) (int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0, fmt.Errorf("invalid jpeg")
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 0, fmt.Errorf("invalid jpeg marker at %d", pos)
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // fill byte
+			pos++
+			continue
+		}
+		if marker == 0xDA {
+			return pos, nil
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 0, fmt.Errorf("truncated jpeg segment")
+		}
+		fn(marker, data[pos:end])
+		pos = end
+	}
+	return 0, fmt.Errorf("jpeg has no image data")
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	sos, err := jpegSegments(data, func(marker byte, segment []byte) {
+		isApp := marker >= 0xE0 && marker <= 0xEF
+		if (isApp && !keptJPEGMarkers[marker]) || marker == 0xFE {
+			return
+		}
+		out.Write(segment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Write(data[sos:])
+	return out.Bytes(), nil
+}
+
+func jpegExif(data []byte) []byte {
+	var exif []byte
+	jpegSegments(data, func(marker byte, segment []byte) {
+		if exif == nil && marker == 0xE1 && bytes.HasPrefix(segment[4:], []byte("Exif\x00\x00")) {
+			exif = segment[4:]
+		}
+	})
+	return exif
+}
+
+// PNG chunks that carry metadata rather than pixels
+var strippedPNGChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+func stripPNG(data []byte) ([]byte, error) {
+	const sigLen = 8
+	if len(data) < sigLen || !bytes.Equal(data[:sigLen], []byte("\x89PNG\r\n\x1a\n")) {
+		return nil, fmt.Errorf("invalid png")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:sigLen])
+	pos := sigLen
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length // length, type, data, crc
+		if length < 0 || end > len(data) {
+			return nil, fmt.Errorf("truncated png chunk")
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		if !strippedPNGChunks[chunkType] {
+			out.Write(data[pos:end])
+		}
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// webpChunks calls fn for every chunk inside the RIFF container
+func webpChunks(data []byte, fn func(fourCC string, chunk []byte, payload []byte)) error {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return fmt.Errorf("invalid webp")
+	}
+	pos := 12
+	for pos+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size
+		if size < 0 || end > len(data) {
+			return fmt.Errorf("truncated webp chunk")
+		}
+		padded := end + size%2
+		if padded > len(data) {
+			padded = len(data)
+		}
+		fn(string(data[pos:pos+4]), data[pos:padded], data[pos+8:end])
+		pos = padded
+	}
+	return nil
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+	err := webpChunks(data, func(fourCC string, chunk []byte, payload []byte) {
+		switch fourCC {
+		case "EXIF", "XMP ":
+			return
+		case "VP8X":
+			// Clear the EXIF and XMP flags so decoders do not look for the removed chunks
+			if len(chunk) > 8 {
+				chunk = append([]byte(nil), chunk...)
+				chunk[8] &^= 0x08 | 0x04
+			}
+		}
+		out.Write(chunk)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	return result, nil
+}
+
+func webpChunk(data []byte, fourCC string) []byte {
+	var found []byte
+	webpChunks(data, func(cc string, _ []byte, payload []byte) {
+		if found == nil && cc == fourCC {
+			found = payload
+		}
+	})
+	return found
+}
+
+// tiffOrientation reads tag 0x0112 from IFD0 of a TIFF (EXIF) block
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			o := int(order.Uint16(tiff[entry+8:]))
+			if o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
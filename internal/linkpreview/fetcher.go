@@ -0,0 +1,199 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrBlocked marks links that are never fetched: private addresses, other schemes or ports.
+// Retrying will not fix them.
+var ErrBlocked = errors.New("blocked link")
+
+// ErrNotHTML marks responses that are not web pages (images, PDFs, ...)
+var ErrNotHTML = errors.New("not an html page")
+
+// Options limits what a fetch may cost
+type Options struct {
+	Timeout      time.Duration // whole request, redirects included
+	MaxRedirects int
+	MaxBodyBytes int64 // the page is cut at this size; metadata sits in <head>
+	UserAgent    string
+}
+
+// Fetcher downloads pages of untrusted links. Every connection is checked after DNS
+// resolution, so a hostname resolving to a private address (or re-resolving to one, DNS
+// rebinding) is refused, on redirects too.
+type Fetcher struct {
+	client  *http.Client
+	options Options
+
+	// The checks on links and on the addresses connected to; tests relax them to reach a
+	// server on loopback
+	checkURL  func(u *url.URL) error
+	allowAddr func(addr netip.AddrPort) bool
+}
+
+func NewFetcher(options Options) *Fetcher {
+	f := &Fetcher{
+		options:   options,
+		checkURL:  CheckURL,
+		allowAddr: func(addr netip.AddrPort) bool { return IsPublicAddr(addr.Addr()) },
+	}
+	dialer := &net.Dialer{
+		Timeout: options.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrBlocked, err)
+			}
+			if !f.allowAddr(addrPort) {
+				return fmt.Errorf("%w: %s is not a public address", ErrBlocked, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		// Never go through a proxy from the environment: it would connect for us, unchecked
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   options.Timeout,
+		ResponseHeaderTimeout: options.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	f.client = &http.Client{
+		Transport: transport,
+		Timeout:   options.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > options.MaxRedirects {
+				return fmt.Errorf("%w: more than %d redirects", ErrBlocked, options.MaxRedirects)
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f
+}
+
+// Page is a fetched web page, cut at MaxBodyBytes
+type Page struct {
+	URL  *url.URL // after redirects
+	Body []byte
+}
+
+// Fetch downloads an html page. Links to private addresses, other schemes or ports fail
+// with ErrBlocked, other content types with ErrNotHTML.
+func (f *Fetcher) Fetch(ctx context.Context, link string) (*Page, error) {
+	target, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBlocked, err)
+	}
+	if err := f.checkURL(target); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBlocked, err)
+	}
+	req.Header.Set("User-Agent", f.options.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: %s", ErrNotHTML, mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.options.MaxBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page: %w", err)
+	}
+	return &Page{URL: resp.Request.URL, Body: withCharset(body, resp.Header.Get("Content-Type"))}, nil
+}
+
+// CheckURL refuses what is never fetched before any connection is made: other schemes,
+// credentials, ports other than 80 and 443 and literal private addresses
+func CheckURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrBlocked, u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in link", ErrBlocked)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: no host", ErrBlocked)
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		return fmt.Errorf("%w: port %s", ErrBlocked, port)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s is not a public address", ErrBlocked, addr)
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return fmt.Errorf("%w: %s is not a public host", ErrBlocked, host)
+	}
+	return nil
+}
+
+// Ranges that are not reachable on the internet, or reach inside it (NAT64, 6to4)
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"), // link-local, cloud metadata
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// IsPublicAddr reports whether addr is a unicast address on the internet
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.Zone() != "" {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package linkpreview
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// Longest values kept, in characters
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxSiteNameLength    = 200
+	maxImageURLLength    = 2048
+)
+
+// Card is what a page says about itself in its OpenGraph and Twitter card tags
+type Card struct {
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// Parse reads the metadata in the <head> of a page. OpenGraph tags win over Twitter card
+// tags, which win over <title> and <meta name="description">. The image is resolved against
+// the page URL; the site name defaults to the host.
+func Parse(page *Page) *Card {
+	values := make(map[string]string)
+	title := ""
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(page.Body))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		name, hasAttr := tokenizer.TagName()
+		tag := string(name)
+		if tokenType == html.EndTagToken {
+			if tag == "head" {
+				break
+			}
+			if tag == "title" {
+				inTitle = false
+			}
+			continue
+		}
+		if tokenType == html.TextToken {
+			if inTitle && title == "" {
+				title = string(tokenizer.Text())
+			}
+			continue
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+		if tag == "body" {
+			break
+		}
+		if tag == "title" {
+			inTitle = true
+			continue
+		}
+		if tag != "meta" || !hasAttr {
+			continue
+		}
+
+		var key, content string
+		for {
+			attrName, attrValue, more := tokenizer.TagAttr()
+			switch strings.ToLower(string(attrName)) {
+			case "property", "name":
+				if key == "" {
+					key = strings.ToLower(strings.TrimSpace(string(attrValue)))
+				}
+			case "content":
+				content = string(attrValue)
+			}
+			if !more {
+				break
+			}
+		}
+		// The first value of a tag counts, e.g. the first of several og:image
+		if key != "" && values[key] == "" {
+			values[key] = clean(content)
+		}
+	}
+
+	card := &Card{
+		Title:       first(values["og:title"], values["twitter:title"], clean(title)),
+		Description: first(values["og:description"], values["twitter:description"], values["description"]),
+		SiteName:    first(values["og:site_name"], strings.TrimPrefix(page.URL.Hostname(), "www.")),
+	}
+	image := first(values["og:image:secure_url"], values["og:image"], values["og:image:url"], values["twitter:image"], values["twitter:image:src"])
+	if image != "" {
+		if ref, err := url.Parse(image); err == nil {
+			resolved := page.URL.ResolveReference(ref)
+			if (resolved.Scheme == "http" || resolved.Scheme == "https") && len(resolved.String()) <= maxImageURLLength {
+				card.ImageURL = resolved.String()
+			}
+		}
+	}
+	card.Title = truncate(card.Title, maxTitleLength)
+	card.Description = truncate(card.Description, maxDescriptionLength)
+	card.SiteName = truncate(card.SiteName, maxSiteNameLength)
+	return card
+}
+
+// withCharset converts a page to UTF-8 using the charset of the Content-Type header or the
+// page itself. The page is kept as is when the charset is unknown.
+func withCharset(body []byte, contentType string) []byte {
+	encoding, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" {
+		return body
+	}
+	decoded, err := io.ReadAll(encoding.NewDecoder().Reader(bytes.NewReader(body)))
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+func first(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// clean collapses whitespace
+func clean(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func truncate(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:length-1])) + "…"
+}
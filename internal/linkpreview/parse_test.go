@@ -0,0 +1,83 @@
+package linkpreview
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func parseHTML(t *testing.T, pageURL, body string) *Card {
+	t.Helper()
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Parse(&Page{URL: u, Body: []byte(body)})
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want Card
+	}{
+		{
+			name: "opengraph wins",
+			body: `<html><head>
+				<title>Title tag</title>
+				<meta name="description" content="Meta description">
+				<meta name="twitter:title" content="Twitter title">
+				<meta property="og:title" content="  OG
+					title ">
+				<meta property="og:description" content="OG description">
+				<meta property="og:image" content="/img/a.jpg">
+				<meta property="og:image" content="/img/b.jpg">
+				<meta property="og:site_name" content="VnExpress">
+			</head></html>`,
+			want: Card{Title: "OG title", Description: "OG description", ImageURL: "https://www.vnexpress.net/img/a.jpg", SiteName: "VnExpress"},
+		},
+		{
+			name: "twitter card",
+			body: `<head><title>Title tag</title>
+				<meta name="twitter:title" content="Twitter title">
+				<meta name="twitter:description" content="Twitter description">
+				<meta name="twitter:image" content="https://cdn.example.com/t.png">`,
+			want: Card{Title: "Twitter title", Description: "Twitter description", ImageURL: "https://cdn.example.com/t.png", SiteName: "vnexpress.net"},
+		},
+		{
+			name: "plain html",
+			body: `<head><title> Tin   mới </title><meta name="description" content="Mô tả"></head>`,
+			want: Card{Title: "Tin mới", Description: "Mô tả", SiteName: "vnexpress.net"},
+		},
+		{
+			name: "image with another scheme",
+			body: `<head><meta property="og:image" content="javascript:alert(1)"></head>`,
+			want: Card{SiteName: "vnexpress.net"},
+		},
+		{
+			name: "tags in the body are ignored",
+			body: `<head><title>Head</title></head><body><meta property="og:title" content="Body"></body>`,
+			want: Card{Title: "Head", SiteName: "vnexpress.net"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseHTML(t, "https://www.vnexpress.net/thoi-su/bai-viet.html", tt.body)
+			if *got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTruncates(t *testing.T) {
+	body := `<head><meta property="og:title" content="` + strings.Repeat("ệ", maxTitleLength+50) + `"></head>`
+	card := parseHTML(t, "https://example.com/", body)
+	if n := utf8.RuneCountInString(card.Title); n != maxTitleLength {
+		t.Errorf("title has %d characters, want %d", n, maxTitleLength)
+	}
+	if !strings.HasSuffix(card.Title, "…") {
+		t.Errorf("title %q does not end with an ellipsis", card.Title)
+	}
+}
@@ -0,0 +1,94 @@
+package linkpreview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Query parameters that only track where a click came from
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"ref_src": true,
+}
+
+// Canonicalize turns a link found in a post into the URL previews are cached under:
+// "www." links get http://, scheme and host are lowercased, default ports, fragments and
+// tracking parameters are dropped and the query is sorted. Trailing punctuation of the
+// sentence around the link is left out.
+func Canonicalize(link string) (string, error) {
+	link = trimPunctuation(link)
+	if strings.HasPrefix(strings.ToLower(link), "www.") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBlocked, err)
+	}
+	if err := CheckURL(u); err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	query := u.Query()
+	for key := range query {
+		if trackingParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	// Encode sorts by key, values are sorted here
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Hash is the cache key of a canonical URL
+func Hash(canonicalURL string) string {
+	sum := sha256.Sum256([]byte(canonicalURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// trimPunctuation drops what usually ends the sentence rather than the link: "xem https://a.vn/b."
+// A closing parenthesis is kept when the link opened one (Wikipedia links).
+func trimPunctuation(link string) string {
+	for len(link) > 0 {
+		last := link[len(link)-1]
+		if strings.IndexByte(".,;:!?'\"]}>", last) >= 0 {
+			link = link[:len(link)-1]
+			continue
+		}
+		if last == ')' && strings.Count(link, "(") < strings.Count(link, ")") {
+			link = link[:len(link)-1]
+			continue
+		}
+		break
+	}
+	return link
+}
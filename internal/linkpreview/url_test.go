@@ -0,0 +1,75 @@
+package linkpreview
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"https://VnExpress.net/a", "https://vnexpress.net/a"},
+		{"www.vnexpress.net", "http://www.vnexpress.net/"},
+		{"https://vnexpress.net:443/a#comments", "https://vnexpress.net/a"},
+		{"http://vnexpress.net:80/a", "http://vnexpress.net/a"},
+		{"https://vnexpress.net/a?utm_source=fb&b=2&fbclid=x&a=1", "https://vnexpress.net/a?a=1&b=2"},
+		{"https://vnexpress.net/a?tag=z&tag=y", "https://vnexpress.net/a?tag=y&tag=z"},
+		{"https://vnexpress.net/a.", "https://vnexpress.net/a"},
+		{"https://vnexpress.net/a),", "https://vnexpress.net/a"},
+		{"https://en.wikipedia.org/wiki/Go_(language)", "https://en.wikipedia.org/wiki/Go_(language)"},
+		{"https://vnexpress.net./a", "https://vnexpress.net/a"},
+	}
+	for _, tt := range tests {
+		got, err := Canonicalize(tt.link)
+		if err != nil {
+			t.Errorf("Canonicalize(%q): %v", tt.link, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalizeBlocked(t *testing.T) {
+	for _, link := range []string{
+		"javascript:alert(1)",
+		"mailto:a@b.vn",
+		"http://192.168.1.1/admin",
+		"http://[fe80::1]/",
+		"http://metadata.google.internal/",
+		"https://example.com:8443/",
+	} {
+		if _, err := Canonicalize(link); !errors.Is(err, ErrBlocked) {
+			t.Errorf("Canonicalize(%q): err = %v, want ErrBlocked", link, err)
+		}
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.0.1", false},
+		{"127.0.0.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"fd00::1", false},
+		{"64:ff9b::a00:1", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.public {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", tt.addr, got, tt.public)
+		}
+	}
+}
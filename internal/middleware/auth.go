@@ -3,8 +3,10 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"vietick-backend/internal/model"
 	"vietick-backend/internal/service"
 	"vietick-backend/pkg/jwt"
 )
@@ -50,11 +52,26 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		// Reject suspended/banned accounts and revoked tokens immediately
+		if err := authService.CheckAccountStatus(claims.UserID, tokenIssuedAt(claims)); err != nil {
+			HandleError(c, err)
+			c.Abort()
+			return
+		}
+
+		// A token scoped to an organization carries no personal identity: only
+		// ActingAsMiddleware sets the user of the request, so routes that don't act on
+		// behalf of an organization see an unauthenticated request
+		c.Set("claims", claims)
+		if claims.OrganizationID != "" {
+			c.Next()
+			return
+		}
+
 		// Set user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
-		c.Set("claims", claims)
 
 		c.Next()
 	}
@@ -90,6 +107,16 @@ func OptionalAuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		// Suspended users and organization tokens are treated as anonymous
+		if err := authService.CheckAccountStatus(claims.UserID, tokenIssuedAt(claims)); err != nil {
+			c.Next()
+			return
+		}
+		if claims.OrganizationID != "" {
+			c.Next()
+			return
+		}
+
 		// Set user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
@@ -172,6 +199,10 @@ func RequireVerificationMiddleware(userService *service.UserService) gin.Handler
 // Note: This is a placeholder implementation. In a real app, you'd have a proper role system
 func AdminMiddleware(userService *service.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rejectOrganizationToken(c) {
+			return
+		}
+
 		userID, exists := c.Get("user_id")
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -191,9 +222,7 @@ func AdminMiddleware(userService *service.UserService) gin.HandlerFunc {
 		}
 
 		// Simple admin check - in a real app, you'd have a proper role system
-		// For now, let's say a specific UUID is admin (replace with real admin UUID)
-		const adminUUID = "00000000-0000-0000-0000-000000000001" // Example admin UUID
-		if user.ID != adminUUID {
+		if user.ID != model.AdminUserID {
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "Admin access required",
 			})
@@ -205,6 +234,128 @@ func AdminMiddleware(userService *service.UserService) gin.HandlerFunc {
 	}
 }
 
+// RequirePermission only lets through users granted the permission (and the admin)
+func RequirePermission(permissionService *service.PermissionService, permission model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectOrganizationToken(c) {
+			return
+		}
+
+		userID, exists := GetUserID(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		allowed, err := permissionService.HasPermission(userID, permission)
+		if err != nil {
+			HandleError(c, err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Permission required: " + string(permission),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ActingAsHeader selects the organization a member acts on behalf of
+const ActingAsHeader = "X-Acting-As"
+
+// ActingAsMiddleware lets members act on behalf of an organization, chosen with the
+// X-Acting-As header or by a token scoped to the organization. The organization becomes
+// the user of the request and the member is kept as acting_user_id. Members need at
+// least minRole; requests without an organization go through unchanged.
+func ActingAsMiddleware(organizationService *service.OrganizationService, minRole model.OrganizationRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Already switched by a middleware of the enclosing group
+		if role, acting := c.Get("organization_role"); acting {
+			if !role.(model.OrganizationRole).AtLeast(minRole) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "This action requires the " + string(minRole) + " role in the organization",
+				})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		userID, exists := GetUserID(c)
+		organizationID := c.GetHeader(ActingAsHeader)
+		if claims, ok := GetClaims(c); ok && claims.OrganizationID != "" {
+			if organizationID != "" && organizationID != claims.OrganizationID {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Token is scoped to another organization",
+				})
+				c.Abort()
+				return
+			}
+			organizationID = claims.OrganizationID
+			// AuthMiddleware leaves the user unset for organization tokens
+			userID, exists = claims.UserID, true
+		}
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+		if organizationID == "" {
+			c.Next()
+			return
+		}
+
+		member, err := organizationService.ResolveActingAs(organizationID, userID, minRole)
+		if err != nil {
+			HandleError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", organizationID)
+		c.Set("acting_user_id", userID)
+		c.Set("organization_role", member.Role)
+
+		c.Next()
+	}
+}
+
+// rejectOrganizationToken aborts the request when it was made with a token scoped to an
+// organization. Admin and reviewer rights are personal and never go with such a token.
+func rejectOrganizationToken(c *gin.Context) bool {
+	if claims, ok := GetClaims(c); ok && claims.OrganizationID != "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Organization tokens cannot be used for this action",
+		})
+		c.Abort()
+		return true
+	}
+	return false
+}
+
+// tokenIssuedAt returns the token's issue time, to the microsecond when the token
+// carries it, or the zero time if it is missing
+func tokenIssuedAt(claims *jwt.Claims) time.Time {
+	if claims.IssuedAtMicro != 0 {
+		return time.UnixMicro(claims.IssuedAtMicro)
+	}
+	if claims.IssuedAt == nil {
+		return time.Time{}
+	}
+	return claims.IssuedAt.Time
+}
+
 // GetUserID is a helper function to get user ID from context
 func GetUserID(c *gin.Context) (string, bool) {
 	userID, exists := c.Get("user_id")
@@ -223,6 +374,17 @@ func GetUserIDPtr(c *gin.Context) *string {
 	return &userID
 }
 
+// GetActingUserID returns the member acting on behalf of the organization, or nil when
+// the user acts as themselves
+func GetActingUserID(c *gin.Context) *string {
+	actingUserID, exists := c.Get("acting_user_id")
+	if !exists {
+		return nil
+	}
+	id := actingUserID.(string)
+	return &id
+}
+
 // GetClaims is a helper function to get JWT claims from context
 func GetClaims(c *gin.Context) (*jwt.Claims, bool) {
 	claims, exists := c.Get("claims")
@@ -27,6 +27,7 @@ func CORSMiddleware(cfg *config.CORSConfig) gin.HandlerFunc {
 			"Authorization",
 			"X-Requested-With",
 			"X-CSRF-Token",
+			ActingAsHeader,
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
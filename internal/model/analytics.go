@@ -0,0 +1,94 @@
+package model
+
+import "time"
+
+type AnalyticsInterval string
+
+const (
+	AnalyticsIntervalHour AnalyticsInterval = "hour"
+	AnalyticsIntervalDay  AnalyticsInterval = "day"
+)
+
+// PostViewStat counts the impressions and views of a post during one hour
+type PostViewStat struct {
+	PostID              string    `json:"post_id" db:"post_id" gorm:"primaryKey"`
+	UserID              string    `json:"user_id" db:"user_id"` // author of the post
+	Hour                time.Time `json:"hour" db:"hour" gorm:"primaryKey"`
+	Impressions         int       `json:"impressions" db:"impressions"`
+	FollowerImpressions int       `json:"follower_impressions" db:"follower_impressions"`
+	Views               int       `json:"views" db:"views"`
+	FollowerViews       int       `json:"follower_views" db:"follower_views"`
+}
+
+// Request models
+type PostAnalyticsFilter struct {
+	Interval AnalyticsInterval `form:"interval" binding:"omitempty,oneof=hour day"` // default day
+	From     string            `form:"from"`                                        // YYYY-MM-DD, UTC
+	To       string            `form:"to"`                                          // YYYY-MM-DD, UTC, inclusive
+}
+
+type DashboardFilter struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=90"` // default 28
+}
+
+// Response models
+type AnalyticsTotals struct {
+	Impressions            int `json:"impressions"`
+	FollowerImpressions    int `json:"follower_impressions"`
+	NonFollowerImpressions int `json:"non_follower_impressions"`
+	Views                  int `json:"views"`
+	FollowerViews          int `json:"follower_views"`
+	NonFollowerViews       int `json:"non_follower_views"`
+	Reactions              int `json:"reactions"` // reactions and comments by others than the author
+	Comments               int `json:"comments"`
+}
+
+// Add adds the counts of other
+func (t *AnalyticsTotals) Add(other AnalyticsTotals) {
+	t.Impressions += other.Impressions
+	t.FollowerImpressions += other.FollowerImpressions
+	t.NonFollowerImpressions += other.NonFollowerImpressions
+	t.Views += other.Views
+	t.FollowerViews += other.FollowerViews
+	t.NonFollowerViews += other.NonFollowerViews
+	t.Reactions += other.Reactions
+	t.Comments += other.Comments
+}
+
+type AnalyticsPoint struct {
+	Time time.Time `json:"time"` // start of the hour or day, UTC
+	AnalyticsTotals
+}
+
+type PostAnalytics struct {
+	PostID   string            `json:"post_id"`
+	Interval AnalyticsInterval `json:"interval"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"` // exclusive
+	// Since the post was published
+	ImpressionCount int              `json:"impression_count"`
+	ViewCount       int              `json:"view_count"`
+	Totals          AnalyticsTotals  `json:"totals"` // between From and To
+	Series          []AnalyticsPoint `json:"series"`
+}
+
+type AccountAnalyticsPoint struct {
+	AnalyticsPoint
+	NewFollowers int `json:"new_followers"`
+}
+
+type TopPost struct {
+	Post        Post `json:"post"`
+	Impressions int  `json:"impressions"`
+	Views       int  `json:"views"`
+}
+
+type AccountAnalytics struct {
+	Days         int                     `json:"days"`
+	From         time.Time               `json:"from"`
+	To           time.Time               `json:"to"` // exclusive
+	Totals       AnalyticsTotals         `json:"totals"`
+	NewFollowers int                     `json:"new_followers"`
+	Series       []AccountAnalyticsPoint `json:"series"`    // daily
+	TopPosts     []TopPost               `json:"top_posts"` // by impressions
+}
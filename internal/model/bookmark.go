@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Bookmark is a post saved by a user for later. Bookmarks are private: only their owner
+// sees them and the author is not notified.
+type Bookmark struct {
+	ID           string    `json:"id" db:"id"`
+	UserID       string    `json:"user_id" db:"user_id"`
+	PostID       string    `json:"post_id" db:"post_id"`
+	CollectionID *string   `json:"collection_id,omitempty" db:"collection_id"` // nil: unsorted
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	Post *Post `json:"post,omitempty" gorm:"-"`
+}
+
+// BookmarkCollection is a named folder of bookmarks
+type BookmarkCollection struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	BookmarkCount int64 `json:"bookmark_count" gorm:"-"`
+}
+
+type BookmarkPostRequest struct {
+	// Collection to save the post in; without it the bookmark is unsorted. Bookmarking a
+	// saved post again moves it.
+	CollectionID *string `json:"collection_id,omitempty" binding:"omitempty,uuid"`
+}
+
+type SaveBookmarkCollectionRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+type BookmarksFilter struct {
+	CollectionID *string `form:"collection_id" binding:"omitempty,uuid"`
+}
+
+type BookmarksResponse struct {
+	Bookmarks  []Bookmark `json:"bookmarks"`
+	TotalCount int64      `json:"total_count"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	HasMore    bool       `json:"has_more"`
+}
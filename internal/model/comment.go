@@ -1,26 +1,33 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Comment struct {
-	ID        string     `json:"id" db:"id" gorm:"type:char(36)"`
-	PostID    string     `json:"post_id" db:"post_id" gorm:"type:char(36)"`
-	UserID    string     `json:"user_id" db:"user_id" gorm:"type:char(36)"`
-	Content   string     `json:"content" db:"content"`
-	LikeCount int        `json:"like_count" db:"like_count"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	ID             string         `json:"id" db:"id" gorm:"type:char(36)"`
+	PostID         string         `json:"post_id" db:"post_id" gorm:"type:char(36)"`
+	UserID         string         `json:"user_id" db:"user_id" gorm:"type:char(36)"`
+	ActingUserID   *string        `json:"acting_user_id,omitempty" db:"acting_user_id" gorm:"type:char(36)"` // member writing for an organization
+	Content        string         `json:"content" db:"content"`
+	LikeCount      int            `json:"like_count" db:"like_count"` // "like" reactions only
+	ReactionCounts ReactionCounts `json:"reaction_counts" db:"reaction_counts" gorm:"type:json"`
+	IsHidden       bool           `json:"is_hidden,omitempty" db:"is_hidden"`
+	EditedAt       *time.Time     `json:"edited_at,omitempty" db:"edited_at"`
+	RevisionCount  int            `json:"revision_count" db:"revision_count"` // number of edits
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+	// Bình luận đã xoá không còn hiện dưới bài viết và vẫn khôi phục được cho tới khi bị xoá hẳn
+	DeletedAt      gorm.DeletedAt  `json:"-" db:"deleted_at"`
+	DeletedBy      *string         `json:"-" db:"deleted_by"`
+	DeletionSource *DeletionSource `json:"-" db:"deletion_source"`
 
 	// Additional fields for API responses
-	User    *UserProfile `json:"user,omitempty"`
-	IsLiked bool         `json:"is_liked,omitempty"`
-}
-
-type CommentLike struct {
-	ID        string    `json:"id" db:"id"`
-	CommentID string    `json:"comment_id" db:"comment_id"`
-	UserID    string    `json:"user_id" db:"user_id"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	User     *UserProfile  `json:"user,omitempty"`
+	IsLiked  bool          `json:"is_liked,omitempty" gorm:"-"`
+	Reaction *ReactionType `json:"reaction,omitempty" gorm:"-"` // the viewer's reaction
 }
 
 // Request models
@@ -34,4 +41,4 @@ type CommentsResponse struct {
 	Page       int       `json:"page"`
 	PageSize   int       `json:"page_size"`
 	HasMore    bool      `json:"has_more"`
-} 
\ No newline at end of file
+}
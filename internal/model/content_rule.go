@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// ContentRule is an admin-managed automatic moderation rule applied to posts and comments
+type ContentRule struct {
+	ID            string            `json:"id" db:"id"`
+	Type          ContentRuleType   `json:"type" db:"type"`
+	Pattern       string            `json:"pattern" db:"pattern"`
+	Threshold     *float64          `json:"threshold,omitempty" db:"threshold"`
+	WindowSeconds *int              `json:"window_seconds,omitempty" db:"window_seconds"`
+	Action        ContentRuleAction `json:"action" db:"action"`
+	Description   *string           `json:"description" db:"description"`
+	IsActive      bool              `json:"is_active" db:"is_active"`
+	CreatedBy     string            `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ContentSubmission is a recent post or comment of an author, kept for the repeated-content rule.
+// Only a fingerprint of the folded text is stored.
+type ContentSubmission struct {
+	ID          int64     `json:"-" db:"id"`
+	AuthorID    string    `json:"author_id" db:"author_id"`
+	Fingerprint string    `json:"fingerprint" db:"fingerprint"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type ContentRuleType string
+
+const (
+	ContentRuleKeyword         ContentRuleType = "keyword"
+	ContentRuleRegex           ContentRuleType = "regex"
+	ContentRuleDomain          ContentRuleType = "domain"
+	ContentRuleRepeatedContent ContentRuleType = "repeated_content"
+	ContentRuleLinkDensity     ContentRuleType = "link_density"
+)
+
+type ContentRuleAction string
+
+const (
+	ContentRuleReject     ContentRuleAction = "reject"
+	ContentRuleHold       ContentRuleAction = "hold"
+	ContentRuleShadowHide ContentRuleAction = "shadow_hide"
+)
+
+// Request models
+type CreateContentRuleRequest struct {
+	Type          ContentRuleType   `json:"type" binding:"required,oneof=keyword regex domain repeated_content link_density"`
+	Pattern       string            `json:"pattern" binding:"max=500"`
+	Threshold     *float64          `json:"threshold,omitempty" binding:"omitempty,gt=0"`
+	WindowSeconds *int              `json:"window_seconds,omitempty" binding:"omitempty,min=1,max=604800"`
+	Action        ContentRuleAction `json:"action" binding:"required,oneof=reject hold shadow_hide"`
+	Description   *string           `json:"description,omitempty" binding:"omitempty,max=255"`
+	IsActive      *bool             `json:"is_active,omitempty"`
+}
+
+type UpdateContentRuleRequest struct {
+	Pattern       *string            `json:"pattern,omitempty" binding:"omitempty,max=500"`
+	Threshold     *float64           `json:"threshold,omitempty" binding:"omitempty,gt=0"`
+	WindowSeconds *int               `json:"window_seconds,omitempty" binding:"omitempty,min=1,max=604800"`
+	Action        *ContentRuleAction `json:"action,omitempty" binding:"omitempty,oneof=reject hold shadow_hide"`
+	Description   *string            `json:"description,omitempty" binding:"omitempty,max=255"`
+	IsActive      *bool              `json:"is_active,omitempty"`
+}
+
+type TestContentRequest struct {
+	Content string `json:"content" binding:"required,min=1,max=5000"`
+}
+
+// ContentDecision is the outcome of running content through the policy pipeline
+type ContentDecision struct {
+	Action   ContentRuleAction `json:"action"`
+	RuleID   string            `json:"rule_id"`
+	RuleType ContentRuleType   `json:"rule_type"`
+	Reason   string            `json:"reason"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+type DeletionSource string
+
+const (
+	// Deleted by its author, who can restore it during the restore period
+	DeletionByAuthor DeletionSource = "author"
+	// Removed by a moderator; only moderators can restore it
+	DeletionByModeration DeletionSource = "moderation"
+)
+
+// Deletion describes a post or comment that was deleted but not purged yet
+type Deletion struct {
+	DeletedAt time.Time      `json:"deleted_at"`
+	DeletedBy *string        `json:"deleted_by,omitempty"`
+	Source    DeletionSource `json:"source"`
+	// Until when the author can restore it; not set when only moderators can
+	RestorableUntil *time.Time `json:"restorable_until,omitempty"`
+	// When it is deleted for good
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+type DeletedPost struct {
+	Post
+	Deletion Deletion `json:"deletion"`
+}
+
+type DeletedComment struct {
+	Comment
+	Deletion Deletion `json:"deletion"`
+}
+
+type DeletedContentFilter struct {
+	Source *DeletionSource `form:"source" binding:"omitempty,oneof=author moderation"`
+}
+
+type DeletedPostsResponse struct {
+	Posts      []DeletedPost `json:"posts"`
+	TotalCount int64         `json:"total_count"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	HasMore    bool          `json:"has_more"`
+}
+
+type DeletedCommentsResponse struct {
+	Comments   []DeletedComment `json:"comments"`
+	TotalCount int64            `json:"total_count"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	HasMore    bool             `json:"has_more"`
+}
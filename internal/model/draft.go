@@ -0,0 +1,88 @@
+package model
+
+import "time"
+
+type PostDraftStatus string
+
+const (
+	// Saved, only visible to the author
+	PostDraftDraft PostDraftStatus = "draft"
+	// Waiting for PublishAt
+	PostDraftScheduled PostDraftStatus = "scheduled"
+	// Being published by the scheduler holding the lock
+	PostDraftPublishing PostDraftStatus = "publishing"
+	PostDraftPublished  PostDraftStatus = "published"
+	// Publishing failed too often; LastError says why
+	PostDraftFailed PostDraftStatus = "failed"
+)
+
+// PostDraftEditable lists the statuses in which a draft can be edited, rescheduled, published
+// or discarded
+var PostDraftEditable = []PostDraftStatus{PostDraftDraft, PostDraftScheduled, PostDraftFailed}
+
+// Editable reports whether the status is one of PostDraftEditable
+func (s PostDraftStatus) Editable() bool {
+	for _, status := range PostDraftEditable {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// PostDraft is a post that is not published yet. It never shows up in feeds or searches;
+// once published, the post gets the draft's ID.
+type PostDraft struct {
+	ID              string          `json:"id" db:"id"`
+	UserID          string          `json:"user_id" db:"user_id"`
+	ActingUserID    *string         `json:"acting_user_id,omitempty" db:"acting_user_id"` // member writing for an organization
+	Content         string          `json:"content" db:"content"`
+	MediaIDs        ImageURLs       `json:"media_ids" db:"media_ids" gorm:"type:json"`
+	Status          PostDraftStatus `json:"status" db:"status"`
+	PublishAt       *time.Time      `json:"publish_at,omitempty" db:"publish_at"`
+	PublishedAt     *time.Time      `json:"published_at,omitempty" db:"published_at"`
+	PostID          *string         `json:"post_id,omitempty" db:"post_id"`
+	PublishAttempts int             `json:"publish_attempts" db:"publish_attempts"`
+	LastError       *string         `json:"last_error,omitempty" db:"last_error"`
+	LockToken       *string         `json:"-" db:"lock_token"`
+	LockedUntil     *time.Time      `json:"-" db:"locked_until"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	Media []MediaAttachment `json:"media,omitempty" gorm:"-"`
+}
+
+// IsEditable reports whether the draft can still be changed by its author
+func (d *PostDraft) IsEditable() bool {
+	for _, status := range PostDraftEditable {
+		if d.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+type SaveDraftRequest struct {
+	Content  string   `json:"content" binding:"required,min=1,max=5000"`
+	MediaIDs []string `json:"media_ids,omitempty" binding:"omitempty,max=4,dive,uuid"`
+	// Schedule the post; without it the draft is only saved
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+type ScheduleDraftRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"`
+}
+
+type DraftsFilter struct {
+	// Only drafts in this status; by default every draft not published yet
+	Status *PostDraftStatus `form:"status" binding:"omitempty,oneof=draft scheduled publishing published failed"`
+}
+
+type PostDraftsResponse struct {
+	Drafts     []PostDraft `json:"drafts"`
+	TotalCount int64       `json:"total_count"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+	HasMore    bool        `json:"has_more"`
+}
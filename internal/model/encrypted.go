@@ -0,0 +1,55 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"vietick-backend/pkg/encryption"
+)
+
+// EncryptedString is a string column encrypted at rest with the PII keyring
+// (encryption.SetDefault). Rows written before encryption was enabled are read as plaintext
+// until cmd/rotate-pii-keys encrypts them.
+type EncryptedString string
+
+// Implement sql.Scanner interface for encrypted fields
+func (es *EncryptedString) Scan(value interface{}) error {
+	if value == nil {
+		*es = ""
+		return nil
+	}
+
+	var stored string
+	switch v := value.(type) {
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("cannot scan %T into EncryptedString", value)
+	}
+
+	keyring := encryption.Default()
+	if keyring == nil {
+		if encryption.IsEncrypted(stored) {
+			return fmt.Errorf("cannot decrypt field: no keyring configured")
+		}
+		*es = EncryptedString(stored)
+		return nil
+	}
+	plaintext, err := keyring.DecryptString(stored)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt field: %w", err)
+	}
+	*es = EncryptedString(plaintext)
+	return nil
+}
+
+// Implement driver.Valuer interface for encrypted fields
+func (es EncryptedString) Value() (driver.Value, error) {
+	keyring := encryption.Default()
+	if keyring == nil {
+		return nil, fmt.Errorf("cannot encrypt field: no keyring configured")
+	}
+	return keyring.EncryptString(string(es))
+}
@@ -0,0 +1,90 @@
+package model
+
+import "time"
+
+// DataExportFormatVersion is the version of the archive layout, written to manifest.json.
+// Bump it whenever files are added, removed or change shape.
+const DataExportFormatVersion = 3
+
+type DataExportStatus string
+
+const (
+	// Waiting for the export worker
+	DataExportPending DataExportStatus = "pending"
+	// Being built by the worker holding the lock
+	DataExportProcessing DataExportStatus = "processing"
+	// Archive can be downloaded until ExpiresAt
+	DataExportReady DataExportStatus = "ready"
+	// Building failed too often; LastError says why
+	DataExportFailed DataExportStatus = "failed"
+	// Archive was deleted after ExpiresAt
+	DataExportExpired DataExportStatus = "expired"
+)
+
+// Sections of the archive, in the order they are built. Each one is a JSON and an HTML file;
+// media also adds the uploaded files.
+const (
+	DataExportSectionProfile      = "profile"
+	DataExportSectionPosts        = "posts"
+	DataExportSectionComments     = "comments"
+	DataExportSectionLikes        = "likes"
+	DataExportSectionVotes        = "votes"
+	DataExportSectionFollowers    = "followers"
+	DataExportSectionFollowing    = "following"
+	DataExportSectionSessions     = "sessions"
+	DataExportSectionVerification = "verification"
+	DataExportSectionMedia        = "media"
+)
+
+var DataExportSections = []string{
+	DataExportSectionProfile,
+	DataExportSectionPosts,
+	DataExportSectionComments,
+	DataExportSectionLikes,
+	DataExportSectionVotes,
+	DataExportSectionFollowers,
+	DataExportSectionFollowing,
+	DataExportSectionSessions,
+	DataExportSectionVerification,
+	DataExportSectionMedia,
+}
+
+// DataExport is a request of a user for an archive of their data
+type DataExport struct {
+	ID                string           `json:"id" db:"id"`
+	UserID            string           `json:"user_id" db:"user_id"`
+	Status            DataExportStatus `json:"status" db:"status"`
+	FormatVersion     int              `json:"format_version" db:"format_version"`
+	CompletedSections ImageURLs        `json:"completed_sections" db:"completed_sections" gorm:"type:json"`
+	Attempts          int              `json:"attempts" db:"attempts"`
+	LastError         *string          `json:"last_error,omitempty" db:"last_error"`
+	LockToken         *string          `json:"-" db:"lock_token"`
+	LockedUntil       *time.Time       `json:"-" db:"locked_until"`
+	StorageKey        *string          `json:"-" db:"storage_key"`
+	SizeBytes         *int64           `json:"size_bytes,omitempty" db:"size_bytes"`
+	ExpiresAt         *time.Time       `json:"expires_at,omitempty" db:"expires_at"`
+	CompletedAt       *time.Time       `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt         time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses: signed link while the archive is ready
+	DownloadURL string `json:"download_url,omitempty" gorm:"-"`
+}
+
+// HasSection reports whether section was already written by an earlier run
+func (e *DataExport) HasSection(section string) bool {
+	for _, done := range e.CompletedSections {
+		if done == section {
+			return true
+		}
+	}
+	return false
+}
+
+type DataExportsResponse struct {
+	Exports    []DataExport `json:"exports"`
+	TotalCount int64        `json:"total_count"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"page_size"`
+	HasMore    bool         `json:"has_more"`
+}
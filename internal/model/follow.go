@@ -24,3 +24,68 @@ type FollowingResponse struct {
 	PageSize   int           `json:"page_size"`
 	HasMore    bool          `json:"has_more"`
 }
+
+type FollowEventType string
+
+const (
+	FollowEventFollow   FollowEventType = "follow"
+	FollowEventUnfollow FollowEventType = "unfollow"
+)
+
+// FollowEvent is kept when the follow is removed, for follower analytics
+type FollowEvent struct {
+	ID          int64           `json:"-" db:"id"`
+	FollowerID  string          `json:"follower_id" db:"follower_id"`
+	FollowingID string          `json:"following_id" db:"following_id"`
+	Event       FollowEventType `json:"event" db:"event"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// FollowerDailyStat is computed nightly for each account and day
+type FollowerDailyStat struct {
+	UserID            string    `json:"-" db:"user_id" gorm:"primaryKey"`
+	Day               time.Time `json:"day" db:"day" gorm:"primaryKey;type:date"`
+	Follows           int       `json:"follows" db:"follows"`
+	Unfollows         int       `json:"unfollows" db:"unfollows"`
+	Followers         int       `json:"followers" db:"followers"` // at the end of the day
+	VerifiedFollowers int       `json:"verified_followers" db:"verified_followers"`
+}
+
+// Request models
+type FollowerAnalyticsFilter struct {
+	From string `form:"from"` // YYYY-MM-DD, UTC
+	To   string `form:"to"`   // YYYY-MM-DD, UTC, inclusive
+}
+
+// Response models
+type FollowerGrowthPoint struct {
+	Day               string `json:"day"` // YYYY-MM-DD
+	Follows           int    `json:"follows"`
+	Unfollows         int    `json:"unfollows"`
+	Net               int    `json:"net"`
+	Followers         int    `json:"followers"`
+	VerifiedFollowers int    `json:"verified_followers"`
+}
+
+type EngagingFollower struct {
+	User      UserProfile `json:"user"`
+	Reactions int         `json:"reactions"`
+	Comments  int         `json:"comments"`
+}
+
+type FollowerAnalytics struct {
+	From string `json:"from"` // YYYY-MM-DD
+	To   string `json:"to"`   // YYYY-MM-DD, inclusive
+	// Followers at the end of the day before From and of To
+	FollowersStart int `json:"followers_start"`
+	FollowersEnd   int `json:"followers_end"`
+	Follows        int `json:"follows"`
+	Unfollows      int `json:"unfollows"`
+	NetGrowth      int `json:"net_growth"`
+	// Unfollows over the followers at the start plus those gained during the range
+	ChurnRate         float64               `json:"churn_rate"`
+	VerifiedFollowers int                   `json:"verified_followers"`
+	VerifiedShare     float64               `json:"verified_share"` // of FollowersEnd
+	Series            []FollowerGrowthPoint `json:"series"`
+	TopFollowers      []EngagingFollower    `json:"top_followers"` // by reactions and comments on my posts
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+type LinkPreviewStatus string
+
+const (
+	LinkPreviewPending    LinkPreviewStatus = "pending"
+	LinkPreviewProcessing LinkPreviewStatus = "processing"
+	LinkPreviewReady      LinkPreviewStatus = "ready"
+	LinkPreviewFailed     LinkPreviewStatus = "failed"
+)
+
+// LinkPreview is the card of a link, cached per canonical URL and shared by every post
+// linking to it. Only previews with a title or description are shown.
+type LinkPreview struct {
+	ID          string            `json:"-" db:"id"`
+	URLHash     string            `json:"-" db:"url_hash"`
+	URL         string            `json:"url" db:"url"`
+	Status      LinkPreviewStatus `json:"-" db:"status"`
+	Title       string            `json:"title" db:"title"`
+	Description string            `json:"description,omitempty" db:"description"`
+	ImageURL    string            `json:"image_url,omitempty" db:"image_url"`
+	SiteName    string            `json:"site_name,omitempty" db:"site_name"`
+	Attempts    int               `json:"-" db:"attempts"`
+	LastError   *string           `json:"-" db:"last_error"`
+	FetchedAt   *time.Time        `json:"-" db:"fetched_at"`
+	ExpiresAt   *time.Time        `json:"-" db:"expires_at"`
+	CreatedAt   time.Time         `json:"-" db:"created_at"`
+	UpdatedAt   time.Time         `json:"-" db:"updated_at"`
+}
+
+// PostLink is a link in the content of a post
+type PostLink struct {
+	PostID        string `json:"post_id" db:"post_id"`
+	LinkPreviewID string `json:"link_preview_id" db:"link_preview_id"`
+	Position      int    `json:"position" db:"position"`
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type MediaPurpose string
+
+const (
+	MediaPurposePost             MediaPurpose = "post"
+	MediaPurposeAvatar           MediaPurpose = "avatar"
+	MediaPurposeIdentityDocument MediaPurpose = "identity_document"
+)
+
+type MediaStatus string
+
+const (
+	MediaStatusPending MediaStatus = "pending" // presigned upload not completed yet
+	MediaStatusReady   MediaStatus = "ready"
+)
+
+type MediaProcessingStatus string
+
+const (
+	MediaProcessingPending    MediaProcessingStatus = "pending"
+	MediaProcessingProcessing MediaProcessingStatus = "processing"
+	MediaProcessingDone       MediaProcessingStatus = "done"
+	MediaProcessingFailed     MediaProcessingStatus = "failed"
+	MediaProcessingSkipped    MediaProcessingStatus = "skipped" // not an image we process (GIF, PDF)
+)
+
+// Media is a file uploaded by a user. Posts, profiles and verification requests
+// reference media by ID instead of taking URLs from the client.
+type Media struct {
+	ID          string       `json:"id" db:"id"`
+	OwnerID     string       `json:"owner_id" db:"owner_id"`
+	Purpose     MediaPurpose `json:"purpose" db:"purpose"`
+	StorageKey  string       `json:"-" db:"storage_key"`
+	ContentType string       `json:"content_type" db:"content_type"`
+	Size        int64        `json:"size" db:"size"`
+	Status      MediaStatus  `json:"status" db:"status"`
+	URL         string       `json:"url" db:"url"`
+	ContentHash string       `json:"-" db:"content_hash"` // SHA-256 of the file as uploaded
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+
+	// Filled in by the image pipeline
+	Width              *int                  `json:"width,omitempty" db:"width"`
+	Height             *int                  `json:"height,omitempty" db:"height"`
+	Blurhash           *string               `json:"blurhash,omitempty" db:"blurhash"`
+	Variants           MediaVariants         `json:"variants,omitempty" db:"variants" gorm:"type:json"`
+	ProcessingStatus   MediaProcessingStatus `json:"processing_status" db:"processing_status"`
+	ProcessingAttempts int                   `json:"-" db:"processing_attempts"`
+	ProcessingError    *string               `json:"-" db:"processing_error"`
+}
+
+func (Media) TableName() string {
+	return "media"
+}
+
+// MediaVariant is a resized copy of an image
+type MediaVariant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Key    string `json:"-"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type MediaVariants []MediaVariant
+
+// Implement sql.Scanner interface for JSON fields
+func (mv *MediaVariants) Scan(value interface{}) error {
+	if value == nil {
+		*mv = MediaVariants{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into MediaVariants", value)
+	}
+
+	var stored []struct {
+		MediaVariant
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(bytes, &stored); err != nil {
+		return err
+	}
+	*mv = make(MediaVariants, len(stored))
+	for i, v := range stored {
+		(*mv)[i] = v.MediaVariant
+		(*mv)[i].Key = v.Key
+	}
+	return nil
+}
+
+// Implement driver.Valuer interface for JSON fields. The storage key is kept in the
+// database but never sent to clients.
+func (mv MediaVariants) Value() (driver.Value, error) {
+	if len(mv) == 0 {
+		return nil, nil
+	}
+	stored := make([]map[string]interface{}, len(mv))
+	for i, v := range mv {
+		stored[i] = map[string]interface{}{
+			"name":   v.Name,
+			"url":    v.URL,
+			"key":    v.Key,
+			"width":  v.Width,
+			"height": v.Height,
+		}
+	}
+	return json.Marshal(stored)
+}
+
+// MediaAttachment is the public view of media attached to a post or profile
+type MediaAttachment struct {
+	ID          string        `json:"id"`
+	ContentType string        `json:"content_type"`
+	URL         string        `json:"url"`
+	Width       *int          `json:"width,omitempty"`
+	Height      *int          `json:"height,omitempty"`
+	Blurhash    *string       `json:"blurhash,omitempty"`
+	Variants    MediaVariants `json:"variants,omitempty"`
+}
+
+func (m *Media) Attachment() MediaAttachment {
+	return MediaAttachment{
+		ID:          m.ID,
+		ContentType: m.ContentType,
+		URL:         m.URL,
+		Width:       m.Width,
+		Height:      m.Height,
+		Blurhash:    m.Blurhash,
+		Variants:    m.Variants,
+	}
+}
+
+// Request models
+type PresignMediaRequest struct {
+	Purpose     MediaPurpose `json:"purpose" binding:"required,oneof=post avatar identity_document"`
+	ContentType string       `json:"content_type" binding:"required"`
+	Size        int64        `json:"size" binding:"required,min=1"`
+}
+
+type PresignMediaResponse struct {
+	Media     *Media            `json:"media"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
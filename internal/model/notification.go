@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+type Notification struct {
+	ID        string           `json:"id" db:"id"`
+	UserID    string           `json:"user_id" db:"user_id"`
+	Type      NotificationType `json:"type" db:"type"`
+	Title     string           `json:"title" db:"title"`
+	Message   string           `json:"message" db:"message"`
+	EntityID  *string          `json:"entity_id" db:"entity_id"`
+	IsRead    bool             `json:"is_read" db:"is_read"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+type NotificationType string
+
+const (
+	NotificationReportOutcome     NotificationType = "report_outcome"
+	NotificationModerationWarning NotificationType = "moderation_warning"
+	NotificationContentRemoved    NotificationType = "content_removed"
+	NotificationAccountSuspended  NotificationType = "account_suspended"
+	NotificationPostPublished     NotificationType = "post_published"
+	NotificationPostPublishFailed NotificationType = "post_publish_failed"
+	NotificationPollClosed        NotificationType = "poll_closed"
+)
+
+type NotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+	TotalCount    int64          `json:"total_count"`
+	UnreadCount   int64          `json:"unread_count"`
+	Page          int            `json:"page"`
+	PageSize      int            `json:"page_size"`
+	HasMore       bool           `json:"has_more"`
+}
@@ -0,0 +1,94 @@
+package model
+
+import "time"
+
+// AccountType separates individuals from organization accounts (companies, news outlets).
+// Organizations have no password: their members act on their behalf.
+type AccountType string
+
+const (
+	AccountTypePersonal     AccountType = "personal"
+	AccountTypeOrganization AccountType = "organization"
+)
+
+type OrganizationRole string
+
+const (
+	// Manages every member, the organization's username and email
+	OrganizationRoleOwner OrganizationRole = "owner"
+	// Manages editors, the profile, follows and the organization's verification
+	OrganizationRoleAdmin OrganizationRole = "admin"
+	// Posts, comments and likes on behalf of the organization
+	OrganizationRoleEditor OrganizationRole = "editor"
+)
+
+var organizationRoleRank = map[OrganizationRole]int{
+	OrganizationRoleEditor: 1,
+	OrganizationRoleAdmin:  2,
+	OrganizationRoleOwner:  3,
+}
+
+// AtLeast reports whether role r grants everything role min does
+func (r OrganizationRole) AtLeast(min OrganizationRole) bool {
+	rank, ok := organizationRoleRank[r]
+	return ok && rank >= organizationRoleRank[min]
+}
+
+// CanManage reports whether a member with role r may add, change or remove a member
+// with role target. Owners manage everyone, admins manage editors.
+func (r OrganizationRole) CanManage(target OrganizationRole) bool {
+	return r == OrganizationRoleOwner || (r == OrganizationRoleAdmin && target == OrganizationRoleEditor)
+}
+
+type OrganizationMember struct {
+	OrganizationID string           `json:"organization_id" db:"organization_id"`
+	UserID         string           `json:"user_id" db:"user_id"`
+	Role           OrganizationRole `json:"role" db:"role"`
+	AddedBy        *string          `json:"added_by,omitempty" db:"added_by"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	User *UserProfile `json:"user,omitempty" gorm:"-"`
+}
+
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// Organization is an organization account together with the caller's role in it
+type Organization struct {
+	UserProfile
+	Role OrganizationRole `json:"role"`
+}
+
+type CreateOrganizationRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	FullName string `json:"full_name" binding:"required,min=1,max=100"`
+	// Contact address of the organization; it receives the verification emails
+	Email string  `json:"email" binding:"required,email"`
+	Bio   *string `json:"bio,omitempty" binding:"omitempty,max=500"`
+}
+
+type AddOrganizationMemberRequest struct {
+	UserID string           `json:"user_id" binding:"required,uuid"`
+	Role   OrganizationRole `json:"role" binding:"required,oneof=owner admin editor"`
+}
+
+type UpdateOrganizationMemberRequest struct {
+	Role OrganizationRole `json:"role" binding:"required,oneof=owner admin editor"`
+}
+
+type OrganizationMembersResponse struct {
+	OrganizationID string               `json:"organization_id"`
+	Members        []OrganizationMember `json:"members"`
+}
+
+// OrganizationTokenResponse is an access token of the member scoped to the organization:
+// requests made with it act on behalf of the organization without the X-Acting-As header
+type OrganizationTokenResponse struct {
+	AccessToken    string           `json:"access_token"`
+	OrganizationID string           `json:"organization_id"`
+	Role           OrganizationRole `json:"role"`
+	ExpiresIn      int64            `json:"expires_in"`
+}
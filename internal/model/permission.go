@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// AdminUserID is the account with admin privileges. It has every permission.
+const AdminUserID = "00000000-0000-0000-0000-000000000001"
+
+type Permission string
+
+const (
+	// Open identity documents of verification requests through signed URLs
+	PermissionViewIdentityDocuments Permission = "verification.documents.view"
+	// Claim and review verification requests; reviewers get requests assigned round-robin
+	PermissionReviewVerifications Permission = "verification.review"
+)
+
+// KnownPermissions lists the permissions an admin can grant
+var KnownPermissions = []Permission{
+	PermissionViewIdentityDocuments,
+	PermissionReviewVerifications,
+}
+
+func (p Permission) IsKnown() bool {
+	for _, known := range KnownPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// UserPermission grants a single permission to a user. The admin account has every
+// permission implicitly.
+type UserPermission struct {
+	UserID     string     `json:"user_id" db:"user_id"`
+	Permission Permission `json:"permission" db:"permission"`
+	GrantedBy  string     `json:"granted_by" db:"granted_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+func (UserPermission) TableName() string {
+	return "user_permissions"
+}
+
+type UserPermissionsResponse struct {
+	UserID      string           `json:"user_id"`
+	Permissions []UserPermission `json:"permissions"`
+}
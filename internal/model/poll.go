@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	PollMinOptions = 2
+	PollMaxOptions = 4
+)
+
+// Poll is attached to a post when it is created. Users vote until ClosesAt; with HideResults
+// the tallies stay hidden until then.
+type Poll struct {
+	ID             string     `json:"id" db:"id"`
+	PostID         string     `json:"post_id" db:"post_id"`
+	MultipleChoice bool       `json:"multiple_choice" db:"multiple_choice"`
+	HideResults    bool       `json:"hide_results" db:"hide_results"`
+	ClosesAt       time.Time  `json:"closes_at" db:"closes_at"`
+	ClosedAt       *time.Time `json:"-" db:"closed_at"` // set once the author and voters are notified
+	VoterCount     int        `json:"voter_count" db:"voter_count"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	Options       []PollOption `json:"options" gorm:"-"`
+	IsClosed      bool         `json:"is_closed" gorm:"-"`
+	ResultsHidden bool         `json:"results_hidden,omitempty" gorm:"-"` // vote counts are 0 until the poll closes
+	MyVote        []string     `json:"my_vote,omitempty" gorm:"-"`        // option IDs the viewer voted for
+}
+
+// Closed reports whether voting has ended at now
+func (p *Poll) Closed(now time.Time) bool {
+	return !now.Before(p.ClosesAt)
+}
+
+type PollOption struct {
+	ID        string `json:"id" db:"id"`
+	PollID    string `json:"-" db:"poll_id"`
+	Position  int    `json:"position" db:"position"`
+	Text      string `json:"text" db:"text"`
+	VoteCount int    `json:"vote_count" db:"vote_count"`
+}
+
+type PollVote struct {
+	ID        string    `json:"id" db:"id"`
+	PollID    string    `json:"poll_id" db:"poll_id"`
+	OptionID  string    `json:"option_id" db:"option_id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Request models
+type CreatePollRequest struct {
+	Options        []string  `json:"options" binding:"required,min=2,max=4,dive,required,max=100"`
+	MultipleChoice bool      `json:"multiple_choice"`
+	ClosesAt       time.Time `json:"closes_at" binding:"required"`
+	HideResults    bool      `json:"hide_results"` // hide the tallies until the poll closes
+}
+
+type PollVoteRequest struct {
+	// One option for single choice polls, one or more otherwise. Voting again replaces the ballot.
+	OptionIDs []string `json:"option_ids" binding:"required,min=1,max=4,dive,uuid"`
+}
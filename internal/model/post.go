@@ -5,21 +5,40 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Post struct {
-	ID           string     `json:"id" db:"id"`
-	UserID       string     `json:"user_id" db:"user_id"`
-	Content      string     `json:"content" db:"content"`
-	ImageURLs    ImageURLs  `json:"image_urls" db:"image_urls" gorm:"type:json"` // Thêm tag này
-	LikeCount    int        `json:"like_count" db:"like_count"`
-	CommentCount int        `json:"comment_count" db:"comment_count"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	ID              string         `json:"id" db:"id"`
+	UserID          string         `json:"user_id" db:"user_id"`
+	ActingUserID    *string        `json:"acting_user_id,omitempty" db:"acting_user_id"` // member writing for an organization
+	Content         string         `json:"content" db:"content"`
+	ImageURLs       ImageURLs      `json:"image_urls" db:"image_urls" gorm:"type:json"` // Thêm tag này
+	MediaIDs        ImageURLs      `json:"-" db:"media_ids" gorm:"type:json"`
+	LikeCount       int            `json:"like_count" db:"like_count"` // "like" reactions only
+	ReactionCounts  ReactionCounts `json:"reaction_counts" db:"reaction_counts" gorm:"type:json"`
+	CommentCount    int            `json:"comment_count" db:"comment_count"`
+	ImpressionCount int            `json:"impression_count" db:"impression_count"` // counted by AnalyticsService
+	ViewCount       int            `json:"view_count" db:"view_count"`
+	IsHidden        bool           `json:"is_hidden,omitempty" db:"is_hidden"`
+	EditedAt        *time.Time     `json:"edited_at,omitempty" db:"edited_at"`
+	RevisionCount   int            `json:"revision_count" db:"revision_count"` // number of edits
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+	// Bài đã xoá bị loại khỏi mọi truy vấn GORM cho tới khi bị xoá hẳn
+	DeletedAt      gorm.DeletedAt  `json:"-" db:"deleted_at"`
+	DeletedBy      *string         `json:"-" db:"deleted_by"`
+	DeletionSource *DeletionSource `json:"-" db:"deletion_source"`
 
 	// Additional fields for API responses
-	User    *UserProfile `json:"user,omitempty"`
-	IsLiked bool         `json:"is_liked,omitempty"`
+	User         *UserProfile      `json:"user,omitempty"`
+	IsLiked      bool              `json:"is_liked,omitempty" gorm:"-"`
+	Reaction     *ReactionType     `json:"reaction,omitempty" gorm:"-"` // the viewer's reaction
+	IsBookmarked bool              `json:"is_bookmarked,omitempty" gorm:"-"`
+	Media        []MediaAttachment `json:"media,omitempty" gorm:"-"`
+	Poll         *Poll             `json:"poll,omitempty" gorm:"-"`
+	LinkPreviews []LinkPreview     `json:"link_previews,omitempty" gorm:"-"`
 }
 
 type ImageURLs []string
@@ -47,22 +66,16 @@ func (iu ImageURLs) Value() (driver.Value, error) {
 	return json.Marshal(iu)
 }
 
-type PostLike struct {
-	ID        string    `json:"id" db:"id"`
-	PostID    string    `json:"post_id" db:"post_id"`
-	UserID    string    `json:"user_id" db:"user_id"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-}
-
 // Request models
 type CreatePostRequest struct {
-	Content   string   `json:"content" binding:"required,min=1,max=5000"`
-	ImageURLs []string `json:"image_urls,omitempty"`
+	Content  string             `json:"content" binding:"required,min=1,max=5000"`
+	MediaIDs []string           `json:"media_ids,omitempty" binding:"omitempty,max=4,dive,uuid"`
+	Poll     *CreatePollRequest `json:"poll,omitempty"`
 }
 
 type UpdatePostRequest struct {
-	Content   string   `json:"content" binding:"required,min=1,max=5000"`
-	ImageURLs []string `json:"image_urls,omitempty"`
+	Content  string   `json:"content" binding:"required,min=1,max=5000"`
+	MediaIDs []string `json:"media_ids,omitempty" binding:"omitempty,max=4,dive,uuid"`
 }
 
 type PostsResponse struct {
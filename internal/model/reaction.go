@@ -0,0 +1,120 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ReactionType is one of the fixed reactions to a post or comment
+type ReactionType string
+
+const (
+	ReactionLike  ReactionType = "like"
+	ReactionLove  ReactionType = "love"
+	ReactionHaha  ReactionType = "haha"
+	ReactionWow   ReactionType = "wow"
+	ReactionSad   ReactionType = "sad"
+	ReactionAngry ReactionType = "angry"
+)
+
+// ReactionTypes lists the reactions in display order
+var ReactionTypes = []ReactionType{ReactionLike, ReactionLove, ReactionHaha, ReactionWow, ReactionSad, ReactionAngry}
+
+// ReactionCounts is the number of reactions of each type, stored as JSON on posts and comments
+type ReactionCounts map[ReactionType]int
+
+// Total returns the number of reactions of any type
+func (rc ReactionCounts) Total() int {
+	total := 0
+	for _, count := range rc {
+		total += count
+	}
+	return total
+}
+
+// Implement sql.Scanner interface for JSON fields; reactions that dropped to 0 are left out
+func (rc *ReactionCounts) Scan(value interface{}) error {
+	*rc = ReactionCounts{}
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into ReactionCounts", value)
+	}
+
+	var counts map[ReactionType]int
+	if err := json.Unmarshal(bytes, &counts); err != nil {
+		return err
+	}
+	for reaction, count := range counts {
+		if count > 0 {
+			(*rc)[reaction] = count
+		}
+	}
+	return nil
+}
+
+// Implement driver.Valuer interface for JSON fields
+func (rc ReactionCounts) Value() (driver.Value, error) {
+	if len(rc) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(rc)
+}
+
+// PostReaction is a user's reaction to a post; a user has at most one per post
+type PostReaction struct {
+	ID        string       `json:"id" db:"id"`
+	PostID    string       `json:"post_id" db:"post_id"`
+	UserID    string       `json:"user_id" db:"user_id"`
+	Reaction  ReactionType `json:"reaction" db:"reaction"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// CommentReaction is a user's reaction to a comment; a user has at most one per comment
+type CommentReaction struct {
+	ID        string       `json:"id" db:"id"`
+	CommentID string       `json:"comment_id" db:"comment_id"`
+	UserID    string       `json:"user_id" db:"user_id"`
+	Reaction  ReactionType `json:"reaction" db:"reaction"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// Reactor is a user who reacted, in reaction lists
+type Reactor struct {
+	User      UserProfile  `json:"user"`
+	Reaction  ReactionType `json:"reaction"`
+	ReactedAt time.Time    `json:"reacted_at"`
+}
+
+// Request models
+type ReactRequest struct {
+	// Reacting again with another type replaces the previous reaction
+	Reaction ReactionType `json:"reaction" binding:"required,oneof=like love haha wow sad angry"`
+}
+
+type ReactionsFilter struct {
+	Type *ReactionType `form:"type" binding:"omitempty,oneof=like love haha wow sad angry"`
+}
+
+// Response models
+type ReactionResponse struct {
+	Reaction       *ReactionType  `json:"reaction"` // nil once removed
+	LikeCount      int            `json:"like_count"`
+	ReactionCounts ReactionCounts `json:"reaction_counts"`
+}
+
+type ReactionsResponse struct {
+	Reactions      []Reactor      `json:"reactions"`
+	ReactionCounts ReactionCounts `json:"reaction_counts"`
+	TotalCount     int64          `json:"total_count"`
+	Page           int            `json:"page"`
+	PageSize       int            `json:"page_size"`
+	HasMore        bool           `json:"has_more"`
+}
@@ -0,0 +1,127 @@
+package model
+
+import "time"
+
+type Report struct {
+	ID          string       `json:"id" db:"id"`
+	ReporterID  *string      `json:"reporter_id" db:"reporter_id"`
+	Source      ReportSource `json:"source" db:"source"`
+	TargetType  ReportTarget `json:"target_type" db:"target_type"`
+	TargetID    string       `json:"target_id" db:"target_id"`
+	TargetOwner *string      `json:"target_owner_id" db:"target_owner_id" gorm:"column:target_owner_id"`
+	Reason      ReportReason `json:"reason" db:"reason"`
+	Details     *string      `json:"details" db:"details"`
+	Status      ReportStatus `json:"status" db:"status"`
+	Resolution  *string      `json:"resolution" db:"resolution"`
+	ClaimedAt   *time.Time   `json:"-" db:"claimed_at"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	ResolvedAt  *time.Time   `json:"resolved_at" db:"resolved_at"`
+	ResolvedBy  *string      `json:"resolved_by" db:"resolved_by"`
+
+	// Additional fields for API responses
+	Reporter    *UserProfile `json:"reporter,omitempty" gorm:"-"`
+	ReportCount int64        `json:"report_count,omitempty" gorm:"-"`
+}
+
+type ReportTarget string
+
+const (
+	ReportTargetPost    ReportTarget = "post"
+	ReportTargetComment ReportTarget = "comment"
+	ReportTargetUser    ReportTarget = "user"
+	ReportTargetMessage ReportTarget = "message"
+)
+
+// ReportSource tells user reports apart from content held by an automatic content rule
+type ReportSource string
+
+const (
+	ReportSourceUser ReportSource = "user"
+	ReportSourceAuto ReportSource = "auto"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonSpam           ReportReason = "spam"
+	ReportReasonHarassment     ReportReason = "harassment"
+	ReportReasonHateSpeech     ReportReason = "hate_speech"
+	ReportReasonViolence       ReportReason = "violence"
+	ReportReasonNudity         ReportReason = "nudity"
+	ReportReasonMisinformation ReportReason = "misinformation"
+	ReportReasonImpersonation  ReportReason = "impersonation"
+	ReportReasonSelfHarm       ReportReason = "self_harm"
+	ReportReasonOther          ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	ReportStatusPending ReportStatus = "pending"
+	// Claimed by a moderator whose action is being applied
+	ReportStatusResolving ReportStatus = "resolving"
+	ReportStatusActioned  ReportStatus = "actioned"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+type ModerationActionType string
+
+const (
+	ModerationActionHide    ModerationActionType = "hide"
+	ModerationActionDelete  ModerationActionType = "delete"
+	ModerationActionWarn    ModerationActionType = "warn"
+	ModerationActionSuspend ModerationActionType = "suspend"
+	ModerationActionDismiss ModerationActionType = "dismiss"
+
+	// Only recorded for direct admin actions on accounts and deleted content, never applied
+	// to a report
+	ModerationActionBan       ModerationActionType = "ban"
+	ModerationActionUnsuspend ModerationActionType = "unsuspend"
+	ModerationActionRestore   ModerationActionType = "restore"
+)
+
+// ModerationAction is an append-only audit record of every moderator decision
+type ModerationAction struct {
+	ID            string               `json:"id" db:"id"`
+	ReportID      *string              `json:"report_id" db:"report_id"`
+	ModeratorID   string               `json:"moderator_id" db:"moderator_id"`
+	Action        ModerationActionType `json:"action" db:"action"`
+	TargetType    ReportTarget         `json:"target_type" db:"target_type"`
+	TargetID      string               `json:"target_id" db:"target_id"`
+	Notes         *string              `json:"notes" db:"notes"`
+	DurationHours *int                 `json:"duration_hours,omitempty" db:"duration_hours"`
+	CreatedAt     time.Time            `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	Moderator *UserProfile `json:"moderator,omitempty" gorm:"-"`
+}
+
+// Request models
+type CreateReportRequest struct {
+	TargetType ReportTarget `json:"target_type" binding:"required,oneof=post comment user message"`
+	TargetID   string       `json:"target_id" binding:"required"`
+	Reason     ReportReason `json:"reason" binding:"required,oneof=spam harassment hate_speech violence nudity misinformation impersonation self_harm other"`
+	Details    *string      `json:"details,omitempty" binding:"omitempty,max=1000"`
+}
+
+type ModerationActionRequest struct {
+	Action        ModerationActionType `json:"action" binding:"required,oneof=hide delete warn suspend dismiss"`
+	Notes         *string              `json:"notes,omitempty" binding:"omitempty,max=1000"`
+	DurationHours *int                 `json:"duration_hours,omitempty" binding:"omitempty,min=1,max=8760"`
+}
+
+type ReportsResponse struct {
+	Reports    []Report `json:"reports"`
+	TotalCount int64    `json:"total_count"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+	HasMore    bool     `json:"has_more"`
+}
+
+type ModerationActionsResponse struct {
+	Actions    []ModerationAction `json:"actions"`
+	TotalCount int64              `json:"total_count"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+	HasMore    bool               `json:"has_more"`
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// PostRevision is a version of a post replaced by an edit. Revision 0 is the original;
+// the current version is not stored here.
+type PostRevision struct {
+	ID        string    `json:"-" db:"id"`
+	PostID    string    `json:"post_id" db:"post_id"`
+	Revision  int       `json:"revision" db:"revision"`
+	Content   string    `json:"content" db:"content"`
+	ImageURLs ImageURLs `json:"image_urls" db:"image_urls" gorm:"type:json"`
+	MediaIDs  ImageURLs `json:"-" db:"media_ids" gorm:"type:json"`
+	Hashtags  ImageURLs `json:"hashtags" db:"hashtags" gorm:"type:json"`
+	// When this version was published
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// Additional fields for API responses
+	Media []MediaAttachment `json:"media,omitempty" gorm:"-"`
+}
+
+type CommentRevision struct {
+	ID        string    `json:"-" db:"id"`
+	CommentID string    `json:"comment_id" db:"comment_id"`
+	Revision  int       `json:"revision" db:"revision"`
+	Content   string    `json:"content" db:"content"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PostHistoryResponse lists every version of a post, the current one first
+type PostHistoryResponse struct {
+	PostID        string         `json:"post_id"`
+	RevisionCount int            `json:"revision_count"`
+	EditedAt      *time.Time     `json:"edited_at,omitempty"`
+	Revisions     []PostRevision `json:"revisions"`
+}
+
+type CommentHistoryResponse struct {
+	CommentID     string            `json:"comment_id"`
+	RevisionCount int               `json:"revision_count"`
+	EditedAt      *time.Time        `json:"edited_at,omitempty"`
+	Revisions     []CommentRevision `json:"revisions"`
+}
@@ -0,0 +1,22 @@
+package model
+
+// UserSuggestion is a lightweight user entry for typeahead results
+type UserSuggestion struct {
+	ID             string  `json:"id"`
+	Username       string  `json:"username"`
+	FullName       string  `json:"full_name"`
+	AvatarURL      *string `json:"avatar_url"`
+	IsVerified     bool    `json:"is_verified"`
+	FollowersCount int     `json:"followers_count"`
+	IsFollowing    bool    `json:"is_following,omitempty"`
+}
+
+type HashtagSuggestion struct {
+	Name      string `json:"name"`
+	PostCount int    `json:"post_count"`
+}
+
+type AutocompleteResponse struct {
+	Users    []UserSuggestion    `json:"users"`
+	Hashtags []HashtagSuggestion `json:"hashtags"`
+}
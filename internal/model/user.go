@@ -10,17 +10,29 @@ import (
 type User struct {
 	ID                         string                     `json:"id" db:"id"`
 	Username                   string                     `json:"username" db:"username"`
+	AccountType                AccountType                `json:"account_type" db:"account_type"`
 	Email                      string                     `json:"email" db:"email"`
 	PasswordHash               string                     `json:"-" db:"password_hash"`
 	FullName                   string                     `json:"full_name" db:"full_name"`
 	Bio                        *string                    `json:"bio" db:"bio"`
 	AvatarURL                  *string                    `json:"avatar_url" db:"avatar_url"`
+	AvatarMediaID              *string                    `json:"-" db:"avatar_media_id"`
 	IsVerified                 bool                       `json:"is_verified" db:"is_verified"`
 	IsEmailVerified            bool                       `json:"is_email_verified" db:"is_email_verified"`
 	EmailVerificationToken     *string                    `json:"-" db:"email_verification_token"`
 	EmailVerificationExpiresAt *time.Time                 `json:"-" db:"email_verification_expires_at"`
 	IdentityVerificationStatus IdentityVerificationStatus `json:"identity_verification_status" db:"identity_verification_status"`
-	IdentityDocuments          *IdentityDocuments         `json:"identity_documents" db:"identity_documents"`
+	VerificationCategory       *VerificationCategory      `json:"verification_category,omitempty" db:"verification_category"`
+	VerifiedAt                 *time.Time                 `json:"verified_at,omitempty" db:"verified_at"`
+	IdentityDocuments          *IdentityDocuments         `json:"-" db:"identity_documents"`
+	AccountStatus              AccountStatus              `json:"account_status" db:"account_status"`
+	StatusReason               *string                    `json:"status_reason,omitempty" db:"status_reason"`
+	SuspendedUntil             *time.Time                 `json:"suspended_until,omitempty" db:"suspended_until"`
+	TokensValidAfter           *time.Time                 `json:"-" db:"tokens_valid_after"`
+	DeactivatedAt              *time.Time                 `json:"-" db:"deactivated_at"`
+	DeletionRequestedAt        *time.Time                 `json:"-" db:"deletion_requested_at"`
+	ErasedAt                   *time.Time                 `json:"-" db:"erased_at"`
+	UsernameReleasedAt         *time.Time                 `json:"-" db:"username_released_at"`
 	CreatedAt                  time.Time                  `json:"created_at" db:"created_at"`
 	UpdatedAt                  time.Time                  `json:"updated_at" db:"updated_at"`
 }
@@ -32,8 +44,69 @@ const (
 	IdentityVerificationPending  IdentityVerificationStatus = "pending"
 	IdentityVerificationApproved IdentityVerificationStatus = "approved"
 	IdentityVerificationRejected IdentityVerificationStatus = "rejected"
+	IdentityVerificationRevoked  IdentityVerificationStatus = "revoked"
+	IdentityVerificationExpired  IdentityVerificationStatus = "expired"
 )
 
+// verificationTransitions lists the status changes a verification request can go through.
+// Rejected, revoked and expired requests are final: the user submits a new request.
+var verificationTransitions = map[IdentityVerificationStatus][]IdentityVerificationStatus{
+	IdentityVerificationPending:  {IdentityVerificationApproved, IdentityVerificationRejected},
+	IdentityVerificationApproved: {IdentityVerificationRevoked, IdentityVerificationExpired},
+}
+
+// CanTransitionTo reports whether a request in status s can move to status to
+func (s IdentityVerificationStatus) CanTransitionTo(to IdentityVerificationStatus) bool {
+	for _, next := range verificationTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type AccountStatus string
+
+const (
+	AccountStatusActive    AccountStatus = "active"
+	AccountStatusSuspended AccountStatus = "suspended"
+	AccountStatusBanned    AccountStatus = "banned"
+	// Closed by the user: deactivated accounts come back on the next login, deleted ones are erased
+	AccountStatusDeactivated AccountStatus = "deactivated"
+	AccountStatusDeleted     AccountStatus = "deleted"
+)
+
+// HiddenAccountStatuses are the statuses whose posts are hidden from every listing
+var HiddenAccountStatuses = []AccountStatus{AccountStatusBanned, AccountStatusDeactivated, AccountStatusDeleted}
+
+// IsHidden reports whether the content of accounts in this status is hidden
+func (s AccountStatus) IsHidden() bool {
+	for _, hidden := range HiddenAccountStatuses {
+		if s == hidden {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOrganization reports whether the account is run by the members of an organization
+func (u *User) IsOrganization() bool {
+	return u.AccountType == AccountTypeOrganization
+}
+
+// EffectiveStatus returns the account status, treating lapsed suspensions as active
+func (u *User) EffectiveStatus() AccountStatus {
+	switch u.AccountStatus {
+	case AccountStatusBanned, AccountStatusDeactivated, AccountStatusDeleted:
+		return u.AccountStatus
+	case AccountStatusSuspended:
+		if u.SuspendedUntil == nil || u.SuspendedUntil.After(time.Now()) {
+			return AccountStatusSuspended
+		}
+	}
+	return AccountStatusActive
+}
+
 type IdentityDocuments struct {
 	FrontImageURL  string `json:"front_image_url"`
 	BackImageURL   string `json:"back_image_url"`
@@ -73,6 +146,14 @@ type UserProfile struct {
 	IsFollowing    bool      `json:"is_following,omitempty"`
 	IsFollowedBy   bool      `json:"is_followed_by,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
+
+	// Only selected by the verified-users directory and profile lookups
+	AccountType          AccountType           `json:"account_type,omitempty"`
+	VerificationCategory *VerificationCategory `json:"verification_category,omitempty"`
+	VerifiedAt           *time.Time            `json:"verified_at,omitempty"`
+
+	AvatarMediaID *string          `json:"-"`
+	Avatar        *MediaAttachment `json:"avatar,omitempty" gorm:"-"`
 }
 
 // RegisterRequest represents user registration data
@@ -91,7 +172,36 @@ type LoginRequest struct {
 
 // UpdateProfileRequest represents profile update data
 type UpdateProfileRequest struct {
-	FullName  *string `json:"full_name,omitempty" binding:"omitempty,min=1,max=100"`
-	Bio       *string `json:"bio,omitempty" binding:"omitempty,max=500"`
-	AvatarURL *string `json:"avatar_url,omitempty"`
+	FullName *string `json:"full_name,omitempty" binding:"omitempty,min=1,max=100"`
+	Bio      *string `json:"bio,omitempty" binding:"omitempty,max=500"`
+	// Media uploaded with purpose "avatar"; an empty string removes the avatar
+	AvatarMediaID *string `json:"avatar_media_id,omitempty" binding:"omitempty,uuid|len=0"`
+}
+
+// DeleteAccountRequest confirms a permanent account deletion with the user's password
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// SuspendUserRequest represents an admin suspension or ban
+type SuspendUserRequest struct {
+	Status        AccountStatus `json:"status" binding:"required,oneof=suspended banned"`
+	Reason        string        `json:"reason" binding:"required,min=1,max=1000"`
+	DurationHours *int          `json:"duration_hours,omitempty" binding:"omitempty,min=1,max=8760"`
+}
+
+// SuspendedUser is the admin view of a restricted account
+type SuspendedUser struct {
+	UserProfile
+	AccountStatus  AccountStatus `json:"account_status"`
+	StatusReason   *string       `json:"status_reason"`
+	SuspendedUntil *time.Time    `json:"suspended_until"`
+}
+
+type SuspendedUsersResponse struct {
+	Users      []SuspendedUser `json:"users"`
+	TotalCount int64           `json:"total_count"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	HasMore    bool            `json:"has_more"`
 }
@@ -3,45 +3,141 @@ package model
 import "time"
 
 type IdentityVerification struct {
-	ID             string                     `json:"id" db:"id"`
-	UserID         string                     `json:"user_id" db:"user_id"`
-	FullName       string                     `json:"full_name" db:"full_name"`
-	IDNumber       string                     `json:"id_number" db:"id_number"`
-	IDType         IdentityDocumentType       `json:"id_type" db:"id_type"`
-	FrontImageURL  string                     `json:"front_image_url" db:"front_image_url"`
-	BackImageURL   *string                    `json:"back_image_url" db:"back_image_url"`
-	SelfieImageURL string                     `json:"selfie_image_url" db:"selfie_image_url"`
-	Status         IdentityVerificationStatus `json:"status" db:"status"`
-	AdminNotes     *string                    `json:"admin_notes" db:"admin_notes"`
-	SubmittedAt    time.Time                  `json:"submitted_at" db:"submitted_at"`
-	ReviewedAt     *time.Time                 `json:"reviewed_at" db:"reviewed_at"`
-	ReviewedBy     *string                    `json:"reviewed_by" db:"reviewed_by"`
+	ID          string                     `json:"id" db:"id"`
+	UserID      string                     `json:"user_id" db:"user_id"`
+	SubmittedBy *string                    `json:"submitted_by,omitempty" db:"submitted_by"` // member submitting for an organization
+	FullName    EncryptedString            `json:"full_name" db:"full_name"`
+	IDNumber    EncryptedString            `json:"id_number" db:"id_number"`
+	IDType      IdentityDocumentType       `json:"id_type" db:"id_type"`
+	Category    *VerificationCategory      `json:"category,omitempty" db:"category"`
+	Status      IdentityVerificationStatus `json:"status" db:"status"`
+	AdminNotes  *string                    `json:"admin_notes" db:"admin_notes"`
+	SubmittedAt time.Time                  `json:"submitted_at" db:"submitted_at"`
+	ReviewedAt  *time.Time                 `json:"reviewed_at" db:"reviewed_at"`
+	ReviewedBy  *string                    `json:"reviewed_by" db:"reviewed_by"`
+	// The blue tick of an approved request lapses at ExpiresAt for document types with a
+	// validity period (nil: never)
+	ExpiresAt        *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	ExpiryRemindedAt *time.Time `json:"-" db:"expiry_reminded_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokedBy        *string    `json:"revoked_by,omitempty" db:"revoked_by"`
+	RevocationReason *string    `json:"revocation_reason,omitempty" db:"revocation_reason"`
+	// Review queue: the reviewer the request was assigned to and the reviewer currently
+	// holding the lease on it. High-risk requests need two approvals.
+	AssignedTo     *string    `json:"assigned_to,omitempty" db:"assigned_to"`
+	AssignedAt     *time.Time `json:"assigned_at,omitempty" db:"assigned_at"`
+	ClaimedBy      *string    `json:"claimed_by,omitempty" db:"claimed_by"`
+	ClaimExpiresAt *time.Time `json:"claim_expires_at,omitempty" db:"claim_expires_at"`
+	HighRisk       bool       `json:"high_risk" db:"high_risk"`
+	// HMAC of the normalized ID number, to find the same document across accounts
+	IDNumberHash string `json:"-" db:"id_number_hash"`
+
+	// Documents live in private storage and are only reachable through signed URLs
+	// handed out to reviewers, never in API responses
+	FrontMediaID      *string    `json:"-" db:"front_media_id"`
+	BackMediaID       *string    `json:"-" db:"back_media_id"`
+	SelfieMediaID     *string    `json:"-" db:"selfie_media_id"`
+	DocumentsPurgedAt *time.Time `json:"documents_purged_at,omitempty" db:"documents_purged_at"`
+	// Public URLs of requests submitted before documents moved to private storage
+	FrontImageURL  string  `json:"-" db:"front_image_url"`
+	BackImageURL   *string `json:"-" db:"back_image_url"`
+	SelfieImageURL string  `json:"-" db:"selfie_image_url"`
 
 	// Additional fields for API responses
 	User     *UserProfile `json:"user,omitempty" gorm:"-"`
 	Reviewer *UserProfile `json:"reviewer,omitempty" gorm:"-"`
+	// Other accounts using the same ID number or document images (admin responses only)
+	DuplicateMatches []VerificationDuplicateMatch `json:"duplicate_matches,omitempty" gorm:"-"`
+	// Status changes of the request (admin responses only)
+	Events []VerificationEvent `json:"events,omitempty" gorm:"-"`
+	// Decisions of each reviewer (admin responses only)
+	Reviews []VerificationReview `json:"reviews,omitempty" gorm:"-"`
 }
 
+// RequiredApprovals is the number of reviewers who have to approve the request
+func (v *IdentityVerification) RequiredApprovals() int {
+	if v.HighRisk {
+		return 2
+	}
+	return 1
+}
+
+// DocumentMediaIDs returns the private media of the request keyed by side (front, back, selfie)
+func (v *IdentityVerification) DocumentMediaIDs() map[string]string {
+	ids := make(map[string]string, 3)
+	for side, id := range map[string]*string{
+		VerificationDocumentFront:  v.FrontMediaID,
+		VerificationDocumentBack:   v.BackMediaID,
+		VerificationDocumentSelfie: v.SelfieMediaID,
+	} {
+		if id != nil && *id != "" {
+			ids[side] = *id
+		}
+	}
+	return ids
+}
+
+const (
+	VerificationDocumentFront  = "front"
+	VerificationDocumentBack   = "back"
+	VerificationDocumentSelfie = "selfie"
+)
+
 type IdentityDocumentType string
 
 const (
 	IdentityDocumentNationalID    IdentityDocumentType = "national_id"
 	IdentityDocumentPassport      IdentityDocumentType = "passport"
 	IdentityDocumentDriverLicense IdentityDocumentType = "driver_license"
+	// Organizations verify with their business registration certificate; the number is the
+	// registration number and the name the registered name
+	IdentityDocumentBusinessRegistration IdentityDocumentType = "business_registration"
+)
+
+// VerificationCategory says what kind of notable account a verified user is
+type VerificationCategory string
+
+const (
+	VerificationCategoryJournalist VerificationCategory = "journalist"
+	VerificationCategoryGovernment VerificationCategory = "government"
+	VerificationCategoryBrand      VerificationCategory = "brand"
+	VerificationCategoryCreator    VerificationCategory = "creator"
 )
 
 type SubmitIdentityVerificationRequest struct {
-	FullName       string               `json:"full_name" binding:"required,min=1,max=100"`
-	IDNumber       string               `json:"id_number" binding:"required,min=1,max=50"`
-	IDType         IdentityDocumentType `json:"id_type" binding:"required,oneof=national_id passport driver_license"`
-	FrontImageURL  string               `json:"front_image_url" binding:"required"`
-	BackImageURL   *string              `json:"back_image_url,omitempty"`
-	SelfieImageURL string               `json:"selfie_image_url" binding:"required"`
+	FullName string               `json:"full_name" binding:"required,min=1,max=100"`
+	IDNumber string               `json:"id_number" binding:"required,min=1,max=50"`
+	IDType   IdentityDocumentType `json:"id_type" binding:"required,oneof=national_id passport driver_license business_registration"`
+	// Category the user applies for; the reviewer confirms or changes it
+	Category *VerificationCategory `json:"category,omitempty" binding:"omitempty,oneof=journalist government brand creator"`
+	// Media uploaded with purpose "identity_document"
+	FrontImageMediaID  string  `json:"front_image_media_id" binding:"required,uuid"`
+	BackImageMediaID   *string `json:"back_image_media_id,omitempty" binding:"omitempty,uuid"`
+	SelfieImageMediaID string  `json:"selfie_image_media_id" binding:"required,uuid"`
 }
 
 type ReviewIdentityVerificationRequest struct {
 	Status     IdentityVerificationStatus `json:"status" binding:"required,oneof=approved rejected"`
 	AdminNotes *string                    `json:"admin_notes,omitempty"`
+	// Category granted on approval, defaults to the one applied for
+	Category *VerificationCategory `json:"category,omitempty" binding:"omitempty,oneof=journalist government brand creator"`
+}
+
+type UpdateVerificationCategoryRequest struct {
+	// null removes the category
+	Category *VerificationCategory `json:"category" binding:"omitempty,oneof=journalist government brand creator"`
+}
+
+// VerifiedUsersFilter selects and orders the verified-users directory
+type VerifiedUsersFilter struct {
+	Category *VerificationCategory `form:"category" binding:"omitempty,oneof=journalist government brand creator"`
+	Query    string                `form:"q" binding:"max=100"`
+	// "followers" (most followed first) or "verified_at" (most recently verified first)
+	Sort string `form:"sort,default=followers" binding:"omitempty,oneof=followers verified_at"`
+}
+
+type RevokeIdentityVerificationRequest struct {
+	Reason string `json:"reason" binding:"required,min=1,max=1000"`
 }
 
 type IdentityVerificationsResponse struct {
@@ -51,3 +147,155 @@ type IdentityVerificationsResponse struct {
 	PageSize      int                    `json:"page_size"`
 	HasMore       bool                   `json:"has_more"`
 }
+
+// VerificationDocumentLink is a short-lived URL to one document of a verification request
+type VerificationDocumentLink struct {
+	Side        string    `json:"side"`
+	ContentType string    `json:"content_type"`
+	URL         string    `json:"url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type VerificationDocumentsResponse struct {
+	VerificationID string                     `json:"verification_id"`
+	Documents      []VerificationDocumentLink `json:"documents"`
+}
+
+type VerificationDocumentAccessAction string
+
+const (
+	VerificationDocumentLinkIssued VerificationDocumentAccessAction = "link_issued"
+	VerificationDocumentViewed     VerificationDocumentAccessAction = "viewed"
+)
+
+// VerificationDocumentAccess records every time a reviewer requests or opens a document
+type VerificationDocumentAccess struct {
+	ID             string                           `json:"id" db:"id"`
+	VerificationID string                           `json:"verification_id" db:"verification_id"`
+	MediaID        string                           `json:"media_id" db:"media_id"`
+	Side           string                           `json:"side" db:"side"`
+	ViewerID       string                           `json:"viewer_id" db:"viewer_id"`
+	Action         VerificationDocumentAccessAction `json:"action" db:"action"`
+	IPAddress      string                           `json:"ip_address" db:"ip_address"`
+	UserAgent      string                           `json:"user_agent" db:"user_agent"`
+	CreatedAt      time.Time                        `json:"created_at" db:"created_at"`
+
+	Viewer *UserProfile `json:"viewer,omitempty" gorm:"-"`
+}
+
+func (VerificationDocumentAccess) TableName() string {
+	return "verification_document_access_logs"
+}
+
+// VerificationDocumentFingerprint identifies a submitted document image. It is kept after
+// the documents are purged so that re-use can still be detected.
+type VerificationDocumentFingerprint struct {
+	VerificationID string    `json:"verification_id" db:"verification_id"`
+	UserID         string    `json:"user_id" db:"user_id"`
+	Side           string    `json:"side" db:"side"`
+	ContentHash    string    `json:"-" db:"content_hash"`    // SHA-256 of the file
+	PerceptualHash *uint64   `json:"-" db:"perceptual_hash"` // dHash, images only
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+func (VerificationDocumentFingerprint) TableName() string {
+	return "verification_document_fingerprints"
+}
+
+type VerificationMatchReason string
+
+const (
+	VerificationMatchIDNumber      VerificationMatchReason = "id_number"
+	VerificationMatchDocumentImage VerificationMatchReason = "document_image"
+	VerificationMatchSelfieImage   VerificationMatchReason = "selfie_image"
+)
+
+// VerificationDuplicateMatch flags another account's request that shares the ID number or
+// a document image with this one
+type VerificationDuplicateMatch struct {
+	ID                    string                     `json:"id" db:"id"`
+	VerificationID        string                     `json:"verification_id" db:"verification_id"`
+	MatchedVerificationID string                     `json:"matched_verification_id" db:"matched_verification_id"`
+	MatchedUserID         string                     `json:"matched_user_id" db:"matched_user_id"`
+	Reason                VerificationMatchReason    `json:"reason" db:"reason"`
+	Exact                 bool                       `json:"exact" db:"exact"` // false: visually similar image
+	Detail                string                     `json:"detail" db:"detail"`
+	MatchedStatus         IdentityVerificationStatus `json:"matched_status" db:"matched_status"` // when detected
+	CreatedAt             time.Time                  `json:"created_at" db:"created_at"`
+
+	MatchedUser *UserProfile `json:"matched_user,omitempty" gorm:"-"`
+}
+
+func (VerificationDuplicateMatch) TableName() string {
+	return "verification_duplicate_matches"
+}
+
+// VerificationEvent is an append-only record of a status change of a verification request
+type VerificationEvent struct {
+	ID             string                      `json:"id" db:"id"`
+	VerificationID string                      `json:"verification_id" db:"verification_id"`
+	UserID         string                      `json:"user_id" db:"user_id"`
+	FromStatus     *IdentityVerificationStatus `json:"from_status" db:"from_status"` // nil: submitted
+	ToStatus       IdentityVerificationStatus  `json:"to_status" db:"to_status"`
+	Reason         *string                     `json:"reason,omitempty" db:"reason"`
+	ActorID        *string                     `json:"actor_id,omitempty" db:"actor_id"` // nil: automatic
+	CreatedAt      time.Time                   `json:"created_at" db:"created_at"`
+}
+
+func (VerificationEvent) TableName() string {
+	return "verification_events"
+}
+
+// VerificationHistoryResponse lists every request of a user and what happened to them
+type VerificationHistoryResponse struct {
+	Verifications []IdentityVerification `json:"verifications"`
+	Events        []VerificationEvent    `json:"events"`
+	// Set while the user has to wait before submitting again after a rejection or revocation
+	ResubmitAfter *time.Time `json:"resubmit_after,omitempty"`
+}
+
+// VerificationReview is one reviewer's decision on a request. A high-risk request stays
+// pending after the first approval until a second reviewer approves it.
+type VerificationReview struct {
+	ID             string                     `json:"id" db:"id"`
+	VerificationID string                     `json:"verification_id" db:"verification_id"`
+	ReviewerID     string                     `json:"reviewer_id" db:"reviewer_id"`
+	Decision       IdentityVerificationStatus `json:"decision" db:"decision"`
+	Notes          *string                    `json:"notes,omitempty" db:"notes"`
+	CreatedAt      time.Time                  `json:"created_at" db:"created_at"`
+
+	Reviewer *UserProfile `json:"reviewer,omitempty" gorm:"-"`
+}
+
+func (VerificationReview) TableName() string {
+	return "verification_reviews"
+}
+
+// VerificationSLAStats describes how fast the review queue is worked through. Durations
+// are in seconds.
+type VerificationSLAStats struct {
+	TimeToFirstReview VerificationDurationStats `json:"time_to_first_review"`
+	BacklogAge        VerificationDurationStats `json:"backlog_age"`
+	Reviewers         []ReviewerThroughput      `json:"reviewers"`
+}
+
+type VerificationDurationStats struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+	P50     int64   `json:"p50"`
+	P90     int64   `json:"p90"`
+	P99     int64   `json:"p99"`
+	Max     int64   `json:"max"`
+}
+
+// ReviewerThroughput counts the decisions of one reviewer
+type ReviewerThroughput struct {
+	ReviewerID   string       `json:"reviewer_id"`
+	Reviews7d    int64        `json:"reviews_7d" gorm:"column:reviews_7d"`
+	Reviews30d   int64        `json:"reviews_30d" gorm:"column:reviews_30d"`
+	Approved30d  int64        `json:"approved_30d" gorm:"column:approved_30d"`
+	Rejected30d  int64        `json:"rejected_30d" gorm:"column:rejected_30d"`
+	Assigned     int64        `json:"assigned_pending" gorm:"-"`
+	ActiveClaims int64        `json:"active_claims" gorm:"-"`
+	Reviewer     *UserProfile `json:"reviewer,omitempty" gorm:"-"`
+}
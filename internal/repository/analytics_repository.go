@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"vietick-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Số id tối đa trong một mệnh đề IN
+const analyticsChunkSize = 1000
+
+type AnalyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// GetFollowedAuthors returns, for each of viewerIDs, the authors among authorIDs they follow
+func (r *AnalyticsRepository) GetFollowedAuthors(viewerIDs, authorIDs []string) (map[string]map[string]bool, error) {
+	followed := make(map[string]map[string]bool)
+	if len(viewerIDs) == 0 || len(authorIDs) == 0 {
+		return followed, nil
+	}
+	for start := 0; start < len(viewerIDs); start += analyticsChunkSize {
+		end := start + analyticsChunkSize
+		if end > len(viewerIDs) {
+			end = len(viewerIDs)
+		}
+		var follows []model.Follow
+		err := r.db.Select("follower_id, following_id").
+			Where("follower_id IN ? AND following_id IN ?", viewerIDs[start:end], authorIDs).
+			Find(&follows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to get follows: %w", err)
+		}
+		for _, follow := range follows {
+			if followed[follow.FollowerID] == nil {
+				followed[follow.FollowerID] = make(map[string]bool)
+			}
+			followed[follow.FollowerID][follow.FollowingID] = true
+		}
+	}
+	return followed, nil
+}
+
+// AddViewStats adds hourly counts to post_view_stats and to the counters of the posts.
+// Posts deleted for good since they were seen are skipped.
+func (r *AnalyticsRepository) AddViewStats(stats []model.PostViewStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	postIDs := make([]string, 0, len(stats))
+	totals := make(map[string]*model.PostViewStat)
+	for i := range stats {
+		total := totals[stats[i].PostID]
+		if total == nil {
+			total = &model.PostViewStat{PostID: stats[i].PostID}
+			totals[stats[i].PostID] = total
+			postIDs = append(postIDs, stats[i].PostID)
+		}
+		total.Impressions += stats[i].Impressions
+		total.Views += stats[i].Views
+	}
+	// Same order in every flush, so concurrent counter updates cannot deadlock
+	sort.Strings(postIDs)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		existing := make(map[string]bool, len(postIDs))
+		for start := 0; start < len(postIDs); start += analyticsChunkSize {
+			end := start + analyticsChunkSize
+			if end > len(postIDs) {
+				end = len(postIDs)
+			}
+			var ids []string
+			if err := tx.Unscoped().Model(&model.Post{}).Where("id IN ?", postIDs[start:end]).Pluck("id", &ids).Error; err != nil {
+				return fmt.Errorf("failed to get posts: %w", err)
+			}
+			for _, id := range ids {
+				existing[id] = true
+			}
+		}
+
+		rows := make([]model.PostViewStat, 0, len(stats))
+		for _, stat := range stats {
+			if existing[stat.PostID] {
+				rows = append(rows, stat)
+			}
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"impressions":          gorm.Expr("impressions + VALUES(impressions)"),
+				"follower_impressions": gorm.Expr("follower_impressions + VALUES(follower_impressions)"),
+				"views":                gorm.Expr("views + VALUES(views)"),
+				"follower_views":       gorm.Expr("follower_views + VALUES(follower_views)"),
+			}),
+		}).CreateInBatches(&rows, 500).Error
+		if err != nil {
+			return fmt.Errorf("failed to save post view stats: %w", err)
+		}
+
+		for _, postID := range postIDs {
+			if !existing[postID] {
+				continue
+			}
+			total := totals[postID]
+			err := tx.Unscoped().Model(&model.Post{}).Where("id = ?", postID).UpdateColumns(map[string]interface{}{
+				"impression_count": gorm.Expr("impression_count + ?", total.Impressions),
+				"view_count":       gorm.Expr("view_count + ?", total.Views),
+				// Lượt xem không phải là chỉnh sửa bài viết
+				"updated_at": gorm.Expr("updated_at"),
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update post counters: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetPostViewStats returns the hourly counts of a post between from and to
+func (r *AnalyticsRepository) GetPostViewStats(postID string, from, to time.Time) ([]model.PostViewStat, error) {
+	var stats []model.PostViewStat
+	err := r.db.Where("post_id = ? AND hour >= ? AND hour < ?", postID, from, to).
+		Order("hour ASC").Find(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post view stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetUserViewStats returns the hourly counts of all posts of a user between from and to
+func (r *AnalyticsRepository) GetUserViewStats(userID string, from, to time.Time) ([]model.PostViewStat, error) {
+	var stats []model.PostViewStat
+	err := r.db.Model(&model.PostViewStat{}).
+		Select("hour, SUM(impressions) AS impressions, SUM(follower_impressions) AS follower_impressions, SUM(views) AS views, SUM(follower_views) AS follower_views").
+		Where("user_id = ? AND hour >= ? AND hour < ?", userID, from, to).
+		Group("hour").Order("hour ASC").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get view stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetTopPosts returns the posts of a user with the most impressions between from and to
+func (r *AnalyticsRepository) GetTopPosts(userID string, from, to time.Time, limit int) ([]model.PostViewStat, error) {
+	var stats []model.PostViewStat
+	err := r.db.Model(&model.PostViewStat{}).
+		Select("post_id, SUM(impressions) AS impressions, SUM(views) AS views").
+		Where("user_id = ? AND hour >= ? AND hour < ?", userID, from, to).
+		Group("post_id").Order("impressions DESC, views DESC").Limit(limit).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top posts: %w", err)
+	}
+	return stats, nil
+}
+
+// CountPostReactionsByHour counts the reactions added to a post by others than its author
+func (r *AnalyticsRepository) CountPostReactionsByHour(postID, authorID string, from, to time.Time) (map[time.Time]int, error) {
+	query := r.db.Table("post_reactions").
+		Where("post_id = ? AND user_id <> ? AND created_at >= ? AND created_at < ?", postID, authorID, from, to)
+	return countByHour(query, "created_at")
+}
+
+// CountPostCommentsByHour counts the comments written on a post by others than its author
+func (r *AnalyticsRepository) CountPostCommentsByHour(postID, authorID string, from, to time.Time) (map[time.Time]int, error) {
+	query := r.db.Model(&model.Comment{}).
+		Where("post_id = ? AND user_id <> ? AND created_at >= ? AND created_at < ?", postID, authorID, from, to)
+	return countByHour(query, "created_at")
+}
+
+// CountUserReactionsByHour counts the reactions others added to the posts of a user
+func (r *AnalyticsRepository) CountUserReactionsByHour(userID string, from, to time.Time) (map[time.Time]int, error) {
+	query := r.db.Table("post_reactions").
+		Joins("JOIN posts ON posts.id = post_reactions.post_id AND posts.deleted_at IS NULL").
+		Where("posts.user_id = ? AND post_reactions.user_id <> ?", userID, userID).
+		Where("post_reactions.created_at >= ? AND post_reactions.created_at < ?", from, to)
+	return countByHour(query, "post_reactions.created_at")
+}
+
+// CountUserCommentsByHour counts the comments others wrote on the posts of a user
+func (r *AnalyticsRepository) CountUserCommentsByHour(userID string, from, to time.Time) (map[time.Time]int, error) {
+	query := r.db.Table("comments").
+		Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Where("comments.deleted_at IS NULL AND posts.user_id = ? AND comments.user_id <> ?", userID, userID).
+		Where("comments.created_at >= ? AND comments.created_at < ?", from, to)
+	return countByHour(query, "comments.created_at")
+}
+
+// CountNewFollowersByHour counts the follows of a user that still exist
+func (r *AnalyticsRepository) CountNewFollowersByHour(userID string, from, to time.Time) (map[time.Time]int, error) {
+	query := r.db.Model(&model.Follow{}).
+		Where("following_id = ? AND created_at >= ? AND created_at < ?", userID, from, to)
+	return countByHour(query, "created_at")
+}
+
+// countByHour counts the rows of query by hour of column. Hours are computed from the Unix
+// time, so they do not depend on the time zone of the connection.
+func countByHour(query *gorm.DB, column string) (map[time.Time]int, error) {
+	var rows []struct {
+		Hour  int64
+		Count int
+	}
+	err := query.Select(fmt.Sprintf("FLOOR(UNIX_TIMESTAMP(%s) / 3600) AS hour, COUNT(*) AS count", column)).
+		Group("hour").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count by hour: %w", err)
+	}
+	counts := make(map[time.Time]int, len(rows))
+	for _, row := range rows {
+		counts[time.Unix(row.Hour*3600, 0).UTC()] = row.Count
+	}
+	return counts, nil
+}
+
+// GetLatestFollowerStatsDay returns the last day (YYYY-MM-DD) follower stats were computed
+// for, or "" before the first run
+func (r *AnalyticsRepository) GetLatestFollowerStatsDay() (string, error) {
+	var day sql.NullString
+	err := r.db.Model(&model.FollowerDailyStat{}).Select("DATE_FORMAT(MAX(day), '%Y-%m-%d')").Scan(&day).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to get follower stats: %w", err)
+	}
+	return day.String, nil
+}
+
+// ComputeFollowerDay sums the follows, unfollows and engagement of followers of every account
+// between start and end into day, and takes the number of followers of each account. It can
+// run again for the same day.
+func (r *AnalyticsRepository) ComputeFollowerDay(day string, start, end time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Người theo dõi đã vô hiệu hoá hoặc xoá tài khoản không được tính, như GetFollowers
+		err := tx.Exec(`INSERT INTO follower_daily_stats (user_id, day, followers, verified_followers)
+			SELECT f.following_id, ?, COUNT(*), SUM(u.is_verified) FROM follows f
+			JOIN users u ON u.id = f.follower_id
+			WHERE `+openAccount+`
+			GROUP BY f.following_id
+			ON DUPLICATE KEY UPDATE followers = VALUES(followers), verified_followers = VALUES(verified_followers)`, day).Error
+		if err != nil {
+			return fmt.Errorf("failed to count followers: %w", err)
+		}
+
+		err = tx.Exec(`INSERT INTO follower_daily_stats (user_id, day, follows, unfollows)
+			SELECT following_id, ?, SUM(event = 'follow'), SUM(event = 'unfollow') FROM follow_events
+			WHERE created_at >= ? AND created_at < ?
+			GROUP BY following_id
+			ON DUPLICATE KEY UPDATE follows = VALUES(follows), unfollows = VALUES(unfollows)`, day, start, end).Error
+		if err != nil {
+			return fmt.Errorf("failed to count follow events: %w", err)
+		}
+
+		err = tx.Exec(`INSERT INTO follower_engagement_daily (user_id, day, follower_id, reactions)
+			SELECT p.user_id, ?, r.user_id, COUNT(*) FROM post_reactions r
+			JOIN posts p ON p.id = r.post_id AND p.deleted_at IS NULL
+			JOIN follows f ON f.follower_id = r.user_id AND f.following_id = p.user_id
+			WHERE r.created_at >= ? AND r.created_at < ?
+			GROUP BY p.user_id, r.user_id
+			ON DUPLICATE KEY UPDATE reactions = VALUES(reactions)`, day, start, end).Error
+		if err != nil {
+			return fmt.Errorf("failed to count follower reactions: %w", err)
+		}
+
+		err = tx.Exec(`INSERT INTO follower_engagement_daily (user_id, day, follower_id, comments)
+			SELECT p.user_id, ?, c.user_id, COUNT(*) FROM comments c
+			JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL
+			JOIN follows f ON f.follower_id = c.user_id AND f.following_id = p.user_id
+			WHERE c.deleted_at IS NULL AND c.created_at >= ? AND c.created_at < ?
+			GROUP BY p.user_id, c.user_id
+			ON DUPLICATE KEY UPDATE comments = VALUES(comments)`, day, start, end).Error
+		if err != nil {
+			return fmt.Errorf("failed to count follower comments: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetFollowerDailyStats returns the computed days of a user between from and to (YYYY-MM-DD,
+// inclusive). Days without a row had no followers and no follow events.
+func (r *AnalyticsRepository) GetFollowerDailyStats(userID, from, to string) ([]model.FollowerDailyStat, error) {
+	var stats []model.FollowerDailyStat
+	err := r.db.Where("user_id = ? AND day >= ? AND day <= ?", userID, from, to).
+		Order("day ASC").Find(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follower stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetTopFollowers returns the followers of a user who reacted to and commented on their posts
+// the most between from and to (YYYY-MM-DD, inclusive)
+func (r *AnalyticsRepository) GetTopFollowers(userID, from, to string, limit int) ([]model.EngagingFollower, error) {
+	var rows []struct {
+		model.UserProfile
+		Reactions int
+		Comments  int
+	}
+	err := r.db.Table("follower_engagement_daily e").
+		Select("u.id, u.username, u.full_name, u.bio, u.avatar_url, u.is_verified, u.created_at, SUM(e.reactions) AS reactions, SUM(e.comments) AS comments").
+		Joins("JOIN users u ON u.id = e.follower_id").
+		Where("e.user_id = ? AND e.day >= ? AND e.day <= ? AND "+openAccount, userID, from, to).
+		Group("u.id").
+		Order("SUM(e.reactions) + SUM(e.comments) DESC, SUM(e.comments) DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top followers: %w", err)
+	}
+	followers := make([]model.EngagingFollower, len(rows))
+	for i, row := range rows {
+		followers[i] = model.EngagingFollower{User: row.UserProfile, Reactions: row.Reactions, Comments: row.Comments}
+	}
+	return followers, nil
+}
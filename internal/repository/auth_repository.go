@@ -48,6 +48,15 @@ func (r *AuthRepository) DeleteUserRefreshTokens(userID string) error {
 	return nil
 }
 
+// GetUserRefreshTokens returns the sessions of userID, newest first
+func (r *AuthRepository) GetUserRefreshTokens(userID string) ([]model.RefreshToken, error) {
+	var tokens []model.RefreshToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user refresh tokens: %w", err)
+	}
+	return tokens, nil
+}
+
 func (r *AuthRepository) CleanupExpiredTokens() error {
 	if err := r.db.Where("expires_at <= ?", time.Now()).Delete(&model.RefreshToken{}).Error; err != nil {
 		return fmt.Errorf("failed to cleanup expired tokens: %w", err)
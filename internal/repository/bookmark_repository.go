@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"fmt"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BookmarkRepository struct {
+	db *gorm.DB
+}
+
+func NewBookmarkRepository(db *gorm.DB) *BookmarkRepository {
+	return &BookmarkRepository{db: db}
+}
+
+// Save bookmarks a post, or moves an existing bookmark to collectionID
+func (r *BookmarkRepository) Save(userID, postID string, collectionID *string) error {
+	err := r.db.Exec(`INSERT INTO bookmarks (id, user_id, post_id, collection_id) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE collection_id = VALUES(collection_id)`,
+		uuid.New().String(), userID, postID, collectionID).Error
+	if err != nil {
+		return fmt.Errorf("failed to bookmark post: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) Remove(userID, postID string) error {
+	result := r.db.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&model.Bookmark{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove bookmark: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("bookmark not found")
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) Get(userID, postID string) (*model.Bookmark, error) {
+	bookmark := &model.Bookmark{}
+	if err := r.db.Where("user_id = ? AND post_id = ?", userID, postID).First(bookmark).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("bookmark not found")
+		}
+		return nil, fmt.Errorf("failed to get bookmark: %w", err)
+	}
+	return bookmark, nil
+}
+
+// GetByUserID returns the bookmarks of userID, newest first. Bookmarks of posts that were
+// deleted, hidden by moderation or whose author is no longer visible are left out.
+func (r *BookmarkRepository) GetByUserID(userID string, collectionID *string, pagination utils.PaginationResult) ([]model.Bookmark, int64, error) {
+	query := r.visibleBookmarks(userID)
+	if collectionID != nil {
+		query = query.Where("b.collection_id = ?", *collectionID)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+
+	var bookmarks []model.Bookmark
+	err := query.Select("b.*").Order("b.created_at DESC, b.id DESC").
+		Limit(pagination.Limit).Offset(pagination.Offset).Scan(&bookmarks).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+	return bookmarks, totalCount, nil
+}
+
+// visibleBookmarks selects the bookmarks of userID that GetByUserID lists, so that the
+// collection counts match the listings
+func (r *BookmarkRepository) visibleBookmarks(userID string) *gorm.DB {
+	return r.db.Table("bookmarks b").
+		Joins("JOIN posts p ON p.id = b.post_id AND p.deleted_at IS NULL").
+		Where("b.user_id = ?", userID).
+		Where("p.is_hidden = FALSE OR p.user_id = ?", userID).
+		Where("p.user_id NOT IN (SELECT id FROM users WHERE account_status IN ?)", model.HiddenAccountStatuses)
+}
+
+func (r *BookmarkRepository) CreateCollection(collection *model.BookmarkCollection) error {
+	if err := r.db.Create(collection).Error; err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) GetCollection(collectionID string) (*model.BookmarkCollection, error) {
+	collection := &model.BookmarkCollection{}
+	if err := r.db.Where("id = ?", collectionID).First(collection).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("collection not found")
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return collection, nil
+}
+
+// GetCollections returns the collections of userID by name, with the number of bookmarks in each
+func (r *BookmarkRepository) GetCollections(userID string) ([]model.BookmarkCollection, error) {
+	var collections []model.BookmarkCollection
+	if err := r.db.Where("user_id = ?", userID).Order("name ASC").Find(&collections).Error; err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+	if len(collections) == 0 {
+		return collections, nil
+	}
+
+	var counts []struct {
+		CollectionID string
+		Count        int64
+	}
+	err := r.visibleBookmarks(userID).Select("b.collection_id, COUNT(*) AS count").
+		Where("b.collection_id IS NOT NULL").
+		Group("b.collection_id").Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+	byID := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byID[c.CollectionID] = c.Count
+	}
+	for i := range collections {
+		collections[i].BookmarkCount = byID[collections[i].ID]
+	}
+	return collections, nil
+}
+
+func (r *BookmarkRepository) CountCollections(userID string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.BookmarkCollection{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count collections: %w", err)
+	}
+	return count, nil
+}
+
+// CollectionNameTaken reports whether userID has another collection called name
+func (r *BookmarkRepository) CollectionNameTaken(userID, name, excludeID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.BookmarkCollection{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check collection name: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *BookmarkRepository) RenameCollection(collectionID, name string) error {
+	err := r.db.Model(&model.BookmarkCollection{}).Where("id = ?", collectionID).Update("name", name).Error
+	if err != nil {
+		return fmt.Errorf("failed to rename collection: %w", err)
+	}
+	return nil
+}
+
+// DeleteCollection deletes a collection; its bookmarks are kept as unsorted bookmarks
+func (r *BookmarkRepository) DeleteCollection(collectionID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Bookmark{}).Where("collection_id = ?", collectionID).Update("collection_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to update bookmarks: %w", err)
+		}
+		if err := tx.Where("id = ?", collectionID).Delete(&model.BookmarkCollection{}).Error; err != nil {
+			return fmt.Errorf("failed to delete collection: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteByUser xoá mọi bookmark và bộ sưu tập của userID, dùng khi xoá tài khoản
+func (r *BookmarkRepository) DeleteByUser(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.Bookmark{}).Error; err != nil {
+			return fmt.Errorf("failed to delete bookmarks: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.BookmarkCollection{}).Error; err != nil {
+			return fmt.Errorf("failed to delete collections: %w", err)
+		}
+		return nil
+	})
+}
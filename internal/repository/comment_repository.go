@@ -2,13 +2,18 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"vietick-backend/internal/model"
 	"vietick-backend/internal/utils"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Bình luận của bài viết đã xoá bị ẩn theo bài viết
+const onLivePost = "post_id IN (SELECT id FROM posts WHERE deleted_at IS NULL)"
+
 type CommentRepository struct {
 	db *gorm.DB
 }
@@ -22,8 +27,23 @@ func (r *CommentRepository) Create(comment *model.Comment) error {
 		if err := tx.Create(comment).Error; err != nil {
 			return fmt.Errorf("failed to create comment: %w", err)
 		}
-		if err := tx.Model(&model.Post{}).Where("id = ?", comment.PostID).UpdateColumn("comment_count", gorm.Expr("comment_count + 1")).Error; err != nil {
-			return fmt.Errorf("failed to update comment count: %w", err)
+		// comment_count only counts visible comments; a held comment is counted once released
+		if comment.IsHidden {
+			var count int64
+			if err := tx.Model(&model.Post{}).Where("id = ?", comment.PostID).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to get post: %w", err)
+			}
+			if count == 0 {
+				return fmt.Errorf("post not found")
+			}
+			return nil
+		}
+		result := tx.Model(&model.Post{}).Where("id = ?", comment.PostID).UpdateColumn("comment_count", gorm.Expr("comment_count + 1"))
+		if result.Error != nil {
+			return fmt.Errorf("failed to update comment count: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("post not found")
 		}
 		return nil
 	})
@@ -31,19 +51,21 @@ func (r *CommentRepository) Create(comment *model.Comment) error {
 
 func (r *CommentRepository) GetByID(commentID string, userID *string) (*model.Comment, error) {
 	comment := &model.Comment{}
-	if err := r.db.First(comment, commentID).Error; err != nil {
+	if err := r.db.Where(onLivePost).First(comment, commentID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("comment not found")
 		}
 		return nil, fmt.Errorf("failed to get comment: %w", err)
 	}
+	if comment.IsHidden && (userID == nil || *userID != comment.UserID) {
+		return nil, fmt.Errorf("comment not found")
+	}
 	user := &model.UserProfile{}
 	r.db.Model(&model.User{}).Select("id, username, full_name, bio, avatar_url, is_verified, created_at").Where("id = ?", comment.UserID).Scan(user)
 	comment.User = user
 	if userID != nil {
-		var count int64
-		r.db.Model(&model.Comment{}).Where("id = ? AND user_id = ?", commentID, *userID).Count(&count)
-		comment.IsLiked = count > 0
+		comment.Reaction, _ = r.GetReaction(commentID, *userID)
+		comment.IsLiked = comment.Reaction != nil && *comment.Reaction == model.ReactionLike
 	}
 	return comment, nil
 }
@@ -51,8 +73,13 @@ func (r *CommentRepository) GetByID(commentID string, userID *string) (*model.Co
 func (r *CommentRepository) GetPostComments(postID string, userID *string, pagination utils.PaginationResult) ([]model.Comment, int64, error) {
 	var comments []model.Comment
 	var totalCount int64
-	r.db.Model(&model.Comment{}).Where("post_id = ?", postID).Count(&totalCount)
-	if err := r.db.Where("post_id = ?", postID).Order("created_at ASC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&comments).Error; err != nil {
+	// Authors still see their own hidden comments, so shadow-hidden content looks published to them
+	visible := r.db.Where("is_hidden = ?", false)
+	if userID != nil {
+		visible = visible.Or("user_id = ?", *userID)
+	}
+	r.db.Model(&model.Comment{}).Where("post_id = ?", postID).Where(onLivePost).Where(visibleAuthor).Where(visible).Count(&totalCount)
+	if err := r.db.Where("post_id = ?", postID).Where(onLivePost).Where(visibleAuthor).Where(visible).Order("created_at ASC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&comments).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get comments: %w", err)
 	}
 	return comments, totalCount, nil
@@ -65,7 +92,54 @@ func (r *CommentRepository) Update(comment *model.Comment) error {
 	return nil
 }
 
-func (r *CommentRepository) Delete(commentID, userID string) error {
+// UpdateWithRevision lưu phiên bản cũ vào lịch sử rồi cập nhật bình luận
+func (r *CommentRepository) UpdateWithRevision(comment *model.Comment, revision *model.CommentRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current model.Comment
+		if err := tx.Select("post_id", "is_hidden").Where("id = ?", comment.ID).First(&current).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("comment not found")
+			}
+			return fmt.Errorf("failed to get comment: %w", err)
+		}
+		result := tx.Model(&model.Comment{}).
+			Where("id = ? AND revision_count = ?", comment.ID, revision.Revision).
+			Updates(map[string]interface{}{
+				"content":        comment.Content,
+				"is_hidden":      comment.IsHidden,
+				"edited_at":      comment.EditedAt,
+				"revision_count": gorm.Expr("revision_count + 1"),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update comment: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("invalid request: the comment was edited in the meantime, reload it and try again")
+		}
+		// An edit held by a content rule takes the comment out of the post's comment_count
+		if comment.IsHidden && !current.IsHidden {
+			if err := addToCommentCount(tx, current.PostID, -1); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("failed to save comment revision: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetRevisions trả về các phiên bản cũ của bình luận, mới nhất trước
+func (r *CommentRepository) GetRevisions(commentID string) ([]model.CommentRevision, error) {
+	var revisions []model.CommentRevision
+	if err := r.db.Where("comment_id = ?", commentID).Order("revision DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comment revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// Delete xoá mềm bình luận của tác giả và giảm comment_count của bài viết
+func (r *CommentRepository) Delete(commentID, userID, deletedBy string) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		var comment model.Comment
 		if err := tx.Where("id = ? AND user_id = ?", commentID, userID).First(&comment).Error; err != nil {
@@ -74,61 +148,400 @@ func (r *CommentRepository) Delete(commentID, userID string) error {
 			}
 			return fmt.Errorf("failed to get comment: %w", err)
 		}
-		if err := tx.Delete(&comment).Error; err != nil {
-			return fmt.Errorf("failed to delete comment: %w", err)
+		return softDeleteComment(tx, &comment, deletedBy, model.DeletionByAuthor)
+	})
+}
+
+// SetHidden hides or shows a comment and keeps the post's comment_count, which only counts
+// visible comments, in sync
+func (r *CommentRepository) SetHidden(commentID string, hidden bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var comment model.Comment
+		if err := tx.Select("id", "post_id", "is_hidden").Where("id = ?", commentID).First(&comment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("comment not found")
+			}
+			return fmt.Errorf("failed to get comment: %w", err)
+		}
+		// Only the update that actually changes the flag adjusts the counter
+		result := tx.Model(&model.Comment{}).Where("id = ? AND is_hidden = ?", commentID, !hidden).UpdateColumn("is_hidden", hidden)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update comment visibility: %w", result.Error)
 		}
-		if err := tx.Model(&model.Post{}).Where("id = ?", comment.PostID).UpdateColumn("comment_count", gorm.Expr("comment_count - 1")).Error; err != nil {
-			return fmt.Errorf("failed to update comment count: %w", err)
+		if result.RowsAffected == 0 {
+			return nil
 		}
+		delta := 1
+		if hidden {
+			delta = -1
+		}
+		return addToCommentCount(tx, comment.PostID, delta)
+	})
+}
+
+// addToCommentCount changes the post's comment_count, even when the post is deleted so that it
+// is right again once the post is restored
+func addToCommentCount(tx *gorm.DB, postID string, delta int) error {
+	err := tx.Unscoped().Model(&model.Post{}).Where("id = ?", postID).
+		UpdateColumn("comment_count", gorm.Expr("comment_count + ?", delta)).Error
+	if err != nil {
+		return fmt.Errorf("failed to update comment count: %w", err)
+	}
+	return nil
+}
+
+// DeleteByID removes a comment regardless of owner, keeping the post's comment_count in sync.
+// A comment the author already deleted becomes removed by moderation, so the author cannot
+// restore it.
+func (r *CommentRepository) DeleteByID(commentID, moderatorID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var comment model.Comment
+		err := tx.Unscoped().
+			Where("id = ? AND (deleted_at IS NULL OR deletion_source = ?)", commentID, model.DeletionByAuthor).
+			First(&comment).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("comment not found")
+			}
+			return fmt.Errorf("failed to get comment: %w", err)
+		}
+		return softDeleteComment(tx, &comment, moderatorID, model.DeletionByModeration)
+	})
+}
+
+// softDeleteComment marks the comment deleted and, if it was visible until now, takes it
+// out of the post's comment_count. The counter is updated even when the post itself is
+// deleted, so that it is right again once the post is restored. Hidden comments are not
+// counted.
+func softDeleteComment(tx *gorm.DB, comment *model.Comment, deletedBy string, source model.DeletionSource) error {
+	err := tx.Unscoped().Model(&model.Comment{}).Where("id = ?", comment.ID).
+		Updates(map[string]interface{}{
+			"deleted_at":      time.Now(),
+			"deleted_by":      deletedBy,
+			"deletion_source": source,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	if comment.DeletedAt.Valid || comment.IsHidden {
 		return nil
+	}
+	return addToCommentCount(tx, comment.PostID, -1)
+}
+
+// GetDeletedByID lấy bình luận đã xoá nhưng chưa bị xoá hẳn
+func (r *CommentRepository) GetDeletedByID(commentID string) (*model.Comment, error) {
+	comment := &model.Comment{}
+	if err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", commentID).First(comment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("deleted comment not found")
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// GetDeleted lists deleted comments, most recently deleted first. userID and since restrict
+// the list to what an author can still restore.
+func (r *CommentRepository) GetDeleted(userID *string, source *model.DeletionSource, since *time.Time, pagination utils.PaginationResult) ([]model.Comment, int64, error) {
+	query := r.db.Unscoped().Model(&model.Comment{}).Where("deleted_at IS NOT NULL")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if source != nil {
+		query = query.Where("deletion_source = ?", *source)
+	}
+	if since != nil {
+		query = query.Where("deleted_at >= ?", *since)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count deleted comments: %w", err)
+	}
+	var comments []model.Comment
+	if err := query.Order("deleted_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&comments).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get deleted comments: %w", err)
+	}
+	return comments, totalCount, nil
+}
+
+// Restore khôi phục bình luận tác giả đã xoá từ thời điểm since trở về sau
+func (r *CommentRepository) Restore(commentID, userID string, since time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var comment model.Comment
+		err := tx.Unscoped().
+			Where("id = ? AND user_id = ? AND deletion_source = ? AND deleted_at >= ?", commentID, userID, model.DeletionByAuthor, since).
+			First(&comment).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("deleted comment not found")
+			}
+			return fmt.Errorf("failed to get comment: %w", err)
+		}
+		return restoreComment(tx, &comment)
 	})
 }
 
-func (r *CommentRepository) LikeComment(commentID, userID string) error {
+// RestoreByID khôi phục bình luận đã xoá bất kể ai xoá (dùng cho kiểm duyệt)
+func (r *CommentRepository) RestoreByID(commentID string) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Insert like
-		res := tx.Exec("INSERT IGNORE INTO comment_likes (comment_id, user_id) VALUES (?, ?)", commentID, userID)
-		if res.Error != nil {
-			return fmt.Errorf("failed to like comment: %w", res.Error)
+		var comment model.Comment
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", commentID).First(&comment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("deleted comment not found")
+			}
+			return fmt.Errorf("failed to get comment: %w", err)
 		}
-		if res.RowsAffected == 0 {
-			return fmt.Errorf("comment already liked")
+		return restoreComment(tx, &comment)
+	})
+}
+
+func restoreComment(tx *gorm.DB, comment *model.Comment) error {
+	// The post has to be restored first; otherwise the comment would come back hidden
+	var count int64
+	if err := tx.Model(&model.Post{}).Where("id = ?", comment.PostID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get post: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("invalid request: the post of this comment is deleted")
+	}
+	if !comment.IsHidden {
+		if err := addToCommentCount(tx, comment.PostID, 1); err != nil {
+			return err
+		}
+	}
+	err := tx.Unscoped().Model(&model.Comment{}).Where("id = ?", comment.ID).
+		Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil, "deletion_source": nil}).Error
+	if err != nil {
+		return fmt.Errorf("failed to restore comment: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeleted xoá hẳn tối đa limit bình luận đã xoá trước before
+func (r *CommentRepository) PurgeDeleted(before time.Time, limit int) (int, error) {
+	var ids []string
+	err := r.db.Unscoped().Model(&model.Comment{}).
+		Where("deleted_at < ?", before).Order("deleted_at ASC").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get comments to purge: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if err := r.db.Unscoped().Where("id IN ? AND deleted_at < ?", ids, before).Delete(&model.Comment{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to purge comments: %w", err)
+	}
+	return len(ids), nil
+}
+
+// PurgeByUser xoá hẳn tối đa limit bình luận của userID, kể cả đã xoá, và trừ comment_count
+// của bài viết với những bình luận còn hiện. Trả về số bình luận đã xét.
+func (r *CommentRepository) PurgeByUser(userID string, limit int) (int, error) {
+	var comments []model.Comment
+	err := r.db.Unscoped().Select("id", "post_id", "is_hidden", "deleted_at").Where("user_id = ?", userID).Limit(limit).Find(&comments).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get comments to purge: %w", err)
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range comments {
+			res := tx.Unscoped().Where("id = ?", comments[i].ID).Delete(&model.Comment{})
+			if res.Error != nil {
+				return fmt.Errorf("failed to purge comment: %w", res.Error)
+			}
+			if res.RowsAffected == 0 || comments[i].DeletedAt.Valid || comments[i].IsHidden {
+				continue
+			}
+			if err := addToCommentCount(tx, comments[i].PostID, -1); err != nil {
+				return err
+			}
 		}
-		// Update like count
-		if err := tx.Model(&model.Comment{}).Where("id = ?", commentID).
-			UpdateColumn("like_count", gorm.Expr("like_count + 1")).Error; err != nil {
-			return fmt.Errorf("failed to update like count: %w", err)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(comments), nil
+}
+
+// RemoveUserReactions xoá tối đa limit reaction trên bình luận của userID và trừ số đếm tương ứng.
+// Trả về số reaction đã xét.
+func (r *CommentRepository) RemoveUserReactions(userID string, limit int) (int, error) {
+	var reactions []model.CommentReaction
+	if err := r.db.Where("user_id = ?", userID).Limit(limit).Find(&reactions).Error; err != nil {
+		return 0, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, reaction := range reactions {
+			res := tx.Where("id = ?", reaction.ID).Delete(&model.CommentReaction{})
+			if res.Error != nil {
+				return fmt.Errorf("failed to remove reaction: %w", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			if err := tx.Unscoped().Model(&model.Comment{}).Where("id = ?", reaction.CommentID).UpdateColumns(reactionCountUpdates(reaction.Reaction, -1)).Error; err != nil {
+				return fmt.Errorf("failed to update reaction count: %w", err)
+			}
 		}
 		return nil
 	})
+	if err != nil {
+		return 0, err
+	}
+	return len(reactions), nil
 }
 
-func (r *CommentRepository) UnlikeComment(commentID, userID string) error {
+// GetUserComments returns the comments written by userID, hidden ones included, oldest first
+func (r *CommentRepository) GetUserComments(userID string, pagination utils.PaginationResult) ([]model.Comment, error) {
+	var comments []model.Comment
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC, id ASC").
+		Limit(pagination.Limit).Offset(pagination.Offset).Find(&comments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user comments: %w", err)
+	}
+	return comments, nil
+}
+
+// GetUserReactions returns the reactions of userID to comments, oldest first
+func (r *CommentRepository) GetUserReactions(userID string, pagination utils.PaginationResult) ([]model.CommentReaction, error) {
+	var reactions []model.CommentReaction
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC, comment_id ASC").
+		Limit(pagination.Limit).Offset(pagination.Offset).Find(&reactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	return reactions, nil
+}
+
+// React đặt reaction của userID cho bình luận, thay cho reaction trước đó nếu có.
+// Trả về reaction trước đó.
+func (r *CommentRepository) React(commentID, userID string, reaction model.ReactionType) (*model.ReactionType, error) {
+	var previous *model.ReactionType
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Bình luận của bài viết đã xoá cũng coi như đã xoá
+		if err := lockRow(tx.Where(onLivePost), &model.Comment{}, commentID, "comment not found"); err != nil {
+			return err
+		}
+		existing := &model.CommentReaction{}
+		err := tx.Where("comment_id = ? AND user_id = ?", commentID, userID).First(existing).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to get reaction: %w", err)
+		}
+		if err == nil {
+			previous = &existing.Reaction
+			if existing.Reaction == reaction {
+				return nil
+			}
+			if err := tx.Model(existing).Update("reaction", reaction).Error; err != nil {
+				return fmt.Errorf("failed to react to comment: %w", err)
+			}
+			if err := tx.Model(&model.Comment{}).Where("id = ?", commentID).UpdateColumns(reactionCountUpdates(*previous, -1)).Error; err != nil {
+				return fmt.Errorf("failed to update reaction count: %w", err)
+			}
+		} else {
+			created := &model.CommentReaction{ID: uuid.New().String(), CommentID: commentID, UserID: userID, Reaction: reaction}
+			if err := tx.Create(created).Error; err != nil {
+				return fmt.Errorf("failed to react to comment: %w", err)
+			}
+		}
+		if err := tx.Model(&model.Comment{}).Where("id = ?", commentID).UpdateColumns(reactionCountUpdates(reaction, 1)).Error; err != nil {
+			return fmt.Errorf("failed to update reaction count: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// Unreact xoá reaction của userID trên bình luận; với only khác nil thì chỉ xoá reaction loại đó
+func (r *CommentRepository) Unreact(commentID, userID string, only *model.ReactionType) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Delete like
-		res := tx.Exec("DELETE FROM comment_likes WHERE comment_id = ? AND user_id = ?", commentID, userID)
-		if res.Error != nil {
-			return fmt.Errorf("failed to unlike comment: %w", res.Error)
+		if err := lockRow(tx.Where(onLivePost), &model.Comment{}, commentID, "comment not found"); err != nil {
+			return err
 		}
-		if res.RowsAffected == 0 {
-			return fmt.Errorf("comment not liked")
+		existing := &model.CommentReaction{}
+		query := tx.Where("comment_id = ? AND user_id = ?", commentID, userID)
+		if only != nil {
+			query = query.Where("reaction = ?", *only)
 		}
-		// Update like count
-		if err := tx.Model(&model.Comment{}).Where("id = ?", commentID).
-			UpdateColumn("like_count", gorm.Expr("like_count - 1")).Error; err != nil {
-			return fmt.Errorf("failed to update like count: %w", err)
+		if err := query.First(existing).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("reaction not found")
+			}
+			return fmt.Errorf("failed to get reaction: %w", err)
+		}
+		if err := tx.Delete(existing).Error; err != nil {
+			return fmt.Errorf("failed to remove reaction: %w", err)
+		}
+		if err := tx.Model(&model.Comment{}).Where("id = ?", commentID).UpdateColumns(reactionCountUpdates(existing.Reaction, -1)).Error; err != nil {
+			return fmt.Errorf("failed to update reaction count: %w", err)
 		}
 		return nil
 	})
 }
 
+// LikeComment giữ cho các endpoint like cũ: like là reaction "like"
+func (r *CommentRepository) LikeComment(commentID, userID string) error {
+	previous, err := r.React(commentID, userID, model.ReactionLike)
+	if err != nil {
+		return err
+	}
+	if previous != nil && *previous == model.ReactionLike {
+		return fmt.Errorf("comment already liked")
+	}
+	return nil
+}
+
+func (r *CommentRepository) UnlikeComment(commentID, userID string) error {
+	like := model.ReactionLike
+	err := r.Unreact(commentID, userID, &like)
+	if err != nil && err.Error() == "reaction not found" {
+		return fmt.Errorf("comment not liked")
+	}
+	return err
+}
+
+// GetReaction returns the reaction of userID to a comment, nil if there is none
+func (r *CommentRepository) GetReaction(commentID, userID string) (*model.ReactionType, error) {
+	reactions, err := r.GetViewerReactions(userID, []string{commentID})
+	if err != nil {
+		return nil, err
+	}
+	if reaction, ok := reactions[commentID]; ok {
+		return &reaction, nil
+	}
+	return nil, nil
+}
+
+// GetViewerReactions returns the reactions of userID to the given comments, by comment ID
+func (r *CommentRepository) GetViewerReactions(userID string, commentIDs []string) (map[string]model.ReactionType, error) {
+	reactions := make(map[string]model.ReactionType)
+	if len(commentIDs) == 0 {
+		return reactions, nil
+	}
+	var found []model.CommentReaction
+	if err := r.db.Select("comment_id, reaction").Where("user_id = ? AND comment_id IN ?", userID, commentIDs).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	for _, reaction := range found {
+		reactions[reaction.CommentID] = reaction.Reaction
+	}
+	return reactions, nil
+}
+
+// GetReactions returns who reacted to a comment, newest first, optionally only with one reaction
+func (r *CommentRepository) GetReactions(commentID string, reaction *model.ReactionType, pagination utils.PaginationResult) ([]model.Reactor, int64, error) {
+	return getReactors(r.db, "comment_reactions", "comment_id", commentID, reaction, pagination)
+}
+
 func (r *CommentRepository) IsCommentLikedByUser(commentID, userID string) (bool, error) {
-	var count int64
-	err := r.db.Table("comment_likes").
-		Where("comment_id = ? AND user_id = ?", commentID, userID).
-		Count(&count).Error
+	reaction, err := r.GetReaction(commentID, userID)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if comment is liked: %w", err)
 	}
-	return count > 0, nil
+	return reaction != nil && *reaction == model.ReactionLike, nil
 }
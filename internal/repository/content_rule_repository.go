@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vietick-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ContentRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewContentRuleRepository(db *gorm.DB) *ContentRuleRepository {
+	return &ContentRuleRepository{db: db}
+}
+
+func (r *ContentRuleRepository) Create(rule *model.ContentRule) error {
+	if err := r.db.Create(rule).Error; err != nil {
+		return fmt.Errorf("failed to create content rule: %w", err)
+	}
+	return nil
+}
+
+func (r *ContentRuleRepository) GetByID(ruleID string) (*model.ContentRule, error) {
+	rule := &model.ContentRule{}
+	if err := r.db.Where("id = ?", ruleID).First(rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("content rule not found")
+		}
+		return nil, fmt.Errorf("failed to get content rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *ContentRuleRepository) GetAll() ([]model.ContentRule, error) {
+	var rules []model.ContentRule
+	if err := r.db.Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get content rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *ContentRuleRepository) GetActive() ([]model.ContentRule, error) {
+	var rules []model.ContentRule
+	if err := r.db.Where("is_active = ?", true).Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active content rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *ContentRuleRepository) Update(rule *model.ContentRule) error {
+	if err := r.db.Save(rule).Error; err != nil {
+		return fmt.Errorf("failed to update content rule: %w", err)
+	}
+	return nil
+}
+
+func (r *ContentRuleRepository) Delete(ruleID string) error {
+	result := r.db.Where("id = ?", ruleID).Delete(&model.ContentRule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete content rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("content rule not found")
+	}
+	return nil
+}
+
+// RecordSubmission adds a post or comment to the history of the repeated-content rule
+func (r *ContentRuleRepository) RecordSubmission(authorID, fingerprint string, at time.Time) error {
+	submission := &model.ContentSubmission{AuthorID: authorID, Fingerprint: fingerprint, CreatedAt: at}
+	if err := r.db.Create(submission).Error; err != nil {
+		return fmt.Errorf("failed to record content submission: %w", err)
+	}
+	return nil
+}
+
+// CountSubmissions counts the author's submissions with the fingerprint since the given time
+func (r *ContentRuleRepository) CountSubmissions(authorID, fingerprint string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ContentSubmission{}).
+		Where("author_id = ? AND fingerprint = ? AND created_at > ?", authorID, fingerprint, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count content submissions: %w", err)
+	}
+	return count, nil
+}
+
+// PruneSubmissions deletes the history older than before
+func (r *ContentRuleRepository) PruneSubmissions(before time.Time) error {
+	if err := r.db.Where("created_at < ?", before).Delete(&model.ContentSubmission{}).Error; err != nil {
+		return fmt.Errorf("failed to prune content submissions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DraftRepository struct {
+	db *gorm.DB
+}
+
+func NewDraftRepository(db *gorm.DB) *DraftRepository {
+	return &DraftRepository{db: db}
+}
+
+func (r *DraftRepository) Create(draft *model.PostDraft) error {
+	if err := r.db.Create(draft).Error; err != nil {
+		return fmt.Errorf("failed to create draft: %w", err)
+	}
+	return nil
+}
+
+func (r *DraftRepository) GetByID(draftID string) (*model.PostDraft, error) {
+	draft := &model.PostDraft{}
+	if err := r.db.Where("id = ?", draftID).First(draft).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("draft not found")
+		}
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+	return draft, nil
+}
+
+func (r *DraftRepository) GetByUserID(userID string, status *model.PostDraftStatus, pagination utils.PaginationResult) ([]model.PostDraft, int64, error) {
+	query := r.db.Model(&model.PostDraft{}).Where("user_id = ?", userID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	} else {
+		query = query.Where("status <> ?", model.PostDraftPublished)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count drafts: %w", err)
+	}
+
+	// Scheduled drafts by publication time, then the most recently edited
+	var drafts []model.PostDraft
+	err := query.Order("publish_at IS NULL, publish_at ASC, updated_at DESC").
+		Limit(pagination.Limit).Offset(pagination.Offset).Find(&drafts).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get drafts: %w", err)
+	}
+	return drafts, totalCount, nil
+}
+
+// Update saves the author's changes unless the draft was picked up by the scheduler in
+// the meantime. The status is checked under a row lock: an unchanged save within the same
+// second affects no row, so the affected rows cannot tell the two apart.
+func (r *DraftRepository) Update(draft *model.PostDraft) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current model.PostDraft
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("status").
+			Where("id = ?", draft.ID).First(&current).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("draft not found")
+			}
+			return fmt.Errorf("failed to update draft: %w", err)
+		}
+		if !current.Status.Editable() {
+			return fmt.Errorf("invalid request: the draft is being published")
+		}
+
+		err = tx.Model(&model.PostDraft{}).Where("id = ?", draft.ID).
+			Updates(map[string]interface{}{
+				"content":          draft.Content,
+				"media_ids":        draft.MediaIDs,
+				"acting_user_id":   draft.ActingUserID,
+				"status":           draft.Status,
+				"publish_at":       draft.PublishAt,
+				"publish_attempts": draft.PublishAttempts,
+				"last_error":       draft.LastError,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update draft: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *DraftRepository) Delete(draftID string) error {
+	result := r.db.Where("id = ? AND status IN ?", draftID, model.PostDraftEditable).Delete(&model.PostDraft{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete draft: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("invalid request: the draft is being published")
+	}
+	return nil
+}
+
+// GetDueIDs returns scheduled drafts whose time has come, oldest first, and drafts left
+// locked by a publisher that stopped (crash, restart)
+func (r *DraftRepository) GetDueIDs(now time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&model.PostDraft{}).
+		Where("(status = ? AND publish_at <= ?) OR (status = ? AND locked_until < ?)",
+			model.PostDraftScheduled, now, model.PostDraftPublishing, now).
+		Order("publish_at ASC").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due drafts: %w", err)
+	}
+	return ids, nil
+}
+
+// ClaimDue locks a due draft for publishing. Only one instance gets the lock; a lock that
+// expired can be taken over.
+func (r *DraftRepository) ClaimDue(draftID, token string, now, lockedUntil time.Time) (bool, error) {
+	result := r.db.Model(&model.PostDraft{}).
+		Where("id = ?", draftID).
+		Where("(status = ? AND publish_at <= ?) OR (status = ? AND locked_until < ?)",
+			model.PostDraftScheduled, now, model.PostDraftPublishing, now).
+		Updates(map[string]interface{}{
+			"status":       model.PostDraftPublishing,
+			"lock_token":   token,
+			"locked_until": lockedUntil,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim draft: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Claim locks an editable draft of the user for publishing right away
+func (r *DraftRepository) Claim(draftID, userID, token string, lockedUntil time.Time) (bool, error) {
+	result := r.db.Model(&model.PostDraft{}).
+		Where("id = ? AND user_id = ? AND status IN ?", draftID, userID, model.PostDraftEditable).
+		Updates(map[string]interface{}{
+			"status":       model.PostDraftPublishing,
+			"lock_token":   token,
+			"locked_until": lockedUntil,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim draft: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Publish creates the post and marks the draft published in one transaction, as long as
+// the lock is still held. The post has the draft's ID, so a draft is never published twice.
+func (r *DraftRepository) Publish(draftID, token string, post *model.Post) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return fmt.Errorf("failed to create post: %w", err)
+		}
+		now := time.Now()
+		result := tx.Model(&model.PostDraft{}).
+			Where("id = ? AND status = ? AND lock_token = ?", draftID, model.PostDraftPublishing, token).
+			Updates(map[string]interface{}{
+				"status":       model.PostDraftPublished,
+				"published_at": now,
+				"post_id":      post.ID,
+				"last_error":   nil,
+				"lock_token":   nil,
+				"locked_until": nil,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update draft: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("invalid request: the draft is no longer being published by this instance")
+		}
+		return nil
+	})
+}
+
+// Release gives up the lock after a failed attempt, leaving the draft in status
+func (r *DraftRepository) Release(draftID, token string, status model.PostDraftStatus, attempts int, lastError *string) error {
+	err := r.db.Model(&model.PostDraft{}).
+		Where("id = ? AND lock_token = ?", draftID, token).
+		Updates(map[string]interface{}{
+			"status":           status,
+			"publish_attempts": attempts,
+			"last_error":       lastError,
+			"lock_token":       nil,
+			"locked_until":     nil,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release draft: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUser xoá mọi bản nháp của userID, dùng khi xoá tài khoản
+func (r *DraftRepository) DeleteByUser(userID string) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&model.PostDraft{}).Error; err != nil {
+		return fmt.Errorf("failed to delete drafts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type ExportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+func (r *ExportRepository) Create(export *model.DataExport) error {
+	if err := r.db.Create(export).Error; err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	return nil
+}
+
+func (r *ExportRepository) GetByID(exportID string) (*model.DataExport, error) {
+	export := &model.DataExport{}
+	if err := r.db.Where("id = ?", exportID).First(export).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("export not found")
+		}
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+	return export, nil
+}
+
+func (r *ExportRepository) GetByUserID(userID string, pagination utils.PaginationResult) ([]model.DataExport, int64, error) {
+	var totalCount int64
+	if err := r.db.Model(&model.DataExport{}).Where("user_id = ?", userID).Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count exports: %w", err)
+	}
+
+	var exports []model.DataExport
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").
+		Limit(pagination.Limit).Offset(pagination.Offset).Find(&exports).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get exports: %w", err)
+	}
+	return exports, totalCount, nil
+}
+
+// GetLatest returns the most recent export of userID that did not fail, or nil
+func (r *ExportRepository) GetLatest(userID string) (*model.DataExport, error) {
+	var exports []model.DataExport
+	err := r.db.Where("user_id = ? AND status <> ?", userID, model.DataExportFailed).
+		Order("created_at DESC").Limit(1).Find(&exports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exports: %w", err)
+	}
+	if len(exports) == 0 {
+		return nil, nil
+	}
+	return &exports[0], nil
+}
+
+// GetDueIDs returns waiting exports, oldest first, and exports left locked by a worker
+// that stopped (crash, restart)
+func (r *ExportRepository) GetDueIDs(now time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&model.DataExport{}).
+		Where("status = ? OR (status = ? AND locked_until < ?)",
+			model.DataExportPending, model.DataExportProcessing, now).
+		Order("created_at ASC").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due exports: %w", err)
+	}
+	return ids, nil
+}
+
+// Claim locks an export for building. Only one instance gets the lock; a lock that expired
+// can be taken over.
+func (r *ExportRepository) Claim(exportID, token string, now, lockedUntil time.Time) (bool, error) {
+	result := r.db.Model(&model.DataExport{}).
+		Where("id = ?", exportID).
+		Where("status = ? OR (status = ? AND locked_until < ?)",
+			model.DataExportPending, model.DataExportProcessing, now).
+		Updates(map[string]interface{}{
+			"status":       model.DataExportProcessing,
+			"lock_token":   token,
+			"locked_until": lockedUntil,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim export: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SaveProgress records the sections written so far, in the given archive format, and extends the lock
+func (r *ExportRepository) SaveProgress(exportID, token string, formatVersion int, sections model.ImageURLs, lockedUntil time.Time) error {
+	result := r.db.Model(&model.DataExport{}).
+		Where("id = ? AND status = ? AND lock_token = ?", exportID, model.DataExportProcessing, token).
+		Updates(map[string]interface{}{
+			"format_version":     formatVersion,
+			"completed_sections": sections,
+			"locked_until":       lockedUntil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to save export progress: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("invalid request: the export is no longer built by this instance")
+	}
+	return nil
+}
+
+// Complete marks the export ready to download, as long as the lock is still held
+func (r *ExportRepository) Complete(exportID, token, storageKey string, size int64, expiresAt time.Time) error {
+	now := time.Now()
+	result := r.db.Model(&model.DataExport{}).
+		Where("id = ? AND status = ? AND lock_token = ?", exportID, model.DataExportProcessing, token).
+		Updates(map[string]interface{}{
+			"status":       model.DataExportReady,
+			"storage_key":  storageKey,
+			"size_bytes":   size,
+			"expires_at":   expiresAt,
+			"completed_at": now,
+			"last_error":   nil,
+			"lock_token":   nil,
+			"locked_until": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to complete export: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("invalid request: the export is no longer built by this instance")
+	}
+	return nil
+}
+
+// Release gives up the lock after a failed attempt, leaving the export in status
+func (r *ExportRepository) Release(exportID, token string, status model.DataExportStatus, attempts int, lastError *string) error {
+	err := r.db.Model(&model.DataExport{}).
+		Where("id = ? AND lock_token = ?", exportID, token).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"attempts":     attempts,
+			"last_error":   lastError,
+			"lock_token":   nil,
+			"locked_until": nil,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release export: %w", err)
+	}
+	return nil
+}
+
+// GetExpired returns ready exports whose download period is over
+func (r *ExportRepository) GetExpired(now time.Time, limit int) ([]model.DataExport, error) {
+	var exports []model.DataExport
+	err := r.db.Where("status = ? AND expires_at < ?", model.DataExportReady, now).
+		Order("expires_at ASC").Limit(limit).Find(&exports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired exports: %w", err)
+	}
+	return exports, nil
+}
+
+func (r *ExportRepository) MarkExpired(exportID string) error {
+	err := r.db.Model(&model.DataExport{}).
+		Where("id = ? AND status = ?", exportID, model.DataExportReady).
+		Updates(map[string]interface{}{
+			"status":      model.DataExportExpired,
+			"storage_key": nil,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to expire export: %w", err)
+	}
+	return nil
+}
+
+// GetAllByUserID returns every export of userID, used when the account is deleted
+func (r *ExportRepository) GetAllByUserID(userID string) ([]model.DataExport, error) {
+	var exports []model.DataExport
+	if err := r.db.Where("user_id = ?", userID).Find(&exports).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exports: %w", err)
+	}
+	return exports, nil
+}
+
+func (r *ExportRepository) Delete(exportID string) error {
+	if err := r.db.Where("id = ?", exportID).Delete(&model.DataExport{}).Error; err != nil {
+		return fmt.Errorf("failed to delete export: %w", err)
+	}
+	return nil
+}
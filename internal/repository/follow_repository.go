@@ -1,14 +1,19 @@
 package repository
 
 import (
+	"database/sql"
 	"fmt"
 
 	"vietick-backend/internal/model"
 	"vietick-backend/internal/utils"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Người theo dõi đã vô hiệu hoá hoặc xoá tài khoản không hiện trong danh sách
+const openAccount = "u.account_status NOT IN ('deactivated', 'deleted')"
+
 type FollowRepository struct {
 	db *gorm.DB
 }
@@ -27,20 +32,32 @@ func (r *FollowRepository) Follow(followerID, followingID string) error {
 	if count > 0 {
 		return fmt.Errorf("already following this user")
 	}
-	follow := &model.Follow{FollowerID: followerID, FollowingID: followingID}
-	if err := r.db.Create(follow).Error; err != nil {
-		return fmt.Errorf("failed to follow user: %w", err)
-	}
-	return nil
+	follow := &model.Follow{ID: uuid.New().String(), FollowerID: followerID, FollowingID: followingID}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(follow).Error; err != nil {
+			return fmt.Errorf("failed to follow user: %w", err)
+		}
+		return logFollowEvent(tx, followerID, followingID, model.FollowEventFollow)
+	})
 }
 
 func (r *FollowRepository) Unfollow(followerID, followingID string) error {
-	result := r.db.Where("follower_id = ? AND following_id = ?", followerID, followingID).Delete(&model.Follow{})
-	if result.Error != nil {
-		return fmt.Errorf("failed to unfollow user: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("not following this user")
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND following_id = ?", followerID, followingID).Delete(&model.Follow{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to unfollow user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("not following this user")
+		}
+		return logFollowEvent(tx, followerID, followingID, model.FollowEventUnfollow)
+	})
+}
+
+// logFollowEvent ghi lại lượt theo dõi hoặc bỏ theo dõi cho thống kê người theo dõi
+func logFollowEvent(tx *gorm.DB, followerID, followingID string, event model.FollowEventType) error {
+	if err := tx.Create(&model.FollowEvent{FollowerID: followerID, FollowingID: followingID, Event: event}).Error; err != nil {
+		return fmt.Errorf("failed to log follow event: %w", err)
 	}
 	return nil
 }
@@ -59,7 +76,7 @@ func (r *FollowRepository) GetFollowers(userID string, viewerID *string, paginat
 		SELECT u.id, u.username, u.full_name, u.bio, u.avatar_url, u.is_verified, u.created_at,
 		       (SELECT COUNT(*) FROM follows WHERE following_id = u.id) as followers_count,
 		       (SELECT COUNT(*) FROM follows WHERE follower_id = u.id) as following_count,
-		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id) as posts_count
+		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id AND deleted_at IS NULL AND is_hidden = FALSE) as posts_count
 	`
 	if viewerID != nil {
 		query += `,
@@ -70,12 +87,12 @@ func (r *FollowRepository) GetFollowers(userID string, viewerID *string, paginat
 	query += `
 		FROM users u
 		JOIN follows f ON u.id = f.follower_id
-		WHERE f.following_id = ?
+		WHERE f.following_id = ? AND ` + openAccount + `
 		ORDER BY f.created_at DESC
 		LIMIT ? OFFSET ?
 	`
 
-	countQuery := `SELECT COUNT(*) FROM follows WHERE following_id = ?`
+	countQuery := `SELECT COUNT(*) FROM follows f JOIN users u ON u.id = f.follower_id WHERE f.following_id = ? AND ` + openAccount
 	var totalCount int64
 	if err := r.db.Raw(countQuery, userID).Scan(&totalCount).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count followers: %w", err)
@@ -99,7 +116,7 @@ func (r *FollowRepository) GetFollowing(userID string, viewerID *string, paginat
 		SELECT u.id, u.username, u.full_name, u.bio, u.avatar_url, u.is_verified, u.created_at,
 		       (SELECT COUNT(*) FROM follows WHERE following_id = u.id) as followers_count,
 		       (SELECT COUNT(*) FROM follows WHERE follower_id = u.id) as following_count,
-		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id) as posts_count
+		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id AND deleted_at IS NULL AND is_hidden = FALSE) as posts_count
 	`
 	if viewerID != nil {
 		query += `,
@@ -110,12 +127,12 @@ func (r *FollowRepository) GetFollowing(userID string, viewerID *string, paginat
 	query += `
 		FROM users u
 		JOIN follows f ON u.id = f.following_id
-		WHERE f.follower_id = ?
+		WHERE f.follower_id = ? AND ` + openAccount + `
 		ORDER BY f.created_at DESC
 		LIMIT ? OFFSET ?
 	`
 
-	countQuery := `SELECT COUNT(*) FROM follows WHERE follower_id = ?`
+	countQuery := `SELECT COUNT(*) FROM follows f JOIN users u ON u.id = f.following_id WHERE f.follower_id = ? AND ` + openAccount
 	var totalCount int64
 	if err := r.db.Raw(countQuery, userID).Scan(&totalCount).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count following: %w", err)
@@ -146,12 +163,46 @@ func (r *FollowRepository) GetFollowCounts(userID string) (followersCount, follo
 	return followersCount, followingCount, nil
 }
 
+// GetAllFollowerCounts returns the follower count of every user with at least one follower
+func (r *FollowRepository) GetAllFollowerCounts() (map[string]int, error) {
+	var rows []struct {
+		FollowingID string
+		Count       int
+	}
+	if err := r.db.Model(&model.Follow{}).Select("following_id, COUNT(*) as count").Group("following_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count followers: %w", err)
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.FollowingID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetFollowingIDs returns the IDs of everyone the user follows
+func (r *FollowRepository) GetFollowingIDs(userID string) ([]string, error) {
+	var ids []string
+	if err := r.db.Model(&model.Follow{}).Where("follower_id = ?", userID).Pluck("following_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get following: %w", err)
+	}
+	return ids, nil
+}
+
+// GetFollowerIDs returns the IDs of everyone following the user
+func (r *FollowRepository) GetFollowerIDs(userID string) ([]string, error) {
+	var ids []string
+	if err := r.db.Model(&model.Follow{}).Where("following_id = ?", userID).Pluck("follower_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get followers: %w", err)
+	}
+	return ids, nil
+}
+
 func (r *FollowRepository) GetMutualFollows(userID1, userID2 string, limit int) ([]model.UserProfile, error) {
 	query := `
 		SELECT u.id, u.username, u.full_name, u.bio, u.avatar_url, u.is_verified, u.created_at,
 		       (SELECT COUNT(*) FROM follows WHERE following_id = u.id) as followers_count,
 		       (SELECT COUNT(*) FROM follows WHERE follower_id = u.id) as following_count,
-		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id) as posts_count
+		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id AND deleted_at IS NULL AND is_hidden = FALSE) as posts_count
 		FROM users u
 		WHERE u.id IN (
 		    SELECT f1.following_id 
@@ -169,3 +220,31 @@ func (r *FollowRepository) GetMutualFollows(userID1, userID2 string, limit int)
 	}
 	return users, nil
 }
+
+// DeleteUserFollows xoá tối đa limit quan hệ theo dõi của userID (theo dõi và được theo dõi).
+// Trả về số dòng đã xoá.
+func (r *FollowRepository) DeleteUserFollows(userID string, limit int) (int64, error) {
+	res := r.db.Exec("DELETE FROM follows WHERE follower_id = ? OR following_id = ? LIMIT ?", userID, userID, limit)
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to delete follows: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// DeleteUserFollowHistory xoá tối đa limit dòng lịch sử theo dõi và thống kê người theo dõi
+// của userID ở mỗi bảng. Trả về số dòng đã xoá.
+func (r *FollowRepository) DeleteUserFollowHistory(userID string, limit int) (int64, error) {
+	var deleted int64
+	for _, query := range []string{
+		"DELETE FROM follow_events WHERE follower_id = @user OR following_id = @user LIMIT @limit",
+		"DELETE FROM follower_engagement_daily WHERE follower_id = @user OR user_id = @user LIMIT @limit",
+		"DELETE FROM follower_daily_stats WHERE user_id = @user LIMIT @limit",
+	} {
+		res := r.db.Exec(query, sql.Named("user", userID), sql.Named("limit", limit))
+		if res.Error != nil {
+			return deleted, fmt.Errorf("failed to delete follow history: %w", res.Error)
+		}
+		deleted += res.RowsAffected
+	}
+	return deleted, nil
+}
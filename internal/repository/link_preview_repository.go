@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vietick-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LinkPreviewRepository struct {
+	db *gorm.DB
+}
+
+func NewLinkPreviewRepository(db *gorm.DB) *LinkPreviewRepository {
+	return &LinkPreviewRepository{db: db}
+}
+
+// GetOrCreate returns the cached previews of the given links, creating pending ones for
+// links never seen before. Previews come back in the order of links.
+func (r *LinkPreviewRepository) GetOrCreate(links []model.LinkPreview) ([]model.LinkPreview, error) {
+	if len(links) == 0 {
+		return nil, nil
+	}
+	// Another post may create the same preview at the same time: the first insert wins
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to create link previews: %w", err)
+	}
+
+	hashes := make([]string, len(links))
+	for i, link := range links {
+		hashes[i] = link.URLHash
+	}
+	var found []model.LinkPreview
+	if err := r.db.Where("url_hash IN ?", hashes).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get link previews: %w", err)
+	}
+	byHash := make(map[string]model.LinkPreview, len(found))
+	for _, preview := range found {
+		byHash[preview.URLHash] = preview
+	}
+	previews := make([]model.LinkPreview, 0, len(links))
+	for _, link := range links {
+		if preview, ok := byHash[link.URLHash]; ok {
+			previews = append(previews, preview)
+		}
+	}
+	return previews, nil
+}
+
+// SetPostLinks replaces the links of a post
+func (r *LinkPreviewRepository) SetPostLinks(postID string, previewIDs []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&model.PostLink{}).Error; err != nil {
+			return fmt.Errorf("failed to clear post links: %w", err)
+		}
+		if len(previewIDs) == 0 {
+			return nil
+		}
+		links := make([]model.PostLink, len(previewIDs))
+		for i, previewID := range previewIDs {
+			links[i] = model.PostLink{PostID: postID, LinkPreviewID: previewID, Position: i + 1}
+		}
+		if err := tx.Create(&links).Error; err != nil {
+			return fmt.Errorf("failed to save post links: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetByPostIDs returns the previews with a card of the given posts, in the order of their
+// links, by post ID
+func (r *LinkPreviewRepository) GetByPostIDs(postIDs []string) (map[string][]model.LinkPreview, error) {
+	previews := make(map[string][]model.LinkPreview)
+	if len(postIDs) == 0 {
+		return previews, nil
+	}
+	var rows []struct {
+		PostID string
+		model.LinkPreview
+	}
+	err := r.db.Table("post_links").
+		Select("post_links.post_id, link_previews.*").
+		Joins("JOIN link_previews ON link_previews.id = post_links.link_preview_id").
+		Where("post_links.post_id IN ?", postIDs).
+		Where("link_previews.title <> '' OR link_previews.description <> ''").
+		Order("post_links.position ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link previews: %w", err)
+	}
+	for _, row := range rows {
+		previews[row.PostID] = append(previews[row.PostID], row.LinkPreview)
+	}
+	return previews, nil
+}
+
+func (r *LinkPreviewRepository) GetByID(previewID string) (*model.LinkPreview, error) {
+	var preview model.LinkPreview
+	if err := r.db.Where("id = ?", previewID).First(&preview).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("link preview not found")
+		}
+		return nil, fmt.Errorf("failed to get link preview: %w", err)
+	}
+	return &preview, nil
+}
+
+// MarkPending queues previews to be fetched again. Previews being fetched are left alone.
+func (r *LinkPreviewRepository) MarkPending(previewIDs []string) error {
+	if len(previewIDs) == 0 {
+		return nil
+	}
+	err := r.db.Model(&model.LinkPreview{}).
+		Where("id IN ? AND status IN ?", previewIDs, []model.LinkPreviewStatus{model.LinkPreviewReady, model.LinkPreviewFailed}).
+		Updates(map[string]interface{}{
+			"status":   model.LinkPreviewPending,
+			"attempts": 0,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to queue link previews: %w", err)
+	}
+	return nil
+}
+
+// ClaimForFetch marks a pending preview, or one whose worker stopped before staleBefore, as
+// being fetched. Only one caller gets true.
+func (r *LinkPreviewRepository) ClaimForFetch(previewID string, staleBefore time.Time) (bool, error) {
+	result := r.db.Model(&model.LinkPreview{}).
+		Where("id = ?", previewID).
+		Where("status = ? OR (status = ? AND updated_at < ?)",
+			model.LinkPreviewPending, model.LinkPreviewProcessing, staleBefore).
+		Updates(map[string]interface{}{
+			"status":     model.LinkPreviewProcessing,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim link preview: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SaveFetched stores the card of a fetched page
+func (r *LinkPreviewRepository) SaveFetched(preview *model.LinkPreview) error {
+	err := r.db.Model(&model.LinkPreview{}).Where("id = ?", preview.ID).Updates(map[string]interface{}{
+		"status":      model.LinkPreviewReady,
+		"title":       preview.Title,
+		"description": preview.Description,
+		"image_url":   preview.ImageURL,
+		"site_name":   preview.SiteName,
+		"attempts":    preview.Attempts,
+		"last_error":  nil,
+		"fetched_at":  preview.FetchedAt,
+		"expires_at":  preview.ExpiresAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save link preview: %w", err)
+	}
+	return nil
+}
+
+// SetFetchStatus records a failed fetch. The card of an earlier fetch is kept unless clear
+// is set.
+func (r *LinkPreviewRepository) SetFetchStatus(previewID string, status model.LinkPreviewStatus, attempts int, fetchErr *string, expiresAt *time.Time, clear bool) error {
+	updates := map[string]interface{}{
+		"status":     status,
+		"attempts":   attempts,
+		"last_error": fetchErr,
+		"expires_at": expiresAt,
+	}
+	if clear {
+		updates["title"] = ""
+		updates["description"] = ""
+		updates["image_url"] = ""
+		updates["site_name"] = ""
+	}
+	if err := r.db.Model(&model.LinkPreview{}).Where("id = ?", previewID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update link preview: %w", err)
+	}
+	return nil
+}
+
+// GetUnfetchedIDs returns previews still waiting for a worker, e.g. because the queue was
+// full or the server restarted
+func (r *LinkPreviewRepository) GetUnfetchedIDs(pendingBefore, staleBefore time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&model.LinkPreview{}).
+		Where("(status = ? AND updated_at < ?) OR (status = ? AND updated_at < ?)",
+			model.LinkPreviewPending, pendingBefore, model.LinkPreviewProcessing, staleBefore).
+		Order("created_at ASC").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unfetched link previews: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteUnused deletes up to limit expired previews no post links to anymore
+func (r *LinkPreviewRepository) DeleteUnused(expiredBefore time.Time, limit int) (int64, error) {
+	result := r.db.Exec(`DELETE FROM link_previews WHERE expires_at < ?
+		AND NOT EXISTS (SELECT 1 FROM post_links WHERE post_links.link_preview_id = link_previews.id) LIMIT ?`, expiredBefore, limit)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete unused link previews: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vietick-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type MediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+func (r *MediaRepository) Create(media *model.Media) error {
+	if err := r.db.Create(media).Error; err != nil {
+		return fmt.Errorf("failed to create media: %w", err)
+	}
+	return nil
+}
+
+func (r *MediaRepository) GetByID(mediaID string) (*model.Media, error) {
+	media := &model.Media{}
+	if err := r.db.Where("id = ?", mediaID).First(media).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("media not found")
+		}
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	return media, nil
+}
+
+// GetByIDs returns the media in the order of the IDs given, skipping unknown IDs
+func (r *MediaRepository) GetByIDs(mediaIDs []string) ([]model.Media, error) {
+	if len(mediaIDs) == 0 {
+		return []model.Media{}, nil
+	}
+
+	var media []model.Media
+	if err := r.db.Where("id IN ?", mediaIDs).Find(&media).Error; err != nil {
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+
+	byID := make(map[string]model.Media, len(media))
+	for _, m := range media {
+		byID[m.ID] = m
+	}
+	ordered := make([]model.Media, 0, len(media))
+	for _, id := range mediaIDs {
+		if m, ok := byID[id]; ok {
+			ordered = append(ordered, m)
+		}
+	}
+	return ordered, nil
+}
+
+func (r *MediaRepository) MarkReady(mediaID, contentType string, size int64, contentHash string) error {
+	err := r.db.Model(&model.Media{}).Where("id = ?", mediaID).Updates(map[string]interface{}{
+		"status":       model.MediaStatusReady,
+		"content_type": contentType,
+		"size":         size,
+		"content_hash": contentHash,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update media: %w", err)
+	}
+	return nil
+}
+
+func (r *MediaRepository) Delete(mediaID string) error {
+	if err := r.db.Where("id = ?", mediaID).Delete(&model.Media{}).Error; err != nil {
+		return fmt.Errorf("failed to delete media: %w", err)
+	}
+	return nil
+}
+
+// IsReferenced reports whether a post, profile or verification request still uses the media
+func (r *MediaRepository) IsReferenced(media *model.Media) (bool, error) {
+	var count int64
+	// Deleted posts keep their images until they are purged, so they can be restored
+	query := r.db.Unscoped().Model(&model.Post{}).Where("JSON_CONTAINS(media_ids, JSON_QUOTE(?))", media.ID)
+	if media.URL != "" {
+		query = query.Or("JSON_CONTAINS(image_urls, JSON_QUOTE(?))", media.URL)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check media usage: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	// Drafts keep their images until they are published or discarded
+	err := r.db.Model(&model.PostDraft{}).
+		Where("status <> ? AND JSON_CONTAINS(media_ids, JSON_QUOTE(?))", model.PostDraftPublished, media.ID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check media usage: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	// Images of earlier versions stay visible in the edit history
+	err = r.db.Model(&model.PostRevision{}).Where("JSON_CONTAINS(media_ids, JSON_QUOTE(?))", media.ID).Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check media usage: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	query = r.db.Model(&model.User{}).Where("avatar_media_id = ?", media.ID)
+	if media.URL != "" {
+		query = query.Or("avatar_url = ?", media.URL)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check media usage: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	query = r.db.Model(&model.IdentityVerification{}).
+		Where("front_media_id = ? OR back_media_id = ? OR selfie_media_id = ?", media.ID, media.ID, media.ID)
+	if media.URL != "" {
+		query = query.Or("front_image_url = ? OR back_image_url = ? OR selfie_image_url = ?", media.URL, media.URL, media.URL)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check media usage: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *MediaRepository) GetIDsByURLs(urls []string) ([]string, error) {
+	var ids []string
+	if len(urls) == 0 {
+		return ids, nil
+	}
+	if err := r.db.Model(&model.Media{}).Where("url IN ?", urls).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	return ids, nil
+}
+
+// GetIDsByOwner returns up to limit media uploaded by ownerID
+func (r *MediaRepository) GetIDsByOwner(ownerID string, limit int) ([]string, error) {
+	var ids []string
+	if err := r.db.Model(&model.Media{}).Where("owner_id = ?", ownerID).Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	return ids, nil
+}
+
+// GetReadyByOwner returns the completed uploads of ownerID for the given purposes, oldest first
+func (r *MediaRepository) GetReadyByOwner(ownerID string, purposes []model.MediaPurpose) ([]model.Media, error) {
+	var media []model.Media
+	err := r.db.Where("owner_id = ? AND status = ? AND purpose IN ?", ownerID, model.MediaStatusReady, purposes).
+		Order("created_at ASC").Find(&media).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	return media, nil
+}
+
+// GetStalePending returns presigned uploads that were never completed
+func (r *MediaRepository) GetStalePending(before time.Time, limit int) ([]model.Media, error) {
+	var media []model.Media
+	err := r.db.Where("status = ? AND created_at < ?", model.MediaStatusPending, before).
+		Order("created_at ASC").Limit(limit).Find(&media).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending media: %w", err)
+	}
+	return media, nil
+}
+
+// ClaimForProcessing marks uploaded media as being processed so that only one worker
+// handles it. Media stuck in processing since before staleBefore (a crashed worker) can
+// be claimed again.
+func (r *MediaRepository) ClaimForProcessing(mediaID string, staleBefore time.Time) (bool, error) {
+	result := r.db.Model(&model.Media{}).
+		Where("id = ? AND status = ?", mediaID, model.MediaStatusReady).
+		Where("processing_status = ? OR (processing_status = ? AND updated_at < ?)",
+			model.MediaProcessingPending, model.MediaProcessingProcessing, staleBefore).
+		Updates(map[string]interface{}{
+			"processing_status": model.MediaProcessingProcessing,
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim media: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *MediaRepository) SaveProcessed(media *model.Media) error {
+	err := r.db.Model(&model.Media{}).Where("id = ?", media.ID).Updates(map[string]interface{}{
+		"content_type":      media.ContentType,
+		"size":              media.Size,
+		"width":             media.Width,
+		"height":            media.Height,
+		"blurhash":          media.Blurhash,
+		"variants":          media.Variants,
+		"processing_status": media.ProcessingStatus,
+		"processing_error":  nil,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update media: %w", err)
+	}
+	return nil
+}
+
+func (r *MediaRepository) SetProcessingStatus(mediaID string, status model.MediaProcessingStatus, attempts int, processingErr *string) error {
+	err := r.db.Model(&model.Media{}).Where("id = ?", mediaID).Updates(map[string]interface{}{
+		"processing_status":   status,
+		"processing_attempts": attempts,
+		"processing_error":    processingErr,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update media: %w", err)
+	}
+	return nil
+}
+
+// GetUnprocessedIDs returns uploads still waiting for the image pipeline, e.g. because the
+// queue was full or the server restarted
+func (r *MediaRepository) GetUnprocessedIDs(pendingBefore, staleBefore time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&model.Media{}).
+		Where("status = ?", model.MediaStatusReady).
+		Where("(processing_status = ? AND updated_at < ?) OR (processing_status = ? AND updated_at < ?)",
+			model.MediaProcessingPending, pendingBefore, model.MediaProcessingProcessing, staleBefore).
+		Order("created_at ASC").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unprocessed media: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"fmt"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(notification *model.Notification) error {
+	if err := r.db.Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) CreateBatch(notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	if err := r.db.CreateInBatches(notifications, 100).Error; err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) GetUserNotifications(userID string, unreadOnly bool, pagination utils.PaginationResult) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var totalCount int64
+	dbQuery := r.db.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		dbQuery = dbQuery.Where("is_read = ?", false)
+	}
+	dbQuery.Count(&totalCount)
+	if err := dbQuery.Order("created_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&notifications).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	return notifications, totalCount, nil
+}
+
+func (r *NotificationRepository) GetUnreadCount(userID string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (r *NotificationRepository) MarkAsRead(notificationID, userID string) error {
+	result := r.db.Model(&model.Notification{}).Where("id = ? AND user_id = ?", notificationID, userID).Update("is_read", true)
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("notification not found")
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllAsRead(userID string) error {
+	if err := r.db.Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Update("is_read", true).Error; err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) DeleteUserNotifications(userID string) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&model.Notification{}).Error; err != nil {
+		return fmt.Errorf("failed to delete notifications: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"fmt"
+
+	"vietick-backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Create stores the organization account together with its first owner
+func (r *OrganizationRepository) Create(organization *model.User, owner *model.OrganizationMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return fmt.Errorf("failed to create organization: %w", err)
+		}
+		if err := tx.Create(owner).Error; err != nil {
+			return fmt.Errorf("failed to add organization owner: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *OrganizationRepository) GetMember(organizationID, userID string) (*model.OrganizationMember, error) {
+	member := &model.OrganizationMember{}
+	err := r.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("organization member not found")
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	return member, nil
+}
+
+// IsMember reports whether the user has any role in the organization
+func (r *OrganizationRepository) IsMember(organizationID, userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check organization member: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetMembers returns the members of the organization, owners first
+func (r *OrganizationRepository) GetMembers(organizationID string) ([]model.OrganizationMember, error) {
+	var members []model.OrganizationMember
+	err := r.db.Where("organization_id = ?", organizationID).
+		Order("FIELD(role, 'owner', 'admin', 'editor'), created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization members: %w", err)
+	}
+	return members, nil
+}
+
+// GetOrganizations returns the organizations the user is a member of with the user's role
+func (r *OrganizationRepository) GetOrganizations(userID string) ([]model.Organization, error) {
+	query := `
+		SELECT u.id, u.username, u.account_type, u.full_name, u.bio, u.avatar_url, u.avatar_media_id,
+		       u.is_verified, u.verification_category, u.verified_at, u.created_at, m.role,
+		       (SELECT COUNT(*) FROM follows WHERE following_id = u.id) as followers_count,
+		       (SELECT COUNT(*) FROM follows WHERE follower_id = u.id) as following_count,
+		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id AND deleted_at IS NULL AND is_hidden = FALSE) as posts_count
+		FROM organization_members m
+		JOIN users u ON u.id = m.organization_id
+		WHERE m.user_id = ?
+		ORDER BY m.created_at ASC
+	`
+	var organizations []model.Organization
+	if err := r.db.Raw(query, userID).Scan(&organizations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
+	}
+	return organizations, nil
+}
+
+func (r *OrganizationRepository) AddMember(member *model.OrganizationMember) error {
+	if err := r.db.Create(member).Error; err != nil {
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+	return nil
+}
+
+func (r *OrganizationRepository) UpdateRole(organizationID, userID string, role model.OrganizationRole) error {
+	result := r.db.Model(&model.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update organization member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("organization member not found")
+	}
+	return nil
+}
+
+func (r *OrganizationRepository) RemoveMember(organizationID, userID string) error {
+	result := r.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&model.OrganizationMember{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove organization member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("organization member not found")
+	}
+	return nil
+}
+
+func (r *OrganizationRepository) CountOwners(organizationID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", organizationID, model.OrganizationRoleOwner).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count organization owners: %w", err)
+	}
+	return count, nil
+}
+
+// GetSoleOwnerships returns the organizations in which userID is the only owner
+func (r *OrganizationRepository) GetSoleOwnerships(userID string) ([]model.User, error) {
+	var organizations []model.User
+	err := r.db.Raw(`
+		SELECT u.* FROM users u
+		JOIN organization_members m ON m.organization_id = u.id
+		WHERE m.user_id = ? AND m.role = ?
+		  AND (SELECT COUNT(*) FROM organization_members o WHERE o.organization_id = u.id AND o.role = ?) = 1
+	`, userID, model.OrganizationRoleOwner, model.OrganizationRoleOwner).Scan(&organizations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owned organizations: %w", err)
+	}
+	return organizations, nil
+}
+
+// RemoveMemberships removes userID from every organization
+func (r *OrganizationRepository) RemoveMemberships(userID string) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&model.OrganizationMember{}).Error; err != nil {
+		return fmt.Errorf("failed to remove organization memberships: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+
+	"vietick-backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+// Grant is idempotent: granting a permission the user already has keeps the original grant
+func (r *PermissionRepository) Grant(permission *model.UserPermission) error {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(permission).Error; err != nil {
+		return fmt.Errorf("failed to grant permission: %w", err)
+	}
+	return nil
+}
+
+func (r *PermissionRepository) Revoke(userID string, permission model.Permission) error {
+	result := r.db.Where("user_id = ? AND permission = ?", userID, permission).Delete(&model.UserPermission{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke permission: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("permission not found")
+	}
+	return nil
+}
+
+func (r *PermissionRepository) Has(userID string, permission model.Permission) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.UserPermission{}).
+		Where("user_id = ? AND permission = ?", userID, permission).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetUserIDs returns the users granted the permission explicitly
+func (r *PermissionRepository) GetUserIDs(permission model.Permission) ([]string, error) {
+	var userIDs []string
+	err := r.db.Model(&model.UserPermission{}).Where("permission = ?", permission).
+		Order("created_at ASC").Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with permission: %w", err)
+	}
+	return userIDs, nil
+}
+
+func (r *PermissionRepository) GetByUserID(userID string) ([]model.UserPermission, error) {
+	var permissions []model.UserPermission
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	return permissions, nil
+}
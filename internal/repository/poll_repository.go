@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PollRepository struct {
+	db *gorm.DB
+}
+
+func NewPollRepository(db *gorm.DB) *PollRepository {
+	return &PollRepository{db: db}
+}
+
+// GetByPostIDs returns the polls of the given posts with their options, by post ID
+func (r *PollRepository) GetByPostIDs(postIDs []string) (map[string]*model.Poll, error) {
+	polls := make(map[string]*model.Poll)
+	if len(postIDs) == 0 {
+		return polls, nil
+	}
+	var found []model.Poll
+	if err := r.db.Where("post_id IN ?", postIDs).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get polls: %w", err)
+	}
+	if len(found) == 0 {
+		return polls, nil
+	}
+
+	pollIDs := make([]string, len(found))
+	byID := make(map[string]*model.Poll, len(found))
+	for i := range found {
+		pollIDs[i] = found[i].ID
+		found[i].Options = []model.PollOption{}
+		byID[found[i].ID] = &found[i]
+		polls[found[i].PostID] = &found[i]
+	}
+	var options []model.PollOption
+	if err := r.db.Where("poll_id IN ?", pollIDs).Order("position ASC").Find(&options).Error; err != nil {
+		return nil, fmt.Errorf("failed to get poll options: %w", err)
+	}
+	for _, option := range options {
+		byID[option.PollID].Options = append(byID[option.PollID].Options, option)
+	}
+	return polls, nil
+}
+
+func (r *PollRepository) GetByPostID(postID string) (*model.Poll, error) {
+	polls, err := r.GetByPostIDs([]string{postID})
+	if err != nil {
+		return nil, err
+	}
+	poll, ok := polls[postID]
+	if !ok {
+		return nil, fmt.Errorf("poll not found")
+	}
+	return poll, nil
+}
+
+// GetViewerVotes returns the options userID voted for in the given polls, by poll ID
+func (r *PollRepository) GetViewerVotes(userID string, pollIDs []string) (map[string][]string, error) {
+	votes := make(map[string][]string)
+	if len(pollIDs) == 0 {
+		return votes, nil
+	}
+	var found []model.PollVote
+	err := r.db.Select("poll_votes.poll_id, poll_votes.option_id").
+		Joins("JOIN poll_options o ON o.id = poll_votes.option_id").
+		Where("poll_votes.user_id = ? AND poll_votes.poll_id IN ?", userID, pollIDs).
+		Order("o.position ASC").Find(&found).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get votes: %w", err)
+	}
+	for _, vote := range found {
+		votes[vote.PollID] = append(votes[vote.PollID], vote.OptionID)
+	}
+	return votes, nil
+}
+
+// Vote thay phiếu của userID trong poll bằng các lựa chọn optionIDs, nếu poll chưa đóng lúc now
+func (r *PollRepository) Vote(pollID, userID string, optionIDs []string, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Khoá poll để các phiếu được đếm lần lượt và không ai bỏ phiếu sau khi poll đóng
+		if err := lockRow(tx.Where("closes_at > ?", now), &model.Poll{}, pollID, "invalid request: the poll is closed"); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&model.PollOption{}).Where("poll_id = ? AND id IN ?", pollID, optionIDs).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check poll options: %w", err)
+		}
+		if int(count) != len(optionIDs) {
+			return fmt.Errorf("invalid request: option is not part of this poll")
+		}
+
+		hadVoted, err := r.removeBallot(tx, pollID, userID)
+		if err != nil {
+			return err
+		}
+		votes := make([]model.PollVote, len(optionIDs))
+		for i, optionID := range optionIDs {
+			votes[i] = model.PollVote{ID: uuid.New().String(), PollID: pollID, OptionID: optionID, UserID: userID}
+		}
+		if err := tx.Create(&votes).Error; err != nil {
+			return fmt.Errorf("failed to vote: %w", err)
+		}
+		if err := tx.Model(&model.PollOption{}).Where("id IN ?", optionIDs).UpdateColumn("vote_count", gorm.Expr("vote_count + 1")).Error; err != nil {
+			return fmt.Errorf("failed to update vote count: %w", err)
+		}
+		if !hadVoted {
+			if err := tx.Model(&model.Poll{}).Where("id = ?", pollID).UpdateColumn("voter_count", gorm.Expr("voter_count + 1")).Error; err != nil {
+				return fmt.Errorf("failed to update voter count: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveVote rút phiếu của userID khỏi poll, nếu poll chưa đóng lúc now
+func (r *PollRepository) RemoveVote(pollID, userID string, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockRow(tx.Where("closes_at > ?", now), &model.Poll{}, pollID, "invalid request: the poll is closed"); err != nil {
+			return err
+		}
+		hadVoted, err := r.removeBallot(tx, pollID, userID)
+		if err != nil {
+			return err
+		}
+		if !hadVoted {
+			return fmt.Errorf("vote not found")
+		}
+		if err := tx.Model(&model.Poll{}).Where("id = ?", pollID).UpdateColumn("voter_count", gorm.Expr("voter_count - 1")).Error; err != nil {
+			return fmt.Errorf("failed to update voter count: %w", err)
+		}
+		return nil
+	})
+}
+
+// removeBallot xoá các phiếu của userID trong poll và trừ số phiếu của từng lựa chọn.
+// voter_count do bên gọi cập nhật.
+func (r *PollRepository) removeBallot(tx *gorm.DB, pollID, userID string) (bool, error) {
+	var optionIDs []string
+	if err := tx.Model(&model.PollVote{}).Where("poll_id = ? AND user_id = ?", pollID, userID).Pluck("option_id", &optionIDs).Error; err != nil {
+		return false, fmt.Errorf("failed to get votes: %w", err)
+	}
+	if len(optionIDs) == 0 {
+		return false, nil
+	}
+	if err := tx.Where("poll_id = ? AND user_id = ?", pollID, userID).Delete(&model.PollVote{}).Error; err != nil {
+		return false, fmt.Errorf("failed to remove votes: %w", err)
+	}
+	if err := tx.Model(&model.PollOption{}).Where("id IN ?", optionIDs).UpdateColumn("vote_count", gorm.Expr("vote_count - 1")).Error; err != nil {
+		return false, fmt.Errorf("failed to update vote count: %w", err)
+	}
+	return true, nil
+}
+
+// GetDueToClose returns polls of live posts that closed before now and whose closing was not handled yet
+func (r *PollRepository) GetDueToClose(now time.Time, limit int) ([]model.Poll, error) {
+	var polls []model.Poll
+	err := r.db.Where("closed_at IS NULL AND closes_at <= ?", now).
+		Where("post_id IN (SELECT id FROM posts WHERE deleted_at IS NULL)").
+		Order("closes_at ASC").Limit(limit).Find(&polls).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get polls to close: %w", err)
+	}
+	return polls, nil
+}
+
+// MarkClosed records that the closing of a poll is handled. Only one caller gets true, so
+// several instances never notify twice.
+func (r *PollRepository) MarkClosed(pollID string, now time.Time) (bool, error) {
+	result := r.db.Model(&model.Poll{}).Where("id = ? AND closed_at IS NULL", pollID).Update("closed_at", now)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to close poll: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetVoterIDs returns the users who voted in a poll
+func (r *PollRepository) GetVoterIDs(pollID string) ([]string, error) {
+	var userIDs []string
+	if err := r.db.Model(&model.PollVote{}).Where("poll_id = ?", pollID).Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get voters: %w", err)
+	}
+	return userIDs, nil
+}
+
+// GetUserVotes returns the votes of userID, oldest first
+func (r *PollRepository) GetUserVotes(userID string, pagination utils.PaginationResult) ([]model.PollVote, error) {
+	var votes []model.PollVote
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC, id ASC").
+		Limit(pagination.Limit).Offset(pagination.Offset).Find(&votes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get votes: %w", err)
+	}
+	return votes, nil
+}
+
+// GetOptions returns poll options by ID
+func (r *PollRepository) GetOptions(optionIDs []string) (map[string]model.PollOption, error) {
+	options := make(map[string]model.PollOption)
+	if len(optionIDs) == 0 {
+		return options, nil
+	}
+	var found []model.PollOption
+	if err := r.db.Where("id IN ?", optionIDs).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get poll options: %w", err)
+	}
+	for _, option := range found {
+		options[option.ID] = option
+	}
+	return options, nil
+}
+
+// RemoveUserVotes xoá tối đa limit poll mà userID đã bỏ phiếu, cùng số phiếu tương ứng.
+// Trả về số poll đã xét.
+func (r *PollRepository) RemoveUserVotes(userID string, limit int) (int, error) {
+	var pollIDs []string
+	if err := r.db.Model(&model.PollVote{}).Where("user_id = ?", userID).Distinct().Limit(limit).Pluck("poll_id", &pollIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to get voted polls: %w", err)
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, pollID := range pollIDs {
+			hadVoted, err := r.removeBallot(tx, pollID, userID)
+			if err != nil {
+				return err
+			}
+			if !hadVoted {
+				continue
+			}
+			if err := tx.Model(&model.Poll{}).Where("id = ?", pollID).UpdateColumn("voter_count", gorm.Expr("voter_count - 1")).Error; err != nil {
+				return fmt.Errorf("failed to update voter count: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(pollIDs), nil
+}
@@ -8,9 +8,80 @@ import (
 	"vietick-backend/internal/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"github.com/google/uuid"
 )
 
+// Bài viết của tài khoản bị cấm, đã vô hiệu hoá hoặc đã xoá không xuất hiện ở bất kỳ danh sách công khai nào
+const visibleAuthor = "user_id NOT IN (SELECT id FROM users WHERE account_status IN ('banned', 'deactivated', 'deleted'))"
+
+// reactionCountUpdates trả về các cột cần cập nhật khi số reaction loại reaction thay đổi delta.
+// Dùng chung cho bài viết và bình luận.
+func reactionCountUpdates(reaction model.ReactionType, delta int) map[string]interface{} {
+	path := "$." + string(reaction)
+	updates := map[string]interface{}{
+		"reaction_counts": gorm.Expr("JSON_SET(COALESCE(reaction_counts, JSON_OBJECT()), ?, COALESCE(JSON_EXTRACT(reaction_counts, ?), 0) + ?)", path, path, delta),
+	}
+	// like_count giữ số reaction "like" cho các client cũ
+	if reaction == model.ReactionLike {
+		updates["like_count"] = gorm.Expr("like_count + ?", delta)
+	}
+	return updates
+}
+
+// lockRow khoá dòng id của bảng của value trong giao dịch tx; dòng đã xoá mềm coi như không tồn tại
+func lockRow(tx *gorm.DB, value interface{}, id, notFound string) error {
+	var count int64
+	err := tx.Model(value).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock row: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s", notFound)
+	}
+	return nil
+}
+
+// getReactors lists the users who reacted in table to the row id of column, newest first
+func getReactors(db *gorm.DB, table, column, id string, reaction *model.ReactionType, pagination utils.PaginationResult) ([]model.Reactor, int64, error) {
+	filter := "r." + column + " = ? AND " + openAccount
+	args := []interface{}{id}
+	if reaction != nil {
+		filter += " AND r.reaction = ?"
+		args = append(args, *reaction)
+	}
+
+	var totalCount int64
+	countQuery := "SELECT COUNT(*) FROM " + table + " r JOIN users u ON u.id = r.user_id WHERE " + filter
+	if err := db.Raw(countQuery, args...).Scan(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count reactions: %w", err)
+	}
+
+	var rows []struct {
+		model.UserProfile
+		Reaction  model.ReactionType
+		ReactedAt time.Time
+	}
+	query := `
+		SELECT u.id, u.username, u.full_name, u.bio, u.avatar_url, u.is_verified, u.created_at,
+		       r.reaction, r.created_at AS reacted_at
+		FROM ` + table + ` r
+		JOIN users u ON u.id = r.user_id
+		WHERE ` + filter + `
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT ? OFFSET ?
+	`
+	if err := db.Raw(query, append(args, pagination.Limit, pagination.Offset)...).Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get reactions: %w", err)
+	}
+
+	reactors := make([]model.Reactor, len(rows))
+	for i, row := range rows {
+		reactors[i] = model.Reactor{User: row.UserProfile, Reaction: row.Reaction, ReactedAt: row.ReactedAt}
+	}
+	return reactors, totalCount, nil
+}
+
 type PostRepository struct {
 	db *gorm.DB
 }
@@ -19,11 +90,23 @@ func NewPostRepository(db *gorm.DB) *PostRepository {
 	return &PostRepository{db: db}
 }
 
+// Create lưu bài viết, cùng với poll của bài (post.Poll) nếu có
 func (r *PostRepository) Create(post *model.Post) error {
-	if err := r.db.Create(post).Error; err != nil {
-		return fmt.Errorf("failed to create post: %w", err)
-	}
-	return nil
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return fmt.Errorf("failed to create post: %w", err)
+		}
+		if post.Poll == nil {
+			return nil
+		}
+		if err := tx.Create(post.Poll).Error; err != nil {
+			return fmt.Errorf("failed to create poll: %w", err)
+		}
+		if err := tx.Create(&post.Poll.Options).Error; err != nil {
+			return fmt.Errorf("failed to create poll options: %w", err)
+		}
+		return nil
+	})
 }
 
 func (r *PostRepository) GetByID(postID string, userID *string) (*model.Post, error) {
@@ -34,33 +117,237 @@ func (r *PostRepository) GetByID(postID string, userID *string) (*model.Post, er
 		}
 		return nil, fmt.Errorf("failed to get post: %w", err)
 	}
+	// Bài viết bị ẩn bởi kiểm duyệt chỉ chủ bài viết mới xem được
+	if post.IsHidden && (userID == nil || *userID != post.UserID) {
+		return nil, fmt.Errorf("post not found")
+	}
+	var hiddenCount int64
+	r.db.Model(&model.User{}).Where("id = ? AND account_status IN ?", post.UserID, model.HiddenAccountStatuses).Count(&hiddenCount)
+	if hiddenCount > 0 {
+		return nil, fmt.Errorf("post not found")
+	}
 	// Lấy thông tin user
 	user := &model.UserProfile{}
 	r.db.Model(&model.User{}).Select("id, username, full_name, bio, avatar_url, is_verified, created_at").Where("id = ?", post.UserID).Scan(user)
 	post.User = user
-	// Nếu có userID, kiểm tra like
+	// Nếu có userID, lấy reaction của người xem
 	if userID != nil {
-		var count int64
-		r.db.Model(&model.PostLike{}).Where("post_id = ? AND user_id = ?", postID, *userID).Count(&count)
-		post.IsLiked = count > 0
+		post.Reaction, _ = r.GetReaction(postID, *userID)
+		post.IsLiked = post.Reaction != nil && *post.Reaction == model.ReactionLike
+		var bookmarkCount int64
+		r.db.Model(&model.Bookmark{}).Where("post_id = ? AND user_id = ?", postID, *userID).Count(&bookmarkCount)
+		post.IsBookmarked = bookmarkCount > 0
 	}
 	return post, nil
 }
 
 func (r *PostRepository) Update(post *model.Post) error {
-	if err := r.db.Save(post).Error; err != nil {
+	// Các bộ đếm lượt hiển thị và lượt xem được cộng dồn riêng, không ghi đè
+	if err := r.db.Omit("impression_count", "view_count").Save(post).Error; err != nil {
 		return fmt.Errorf("failed to update post: %w", err)
 	}
 	return nil
 }
 
-func (r *PostRepository) Delete(postID, userID string) error {
-	if err := r.db.Where("id = ? AND user_id = ?", postID, userID).Delete(&model.Post{}).Error; err != nil {
-		return fmt.Errorf("failed to delete post: %w", err)
+// UpdateWithRevision lưu phiên bản cũ vào lịch sử rồi cập nhật bài viết. Khi hai lần sửa
+// diễn ra cùng lúc thì chỉ một lần thành công.
+func (r *PostRepository) UpdateWithRevision(post *model.Post, revision *model.PostRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Post{}).
+			Where("id = ? AND revision_count = ?", post.ID, revision.Revision).
+			Updates(map[string]interface{}{
+				"content":        post.Content,
+				"image_urls":     post.ImageURLs,
+				"media_ids":      post.MediaIDs,
+				"is_hidden":      post.IsHidden,
+				"edited_at":      post.EditedAt,
+				"revision_count": gorm.Expr("revision_count + 1"),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update post: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("invalid request: the post was edited in the meantime, reload it and try again")
+		}
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("failed to save post revision: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetRevisions trả về các phiên bản cũ của bài viết, mới nhất trước
+func (r *PostRepository) GetRevisions(postID string) ([]model.PostRevision, error) {
+	var revisions []model.PostRevision
+	if err := r.db.Where("post_id = ?", postID).Order("revision DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get post revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// Delete xoá mềm bài viết của tác giả; lượt thích, hashtag và bình luận được giữ nguyên để
+// có thể khôi phục
+func (r *PostRepository) Delete(postID, userID, deletedBy string) error {
+	result := r.db.Model(&model.Post{}).
+		Where("id = ? AND user_id = ?", postID, userID).
+		Updates(map[string]interface{}{
+			"deleted_at":      time.Now(),
+			"deleted_by":      deletedBy,
+			"deletion_source": model.DeletionByAuthor,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete post: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("post not found")
 	}
 	return nil
 }
 
+// SetHidden ẩn hoặc hiện bài viết (dùng cho kiểm duyệt)
+func (r *PostRepository) SetHidden(postID string, hidden bool) error {
+	result := r.db.Model(&model.Post{}).Where("id = ?", postID).UpdateColumn("is_hidden", hidden)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update post visibility: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("post not found")
+	}
+	return nil
+}
+
+// DeleteByID xóa mềm bài viết không kiểm tra chủ sở hữu (dùng cho kiểm duyệt). Bài tác giả
+// đã tự xoá cũng được chuyển sang do kiểm duyệt xoá để tác giả không khôi phục được.
+func (r *PostRepository) DeleteByID(postID, moderatorID string) error {
+	result := r.db.Unscoped().Model(&model.Post{}).
+		Where("id = ? AND (deleted_at IS NULL OR deletion_source = ?)", postID, model.DeletionByAuthor).
+		Updates(map[string]interface{}{
+			"deleted_at":      time.Now(),
+			"deleted_by":      moderatorID,
+			"deletion_source": model.DeletionByModeration,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete post: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("post not found")
+	}
+	return nil
+}
+
+// GetDeletedByID lấy bài viết đã xoá nhưng chưa bị xoá hẳn
+func (r *PostRepository) GetDeletedByID(postID string) (*model.Post, error) {
+	post := &model.Post{}
+	if err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", postID).First(post).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("deleted post not found")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	return post, nil
+}
+
+// GetDeleted lists deleted posts, most recently deleted first. userID and since restrict
+// the list to what an author can still restore.
+func (r *PostRepository) GetDeleted(userID *string, source *model.DeletionSource, since *time.Time, pagination utils.PaginationResult) ([]model.Post, int64, error) {
+	query := r.db.Unscoped().Model(&model.Post{}).Where("deleted_at IS NOT NULL")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if source != nil {
+		query = query.Where("deletion_source = ?", *source)
+	}
+	if since != nil {
+		query = query.Where("deleted_at >= ?", *since)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count deleted posts: %w", err)
+	}
+	var posts []model.Post
+	if err := query.Order("deleted_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&posts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get deleted posts: %w", err)
+	}
+	return posts, totalCount, nil
+}
+
+// Restore khôi phục bài viết tác giả đã xoá từ thời điểm since trở về sau
+func (r *PostRepository) Restore(postID, userID string, since time.Time) error {
+	result := r.db.Unscoped().Model(&model.Post{}).
+		Where("id = ? AND user_id = ? AND deletion_source = ? AND deleted_at >= ?", postID, userID, model.DeletionByAuthor, since).
+		Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil, "deletion_source": nil})
+	if result.Error != nil {
+		return fmt.Errorf("failed to restore post: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("deleted post not found")
+	}
+	return nil
+}
+
+// RestoreByID khôi phục bài viết đã xoá bất kể ai xoá (dùng cho kiểm duyệt)
+func (r *PostRepository) RestoreByID(postID string) error {
+	result := r.db.Unscoped().Model(&model.Post{}).
+		Where("id = ? AND deleted_at IS NOT NULL", postID).
+		Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil, "deletion_source": nil})
+	if result.Error != nil {
+		return fmt.Errorf("failed to restore post: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("deleted post not found")
+	}
+	return nil
+}
+
+// PurgeDeleted xoá hẳn tối đa limit bài viết đã xoá trước before, cùng lượt thích, hashtag,
+// bình luận và lịch sử sửa (ON DELETE CASCADE). Trả về ID bài viết và media chúng dùng.
+func (r *PostRepository) PurgeDeleted(before time.Time, limit int) ([]string, []string, error) {
+	var posts []model.Post
+	err := r.db.Unscoped().Select("id", "media_ids").
+		Where("deleted_at < ?", before).Order("deleted_at ASC").Limit(limit).Find(&posts).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get posts to purge: %w", err)
+	}
+	return r.purge(posts, "deleted_at < ?", before)
+}
+
+// PurgeByUser xoá hẳn tối đa limit bài viết của userID, kể cả bài đã xoá; dùng khi xoá tài khoản
+func (r *PostRepository) PurgeByUser(userID string, limit int) ([]string, []string, error) {
+	var posts []model.Post
+	err := r.db.Unscoped().Select("id", "media_ids").Where("user_id = ?", userID).Limit(limit).Find(&posts).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get posts to purge: %w", err)
+	}
+	return r.purge(posts, "user_id = ?", userID)
+}
+
+// purge xoá hẳn các bài viết còn thoả điều kiện cond và trả về ID của chúng cùng media chúng dùng
+func (r *PostRepository) purge(posts []model.Post, cond string, args ...interface{}) ([]string, []string, error) {
+	if len(posts) == 0 {
+		return nil, nil, nil
+	}
+
+	postIDs := make([]string, len(posts))
+	var mediaIDs []string
+	for i, p := range posts {
+		postIDs[i] = p.ID
+		mediaIDs = append(mediaIDs, p.MediaIDs...)
+	}
+	var revisions []model.PostRevision
+	if err := r.db.Select("media_ids").Where("post_id IN ?", postIDs).Find(&revisions).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get post revisions: %w", err)
+	}
+	for _, rev := range revisions {
+		mediaIDs = append(mediaIDs, rev.MediaIDs...)
+	}
+
+	if err := r.db.Unscoped().Where("id IN ?", postIDs).Where(cond, args...).Delete(&model.Post{}).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to purge posts: %w", err)
+	}
+	return postIDs, mediaIDs, nil
+}
+
 func (r *PostRepository) GetFeed(userID string, pagination utils.PaginationResult) ([]model.Post, int64, error) {
 	var posts []model.Post
 	// Lấy danh sách user_id mà user này theo dõi + chính user đó
@@ -71,9 +358,9 @@ func (r *PostRepository) GetFeed(userID string, pagination utils.PaginationResul
 	followingIDs = append(followingIDs, ids...)
 	// Đếm tổng số post
 	var totalCount int64
-	r.db.Model(&model.Post{}).Where("user_id IN ?", followingIDs).Count(&totalCount)
+	r.db.Model(&model.Post{}).Where("user_id IN ? AND is_hidden = ?", followingIDs, false).Where(visibleAuthor).Count(&totalCount)
 	// Lấy post
-	if err := r.db.Where("user_id IN ?", followingIDs).Order("created_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&posts).Error; err != nil {
+	if err := r.db.Where("user_id IN ? AND is_hidden = ?", followingIDs, false).Where(visibleAuthor).Order("created_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&posts).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get feed: %w", err)
 	}
 	return posts, totalCount, nil
@@ -82,63 +369,205 @@ func (r *PostRepository) GetFeed(userID string, pagination utils.PaginationResul
 func (r *PostRepository) GetUserPosts(userID string, viewerID *string, pagination utils.PaginationResult) ([]model.Post, int64, error) {
 	var posts []model.Post
 	var totalCount int64
-	r.db.Model(&model.Post{}).Where("user_id = ?", userID).Count(&totalCount)
-	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&posts).Error; err != nil {
+	// Authors still see their own hidden posts, so shadow-hidden content looks published to them
+	visible := "is_hidden = FALSE"
+	if viewerID != nil && *viewerID == userID {
+		visible = "1 = 1"
+	}
+	r.db.Model(&model.Post{}).Where("user_id = ?", userID).Where(visible).Where(visibleAuthor).Count(&totalCount)
+	if err := r.db.Where("user_id = ?", userID).Where(visible).Where(visibleAuthor).Order("created_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&posts).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get user posts: %w", err)
 	}
 	return posts, totalCount, nil
 }
 
-func (r *PostRepository) LikePost(postID, userID string) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Insert like
-		res := tx.Exec("INSERT IGNORE INTO post_likes (post_id, user_id) VALUES (?, ?)", postID, userID)
-		if res.Error != nil {
-			return fmt.Errorf("failed to like post: %w", res.Error)
+// React đặt reaction của userID cho bài viết, thay cho reaction trước đó nếu có.
+// Trả về reaction trước đó.
+func (r *PostRepository) React(postID, userID string, reaction model.ReactionType) (*model.ReactionType, error) {
+	var previous *model.ReactionType
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Khoá bài viết để các reaction trên cùng một bài được đếm lần lượt; bài đã xoá thì không được
+		if err := lockRow(tx, &model.Post{}, postID, "post not found"); err != nil {
+			return err
+		}
+		existing := &model.PostReaction{}
+		err := tx.Where("post_id = ? AND user_id = ?", postID, userID).First(existing).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to get reaction: %w", err)
 		}
-		if res.RowsAffected == 0 {
-			return fmt.Errorf("post already liked")
+		if err == nil {
+			previous = &existing.Reaction
+			if existing.Reaction == reaction {
+				return nil
+			}
+			if err := tx.Model(existing).Update("reaction", reaction).Error; err != nil {
+				return fmt.Errorf("failed to react to post: %w", err)
+			}
+			if err := tx.Model(&model.Post{}).Where("id = ?", postID).UpdateColumns(reactionCountUpdates(*previous, -1)).Error; err != nil {
+				return fmt.Errorf("failed to update reaction count: %w", err)
+			}
+		} else {
+			created := &model.PostReaction{ID: uuid.New().String(), PostID: postID, UserID: userID, Reaction: reaction}
+			if err := tx.Create(created).Error; err != nil {
+				return fmt.Errorf("failed to react to post: %w", err)
+			}
 		}
-		// Update like count
-		if err := tx.Model(&model.Post{}).Where("id = ?", postID).
-			UpdateColumn("like_count", gorm.Expr("like_count + 1")).Error; err != nil {
-			return fmt.Errorf("failed to update like count: %w", err)
+		if err := tx.Model(&model.Post{}).Where("id = ?", postID).UpdateColumns(reactionCountUpdates(reaction, 1)).Error; err != nil {
+			return fmt.Errorf("failed to update reaction count: %w", err)
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
 }
 
-func (r *PostRepository) UnlikePost(postID, userID string) error {
+// Unreact xoá reaction của userID trên bài viết; với only khác nil thì chỉ xoá reaction loại đó
+func (r *PostRepository) Unreact(postID, userID string, only *model.ReactionType) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Delete like
-		res := tx.Exec("DELETE FROM post_likes WHERE post_id = ? AND user_id = ?", postID, userID)
-		if res.Error != nil {
-			return fmt.Errorf("failed to unlike post: %w", res.Error)
+		if err := lockRow(tx, &model.Post{}, postID, "post not found"); err != nil {
+			return err
+		}
+		existing := &model.PostReaction{}
+		query := tx.Where("post_id = ? AND user_id = ?", postID, userID)
+		if only != nil {
+			query = query.Where("reaction = ?", *only)
 		}
-		if res.RowsAffected == 0 {
-			return fmt.Errorf("post not liked")
+		if err := query.First(existing).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("reaction not found")
+			}
+			return fmt.Errorf("failed to get reaction: %w", err)
 		}
-		// Update like count
-		if err := tx.Model(&model.Post{}).Where("id = ?", postID).
-			UpdateColumn("like_count", gorm.Expr("like_count - 1")).Error; err != nil {
-			return fmt.Errorf("failed to update like count: %w", err)
+		if err := tx.Delete(existing).Error; err != nil {
+			return fmt.Errorf("failed to remove reaction: %w", err)
+		}
+		if err := tx.Model(&model.Post{}).Where("id = ?", postID).UpdateColumns(reactionCountUpdates(existing.Reaction, -1)).Error; err != nil {
+			return fmt.Errorf("failed to update reaction count: %w", err)
 		}
 		return nil
 	})
 }
 
+// LikePost giữ cho các endpoint like cũ: like là reaction "like"
+func (r *PostRepository) LikePost(postID, userID string) error {
+	previous, err := r.React(postID, userID, model.ReactionLike)
+	if err != nil {
+		return err
+	}
+	if previous != nil && *previous == model.ReactionLike {
+		return fmt.Errorf("post already liked")
+	}
+	return nil
+}
+
+func (r *PostRepository) UnlikePost(postID, userID string) error {
+	like := model.ReactionLike
+	err := r.Unreact(postID, userID, &like)
+	if err != nil && err.Error() == "reaction not found" {
+		return fmt.Errorf("post not liked")
+	}
+	return err
+}
+
+// RemoveUserReactions xoá tối đa limit reaction của userID và trừ số đếm tương ứng,
+// kể cả trên bài viết đã xoá. Trả về số reaction đã xét.
+func (r *PostRepository) RemoveUserReactions(userID string, limit int) (int, error) {
+	var reactions []model.PostReaction
+	if err := r.db.Where("user_id = ?", userID).Limit(limit).Find(&reactions).Error; err != nil {
+		return 0, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, reaction := range reactions {
+			res := tx.Where("id = ?", reaction.ID).Delete(&model.PostReaction{})
+			if res.Error != nil {
+				return fmt.Errorf("failed to remove reaction: %w", res.Error)
+			}
+			// Đã bị xoá bởi tiến trình khác
+			if res.RowsAffected == 0 {
+				continue
+			}
+			if err := tx.Unscoped().Model(&model.Post{}).Where("id = ?", reaction.PostID).UpdateColumns(reactionCountUpdates(reaction.Reaction, -1)).Error; err != nil {
+				return fmt.Errorf("failed to update reaction count: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(reactions), nil
+}
+
+// GetUserReactions returns the reactions of userID to posts, oldest first
+func (r *PostRepository) GetUserReactions(userID string, pagination utils.PaginationResult) ([]model.PostReaction, error) {
+	var reactions []model.PostReaction
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC, post_id ASC").
+		Limit(pagination.Limit).Offset(pagination.Offset).Find(&reactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	return reactions, nil
+}
+
+// GetReaction returns the reaction of userID to a post, nil if there is none
+func (r *PostRepository) GetReaction(postID, userID string) (*model.ReactionType, error) {
+	reactions, err := r.GetViewerReactions(userID, []string{postID})
+	if err != nil {
+		return nil, err
+	}
+	if reaction, ok := reactions[postID]; ok {
+		return &reaction, nil
+	}
+	return nil, nil
+}
+
+// GetViewerReactions returns the reactions of userID to the given posts, by post ID
+func (r *PostRepository) GetViewerReactions(userID string, postIDs []string) (map[string]model.ReactionType, error) {
+	reactions := make(map[string]model.ReactionType)
+	if len(postIDs) == 0 {
+		return reactions, nil
+	}
+	var found []model.PostReaction
+	if err := r.db.Select("post_id, reaction").Where("user_id = ? AND post_id IN ?", userID, postIDs).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	for _, reaction := range found {
+		reactions[reaction.PostID] = reaction.Reaction
+	}
+	return reactions, nil
+}
+
+// GetReactions returns who reacted to a post, newest first, optionally only with one reaction
+func (r *PostRepository) GetReactions(postID string, reaction *model.ReactionType, pagination utils.PaginationResult) ([]model.Reactor, int64, error) {
+	return getReactors(r.db, "post_reactions", "post_id", postID, reaction, pagination)
+}
+
+// GetViewerBookmarks returns which of the posts the user has bookmarked
+func (r *PostRepository) GetViewerBookmarks(userID string, postIDs []string) (map[string]bool, error) {
+	bookmarked := make(map[string]bool)
+	if len(postIDs) == 0 {
+		return bookmarked, nil
+	}
+	var found []string
+	if err := r.db.Model(&model.Bookmark{}).Where("user_id = ? AND post_id IN ?", userID, postIDs).Pluck("post_id", &found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+	for _, postID := range found {
+		bookmarked[postID] = true
+	}
+	return bookmarked, nil
+}
+
 func (r *PostRepository) IsPostLikedByUser(postID, userID string) (bool, error) {
-	var count int64
-	err := r.db.Model(&model.PostLike{}).
-		Where("post_id = ? AND user_id = ?", postID, userID).
-		Count(&count).Error
+	reaction, err := r.GetReaction(postID, userID)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if post is liked: %w", err)
 	}
-	return count > 0, nil
+	return reaction != nil && *reaction == model.ReactionLike, nil
 }
 
-// Hashtag repository methods
 func (r *PostRepository) FindOrCreateHashtag(name string) (*model.Hashtag, error) {
 	hashtag := &model.Hashtag{}
 	err := r.db.Where("name = ?", name).First(hashtag).Error
@@ -188,14 +617,87 @@ func (r *PostRepository) GetHashtagsByPost(postID string) ([]model.Hashtag, erro
 
 func (r *PostRepository) GetPostsByHashtag(hashtagName string, limit, offset int) ([]model.Post, error) {
 	var posts []model.Post
-	err := r.db.Raw(`SELECT p.* FROM posts p JOIN post_hashtags ph ON p.id = ph.post_id JOIN hashtags h ON ph.hashtag_id = h.id WHERE h.name = ? ORDER BY p.created_at DESC LIMIT ? OFFSET ?`, hashtagName, limit, offset).Scan(&posts).Error
+	err := r.db.Raw(`SELECT p.* FROM posts p JOIN post_hashtags ph ON p.id = ph.post_id JOIN hashtags h ON ph.hashtag_id = h.id WHERE h.name = ? AND p.is_hidden = FALSE AND p.deleted_at IS NULL AND p.`+visibleAuthor+` ORDER BY p.created_at DESC LIMIT ? OFFSET ?`, hashtagName, limit, offset).Scan(&posts).Error
 	if err != nil {
 		return nil, err
 	}
 	return posts, nil
 }
 
-// SearchPosts tìm kiếm post theo content, hashtag, username, full_name
+// ForEachPost duyệt toàn bộ post theo lô, dùng để dựng lại chỉ mục tìm kiếm
+func (r *PostRepository) ForEachPost(batchSize int, fn func(posts []model.Post) error) error {
+	var batch []model.Post
+	result := r.db.Model(&model.Post{}).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to scan posts: %w", result.Error)
+	}
+	return nil
+}
+
+// FilterVisibleIDs trả về các post trong ids mà người khác được phép xem (không bị ẩn, tác giả không bị cấm)
+func (r *PostRepository) FilterVisibleIDs(ids []string) (map[string]bool, error) {
+	visible := make(map[string]bool, len(ids))
+	if len(ids) == 0 {
+		return visible, nil
+	}
+	var found []string
+	if err := r.db.Model(&model.Post{}).Where("id IN ? AND is_hidden = ?", ids, false).Where(visibleAuthor).Pluck("id", &found).Error; err != nil {
+		return nil, fmt.Errorf("failed to filter posts: %w", err)
+	}
+	for _, id := range found {
+		visible[id] = true
+	}
+	return visible, nil
+}
+
+// GetByIDs lấy các post theo đúng thứ tự của ids
+func (r *PostRepository) GetByIDs(ids []string) ([]model.Post, error) {
+	if len(ids) == 0 {
+		return []model.Post{}, nil
+	}
+	var found []model.Post
+	if err := r.db.Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+	byID := make(map[string]model.Post, len(found))
+	for _, p := range found {
+		byID[p.ID] = p
+	}
+	posts := make([]model.Post, 0, len(found))
+	for _, id := range ids {
+		if p, ok := byID[id]; ok {
+			posts = append(posts, p)
+		}
+	}
+	return posts, nil
+}
+
+// GetHashtagsByNames lấy hashtag theo đúng thứ tự của names
+func (r *PostRepository) GetHashtagsByNames(names []string) ([]model.Hashtag, error) {
+	if len(names) == 0 {
+		return []model.Hashtag{}, nil
+	}
+	var found []model.Hashtag
+	if err := r.db.Where("name IN ?", names).Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to get hashtags: %w", err)
+	}
+	byName := make(map[string]model.Hashtag, len(found))
+	for _, h := range found {
+		byName[h.Name] = h
+	}
+	hashtags := make([]model.Hashtag, 0, len(found))
+	for _, name := range names {
+		if h, ok := byName[name]; ok {
+			hashtags = append(hashtags, h)
+		}
+	}
+	return hashtags, nil
+}
+
+// SearchPosts tìm kiếm post theo content, hashtag, username, full_name.
+// Chỉ dùng khi chỉ mục tìm kiếm chưa dựng xong.
 func (r *PostRepository) SearchPosts(query string, page, pageSize int) ([]model.Post, int64, error) {
 	var posts []model.Post
 	var totalCount int64
@@ -206,6 +708,8 @@ func (r *PostRepository) SearchPosts(query string, page, pageSize int) ([]model.
 		Joins("LEFT JOIN users u ON p.user_id = u.id").
 		Joins("LEFT JOIN post_hashtags ph ON p.id = ph.post_id").
 		Joins("LEFT JOIN hashtags h ON ph.hashtag_id = h.id").
+		Where("p.is_hidden = ? AND p.deleted_at IS NULL", false).
+		Where("u.account_status NOT IN ?", model.HiddenAccountStatuses).
 		Where("p.content LIKE ? OR h.name LIKE ? OR u.username LIKE ? OR u.full_name LIKE ?", q, q, q, q)
 
 	db.Count(&totalCount)
@@ -220,7 +724,7 @@ func (r *PostRepository) SearchPosts(query string, page, pageSize int) ([]model.
 	return posts, totalCount, nil
 }
 
-// SearchHashtags tìm kiếm hashtag theo tên
+// SearchHashtags tìm kiếm hashtag theo tên (dự phòng khi chỉ mục chưa sẵn sàng)
 func (r *PostRepository) SearchHashtags(query string, page, pageSize int) ([]model.Hashtag, int64, error) {
 	var hashtags []model.Hashtag
 	var totalCount int64
@@ -244,7 +748,8 @@ func (r *PostRepository) SearchPostsByContent(query string, page, pageSize int)
 	var totalCount int64
 	q := "%" + query + "%"
 	db := r.db.Model(&model.Post{}).
-		Where("content LIKE ?", q)
+		Where("content LIKE ? AND is_hidden = ?", q, false).
+		Where(visibleAuthor)
 	db.Count(&totalCount)
 	err := db.Order("created_at DESC").
 		Limit(pageSize).
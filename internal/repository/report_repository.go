@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+func (r *ReportRepository) Create(report *model.Report) error {
+	if err := r.db.Create(report).Error; err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	return nil
+}
+
+func (r *ReportRepository) GetByID(reportID string) (*model.Report, error) {
+	report := &model.Report{}
+	if err := r.db.Where("id = ?", reportID).First(report).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("report not found")
+		}
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	r.attachReportCounts([]*model.Report{report})
+	return report, nil
+}
+
+func (r *ReportRepository) HasPendingReport(reporterID string, targetType model.ReportTarget, targetID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?", reporterID, targetType, targetID, model.ReportStatusPending).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending report: %w", err)
+	}
+	return count > 0, nil
+}
+
+// HasPendingAutoReport reports whether the target is currently held for review by a content rule
+// (including while a moderator resolves it)
+func (r *ReportRepository) HasPendingAutoReport(targetType model.ReportTarget, targetID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Report{}).
+		Where("source = ? AND target_type = ? AND target_id = ? AND status IN ?", model.ReportSourceAuto, targetType, targetID,
+			[]model.ReportStatus{model.ReportStatusPending, model.ReportStatusResolving}).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check held content: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetTargetOwner returns the user responsible for the reported content.
+// Messages have no backing table yet, so their owner is unknown.
+func (r *ReportRepository) GetTargetOwner(targetType model.ReportTarget, targetID string) (*string, error) {
+	var ownerID string
+	var err error
+	switch targetType {
+	case model.ReportTargetPost:
+		err = r.db.Model(&model.Post{}).Where("id = ?", targetID).Pluck("user_id", &ownerID).Error
+	case model.ReportTargetComment:
+		err = r.db.Model(&model.Comment{}).Where("id = ?", targetID).Pluck("user_id", &ownerID).Error
+	case model.ReportTargetUser:
+		err = r.db.Model(&model.User{}).Where("id = ?", targetID).Pluck("id", &ownerID).Error
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up reported %s: %w", targetType, err)
+	}
+	if ownerID == "" {
+		return nil, fmt.Errorf("reported %s not found", targetType)
+	}
+	return &ownerID, nil
+}
+
+func (r *ReportRepository) GetPending(pagination utils.PaginationResult) ([]model.Report, int64, error) {
+	var reports []model.Report
+	var totalCount int64
+	r.db.Model(&model.Report{}).Where("status = ?", model.ReportStatusPending).Count(&totalCount)
+	if err := r.db.Where("status = ?", model.ReportStatusPending).Order("created_at ASC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&reports).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get pending reports: %w", err)
+	}
+	r.attachReportCounts(reportPtrs(reports))
+	return reports, totalCount, nil
+}
+
+func (r *ReportRepository) GetAll(status *model.ReportStatus, targetType *model.ReportTarget, pagination utils.PaginationResult) ([]model.Report, int64, error) {
+	var reports []model.Report
+	var totalCount int64
+	dbQuery := r.db.Model(&model.Report{})
+	if status != nil {
+		dbQuery = dbQuery.Where("status = ?", *status)
+	}
+	if targetType != nil {
+		dbQuery = dbQuery.Where("target_type = ?", *targetType)
+	}
+	dbQuery.Count(&totalCount)
+	if err := dbQuery.Order("created_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&reports).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get reports: %w", err)
+	}
+	r.attachReportCounts(reportPtrs(reports))
+	return reports, totalCount, nil
+}
+
+// ClaimTarget moves the report and every other pending report against the same target to
+// resolving, so that only one moderator applies an action to the target. Claims older than
+// staleBefore were left by a failed attempt and can be taken over.
+func (r *ReportRepository) ClaimTarget(report *model.Report, moderatorID string, staleBefore time.Time) error {
+	claim := map[string]interface{}{
+		"status":      model.ReportStatusResolving,
+		"resolved_by": moderatorID,
+		"claimed_at":  time.Now(),
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Report{}).
+			Where("id = ? AND (status = ? OR (status = ? AND claimed_at < ?))",
+				report.ID, model.ReportStatusPending, model.ReportStatusResolving, staleBefore).
+			Updates(claim)
+		if result.Error != nil {
+			return fmt.Errorf("failed to claim report: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			var current model.Report
+			if err := tx.Select("status").Where("id = ?", report.ID).First(&current).Error; err != nil {
+				return fmt.Errorf("report not found")
+			}
+			if current.Status == model.ReportStatusResolving {
+				return fmt.Errorf("invalid request: report is being resolved by another moderator")
+			}
+			return fmt.Errorf("report has already been resolved")
+		}
+
+		if err := tx.Model(&model.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, model.ReportStatusPending).
+			Updates(claim).Error; err != nil {
+			return fmt.Errorf("failed to claim reports: %w", err)
+		}
+
+		// Another report against the target may have been claimed by someone else in the meantime
+		var others int64
+		if err := tx.Model(&model.Report{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("target_type = ? AND target_id = ? AND status = ? AND resolved_by <> ? AND claimed_at >= ?",
+				report.TargetType, report.TargetID, model.ReportStatusResolving, moderatorID, staleBefore).
+			Count(&others).Error; err != nil {
+			return fmt.Errorf("failed to claim reports: %w", err)
+		}
+		if others > 0 {
+			return fmt.Errorf("invalid request: report is being resolved by another moderator")
+		}
+		return nil
+	})
+}
+
+// ReleaseClaim puts the reports claimed by the moderator back in the queue after their action failed
+func (r *ReportRepository) ReleaseClaim(targetType model.ReportTarget, targetID, moderatorID string) error {
+	err := r.db.Model(&model.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ? AND resolved_by = ?", targetType, targetID, model.ReportStatusResolving, moderatorID).
+		Updates(map[string]interface{}{
+			"status":      model.ReportStatusPending,
+			"resolved_by": nil,
+			"claimed_at":  nil,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release reports: %w", err)
+	}
+	return nil
+}
+
+// ModerationTx gives a moderation action the repositories it writes through, bound to the
+// transaction that resolves the reports
+type ModerationTx struct {
+	Posts    *PostRepository
+	Comments *CommentRepository
+	Users    *UserRepository
+	Auth     *AuthRepository
+}
+
+// ResolveTarget applies the moderator action through apply, closes the reports the moderator
+// claimed and any report filed against the target since, and records the action in the audit
+// trail, all in one transaction: if any step fails nothing is applied. It returns the reports
+// that were closed.
+func (r *ReportRepository) ResolveTarget(action *model.ModerationAction, status model.ReportStatus, resolution string, apply func(*ModerationTx) error) ([]model.Report, error) {
+	var resolved []model.Report
+	open := "target_type = ? AND target_id = ? AND (status = ? OR (status = ? AND resolved_by = ?))"
+	args := []interface{}{action.TargetType, action.TargetID, model.ReportStatusPending, model.ReportStatusResolving, action.ModeratorID}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := apply(&ModerationTx{
+			Posts:    NewPostRepository(tx),
+			Comments: NewCommentRepository(tx),
+			Users:    NewUserRepository(tx),
+			Auth:     NewAuthRepository(tx),
+		}); err != nil {
+			return err
+		}
+		if err := tx.Where(open, args...).Find(&resolved).Error; err != nil {
+			return fmt.Errorf("failed to get pending reports: %w", err)
+		}
+		if len(resolved) > 0 {
+			if err := tx.Model(&model.Report{}).
+				Where(open, args...).
+				Updates(map[string]interface{}{
+					"status":      status,
+					"resolution":  resolution,
+					"resolved_at": time.Now(),
+					"resolved_by": action.ModeratorID,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to resolve reports: %w", err)
+			}
+		}
+		if err := tx.Create(action).Error; err != nil {
+			return fmt.Errorf("failed to record moderation action: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+func (r *ReportRepository) CreateAction(action *model.ModerationAction) error {
+	if err := r.db.Create(action).Error; err != nil {
+		return fmt.Errorf("failed to record moderation action: %w", err)
+	}
+	return nil
+}
+
+func (r *ReportRepository) GetActions(targetType *model.ReportTarget, targetID *string, pagination utils.PaginationResult) ([]model.ModerationAction, int64, error) {
+	var actions []model.ModerationAction
+	var totalCount int64
+	dbQuery := r.db.Model(&model.ModerationAction{})
+	if targetType != nil {
+		dbQuery = dbQuery.Where("target_type = ?", *targetType)
+	}
+	if targetID != nil {
+		dbQuery = dbQuery.Where("target_id = ?", *targetID)
+	}
+	dbQuery.Count(&totalCount)
+	if err := dbQuery.Order("created_at DESC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&actions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get moderation actions: %w", err)
+	}
+	return actions, totalCount, nil
+}
+
+type reportCountRow struct {
+	Key   string
+	Count int64
+}
+
+func (r *ReportRepository) GetStats() (map[string]interface{}, error) {
+	var byStatus, byReason, byTarget []reportCountRow
+	if err := r.db.Model(&model.Report{}).Select("status as `key`, COUNT(*) as count").Group("status").Scan(&byStatus).Error; err != nil {
+		return nil, fmt.Errorf("failed to count reports by status: %w", err)
+	}
+	if err := r.db.Model(&model.Report{}).Select("reason as `key`, COUNT(*) as count").
+		Where("status = ?", model.ReportStatusPending).Group("reason").Scan(&byReason).Error; err != nil {
+		return nil, fmt.Errorf("failed to count reports by reason: %w", err)
+	}
+	if err := r.db.Model(&model.Report{}).Select("target_type as `key`, COUNT(*) as count").
+		Where("status = ?", model.ReportStatusPending).Group("target_type").Scan(&byTarget).Error; err != nil {
+		return nil, fmt.Errorf("failed to count reports by target: %w", err)
+	}
+
+	var oldestPending *time.Time
+	var oldest model.Report
+	if err := r.db.Where("status = ?", model.ReportStatusPending).Order("created_at ASC").First(&oldest).Error; err == nil {
+		oldestPending = &oldest.CreatedAt
+	}
+
+	var actionsLast24h int64
+	r.db.Model(&model.ModerationAction{}).Where("created_at > ?", time.Now().Add(-24*time.Hour)).Count(&actionsLast24h)
+
+	return map[string]interface{}{
+		"by_status":         rowsToMap(byStatus),
+		"pending_by_reason": rowsToMap(byReason),
+		"pending_by_target": rowsToMap(byTarget),
+		"oldest_pending_at": oldestPending,
+		"actions_last_24h":  actionsLast24h,
+	}, nil
+}
+
+type targetReportCount struct {
+	TargetType model.ReportTarget
+	TargetID   string
+	Count      int64
+}
+
+// attachReportCounts fills in how many pending reports exist for each report's target
+func (r *ReportRepository) attachReportCounts(reports []*model.Report) {
+	if len(reports) == 0 {
+		return
+	}
+	targetIDs := make([]string, len(reports))
+	for i, report := range reports {
+		targetIDs[i] = report.TargetID
+	}
+	var rows []targetReportCount
+	if err := r.db.Model(&model.Report{}).
+		Select("target_type, target_id, COUNT(*) as count").
+		Where("target_id IN ? AND status = ?", targetIDs, model.ReportStatusPending).
+		Group("target_type, target_id").
+		Scan(&rows).Error; err != nil {
+		return
+	}
+	counts := make(map[model.ReportTarget]map[string]int64)
+	for _, row := range rows {
+		if counts[row.TargetType] == nil {
+			counts[row.TargetType] = make(map[string]int64)
+		}
+		counts[row.TargetType][row.TargetID] = row.Count
+	}
+	for _, report := range reports {
+		report.ReportCount = counts[report.TargetType][report.TargetID]
+	}
+}
+
+func reportPtrs(reports []model.Report) []*model.Report {
+	ptrs := make([]*model.Report, len(reports))
+	for i := range reports {
+		ptrs[i] = &reports[i]
+	}
+	return ptrs
+}
+
+func rowsToMap(rows []reportCountRow) map[string]int64 {
+	result := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		result[row.Key] = row.Count
+	}
+	return result
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
 
 	"gorm.io/gorm"
 )
@@ -97,12 +98,179 @@ func (r *UserRepository) UpdatePassword(userID string, passwordHash string) erro
 	return nil
 }
 
-func (r *UserRepository) GetProfile(userID string, viewerID *string) (*model.UserProfile, error) {
+// UpdateAccountStatus changes the account status. When tokensValidAfter is set,
+// every access token issued before that moment stops being accepted.
+func (r *UserRepository) UpdateAccountStatus(userID string, status model.AccountStatus, reason *string, until *time.Time, tokensValidAfter *time.Time) error {
+	updates := map[string]interface{}{
+		"account_status":  status,
+		"status_reason":   reason,
+		"suspended_until": until,
+		"updated_at":      time.Now(),
+	}
+	if tokensValidAfter != nil {
+		updates["tokens_valid_after"] = *tokensValidAfter
+	}
+	result := r.db.Model(&model.User{}).Where("id = ?", userID).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update account status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// CloseAccount deactivates or deletes the account and revokes every access token issued
+// until now. Only active accounts (or lapsed suspensions) and, for a deletion, deactivated
+// ones can be closed.
+func (r *UserRepository) CloseAccount(userID string, status model.AccountStatus, now time.Time) error {
+	updates := map[string]interface{}{
+		"account_status":     status,
+		"status_reason":      nil,
+		"suspended_until":    nil,
+		"tokens_valid_after": now,
+		"updated_at":         now,
+	}
+	from := []model.AccountStatus{model.AccountStatusActive, model.AccountStatusSuspended}
+	if status == model.AccountStatusDeactivated {
+		updates["deactivated_at"] = now
+	} else {
+		updates["deletion_requested_at"] = now
+		from = append(from, model.AccountStatusDeactivated)
+	}
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND account_status IN ?", userID, from).
+		Where("account_status <> ? OR suspended_until <= ?", model.AccountStatusSuspended, now).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update account status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("invalid request: the account cannot be closed in its current status")
+	}
+	return nil
+}
+
+// Reactivate reopens an account deactivated after since. Returns false when there is
+// nothing to reopen (not deactivated, or deactivated for too long).
+func (r *UserRepository) Reactivate(userID string, since time.Time) (bool, error) {
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND account_status = ? AND deactivated_at >= ?", userID, model.AccountStatusDeactivated, since).
+		Updates(map[string]interface{}{
+			"account_status": model.AccountStatusActive,
+			"deactivated_at": nil,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to reactivate account: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteLapsedDeactivations turns accounts deactivated before the given time into deleted ones
+func (r *UserRepository) DeleteLapsedDeactivations(before time.Time) (int64, error) {
+	result := r.db.Model(&model.User{}).
+		Where("account_status = ? AND deactivated_at < ?", model.AccountStatusDeactivated, before).
+		Updates(map[string]interface{}{
+			"account_status":        model.AccountStatusDeleted,
+			"deletion_requested_at": gorm.Expr("NOW()"),
+			"updated_at":            gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete lapsed deactivations: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetPendingErasure returns deleted accounts whose data has not been erased yet, oldest request first
+func (r *UserRepository) GetPendingErasure(limit int) ([]model.User, error) {
+	var users []model.User
+	err := r.db.Where("account_status = ? AND erased_at IS NULL", model.AccountStatusDeleted).
+		Order("deletion_requested_at ASC").Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts to erase: %w", err)
+	}
+	return users, nil
+}
+
+// Anonymize removes the personal data left on a deleted account once its content is gone.
+// The username stays reserved until ReleaseUsernames. Returns false if another run already
+// anonymized it.
+func (r *UserRepository) Anonymize(userID string) (bool, error) {
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND account_status = ? AND erased_at IS NULL", userID, model.AccountStatusDeleted).
+		Updates(map[string]interface{}{
+			"email":                         gorm.Expr("CONCAT(id, '@deleted.invalid')"),
+			"password_hash":                 "",
+			"full_name":                     "Deleted user",
+			"bio":                           nil,
+			"avatar_url":                    nil,
+			"avatar_media_id":               nil,
+			"is_verified":                   false,
+			"is_email_verified":             false,
+			"email_verification_token":      nil,
+			"email_verification_expires_at": nil,
+			"identity_verification_status":  model.IdentityVerificationNone,
+			"verification_category":         nil,
+			"verified_at":                   nil,
+			"identity_documents":            nil,
+			"erased_at":                     gorm.Expr("NOW()"),
+			"updated_at":                    gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to anonymize account: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseUsernames frees the usernames of accounts erased and deleted before the given time
+func (r *UserRepository) ReleaseUsernames(before time.Time) (int64, error) {
+	result := r.db.Model(&model.User{}).
+		Where("account_status = ? AND erased_at IS NOT NULL AND username_released_at IS NULL AND deletion_requested_at < ?",
+			model.AccountStatusDeleted, before).
+		Updates(map[string]interface{}{
+			"username":             gorm.Expr("CONCAT('deleted-', id)"),
+			"username_released_at": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to release usernames: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *UserRepository) GetSuspendedUsers(pagination utils.PaginationResult) ([]model.SuspendedUser, int64, error) {
+	where := `u.account_status = 'banned' OR (u.account_status = 'suspended' AND (u.suspended_until IS NULL OR u.suspended_until > NOW()))`
+
+	var totalCount int64
+	if err := r.db.Raw(`SELECT COUNT(*) FROM users u WHERE ` + where).Scan(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count suspended users: %w", err)
+	}
+
 	query := `
 		SELECT u.id, u.username, u.full_name, u.bio, u.avatar_url, u.is_verified, u.created_at,
+		       u.account_status, u.status_reason, u.suspended_until,
 		       (SELECT COUNT(*) FROM follows WHERE following_id = u.id) as followers_count,
 		       (SELECT COUNT(*) FROM follows WHERE follower_id = u.id) as following_count,
-		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id) as posts_count
+		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id AND deleted_at IS NULL AND is_hidden = FALSE) as posts_count
+		FROM users u
+		WHERE ` + where + `
+		ORDER BY u.updated_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var users []model.SuspendedUser
+	if err := r.db.Raw(query, pagination.Limit, pagination.Offset).Scan(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get suspended users: %w", err)
+	}
+	return users, totalCount, nil
+}
+
+func (r *UserRepository) GetProfile(userID string, viewerID *string) (*model.UserProfile, error) {
+	query := `
+		SELECT u.id, u.username, u.full_name, u.bio, u.avatar_url, u.avatar_media_id, u.is_verified, u.created_at,
+		       u.account_type, u.verification_category, u.verified_at,
+		       (SELECT COUNT(*) FROM follows WHERE following_id = u.id) as followers_count,
+		       (SELECT COUNT(*) FROM follows WHERE follower_id = u.id) as following_count,
+		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id AND deleted_at IS NULL AND is_hidden = FALSE) as posts_count
 	`
 	if viewerID != nil {
 		query += `,
@@ -110,7 +278,8 @@ func (r *UserRepository) GetProfile(userID string, viewerID *string) (*model.Use
 		       EXISTS(SELECT 1 FROM follows WHERE follower_id = u.id AND following_id = ?) as is_followed_by
 		`
 	}
-	query += ` FROM users u WHERE u.id = ?`
+	// Tài khoản đã vô hiệu hoá hoặc đã xoá không còn hồ sơ công khai
+	query += ` FROM users u WHERE u.id = ? AND u.account_status NOT IN ('deactivated', 'deleted')`
 
 	profile := &model.UserProfile{}
 	var args []interface{}
@@ -122,20 +291,135 @@ func (r *UserRepository) GetProfile(userID string, viewerID *string) (*model.Use
 	if err := r.db.Raw(query, args...).Scan(profile).Error; err != nil {
 		return nil, fmt.Errorf("failed to get user profile: %w", err)
 	}
+	if profile.ID == "" {
+		return nil, fmt.Errorf("user not found")
+	}
 	return profile, nil
 }
 
-func (r *UserRepository) SearchUsers(query string, page, pageSize int) ([]model.User, int64, error) {
-	var users []model.User
+// GetVerifiedUsers lists verified accounts that are not suspended or banned, with the
+// viewer's follow status
+func (r *UserRepository) GetVerifiedUsers(filter *model.VerifiedUsersFilter, viewerID *string, pagination utils.PaginationResult) ([]model.UserProfile, int64, error) {
+	where := `u.is_verified = TRUE AND (u.account_status = 'active' OR (u.account_status = 'suspended' AND u.suspended_until <= NOW()))`
+	var whereArgs []interface{}
+	if filter.Category != nil {
+		where += ` AND u.verification_category = ?`
+		whereArgs = append(whereArgs, *filter.Category)
+	}
+	if filter.Query != "" {
+		q := "%" + filter.Query + "%"
+		where += ` AND (u.username LIKE ? OR u.full_name LIKE ?)`
+		whereArgs = append(whereArgs, q, q)
+	}
+
+	query := `
+		SELECT u.id, u.username, u.full_name, u.bio, u.avatar_url, u.avatar_media_id, u.is_verified, u.created_at,
+		       u.account_type, u.verification_category, u.verified_at,
+		       (SELECT COUNT(*) FROM follows WHERE following_id = u.id) as followers_count,
+		       (SELECT COUNT(*) FROM follows WHERE follower_id = u.id) as following_count,
+		       (SELECT COUNT(*) FROM posts WHERE user_id = u.id AND deleted_at IS NULL AND is_hidden = FALSE) as posts_count
+	`
+	var args []interface{}
+	if viewerID != nil {
+		query += `,
+		       EXISTS(SELECT 1 FROM follows WHERE follower_id = ? AND following_id = u.id) as is_following,
+		       EXISTS(SELECT 1 FROM follows WHERE follower_id = u.id AND following_id = ?) as is_followed_by
+		`
+		args = append(args, *viewerID, *viewerID)
+	}
+	query += ` FROM users u WHERE ` + where
+	if filter.Sort == "verified_at" {
+		query += ` ORDER BY u.verified_at IS NULL, u.verified_at DESC, u.id`
+	} else {
+		query += ` ORDER BY followers_count DESC, u.id`
+	}
+	query += ` LIMIT ? OFFSET ?`
+	args = append(args, whereArgs...)
+	args = append(args, pagination.Limit, pagination.Offset)
+
+	var totalCount int64
+	if err := r.db.Raw(`SELECT COUNT(*) FROM users u WHERE `+where, whereArgs...).Scan(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count verified users: %w", err)
+	}
+
+	var users []model.UserProfile
+	if err := r.db.Raw(query, args...).Scan(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get verified users: %w", err)
+	}
+	return users, totalCount, nil
+}
+
+func (r *UserRepository) UpdateVerificationCategory(userID string, category *model.VerificationCategory) error {
+	err := r.db.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"verification_category": category,
+		"updated_at":            time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update verification category: %w", err)
+	}
+	return nil
+}
+
+// ForEachUser walks every account in batches, used to rebuild the search index
+func (r *UserRepository) ForEachUser(batchSize int, fn func(users []model.User) error) error {
+	var batch []model.User
+	result := r.db.Model(&model.User{}).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to scan users: %w", result.Error)
+	}
+	return nil
+}
+
+// publicProfileColumns are the columns of a UserProfile, for listings that must not expose
+// the email or the account status
+const publicProfileColumns = `u.id, u.username, u.full_name, u.bio, u.avatar_url, u.avatar_media_id, u.is_verified, u.created_at,
+		u.account_type, u.verification_category, u.verified_at,
+		(SELECT COUNT(*) FROM follows WHERE following_id = u.id) as followers_count,
+		(SELECT COUNT(*) FROM follows WHERE follower_id = u.id) as following_count,
+		(SELECT COUNT(*) FROM posts WHERE user_id = u.id AND deleted_at IS NULL AND is_hidden = FALSE) as posts_count`
+
+// GetProfilesByIDs returns the profiles in the order of ids, leaving out banned, deactivated and deleted accounts
+func (r *UserRepository) GetProfilesByIDs(ids []string) ([]model.UserProfile, error) {
+	if len(ids) == 0 {
+		return []model.UserProfile{}, nil
+	}
+	var found []model.UserProfile
+	err := r.db.Table("users u").Select(publicProfileColumns).
+		Where("u.id IN ? AND u.account_status NOT IN ?", ids, model.HiddenAccountStatuses).
+		Scan(&found).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	byID := make(map[string]model.UserProfile, len(found))
+	for _, u := range found {
+		byID[u.ID] = u
+	}
+	users := make([]model.UserProfile, 0, len(found))
+	for _, id := range ids {
+		if u, ok := byID[id]; ok {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// SearchUsers is the LIKE-based fallback used while the search index is being built
+func (r *UserRepository) SearchUsers(query string, page, pageSize int) ([]model.UserProfile, int64, error) {
+	var users []model.UserProfile
 	var totalCount int64
 	q := "%" + query + "%"
-	db := r.db.Model(&model.User{}).
-		Where("username LIKE ? OR full_name LIKE ? OR email LIKE ?", q, q, q)
-	db.Count(&totalCount)
-	err := db.Order("created_at DESC").
+	db := r.db.Table("users u").
+		Where("(u.username LIKE ? OR u.full_name LIKE ?) AND u.account_status NOT IN ?", q, q, model.HiddenAccountStatuses)
+	if err := db.Session(&gorm.Session{}).Count(&totalCount).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	err := db.Select(publicProfileColumns).
+		Order("u.created_at DESC").
 		Limit(pageSize).
-		Offset((page-1)*pageSize).
-		Find(&users).Error
+		Offset((page - 1) * pageSize).
+		Scan(&users).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"vietick-backend/internal/model"
 	"vietick-backend/internal/utils"
@@ -17,11 +18,17 @@ func NewVerificationRepository(db *gorm.DB) *VerificationRepository {
 	return &VerificationRepository{db: db}
 }
 
-func (r *VerificationRepository) Create(verification *model.IdentityVerification) error {
-	if err := r.db.Create(verification).Error; err != nil {
-		return fmt.Errorf("failed to create identity verification: %w", err)
-	}
-	return nil
+// Create saves a new request together with its submission event
+func (r *VerificationRepository) Create(verification *model.IdentityVerification, event *model.VerificationEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(verification).Error; err != nil {
+			return fmt.Errorf("failed to create identity verification: %w", err)
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to record verification event: %w", err)
+		}
+		return nil
+	})
 }
 
 func (r *VerificationRepository) GetByID(verificationID string) (*model.IdentityVerification, error) {
@@ -46,39 +53,54 @@ func (r *VerificationRepository) GetByUserID(userID string) (*model.IdentityVeri
 	return verification, nil
 }
 
-func (r *VerificationRepository) GetPending(pagination utils.PaginationResult) ([]model.IdentityVerification, int64, error) {
+// GetPending returns the review queue, oldest first; assignedTo limits it to one reviewer
+func (r *VerificationRepository) GetPending(assignedTo *string, pagination utils.PaginationResult) ([]model.IdentityVerification, int64, error) {
 	var verifications []model.IdentityVerification
 	var totalCount int64
-	r.db.Model(&model.IdentityVerification{}).Where("status = ?", "pending").Count(&totalCount)
-	if err := r.db.Where("status = ?", "pending").Order("submitted_at ASC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&verifications).Error; err != nil {
+	dbQuery := r.db.Model(&model.IdentityVerification{}).Where("status = ?", "pending")
+	if assignedTo != nil {
+		dbQuery = dbQuery.Where("assigned_to = ?", *assignedTo)
+	}
+	dbQuery.Count(&totalCount)
+	if err := dbQuery.Order("submitted_at ASC").Limit(pagination.Limit).Offset(pagination.Offset).Find(&verifications).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to get pending verifications: %w", err)
 	}
 	return verifications, totalCount, nil
 }
 
-func (r *VerificationRepository) Review(verificationID string, status model.IdentityVerificationStatus, adminNotes *string, reviewedBy string) error {
+// Transition moves a request from event.FromStatus to event.ToStatus and records the event.
+// updates are extra columns of the request; userUpdates (if any) are applied to the user in
+// the same transaction. It fails if the request is no longer in FromStatus, so two reviewers
+// cannot decide the same request.
+func (r *VerificationRepository) Transition(event *model.VerificationEvent, updates, userUpdates map[string]interface{}) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Model(&model.IdentityVerification{}).Where("id = ?", verificationID).Updates(map[string]interface{}{
-			"status":      status,
-			"admin_notes": adminNotes,
-			"reviewed_at": gorm.Expr("NOW()"),
-			"reviewed_by": reviewedBy,
-		}).Error; err != nil {
-			return fmt.Errorf("failed to update verification: %w", err)
+		columns := map[string]interface{}{"status": event.ToStatus}
+		for column, value := range updates {
+			columns[column] = value
 		}
-		if status == model.IdentityVerificationApproved {
-			var verification model.IdentityVerification
-			if err := tx.First(&verification, verificationID).Error; err != nil {
-				return fmt.Errorf("failed to get verification: %w", err)
+		result := tx.Model(&model.IdentityVerification{}).
+			Where("id = ? AND status = ?", event.VerificationID, *event.FromStatus).
+			Updates(columns)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update verification: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("invalid status transition: verification is no longer %s", *event.FromStatus)
+		}
+
+		if len(userUpdates) > 0 {
+			columns := map[string]interface{}{"updated_at": gorm.Expr("NOW()")}
+			for column, value := range userUpdates {
+				columns[column] = value
 			}
-			if err := tx.Model(&model.User{}).Where("id = ?", verification.UserID).Updates(map[string]interface{}{
-				"is_verified":                  true,
-				"identity_verification_status": "approved",
-				"updated_at":                   gorm.Expr("NOW()"),
-			}).Error; err != nil {
+			if err := tx.Model(&model.User{}).Where("id = ?", event.UserID).Updates(columns).Error; err != nil {
 				return fmt.Errorf("failed to update user verification status: %w", err)
 			}
 		}
+
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to record verification event: %w", err)
+		}
 		return nil
 	})
 }
@@ -118,3 +140,457 @@ func (r *VerificationRepository) Delete(verificationID string) error {
 	}
 	return nil
 }
+
+// GetByDocumentMediaID returns the verification request a private document belongs to
+func (r *VerificationRepository) GetByDocumentMediaID(mediaID string) (*model.IdentityVerification, error) {
+	verification := &model.IdentityVerification{}
+	err := r.db.Where("front_media_id = ? OR back_media_id = ? OR selfie_media_id = ?", mediaID, mediaID, mediaID).
+		First(verification).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("verification not found")
+		}
+		return nil, fmt.Errorf("failed to get verification: %w", err)
+	}
+	return verification, nil
+}
+
+func (r *VerificationRepository) CreateDocumentAccess(access *model.VerificationDocumentAccess) error {
+	if err := r.db.Create(access).Error; err != nil {
+		return fmt.Errorf("failed to record document access: %w", err)
+	}
+	return nil
+}
+
+func (r *VerificationRepository) GetDocumentAccess(verificationID string) ([]model.VerificationDocumentAccess, error) {
+	var accesses []model.VerificationDocumentAccess
+	if err := r.db.Where("verification_id = ?", verificationID).Order("created_at DESC").Find(&accesses).Error; err != nil {
+		return nil, fmt.Errorf("failed to get document access log: %w", err)
+	}
+	return accesses, nil
+}
+
+// GetPurgeable returns decided requests whose documents are past their retention period
+func (r *VerificationRepository) GetPurgeable(decidedBefore time.Time, limit int) ([]model.IdentityVerification, error) {
+	var verifications []model.IdentityVerification
+	err := r.db.Where("status IN ? AND reviewed_at < ? AND documents_purged_at IS NULL",
+		[]model.IdentityVerificationStatus{model.IdentityVerificationApproved, model.IdentityVerificationRejected,
+			model.IdentityVerificationRevoked, model.IdentityVerificationExpired}, decidedBefore).
+		Order("reviewed_at ASC").Limit(limit).Find(&verifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verifications to purge: %w", err)
+	}
+	return verifications, nil
+}
+
+// GetWithLegacyDocuments returns requests after afterID (in ID order) that still reference
+// documents by public URL. Only the document columns are loaded.
+func (r *VerificationRepository) GetWithLegacyDocuments(afterID string, limit int) ([]model.IdentityVerification, error) {
+	var verifications []model.IdentityVerification
+	err := r.db.Select("id, user_id, front_media_id, back_media_id, selfie_media_id, front_image_url, back_image_url, selfie_image_url").
+		Where("id > ? AND documents_purged_at IS NULL", afterID).
+		Where("front_image_url <> '' OR selfie_image_url <> '' OR COALESCE(back_image_url, '') <> ''").
+		Order("id ASC").Limit(limit).Find(&verifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verifications with legacy documents: %w", err)
+	}
+	return verifications, nil
+}
+
+// SetDocumentMedia points a side of a request to a private media instead of its public URL
+func (r *VerificationRepository) SetDocumentMedia(verificationID, side, mediaID string) error {
+	var urlValue interface{} = ""
+	if side == model.VerificationDocumentBack {
+		urlValue = nil
+	}
+	err := r.db.Model(&model.IdentityVerification{}).Where("id = ?", verificationID).Updates(map[string]interface{}{
+		side + "_media_id":  mediaID,
+		side + "_image_url": urlValue,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update verification: %w", err)
+	}
+	return nil
+}
+
+// MarkDocumentsPurged forgets the documents of a request once their files are deleted
+func (r *VerificationRepository) MarkDocumentsPurged(verificationID string) error {
+	err := r.db.Model(&model.IdentityVerification{}).Where("id = ?", verificationID).Updates(map[string]interface{}{
+		"front_media_id":      nil,
+		"back_media_id":       nil,
+		"selfie_media_id":     nil,
+		"front_image_url":     "",
+		"back_image_url":      nil,
+		"selfie_image_url":    "",
+		"documents_purged_at": gorm.Expr("NOW()"),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update verification: %w", err)
+	}
+	return nil
+}
+
+func (r *VerificationRepository) CreateFingerprints(fingerprints []model.VerificationDocumentFingerprint) error {
+	if len(fingerprints) == 0 {
+		return nil
+	}
+	if err := r.db.Create(&fingerprints).Error; err != nil {
+		return fmt.Errorf("failed to save document fingerprints: %w", err)
+	}
+	return nil
+}
+
+// FingerprintMatch is a document of another user's request that matches a fingerprint
+type FingerprintMatch struct {
+	model.VerificationDocumentFingerprint
+	Status   model.IdentityVerificationStatus
+	Distance int
+}
+
+// FindFingerprintMatches returns documents of other users with the same content hash or a
+// perceptual hash at most maxDistance bits away
+func (r *VerificationRepository) FindFingerprintMatches(fingerprint *model.VerificationDocumentFingerprint, maxDistance int) ([]FingerprintMatch, error) {
+	var matches []FingerprintMatch
+	query := r.db.Table("verification_document_fingerprints f").
+		Joins("JOIN identity_verifications v ON v.id = f.verification_id").
+		Where("f.user_id <> ?", fingerprint.UserID)
+	if fingerprint.PerceptualHash != nil {
+		query = query.Select("f.*, v.status, BIT_COUNT(f.perceptual_hash ^ ?) AS distance", *fingerprint.PerceptualHash).
+			Where("f.content_hash = ? OR BIT_COUNT(f.perceptual_hash ^ ?) <= ?",
+				fingerprint.ContentHash, *fingerprint.PerceptualHash, maxDistance)
+	} else {
+		query = query.Select("f.*, v.status, 0 AS distance").
+			Where("f.content_hash = ?", fingerprint.ContentHash)
+	}
+	if err := query.Order("f.created_at ASC").Limit(20).Scan(&matches).Error; err != nil {
+		return nil, fmt.Errorf("failed to find matching documents: %w", err)
+	}
+	return matches, nil
+}
+
+// FindByIDNumberHash returns other users' requests with the same ID number
+func (r *VerificationRepository) FindByIDNumberHash(idNumberHash, excludeUserID string) ([]model.IdentityVerification, error) {
+	var verifications []model.IdentityVerification
+	err := r.db.Select("id, user_id, status").
+		Where("id_number_hash = ? AND user_id <> ?", idNumberHash, excludeUserID).
+		Order("submitted_at ASC").Limit(20).Find(&verifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find matching verifications: %w", err)
+	}
+	return verifications, nil
+}
+
+func (r *VerificationRepository) CreateDuplicateMatches(matches []model.VerificationDuplicateMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+	if err := r.db.Create(&matches).Error; err != nil {
+		return fmt.Errorf("failed to save duplicate matches: %w", err)
+	}
+	return nil
+}
+
+func (r *VerificationRepository) GetDuplicateMatches(verificationID string) ([]model.VerificationDuplicateMatch, error) {
+	var matches []model.VerificationDuplicateMatch
+	if err := r.db.Where("verification_id = ?", verificationID).Order("created_at ASC").Find(&matches).Error; err != nil {
+		return nil, fmt.Errorf("failed to get duplicate matches: %w", err)
+	}
+	return matches, nil
+}
+
+// GetByUserIDAndStatus returns the user's requests in a status, newest first
+func (r *VerificationRepository) GetByUserIDAndStatus(userID string, status model.IdentityVerificationStatus) ([]model.IdentityVerification, error) {
+	var verifications []model.IdentityVerification
+	err := r.db.Where("user_id = ? AND status = ?", userID, status).
+		Order("submitted_at DESC").Find(&verifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verifications: %w", err)
+	}
+	return verifications, nil
+}
+
+// GetHistory returns every request of a user and their status changes, newest first
+func (r *VerificationRepository) GetHistory(userID string) ([]model.IdentityVerification, []model.VerificationEvent, error) {
+	var verifications []model.IdentityVerification
+	if err := r.db.Where("user_id = ?", userID).Order("submitted_at DESC").Find(&verifications).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get verifications: %w", err)
+	}
+	var events []model.VerificationEvent
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&events).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get verification events: %w", err)
+	}
+	return verifications, events, nil
+}
+
+func (r *VerificationRepository) GetEvents(verificationID string) ([]model.VerificationEvent, error) {
+	var events []model.VerificationEvent
+	if err := r.db.Where("verification_id = ?", verificationID).Order("created_at ASC").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to get verification events: %w", err)
+	}
+	return events, nil
+}
+
+// GetLastEvent returns the user's most recent change to one of the statuses, nil if none
+func (r *VerificationRepository) GetLastEvent(userID string, statuses []model.IdentityVerificationStatus) (*model.VerificationEvent, error) {
+	var events []model.VerificationEvent
+	err := r.db.Where("user_id = ? AND to_status IN ?", userID, statuses).
+		Order("created_at DESC").Limit(1).Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return &events[0], nil
+}
+
+// GetExpiring returns approved requests expiring before the given time that have not been
+// reminded yet
+func (r *VerificationRepository) GetExpiring(before time.Time, limit int) ([]model.IdentityVerification, error) {
+	var verifications []model.IdentityVerification
+	err := r.db.Where("status = ? AND expires_at < ? AND expiry_reminded_at IS NULL",
+		model.IdentityVerificationApproved, before).
+		Order("expires_at ASC").Limit(limit).Find(&verifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring verifications: %w", err)
+	}
+	return verifications, nil
+}
+
+// GetExpired returns approved requests past their expiry
+func (r *VerificationRepository) GetExpired(now time.Time, limit int) ([]model.IdentityVerification, error) {
+	var verifications []model.IdentityVerification
+	err := r.db.Where("status = ? AND expires_at <= ?", model.IdentityVerificationApproved, now).
+		Order("expires_at ASC").Limit(limit).Find(&verifications).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired verifications: %w", err)
+	}
+	return verifications, nil
+}
+
+func (r *VerificationRepository) MarkExpiryReminded(verificationID string) error {
+	err := r.db.Model(&model.IdentityVerification{}).Where("id = ?", verificationID).
+		Update("expiry_reminded_at", gorm.Expr("NOW()")).Error
+	if err != nil {
+		return fmt.Errorf("failed to update verification: %w", err)
+	}
+	return nil
+}
+
+func (r *VerificationRepository) CountByStatus() (map[model.IdentityVerificationStatus]int64, error) {
+	var rows []struct {
+		Status model.IdentityVerificationStatus
+		Count  int64
+	}
+	err := r.db.Model(&model.IdentityVerification{}).Select("status, COUNT(*) AS count").
+		Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count verifications: %w", err)
+	}
+	counts := make(map[model.IdentityVerificationStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// Claim gives the reviewer a lease on a pending request until the given time. It succeeds
+// if nobody holds the request, the previous lease ran out or the reviewer already holds it
+// (renewal).
+func (r *VerificationRepository) Claim(verificationID, reviewerID string, until time.Time) (bool, error) {
+	err := r.db.Model(&model.IdentityVerification{}).
+		Where("id = ? AND status = ?", verificationID, model.IdentityVerificationPending).
+		Where("claimed_by IS NULL OR claimed_by = ? OR claim_expires_at < ?", reviewerID, time.Now()).
+		Updates(map[string]interface{}{
+			"claimed_by":       reviewerID,
+			"claim_expires_at": until,
+		}).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to claim verification: %w", err)
+	}
+
+	// A renewal within the same second changes no row, so the owner is read back rather
+	// than inferred from the affected rows
+	var current model.IdentityVerification
+	err = r.db.Select("status", "claimed_by").Where("id = ?", verificationID).First(&current).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim verification: %w", err)
+	}
+	return current.Status == model.IdentityVerificationPending &&
+		current.ClaimedBy != nil && *current.ClaimedBy == reviewerID, nil
+}
+
+// ReleaseClaim gives up the reviewer's lease on a request
+func (r *VerificationRepository) ReleaseClaim(verificationID, reviewerID string) error {
+	result := r.db.Model(&model.IdentityVerification{}).
+		Where("id = ? AND claimed_by = ?", verificationID, reviewerID).
+		Updates(map[string]interface{}{
+			"claimed_by":       nil,
+			"claim_expires_at": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to release verification: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("claim not found")
+	}
+	return nil
+}
+
+func (r *VerificationRepository) SetHighRisk(verificationID string) error {
+	err := r.db.Model(&model.IdentityVerification{}).Where("id = ?", verificationID).
+		Update("high_risk", true).Error
+	if err != nil {
+		return fmt.Errorf("failed to update verification: %w", err)
+	}
+	return nil
+}
+
+func (r *VerificationRepository) Assign(verificationID, reviewerID string) error {
+	err := r.db.Model(&model.IdentityVerification{}).Where("id = ?", verificationID).
+		Updates(map[string]interface{}{
+			"assigned_to": reviewerID,
+			"assigned_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to assign verification: %w", err)
+	}
+	return nil
+}
+
+// GetUnassigned returns pending requests without a reviewer or assigned to a user who is no
+// longer a reviewer
+func (r *VerificationRepository) GetUnassigned(reviewerIDs []string, limit int) ([]model.IdentityVerification, error) {
+	var verifications []model.IdentityVerification
+	query := r.db.Where("status = ?", model.IdentityVerificationPending)
+	if len(reviewerIDs) > 0 {
+		query = query.Where("assigned_to IS NULL OR assigned_to NOT IN ?", reviewerIDs)
+	} else {
+		query = query.Where("assigned_to IS NULL")
+	}
+	if err := query.Order("submitted_at ASC").Limit(limit).Find(&verifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to get unassigned verifications: %w", err)
+	}
+	return verifications, nil
+}
+
+// GetLastAssignedAt returns when each reviewer was last assigned a request
+func (r *VerificationRepository) GetLastAssignedAt(reviewerIDs []string) (map[string]time.Time, error) {
+	var rows []struct {
+		AssignedTo string
+		LastAt     time.Time
+	}
+	err := r.db.Model(&model.IdentityVerification{}).Select("assigned_to, MAX(assigned_at) AS last_at").
+		Where("assigned_to IN ?", reviewerIDs).Group("assigned_to").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer assignments: %w", err)
+	}
+	lastAt := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		lastAt[row.AssignedTo] = row.LastAt
+	}
+	return lastAt, nil
+}
+
+func (r *VerificationRepository) CreateReview(review *model.VerificationReview) error {
+	if err := r.db.Create(review).Error; err != nil {
+		return fmt.Errorf("failed to record review: %w", err)
+	}
+	return nil
+}
+
+func (r *VerificationRepository) GetReviews(verificationID string) ([]model.VerificationReview, error) {
+	var reviews []model.VerificationReview
+	if err := r.db.Where("verification_id = ?", verificationID).Order("created_at ASC").Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+// GetPendingSubmittedAt returns the submission time of every request in the queue
+func (r *VerificationRepository) GetPendingSubmittedAt() ([]time.Time, error) {
+	var submittedAt []time.Time
+	err := r.db.Model(&model.IdentityVerification{}).Where("status = ?", model.IdentityVerificationPending).
+		Pluck("submitted_at", &submittedAt).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending verifications: %w", err)
+	}
+	return submittedAt, nil
+}
+
+// GetFirstReviewDelays returns, for requests submitted since the given time that have been
+// reviewed, the seconds between submission and the first review
+func (r *VerificationRepository) GetFirstReviewDelays(since time.Time) ([]int64, error) {
+	var delays []int64
+	err := r.db.Raw(`SELECT TIMESTAMPDIFF(SECOND, v.submitted_at, MIN(r.created_at))
+		FROM identity_verifications v
+		JOIN verification_reviews r ON r.verification_id = v.id
+		WHERE v.submitted_at >= ?
+		GROUP BY v.id, v.submitted_at`, since).Scan(&delays).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review delays: %w", err)
+	}
+	return delays, nil
+}
+
+// GetReviewerThroughput counts the decisions of each reviewer over the last 7 and 30 days
+func (r *VerificationRepository) GetReviewerThroughput(now time.Time) ([]model.ReviewerThroughput, error) {
+	var throughput []model.ReviewerThroughput
+	err := r.db.Model(&model.VerificationReview{}).
+		Select(`reviewer_id,
+			SUM(created_at >= ?) AS reviews_7d,
+			COUNT(*) AS reviews_30d,
+			SUM(decision = ?) AS approved_30d,
+			SUM(decision = ?) AS rejected_30d`,
+			now.AddDate(0, 0, -7), model.IdentityVerificationApproved, model.IdentityVerificationRejected).
+		Where("created_at >= ?", now.AddDate(0, 0, -30)).
+		Group("reviewer_id").Order("reviews_30d DESC").Scan(&throughput).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer throughput: %w", err)
+	}
+	return throughput, nil
+}
+
+// GetQueueLoad returns how many pending requests each reviewer is assigned and how many
+// they currently hold a lease on
+func (r *VerificationRepository) GetQueueLoad(now time.Time) (assigned, claimed map[string]int64, err error) {
+	var rows []struct {
+		UserID string
+		Count  int64
+	}
+	assigned = make(map[string]int64)
+	err = r.db.Model(&model.IdentityVerification{}).Select("assigned_to AS user_id, COUNT(*) AS count").
+		Where("status = ? AND assigned_to IS NOT NULL", model.IdentityVerificationPending).
+		Group("assigned_to").Scan(&rows).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get reviewer assignments: %w", err)
+	}
+	for _, row := range rows {
+		assigned[row.UserID] = row.Count
+	}
+
+	rows = nil
+	claimed = make(map[string]int64)
+	err = r.db.Model(&model.IdentityVerification{}).Select("claimed_by AS user_id, COUNT(*) AS count").
+		Where("status = ? AND claimed_by IS NOT NULL AND claim_expires_at > ?", model.IdentityVerificationPending, now).
+		Group("claimed_by").Scan(&rows).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get reviewer claims: %w", err)
+	}
+	for _, row := range rows {
+		claimed[row.UserID] = row.Count
+	}
+	return assigned, claimed, nil
+}
+
+// GetAllByUserID returns every verification request of the user, documents purged or not
+func (r *VerificationRepository) GetAllByUserID(userID string) ([]model.IdentityVerification, error) {
+	var verifications []model.IdentityVerification
+	if err := r.db.Where("user_id = ?", userID).Find(&verifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to get verifications: %w", err)
+	}
+	return verifications, nil
+}
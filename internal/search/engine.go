@@ -0,0 +1,514 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vietick-backend/internal/model"
+)
+
+// Engine is the search backend used by posts, users and hashtags. It only returns
+// ranked IDs; callers load the records and apply visibility rules themselves.
+type Engine interface {
+	IndexPost(doc PostDocument)
+	RemovePost(postID string)
+	SearchPosts(q *Query, limit int) []string
+
+	IndexUser(doc UserDocument)
+	RemoveUser(userID string)
+	SearchUsers(text string, limit int) []string
+
+	SearchHashtags(text string, limit int) []string
+
+	// Typeahead
+	SetFollowers(userID string, count int)
+	AdjustFollowers(userID string, delta int)
+	AutocompleteUsers(prefix string, affinity map[string]float64, limit int) []model.UserSuggestion
+	AutocompleteHashtags(prefix string, limit int) []model.HashtagSuggestion
+
+	Stats() map[string]int
+}
+
+type PostDocument struct {
+	ID        string
+	AuthorID  string
+	Content   string
+	Hashtags  []string
+	HasImage  bool
+	CreatedAt time.Time
+}
+
+type UserDocument struct {
+	ID         string
+	Username   string
+	FullName   string
+	Bio        string
+	AvatarURL  *string
+	IsVerified bool
+	Hidden     bool // banned, deactivated or deleted: kept out of suggestions
+}
+
+type postMeta struct {
+	authorID  string
+	hashtags  []string // as stored, e.g. "việtnam"
+	folded    []string // for filtering, e.g. "vietnam"
+	hasImage  bool
+	createdAt time.Time
+}
+
+type userCard struct {
+	suggestion model.UserSuggestion
+	keys       []string
+}
+
+// MemoryEngine keeps every index in process memory. It is rebuilt from the database
+// on startup and kept current by the services as posts and users change.
+type MemoryEngine struct {
+	posts *Index
+	users *Index
+
+	mu        sync.RWMutex
+	postMeta  map[string]*postMeta
+	usernames map[string]string // folded username -> user ID
+	userNames map[string]string // user ID -> folded username
+	hashtags  map[string]int    // hashtag -> number of indexed posts using it
+	cards     map[string]*userCard
+
+	userPrefixes    *Trie
+	hashtagPrefixes *Trie
+}
+
+func NewMemoryEngine() *MemoryEngine {
+	return &MemoryEngine{
+		posts:     NewIndex(),
+		users:     NewIndex(),
+		postMeta:  make(map[string]*postMeta),
+		usernames: make(map[string]string),
+		userNames: make(map[string]string),
+		hashtags:  make(map[string]int),
+		cards:     make(map[string]*userCard),
+
+		userPrefixes:    NewTrie(),
+		hashtagPrefixes: NewTrie(),
+	}
+}
+
+func (e *MemoryEngine) IndexPost(doc PostDocument) {
+	meta := &postMeta{
+		authorID:  doc.AuthorID,
+		hashtags:  doc.Hashtags,
+		hasImage:  doc.HasImage,
+		createdAt: doc.CreatedAt,
+	}
+	for _, tag := range doc.Hashtags {
+		meta.folded = append(meta.folded, Normalize(tag))
+	}
+
+	e.mu.Lock()
+	e.removePostMetaLocked(doc.ID)
+	e.postMeta[doc.ID] = meta
+	for _, tag := range doc.Hashtags {
+		if e.hashtags[tag]++; e.hashtags[tag] == 1 {
+			e.hashtagPrefixes.Insert(Normalize(tag), tag)
+		}
+	}
+	e.mu.Unlock()
+
+	e.posts.Add(doc.ID, Tokenize(doc.Content))
+}
+
+func (e *MemoryEngine) RemovePost(postID string) {
+	e.mu.Lock()
+	e.removePostMetaLocked(postID)
+	e.mu.Unlock()
+
+	e.posts.Remove(postID)
+}
+
+func (e *MemoryEngine) removePostMetaLocked(postID string) {
+	old, ok := e.postMeta[postID]
+	if !ok {
+		return
+	}
+	for _, tag := range old.hashtags {
+		if e.hashtags[tag]--; e.hashtags[tag] <= 0 {
+			delete(e.hashtags, tag)
+			e.hashtagPrefixes.Remove(Normalize(tag), tag)
+		}
+	}
+	delete(e.postMeta, postID)
+}
+
+// SearchPosts ranks by BM25 (newest first on ties). A query with only filters lists
+// the matching posts newest first.
+func (e *MemoryEngine) SearchPosts(q *Query, limit int) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var authorID string
+	if q.From != "" {
+		id, ok := e.usernames[q.From]
+		if !ok {
+			return nil
+		}
+		authorID = id
+	}
+
+	accept := func(postID string) bool {
+		meta, ok := e.postMeta[postID]
+		if !ok {
+			return false
+		}
+		if authorID != "" && meta.authorID != authorID {
+			return false
+		}
+		if q.HasImage && !meta.hasImage {
+			return false
+		}
+		if q.Since != nil && meta.createdAt.Before(*q.Since) {
+			return false
+		}
+		if q.Until != nil && !meta.createdAt.Before(*q.Until) {
+			return false
+		}
+		for _, tag := range q.Hashtags {
+			if !containsString(meta.folded, tag) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var hits []Hit
+	if q.HasText() {
+		hits = e.posts.Search(q.Terms, q.Phrases, accept)
+	} else if !q.IsEmpty() {
+		for postID := range e.postMeta {
+			if accept(postID) {
+				hits = append(hits, Hit{ID: postID})
+			}
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return e.postMeta[hits[i].ID].createdAt.After(e.postMeta[hits[j].ID].createdAt)
+	})
+	return hitIDs(hits, limit)
+}
+
+func (e *MemoryEngine) IndexUser(doc UserDocument) {
+	username := Normalize(doc.Username)
+
+	e.mu.Lock()
+	if old, ok := e.userNames[doc.ID]; ok {
+		delete(e.usernames, old)
+	}
+	e.usernames[username] = doc.ID
+	e.userNames[doc.ID] = username
+
+	// Follower counts are maintained separately and survive a re-index
+	followers := 0
+	if old, ok := e.cards[doc.ID]; ok {
+		followers = old.suggestion.FollowersCount
+		for _, key := range old.keys {
+			e.userPrefixes.Remove(key, doc.ID)
+		}
+	}
+	card := &userCard{
+		suggestion: model.UserSuggestion{
+			ID:             doc.ID,
+			Username:       doc.Username,
+			FullName:       doc.FullName,
+			AvatarURL:      doc.AvatarURL,
+			IsVerified:     doc.IsVerified,
+			FollowersCount: followers,
+		},
+	}
+	if !doc.Hidden {
+		card.keys = prefixKeys(username, doc.FullName)
+		for _, key := range card.keys {
+			e.userPrefixes.Insert(key, doc.ID)
+		}
+	}
+	e.cards[doc.ID] = card
+	e.mu.Unlock()
+
+	tokens := Tokenize(doc.Username)
+	tokens = append(tokens, Tokenize(doc.FullName)...)
+	tokens = append(tokens, Tokenize(doc.Bio)...)
+	e.users.Add(doc.ID, tokens)
+}
+
+func (e *MemoryEngine) RemoveUser(userID string) {
+	e.mu.Lock()
+	if old, ok := e.userNames[userID]; ok {
+		delete(e.usernames, old)
+		delete(e.userNames, userID)
+	}
+	if card, ok := e.cards[userID]; ok {
+		for _, key := range card.keys {
+			e.userPrefixes.Remove(key, userID)
+		}
+		delete(e.cards, userID)
+	}
+	e.mu.Unlock()
+
+	e.users.Remove(userID)
+}
+
+func (e *MemoryEngine) SearchUsers(text string, limit int) []string {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "@")
+
+	hits := e.users.Search(Tokenize(text), nil, nil)
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	ids := hitIDs(hits, 0)
+
+	// An exact handle always comes first, even one that splits into several terms ("nguyen_van_a")
+	e.mu.RLock()
+	exact, ok := e.usernames[Normalize(text)]
+	e.mu.RUnlock()
+	if ok {
+		ranked := []string{exact}
+		for _, id := range ids {
+			if id != exact {
+				ranked = append(ranked, id)
+			}
+		}
+		ids = ranked
+	}
+
+	return truncate(ids, limit)
+}
+
+// SearchHashtags matches hashtag names ignoring diacritics: exact matches first, then
+// prefixes, then anything containing the text, most used first within each group.
+func (e *MemoryEngine) SearchHashtags(text string, limit int) []string {
+	needle := Normalize(strings.TrimPrefix(strings.TrimSpace(text), "#"))
+	if needle == "" {
+		return nil
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	type match struct {
+		name  string
+		rank  int
+		posts int
+	}
+	var matches []match
+	for name, posts := range e.hashtags {
+		folded := Normalize(name)
+		switch {
+		case folded == needle:
+			matches = append(matches, match{name, 0, posts})
+		case strings.HasPrefix(folded, needle):
+			matches = append(matches, match{name, 1, posts})
+		case strings.Contains(folded, needle):
+			matches = append(matches, match{name, 2, posts})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		if matches[i].posts != matches[j].posts {
+			return matches[i].posts > matches[j].posts
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, m.name)
+	}
+	return truncate(names, limit)
+}
+
+// prefixKeys returns the typeahead keys for a user: the handle, the full name and the
+// name starting at each later word, so "văn" and "a" both find "Nguyễn Văn A"
+func prefixKeys(username, fullName string) []string {
+	keys := []string{username}
+	words := Tokenize(fullName)
+	for i := range words {
+		keys = append(keys, strings.Join(words[i:], " "))
+	}
+	return keys
+}
+
+func (e *MemoryEngine) SetFollowers(userID string, count int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if card, ok := e.cards[userID]; ok {
+		card.suggestion.FollowersCount = count
+	}
+}
+
+func (e *MemoryEngine) AdjustFollowers(userID string, delta int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if card, ok := e.cards[userID]; ok {
+		card.suggestion.FollowersCount += delta
+		if card.suggestion.FollowersCount < 0 {
+			card.suggestion.FollowersCount = 0
+		}
+	}
+}
+
+// Candidates collected from the trie before ranking
+const maxPrefixCandidates = 2000
+
+// AutocompleteUsers ranks users whose handle or name starts with prefix by follower count,
+// verified status and the viewer's affinity (a boost per user ID, e.g. for people they follow).
+// Users in affinity are always considered, even when the prefix matches more candidates than the cap.
+func (e *MemoryEngine) AutocompleteUsers(prefix string, affinity map[string]float64, limit int) []model.UserSuggestion {
+	// rawPrefix matches handles as typed ("john_d"), prefix matches names word by word
+	rawPrefix := Normalize(strings.TrimPrefix(strings.TrimSpace(prefix), "@"))
+	prefix = strings.Join(Tokenize(rawPrefix), " ")
+	if rawPrefix == "" {
+		return nil
+	}
+
+	candidates := e.userPrefixes.Collect(rawPrefix, maxPrefixCandidates)
+	if prefix != "" && prefix != rawPrefix {
+		candidates = append(candidates, e.userPrefixes.Collect(prefix, maxPrefixCandidates)...)
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for userID := range affinity {
+		card, ok := e.cards[userID]
+		if !ok {
+			continue
+		}
+		for _, key := range card.keys {
+			if strings.HasPrefix(key, rawPrefix) || (prefix != "" && strings.HasPrefix(key, prefix)) {
+				candidates = append(candidates, userID)
+				break
+			}
+		}
+	}
+
+	type scored struct {
+		suggestion model.UserSuggestion
+		score      float64
+	}
+	seen := make(map[string]struct{}, len(candidates))
+	var results []scored
+	for _, userID := range candidates {
+		if _, ok := seen[userID]; ok {
+			continue
+		}
+		seen[userID] = struct{}{}
+		card, ok := e.cards[userID]
+		if !ok {
+			continue
+		}
+
+		score := math.Log1p(float64(card.suggestion.FollowersCount))
+		if card.suggestion.IsVerified {
+			score += 2
+		}
+		score += affinity[userID]
+		if username := e.userNames[userID]; username == rawPrefix {
+			score += 10
+		} else if strings.HasPrefix(username, rawPrefix) {
+			score += 1
+		}
+		results = append(results, scored{suggestion: card.suggestion, score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].suggestion.Username < results[j].suggestion.Username
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	suggestions := make([]model.UserSuggestion, len(results))
+	for i, r := range results {
+		suggestions[i] = r.suggestion
+	}
+	return suggestions
+}
+
+// AutocompleteHashtags returns hashtags starting with prefix, most used first
+func (e *MemoryEngine) AutocompleteHashtags(prefix string, limit int) []model.HashtagSuggestion {
+	prefix = Normalize(strings.TrimPrefix(strings.TrimSpace(prefix), "#"))
+	if prefix == "" {
+		return nil
+	}
+
+	names := e.hashtagPrefixes.Collect(prefix, maxPrefixCandidates)
+
+	e.mu.RLock()
+	suggestions := make([]model.HashtagSuggestion, 0, len(names))
+	for _, name := range names {
+		if count, ok := e.hashtags[name]; ok {
+			suggestions = append(suggestions, model.HashtagSuggestion{Name: name, PostCount: count})
+		}
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].PostCount != suggestions[j].PostCount {
+			return suggestions[i].PostCount > suggestions[j].PostCount
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+func (e *MemoryEngine) Stats() map[string]int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return map[string]int{
+		"posts":    len(e.postMeta),
+		"users":    e.users.Len(),
+		"hashtags": len(e.hashtags),
+	}
+}
+
+func hitIDs(hits []Hit, limit int) []string {
+	ids := make([]string, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	return truncate(ids, limit)
+}
+
+func truncate(ids []string, limit int) []string {
+	if limit > 0 && len(ids) > limit {
+		return ids[:limit]
+	}
+	return ids
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAutocompleteUsersHandles(t *testing.T) {
+	e := NewMemoryEngine()
+	e.IndexUser(UserDocument{ID: "1", Username: "john_doe", FullName: "Đỗ Minh"})
+	e.IndexUser(UserDocument{ID: "2", Username: "trang.nguyen", FullName: "Nguyễn Thu Trang"})
+	e.IndexUser(UserDocument{ID: "3", Username: "johnny", FullName: "Johnny Walker"})
+	e.IndexUser(UserDocument{ID: "4", Username: "john_hidden", FullName: "John Hidden", Hidden: true})
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"john_d", []string{"1"}},
+		{"@john_doe", []string{"1"}},
+		{"trang.n", []string{"2"}},
+		{"TRANG.N", []string{"2"}},
+		{"john", []string{"1", "3"}},
+		{"nguyen thu", []string{"2"}},
+		{"thu tr", []string{"2"}},
+		{"do minh", []string{"1"}},
+		{"john_h", nil},
+		{"@", nil},
+	}
+	for _, tt := range tests {
+		got := e.AutocompleteUsers(tt.prefix, nil, 10)
+		ids := make(map[string]bool, len(got))
+		for _, s := range got {
+			ids[s.ID] = true
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("AutocompleteUsers(%q) returned %d users, want %v", tt.prefix, len(got), tt.want)
+			continue
+		}
+		for _, id := range tt.want {
+			if !ids[id] {
+				t.Errorf("AutocompleteUsers(%q) is missing user %s", tt.prefix, id)
+			}
+		}
+	}
+}
+
+func newPostsEngine() *MemoryEngine {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+
+	e := NewMemoryEngine()
+	e.IndexUser(UserDocument{ID: "u1", Username: "lan_anh", FullName: "Lan Anh"})
+	e.IndexUser(UserDocument{ID: "u2", Username: "minh", FullName: "Minh Trần"})
+	e.IndexPost(PostDocument{ID: "p1", AuthorID: "u1", Content: "Phở Hà Nội ngon nhất Việt Nam", CreatedAt: day(1)})
+	e.IndexPost(PostDocument{ID: "p2", AuthorID: "u2", Content: "phở phở phở, ăn phở mỗi sáng", HasImage: true, CreatedAt: day(5)})
+	e.IndexPost(PostDocument{ID: "p3", AuthorID: "u2", Content: "Nam đi Việt Bắc, không ăn phở", CreatedAt: day(10)})
+	e.IndexPost(PostDocument{ID: "p4", AuthorID: "u1", Content: "Du lịch Đà Lạt", Hashtags: []string{"dulịch"}, HasImage: true, CreatedAt: day(20)})
+	return e
+}
+
+func searchPosts(t *testing.T, e *MemoryEngine, raw string) []string {
+	t.Helper()
+	q, err := ParseQuery(raw)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q) returned error: %v", raw, err)
+	}
+	return e.SearchPosts(q, 10)
+}
+
+func TestSearchPostsRanksByBM25(t *testing.T) {
+	e := newPostsEngine()
+
+	// p2 repeats the term and is ranked first; all three contain it
+	got := searchPosts(t, e, "phở")
+	if len(got) != 3 || got[0] != "p2" {
+		t.Errorf("SearchPosts(phở) = %v, want p2 first of 3 posts", got)
+	}
+
+	// Every term is required
+	got = searchPosts(t, e, "ngon phở")
+	if !reflect.DeepEqual(got, []string{"p1"}) {
+		t.Errorf("SearchPosts(ngon phở) = %v, want [p1]", got)
+	}
+
+	// With the same term frequency the shorter post ranks first
+	e = NewMemoryEngine()
+	e.IndexPost(PostDocument{ID: "long", Content: "cà phê sữa đá ở quán quen gần nhà mỗi sáng", CreatedAt: time.Now()})
+	e.IndexPost(PostDocument{ID: "short", Content: "cà phê sữa đá", CreatedAt: time.Now().Add(-time.Hour)})
+	got = searchPosts(t, e, "sua da")
+	if !reflect.DeepEqual(got, []string{"short", "long"}) {
+		t.Errorf("SearchPosts(sua da) = %v, want [short long]", got)
+	}
+}
+
+func TestSearchPostsPhrases(t *testing.T) {
+	e := newPostsEngine()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Both posts have the terms, only p1 has them next to each other
+		{"viet nam", []string{"p1", "p3"}},
+		{`"viet nam"`, []string{"p1"}},
+		{`"viet bac"`, []string{"p3"}},
+		{`"nam ha noi"`, nil},
+		// A quoted single term is an ordinary term
+		{`"ngon"`, []string{"p1"}},
+	}
+	for _, tt := range tests {
+		got := searchPosts(t, e, tt.query)
+		if !sameIDs(got, tt.want) {
+			t.Errorf("SearchPosts(%s) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchPostsFilters(t *testing.T) {
+	e := newPostsEngine()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"phở from:minh", []string{"p2", "p3"}},
+		{"phở from:@LAN_ANH", []string{"p1"}},
+		{"phở from:nobody", nil},
+		{"from:lan_anh", []string{"p4", "p1"}},
+		{"phở since:2024-03-05", []string{"p2", "p3"}},
+		{"phở until:2024-03-05", []string{"p1", "p2"}},
+		{"since:2024-03-02 until:2024-03-10", []string{"p3", "p2"}},
+		{"has:image", []string{"p4", "p2"}},
+		{"phở has:image", []string{"p2"}},
+		{"#dulich", []string{"p4"}},
+		{"#dulich from:minh", nil},
+	}
+	for _, tt := range tests {
+		got := searchPosts(t, e, tt.query)
+		if !sameIDs(got, tt.want) {
+			t.Errorf("SearchPosts(%s) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// Without text the filters list posts newest first
+	got := searchPosts(t, e, "from:lan_anh")
+	if !reflect.DeepEqual(got, []string{"p4", "p1"}) {
+		t.Errorf("SearchPosts(from:lan_anh) = %v, want newest first [p4 p1]", got)
+	}
+
+	if _, err := ParseQuery("since:03/2024"); err == nil {
+		t.Error("ParseQuery(since:03/2024) returned no error")
+	}
+}
+
+func TestSearchPostsFoldsDiacritics(t *testing.T) {
+	e := newPostsEngine()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"pho ha noi", []string{"p1"}},
+		{"PHỞ HÀ NỘI", []string{"p1"}},
+		{"da lat", []string{"p4"}},
+		{"Đà Lạt", []string{"p4"}},
+		{`"du lich"`, []string{"p4"}},
+		{"#dulịch", []string{"p4"}},
+	}
+	for _, tt := range tests {
+		got := searchPosts(t, e, tt.query)
+		if !sameIDs(got, tt.want) {
+			t.Errorf("SearchPosts(%s) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if got := Tokenize("Việt Nam, đẹp!"); !reflect.DeepEqual(got, []string{"viet", "nam", "dep"}) {
+		t.Errorf("Tokenize = %v, want [viet nam dep]", got)
+	}
+}
+
+// sameIDs compares result sets regardless of order
+func sameIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]bool, len(got))
+	for _, id := range got {
+		seen[id] = true
+	}
+	for _, id := range want {
+		if !seen[id] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Hit is a matching document and its relevance score
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index is an in-memory inverted index with positional postings, so it can answer
+// phrase queries as well as ranked term queries. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[string][]int // term -> document -> positions
+	docTerms map[string][]string         // document -> distinct terms, for removal
+	docLen   map[string]int
+	totalLen int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string][]int),
+		docTerms: make(map[string][]string),
+		docLen:   make(map[string]int),
+	}
+}
+
+// Add indexes the document, replacing any previous version of it
+func (ix *Index) Add(docID string, tokens []string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.removeLocked(docID)
+	if len(tokens) == 0 {
+		return
+	}
+
+	var terms []string
+	for pos, term := range tokens {
+		docs, ok := ix.postings[term]
+		if !ok {
+			docs = make(map[string][]int)
+			ix.postings[term] = docs
+		}
+		if _, seen := docs[docID]; !seen {
+			terms = append(terms, term)
+		}
+		docs[docID] = append(docs[docID], pos)
+	}
+	ix.docTerms[docID] = terms
+	ix.docLen[docID] = len(tokens)
+	ix.totalLen += len(tokens)
+}
+
+func (ix *Index) Remove(docID string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeLocked(docID)
+}
+
+func (ix *Index) removeLocked(docID string) {
+	terms, ok := ix.docTerms[docID]
+	if !ok {
+		return
+	}
+	for _, term := range terms {
+		docs := ix.postings[term]
+		delete(docs, docID)
+		if len(docs) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	ix.totalLen -= ix.docLen[docID]
+	delete(ix.docTerms, docID)
+	delete(ix.docLen, docID)
+}
+
+// Len returns the number of indexed documents
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docLen)
+}
+
+// Search returns the documents containing every term and every phrase, scored with BM25.
+// accept, when set, filters candidates before they are scored. Hits are not sorted.
+func (ix *Index) Search(terms []string, phrases [][]string, accept func(docID string) bool) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	// Every phrase term is also a required term
+	required := map[string]struct{}{}
+	for _, term := range terms {
+		required[term] = struct{}{}
+	}
+	for _, phrase := range phrases {
+		for _, term := range phrase {
+			required[term] = struct{}{}
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+
+	// Walk the rarest term's postings and check the others against it
+	var rarest string
+	for term := range required {
+		docs, ok := ix.postings[term]
+		if !ok {
+			return nil
+		}
+		if rarest == "" || len(docs) < len(ix.postings[rarest]) {
+			rarest = term
+		}
+	}
+
+	n := float64(len(ix.docLen))
+	avgLen := float64(ix.totalLen) / n
+	idf := make(map[string]float64, len(required))
+	for term := range required {
+		df := float64(len(ix.postings[term]))
+		idf[term] = math.Log(1 + (n-df+0.5)/(df+0.5))
+	}
+
+	var hits []Hit
+candidates:
+	for docID := range ix.postings[rarest] {
+		for term := range required {
+			if _, ok := ix.postings[term][docID]; !ok {
+				continue candidates
+			}
+		}
+		for _, phrase := range phrases {
+			if !ix.hasPhrase(docID, phrase) {
+				continue candidates
+			}
+		}
+		if accept != nil && !accept(docID) {
+			continue
+		}
+
+		dl := float64(ix.docLen[docID])
+		score := 0.0
+		for term := range required {
+			tf := float64(len(ix.postings[term][docID]))
+			score += idf[term] * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*dl/avgLen))
+		}
+		hits = append(hits, Hit{ID: docID, Score: score})
+	}
+	return hits
+}
+
+// hasPhrase reports whether the terms appear next to each other in order
+func (ix *Index) hasPhrase(docID string, phrase []string) bool {
+	if len(phrase) == 0 {
+		return true
+	}
+	for _, start := range ix.postings[phrase[0]][docID] {
+		matched := true
+		for i := 1; i < len(phrase); i++ {
+			positions := ix.postings[phrase[i]][docID]
+			j := sort.SearchInts(positions, start+i)
+			if j >= len(positions) || positions[j] != start+i {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
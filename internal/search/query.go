@@ -0,0 +1,89 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Query is a parsed search string. Supported syntax:
+//
+//	việt nam            every term must appear (any order)
+//	"việt nam"          exact phrase
+//	#dulich             post has the hashtag
+//	from:username       post author
+//	since:2024-01-31    created on or after the date
+//	until:2024-02-28    created on or before the date
+//	has:image           post has at least one image
+type Query struct {
+	Terms    []string
+	Phrases  [][]string
+	Hashtags []string
+	From     string
+	Since    *time.Time
+	Until    *time.Time
+	HasImage bool
+}
+
+var phraseRegex = regexp.MustCompile(`"([^"]*)"`)
+
+const dateLayout = "2006-01-02"
+
+func ParseQuery(raw string) (*Query, error) {
+	q := &Query{}
+
+	for _, m := range phraseRegex.FindAllStringSubmatch(raw, -1) {
+		tokens := Tokenize(m[1])
+		switch len(tokens) {
+		case 0:
+		case 1:
+			q.Terms = append(q.Terms, tokens[0])
+		default:
+			q.Phrases = append(q.Phrases, tokens)
+		}
+	}
+	raw = phraseRegex.ReplaceAllString(raw, " ")
+
+	for _, field := range strings.Fields(raw) {
+		lower := strings.ToLower(field)
+		switch {
+		case strings.HasPrefix(lower, "from:"):
+			q.From = Normalize(strings.TrimPrefix(field[len("from:"):], "@"))
+		case strings.HasPrefix(lower, "since:"):
+			t, err := time.Parse(dateLayout, field[len("since:"):])
+			if err != nil {
+				return nil, fmt.Errorf("invalid search query: since must be YYYY-MM-DD")
+			}
+			q.Since = &t
+		case strings.HasPrefix(lower, "until:"):
+			t, err := time.Parse(dateLayout, field[len("until:"):])
+			if err != nil {
+				return nil, fmt.Errorf("invalid search query: until must be YYYY-MM-DD")
+			}
+			// Inclusive: everything before the start of the next day
+			t = t.Add(24 * time.Hour)
+			q.Until = &t
+		case lower == "has:image" || lower == "has:images":
+			q.HasImage = true
+		case strings.HasPrefix(field, "#") && len(field) > 1:
+			if tag := Normalize(field[1:]); tag != "" {
+				q.Hashtags = append(q.Hashtags, tag)
+			}
+		default:
+			q.Terms = append(q.Terms, Tokenize(field)...)
+		}
+	}
+
+	return q, nil
+}
+
+// HasText reports whether the query has anything to rank by relevance
+func (q *Query) HasText() bool {
+	return len(q.Terms) > 0 || len(q.Phrases) > 0
+}
+
+// IsEmpty reports whether the query has neither text nor filters
+func (q *Query) IsEmpty() bool {
+	return !q.HasText() && len(q.Hashtags) == 0 && q.From == "" && q.Since == nil && q.Until == nil && !q.HasImage
+}
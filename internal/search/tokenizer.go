@@ -0,0 +1,25 @@
+package search
+
+// search – bộ máy tìm kiếm toàn văn nhúng trong tiến trình: chỉ mục đảo ngược,
+// xếp hạng BM25, bỏ dấu tiếng Việt để "viet nam" khớp với "Việt Nam".
+
+import (
+	"strings"
+	"unicode"
+
+	"vietick-backend/internal/utils"
+)
+
+// Tokenize folds diacritics and case and splits text into terms on anything that
+// is not a letter or a digit. Vietnamese is written one syllable per word, so each
+// syllable becomes its own term and multi-syllable words are found with phrases.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(utils.FoldVietnamese(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Normalize folds a single keyword (username, hashtag) without splitting it
+func Normalize(text string) string {
+	return strings.TrimSpace(utils.FoldVietnamese(text))
+}
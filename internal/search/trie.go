@@ -0,0 +1,117 @@
+package search
+
+import "sync"
+
+// Trie maps folded keys to IDs for prefix lookups (typeahead). Several keys may point
+// at the same ID, e.g. a user's handle and each word of their name.
+type Trie struct {
+	mu   sync.RWMutex
+	root *trieNode
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	ids      map[string]struct{}
+}
+
+func NewTrie() *Trie {
+	return &Trie{root: &trieNode{}}
+}
+
+func (t *Trie) Insert(key, id string) {
+	if key == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	node := t.root
+	for _, r := range key {
+		if node.children == nil {
+			node.children = make(map[rune]*trieNode)
+		}
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	if node.ids == nil {
+		node.ids = make(map[string]struct{})
+	}
+	node.ids[id] = struct{}{}
+}
+
+// Remove deletes the key for the ID and prunes empty branches
+func (t *Trie) Remove(key, id string) {
+	if key == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	runes := []rune(key)
+	path := make([]*trieNode, 0, len(runes)+1)
+	node := t.root
+	path = append(path, node)
+	for _, r := range runes {
+		child, ok := node.children[r]
+		if !ok {
+			return
+		}
+		node = child
+		path = append(path, node)
+	}
+	delete(node.ids, id)
+
+	for i := len(runes); i > 0; i-- {
+		n := path[i]
+		if len(n.ids) > 0 || len(n.children) > 0 {
+			break
+		}
+		delete(path[i-1].children, runes[i-1])
+	}
+}
+
+// Collect returns up to max distinct IDs whose keys start with prefix, shortest keys first
+// so that exact and near-exact matches survive the cap
+func (t *Trie) Collect(prefix string, max int) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	node := t.root
+	for _, r := range prefix {
+		child, ok := node.children[r]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	seen := make(map[string]struct{})
+	var ids []string
+	level := []*trieNode{node}
+	for len(level) > 0 && len(ids) < max {
+		var next []*trieNode
+		for _, n := range level {
+			for id := range n.ids {
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+				if len(ids) >= max {
+					return ids
+				}
+			}
+			for _, child := range n.children {
+				next = append(next, child)
+			}
+		}
+		level = next
+	}
+	return ids
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"vietick-backend/internal/config"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/utils"
+	"vietick-backend/pkg/email"
+)
+
+// eraseBatchSize is how many rows of each kind are removed per query when erasing an account
+const eraseBatchSize = 200
+
+// AccountService handles users closing their own account: deactivation, which is undone by
+// logging back in, and permanent deletion, which erases the account in the background
+type AccountService struct {
+	userRepo            *repository.UserRepository
+	authRepo            *repository.AuthRepository
+	postRepo            *repository.PostRepository
+	commentRepo         *repository.CommentRepository
+	followRepo          *repository.FollowRepository
+	draftRepo           *repository.DraftRepository
+	bookmarkRepo        *repository.BookmarkRepository
+	pollRepo            *repository.PollRepository
+	notificationRepo    *repository.NotificationRepository
+	organizationRepo    *repository.OrganizationRepository
+	authService         *AuthService
+	verificationService *VerificationService
+	mediaService        *MediaService
+	searchService       *SearchService
+	exportService       *ExportService
+	emailService        *email.EmailService
+	config              *config.AccountConfig
+}
+
+func NewAccountService(userRepo *repository.UserRepository, authRepo *repository.AuthRepository,
+	postRepo *repository.PostRepository, commentRepo *repository.CommentRepository, followRepo *repository.FollowRepository,
+	draftRepo *repository.DraftRepository, bookmarkRepo *repository.BookmarkRepository, pollRepo *repository.PollRepository,
+	notificationRepo *repository.NotificationRepository, organizationRepo *repository.OrganizationRepository, authService *AuthService, verificationService *VerificationService,
+	mediaService *MediaService, searchService *SearchService, exportService *ExportService, emailService *email.EmailService,
+	cfg *config.AccountConfig) *AccountService {
+	return &AccountService{
+		userRepo:            userRepo,
+		authRepo:            authRepo,
+		postRepo:            postRepo,
+		commentRepo:         commentRepo,
+		followRepo:          followRepo,
+		draftRepo:           draftRepo,
+		bookmarkRepo:        bookmarkRepo,
+		pollRepo:            pollRepo,
+		notificationRepo:    notificationRepo,
+		organizationRepo:    organizationRepo,
+		authService:         authService,
+		verificationService: verificationService,
+		mediaService:        mediaService,
+		searchService:       searchService,
+		exportService:       exportService,
+		emailService:        emailService,
+		config:              cfg,
+	}
+}
+
+// Deactivate hides the account and its content and signs it out everywhere. Logging in
+// within the reactivation period reopens it; after that it is deleted.
+func (s *AccountService) Deactivate(userID string) (time.Time, error) {
+	if err := s.checkClosable(userID); err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	if err := s.close(userID, model.AccountStatusDeactivated, now); err != nil {
+		return time.Time{}, err
+	}
+	return now.AddDate(0, 0, s.config.ReactivationDays), nil
+}
+
+// Delete permanently deletes the account after checking the password again. The account
+// disappears immediately; its data is erased by EraseDeleted.
+func (s *AccountService) Delete(userID string, req *model.DeleteAccountRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if !utils.CheckPassword(req.Password, user.PasswordHash) {
+		return fmt.Errorf("invalid request: password is incorrect")
+	}
+	if err := s.checkClosable(userID); err != nil {
+		return err
+	}
+
+	return s.close(userID, model.AccountStatusDeleted, time.Now())
+}
+
+// checkClosable refuses organizations and the last owner of an organization, which would
+// otherwise be left without anyone able to manage it
+func (s *AccountService) checkClosable(userID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found")
+	}
+	if user.IsOrganization() {
+		return fmt.Errorf("forbidden: organization accounts cannot be closed by a member")
+	}
+
+	organizations, err := s.organizationRepo.GetSoleOwnerships(userID)
+	if err != nil {
+		return err
+	}
+	if len(organizations) > 0 {
+		return fmt.Errorf("invalid request: you are the only owner of @%s, add another owner first", organizations[0].Username)
+	}
+	return nil
+}
+
+func (s *AccountService) close(userID string, status model.AccountStatus, now time.Time) error {
+	if err := s.userRepo.CloseAccount(userID, status, now); err != nil {
+		return err
+	}
+	if err := s.authRepo.DeleteUserRefreshTokens(userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	s.authService.InvalidateAccountStatus(userID)
+	s.searchService.ReindexUser(userID)
+	return nil
+}
+
+// EraseDeleted deletes the accounts deactivated for longer than the reactivation period,
+// erases the data of deleted accounts and releases usernames at the end of their cooldown.
+// Every step is idempotent, so an interrupted erasure resumes on the next run.
+func (s *AccountService) EraseDeleted() (int, error) {
+	lapsedBefore := time.Now().AddDate(0, 0, -s.config.ReactivationDays)
+	if _, err := s.userRepo.DeleteLapsedDeactivations(lapsedBefore); err != nil {
+		return 0, err
+	}
+
+	users, err := s.userRepo.GetPendingErasure(10)
+	if err != nil {
+		return 0, err
+	}
+	erased := 0
+	for i := range users {
+		if err := s.erase(&users[i]); err != nil {
+			log.Printf("Failed to erase account %s: %v", users[i].ID, err)
+			continue
+		}
+		erased++
+	}
+
+	releaseBefore := time.Now().AddDate(0, 0, -s.config.UsernameCooldownDays)
+	if _, err := s.userRepo.ReleaseUsernames(releaseBefore); err != nil {
+		return erased, err
+	}
+	return erased, nil
+}
+
+// erase removes the content and personal data of a deleted account, keeping the counters
+// of other users' posts and comments right, then anonymizes the account itself
+func (s *AccountService) erase(user *model.User) error {
+	if err := s.authRepo.DeleteUserRefreshTokens(user.ID); err != nil {
+		return err
+	}
+
+	for {
+		n, err := s.postRepo.RemoveUserReactions(user.ID, eraseBatchSize)
+		if err != nil {
+			return err
+		}
+		if n < eraseBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := s.commentRepo.RemoveUserReactions(user.ID, eraseBatchSize)
+		if err != nil {
+			return err
+		}
+		if n < eraseBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := s.pollRepo.RemoveUserVotes(user.ID, eraseBatchSize)
+		if err != nil {
+			return err
+		}
+		if n < eraseBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := s.commentRepo.PurgeByUser(user.ID, eraseBatchSize)
+		if err != nil {
+			return err
+		}
+		if n < eraseBatchSize {
+			break
+		}
+	}
+	for {
+		postIDs, _, err := s.postRepo.PurgeByUser(user.ID, eraseBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, postID := range postIDs {
+			s.searchService.RemovePost(postID)
+		}
+		if len(postIDs) < eraseBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := s.followRepo.DeleteUserFollows(user.ID, eraseBatchSize)
+		if err != nil {
+			return err
+		}
+		if n < eraseBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := s.followRepo.DeleteUserFollowHistory(user.ID, eraseBatchSize)
+		if err != nil {
+			return err
+		}
+		if n < eraseBatchSize {
+			break
+		}
+	}
+
+	if err := s.draftRepo.DeleteByUser(user.ID); err != nil {
+		return err
+	}
+	if err := s.bookmarkRepo.DeleteByUser(user.ID); err != nil {
+		return err
+	}
+	if err := s.notificationRepo.DeleteUserNotifications(user.ID); err != nil {
+		return err
+	}
+	if err := s.organizationRepo.RemoveMemberships(user.ID); err != nil {
+		return err
+	}
+	if err := s.verificationService.DeleteUserVerifications(user.ID); err != nil {
+		return err
+	}
+	if err := s.exportService.DeleteUserExports(user.ID); err != nil {
+		return err
+	}
+	// Avatar, post images and anything else the user uploaded
+	if err := s.mediaService.PurgeOwned(user.ID); err != nil {
+		return err
+	}
+
+	anonymized, err := s.userRepo.Anonymize(user.ID)
+	if err != nil {
+		return err
+	}
+	s.searchService.RemoveUser(user.ID)
+	if !anonymized {
+		return nil
+	}
+
+	if err := s.emailService.SendAccountDeleted(user.Email, user.FullName); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Failed to send account deletion email: %v\n", err)
+	}
+	return nil
+}
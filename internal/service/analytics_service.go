@@ -0,0 +1,557 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"vietick-backend/internal/config"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+)
+
+const (
+	// Impressions and views waiting for the next flush; more are dropped until then
+	maxBufferedViewEvents = 100000
+	// Hourly series cover at most 7 days, daily series 90
+	maxHourlyAnalyticsDays = 7
+	maxDailyAnalyticsDays  = 90
+	defaultDashboardDays   = 28
+	dashboardTopPosts      = 5
+	analyticsDateLayout    = "2006-01-02"
+	// Days the nightly follower job catches up after downtime
+	followerStatsCatchUpDays     = 7
+	maxFollowerAnalyticsDays     = 365
+	defaultFollowerAnalyticsDays = 30
+	topFollowersLimit            = 10
+)
+
+// viewEvent is an impression (a post shown in a list) or a view (a post opened)
+type viewEvent struct {
+	postID   string
+	authorID string
+	viewerID string
+	view     bool
+	at       time.Time
+}
+
+// AnalyticsService counts how many people see each post. Impressions and views are kept in
+// memory, each viewer counted once per post and window, and written in batches by Flush so
+// reading a feed costs no query. Whether a viewer follows the author is looked up at flush.
+type AnalyticsService struct {
+	analyticsRepo *repository.AnalyticsRepository
+	postRepo      *repository.PostRepository
+	config        *config.AnalyticsConfig
+
+	mu      sync.Mutex
+	events  []viewEvent
+	dropped int
+	// Last time a viewer was counted, by kind, post and viewer
+	seen map[string]time.Time
+	// Counts of a failed flush, written with the next one
+	unsaved []model.PostViewStat
+}
+
+func NewAnalyticsService(analyticsRepo *repository.AnalyticsRepository, postRepo *repository.PostRepository, cfg *config.AnalyticsConfig) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		postRepo:      postRepo,
+		config:        cfg,
+		seen:          make(map[string]time.Time),
+	}
+}
+
+// RecordImpressions counts posts shown to viewerID in a feed or list
+func (s *AnalyticsService) RecordImpressions(posts []model.Post, viewerID string) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range posts {
+		s.record(posts[i].ID, posts[i].UserID, viewerID, false, now)
+	}
+}
+
+// RecordView counts a post opened by viewerID
+func (s *AnalyticsService) RecordView(post *model.Post, viewerID string) {
+	if post == nil {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(post.ID, post.UserID, viewerID, true, now)
+}
+
+// record must be called with mu held. Authors seeing their own posts are not counted.
+func (s *AnalyticsService) record(postID, authorID, viewerID string, view bool, now time.Time) {
+	if viewerID == "" || viewerID == authorID {
+		return
+	}
+	kind := "i"
+	if view {
+		kind = "v"
+	}
+	key := kind + ":" + postID + ":" + viewerID
+	if last, ok := s.seen[key]; ok && now.Sub(last) < s.dedupWindow() {
+		return
+	}
+	if len(s.events) >= maxBufferedViewEvents {
+		s.dropped++
+		return
+	}
+	s.seen[key] = now
+	s.events = append(s.events, viewEvent{postID: postID, authorID: authorID, viewerID: viewerID, view: view, at: now})
+}
+
+func (s *AnalyticsService) dedupWindow() time.Duration {
+	return time.Duration(s.config.DedupWindowMinutes) * time.Minute
+}
+
+// FlushInterval is how often Flush should run
+func (s *AnalyticsService) FlushInterval() time.Duration {
+	if s.config.FlushIntervalSeconds < 1 {
+		return time.Second
+	}
+	return time.Duration(s.config.FlushIntervalSeconds) * time.Second
+}
+
+// Flush writes the buffered impressions and views, grouped by post and hour. Counts that
+// could not be written are kept for the next flush.
+func (s *AnalyticsService) Flush() (int, error) {
+	now := time.Now()
+	s.mu.Lock()
+	events := s.events
+	unsaved := s.unsaved
+	dropped := s.dropped
+	s.events = nil
+	s.unsaved = nil
+	s.dropped = 0
+	for key, at := range s.seen {
+		if now.Sub(at) >= s.dedupWindow() {
+			delete(s.seen, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		log.Printf("Analytics buffer was full, %d impressions and views were not counted", dropped)
+	}
+	if len(events) == 0 && len(unsaved) == 0 {
+		return 0, nil
+	}
+
+	stats, err := s.aggregate(events, unsaved)
+	if err != nil {
+		s.requeue(events, unsaved)
+		return 0, err
+	}
+	if err := s.analyticsRepo.AddViewStats(stats); err != nil {
+		s.requeue(nil, stats)
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// requeue puts back what a failed flush could not write, within the size of the buffer
+func (s *AnalyticsService) requeue(events []viewEvent, stats []model.PostViewStat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if room := maxBufferedViewEvents - len(s.events); len(events) > room {
+		s.dropped += len(events) - room
+		events = events[:room]
+	}
+	s.events = append(events, s.events...)
+	if len(s.unsaved)+len(stats) <= maxBufferedViewEvents {
+		s.unsaved = append(s.unsaved, stats...)
+	} else {
+		log.Printf("Analytics buffer was full, %d hourly counts were not saved", len(stats))
+	}
+}
+
+// aggregate groups events by post and hour, splitting those by followers of the author
+func (s *AnalyticsService) aggregate(events []viewEvent, unsaved []model.PostViewStat) ([]model.PostViewStat, error) {
+	var viewerIDs, authorIDs []string
+	viewers := make(map[string]bool)
+	authors := make(map[string]bool)
+	for _, event := range events {
+		if !viewers[event.viewerID] {
+			viewers[event.viewerID] = true
+			viewerIDs = append(viewerIDs, event.viewerID)
+		}
+		if !authors[event.authorID] {
+			authors[event.authorID] = true
+			authorIDs = append(authorIDs, event.authorID)
+		}
+	}
+	followed, err := s.analyticsRepo.GetFollowedAuthors(viewerIDs, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []model.PostViewStat
+	index := make(map[string]int)
+	row := func(postID, authorID string, hour time.Time) *model.PostViewStat {
+		key := postID + ":" + hour.Format(time.RFC3339)
+		i, ok := index[key]
+		if !ok {
+			i = len(stats)
+			index[key] = i
+			stats = append(stats, model.PostViewStat{PostID: postID, UserID: authorID, Hour: hour})
+		}
+		return &stats[i]
+	}
+	for _, event := range events {
+		stat := row(event.postID, event.authorID, event.at.UTC().Truncate(time.Hour))
+		follower := followed[event.viewerID][event.authorID]
+		switch {
+		case event.view && follower:
+			stat.Views++
+			stat.FollowerViews++
+		case event.view:
+			stat.Views++
+		case follower:
+			stat.Impressions++
+			stat.FollowerImpressions++
+		default:
+			stat.Impressions++
+		}
+	}
+	for _, previous := range unsaved {
+		stat := row(previous.PostID, previous.UserID, previous.Hour.UTC())
+		stat.Impressions += previous.Impressions
+		stat.FollowerImpressions += previous.FollowerImpressions
+		stat.Views += previous.Views
+		stat.FollowerViews += previous.FollowerViews
+	}
+	return stats, nil
+}
+
+// GetPostAnalytics returns the hourly or daily series of a post. Only its author may see it;
+// the caller checks that.
+func (s *AnalyticsService) GetPostAnalytics(post *model.Post, filter *model.PostAnalyticsFilter) (*model.PostAnalytics, error) {
+	interval := filter.Interval
+	if interval == "" {
+		interval = model.AnalyticsIntervalDay
+	}
+	step, maxDays, defaultDays, name := 24*time.Hour, maxDailyAnalyticsDays, 30, "daily"
+	if interval == model.AnalyticsIntervalHour {
+		step, maxDays, defaultDays, name = time.Hour, maxHourlyAnalyticsDays, 2, "hourly"
+	}
+
+	now := time.Now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, 1-defaultDays)
+	to := today.AddDate(0, 0, 1)
+	if filter.From != "" {
+		t, err := time.Parse(analyticsDateLayout, filter.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid analytics range: from must be YYYY-MM-DD")
+		}
+		from = t
+	}
+	if filter.To != "" {
+		t, err := time.Parse(analyticsDateLayout, filter.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid analytics range: to must be YYYY-MM-DD")
+		}
+		// Inclusive: everything before the start of the next day
+		to = t.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid analytics range: from must not be after to")
+	}
+	if to.Sub(from) > time.Duration(maxDays)*24*time.Hour {
+		return nil, fmt.Errorf("invalid analytics range: %s analytics cover at most %d days", name, maxDays)
+	}
+	// Nothing happens before the post is published or after now
+	if published := post.CreatedAt.UTC().Truncate(step); from.Before(published) {
+		from = published
+	}
+	if end := now.Truncate(step).Add(step); to.After(end) {
+		to = end
+	}
+
+	analytics := &model.PostAnalytics{
+		PostID:          post.ID,
+		Interval:        interval,
+		From:            from,
+		To:              to,
+		ImpressionCount: post.ImpressionCount,
+		ViewCount:       post.ViewCount,
+		Series:          []model.AnalyticsPoint{},
+	}
+	if !from.Before(to) {
+		return analytics, nil
+	}
+
+	series := newAnalyticsSeries(from, to, step)
+	stats, err := s.analyticsRepo.GetPostViewStats(post.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	series.addViewStats(stats)
+	reactions, err := s.analyticsRepo.CountPostReactionsByHour(post.ID, post.UserID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.analyticsRepo.CountPostCommentsByHour(post.ID, post.UserID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	series.addCounts(reactions, func(p *model.AnalyticsPoint, n int) { p.Reactions += n })
+	series.addCounts(comments, func(p *model.AnalyticsPoint, n int) { p.Comments += n })
+
+	analytics.Series = series.points
+	analytics.Totals = series.totals()
+	return analytics, nil
+}
+
+// GetAccountAnalytics returns the daily series of all posts of a user over the last days,
+// today included, with their new followers and best posts
+func (s *AnalyticsService) GetAccountAnalytics(userID string, days int) (*model.AccountAnalytics, error) {
+	if days <= 0 {
+		days = defaultDashboardDays
+	}
+	if days > maxDailyAnalyticsDays {
+		days = maxDailyAnalyticsDays
+	}
+	step := 24 * time.Hour
+	to := time.Now().UTC().Truncate(step).Add(step)
+	from := to.AddDate(0, 0, -days)
+
+	series := newAnalyticsSeries(from, to, step)
+	stats, err := s.analyticsRepo.GetUserViewStats(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	series.addViewStats(stats)
+	reactions, err := s.analyticsRepo.CountUserReactionsByHour(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.analyticsRepo.CountUserCommentsByHour(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := s.analyticsRepo.CountNewFollowersByHour(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	series.addCounts(reactions, func(p *model.AnalyticsPoint, n int) { p.Reactions += n })
+	series.addCounts(comments, func(p *model.AnalyticsPoint, n int) { p.Comments += n })
+
+	analytics := &model.AccountAnalytics{
+		Days:     days,
+		From:     from,
+		To:       to,
+		Totals:   series.totals(),
+		Series:   make([]model.AccountAnalyticsPoint, len(series.points)),
+		TopPosts: []model.TopPost{},
+	}
+	for i, point := range series.points {
+		analytics.Series[i].AnalyticsPoint = point
+	}
+	for hour, count := range followers {
+		if i := series.index(hour); i >= 0 {
+			analytics.Series[i].NewFollowers += count
+			analytics.NewFollowers += count
+		}
+	}
+
+	top, err := s.analyticsRepo.GetTopPosts(userID, from, to, dashboardTopPosts)
+	if err != nil {
+		return nil, err
+	}
+	postIDs := make([]string, len(top))
+	for i, stat := range top {
+		postIDs[i] = stat.PostID
+	}
+	// Deleted posts are left out
+	posts, err := s.postRepo.GetByIDs(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]model.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+	for _, stat := range top {
+		if post, ok := byID[stat.PostID]; ok {
+			analytics.TopPosts = append(analytics.TopPosts, model.TopPost{Post: post, Impressions: stat.Impressions, Views: stat.Views})
+		}
+	}
+	return analytics, nil
+}
+
+// analyticsSeries is a run of consecutive points of step length starting at from
+type analyticsSeries struct {
+	from   time.Time
+	step   time.Duration
+	points []model.AnalyticsPoint
+}
+
+func newAnalyticsSeries(from, to time.Time, step time.Duration) *analyticsSeries {
+	series := &analyticsSeries{from: from, step: step}
+	for t := from; t.Before(to); t = t.Add(step) {
+		series.points = append(series.points, model.AnalyticsPoint{Time: t})
+	}
+	return series
+}
+
+// index returns the point t falls in, or -1
+func (s *analyticsSeries) index(t time.Time) int {
+	if t.Before(s.from) {
+		return -1
+	}
+	i := int(t.Sub(s.from) / s.step)
+	if i >= len(s.points) {
+		return -1
+	}
+	return i
+}
+
+func (s *analyticsSeries) addViewStats(stats []model.PostViewStat) {
+	for _, stat := range stats {
+		i := s.index(stat.Hour)
+		if i < 0 {
+			continue
+		}
+		point := &s.points[i]
+		point.Impressions += stat.Impressions
+		point.FollowerImpressions += stat.FollowerImpressions
+		point.NonFollowerImpressions += stat.Impressions - stat.FollowerImpressions
+		point.Views += stat.Views
+		point.FollowerViews += stat.FollowerViews
+		point.NonFollowerViews += stat.Views - stat.FollowerViews
+	}
+}
+
+func (s *analyticsSeries) addCounts(counts map[time.Time]int, add func(*model.AnalyticsPoint, int)) {
+	for hour, count := range counts {
+		if i := s.index(hour); i >= 0 {
+			add(&s.points[i], count)
+		}
+	}
+}
+
+func (s *analyticsSeries) totals() model.AnalyticsTotals {
+	var totals model.AnalyticsTotals
+	for _, point := range s.points {
+		totals.Add(point.AnalyticsTotals)
+	}
+	return totals
+}
+
+// ComputeFollowerStats sums every finished day (UTC) not computed yet into the daily follower
+// stats, going back at most followerStatsCatchUpDays. Follower counts are those at the time
+// it runs, so it should run shortly after midnight. Returns the number of days computed.
+func (s *AnalyticsService) ComputeFollowerStats() (int, error) {
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	day := yesterday.AddDate(0, 0, 1-followerStatsCatchUpDays)
+	latest, err := s.analyticsRepo.GetLatestFollowerStatsDay()
+	if err != nil {
+		return 0, err
+	}
+	if latest != "" {
+		if t, err := time.Parse(analyticsDateLayout, latest); err == nil && !t.Before(day) {
+			day = t.AddDate(0, 0, 1)
+		}
+	} else {
+		day = yesterday
+	}
+
+	computed := 0
+	for ; !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+		if err := s.analyticsRepo.ComputeFollowerDay(day.Format(analyticsDateLayout), day, day.AddDate(0, 0, 1)); err != nil {
+			return computed, err
+		}
+		computed++
+	}
+	return computed, nil
+}
+
+// GetFollowerAnalytics returns the follower growth of a user from the nightly stats, by day
+// up to yesterday (UTC)
+func (s *AnalyticsService) GetFollowerAnalytics(userID string, filter *model.FollowerAnalyticsFilter) (*model.FollowerAnalytics, error) {
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	to := yesterday
+	if filter.To != "" {
+		t, err := time.Parse(analyticsDateLayout, filter.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid analytics range: to must be YYYY-MM-DD")
+		}
+		// Today is not computed yet
+		if t.Before(yesterday) {
+			to = t
+		}
+	}
+	from := to.AddDate(0, 0, 1-defaultFollowerAnalyticsDays)
+	if filter.From != "" {
+		t, err := time.Parse(analyticsDateLayout, filter.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid analytics range: from must be YYYY-MM-DD")
+		}
+		from = t
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("invalid analytics range: from must not be after to")
+	}
+	if days := int(to.Sub(from)/(24*time.Hour)) + 1; days > maxFollowerAnalyticsDays {
+		return nil, fmt.Errorf("invalid analytics range: follower analytics cover at most %d days", maxFollowerAnalyticsDays)
+	}
+
+	fromDay := from.Format(analyticsDateLayout)
+	toDay := to.Format(analyticsDateLayout)
+	// The day before the range gives the number of followers at its start
+	stats, err := s.analyticsRepo.GetFollowerDailyStats(userID, from.AddDate(0, 0, -1).Format(analyticsDateLayout), toDay)
+	if err != nil {
+		return nil, err
+	}
+	byDay := make(map[string]model.FollowerDailyStat, len(stats))
+	for _, stat := range stats {
+		byDay[stat.Day.Format(analyticsDateLayout)] = stat
+	}
+
+	analytics := &model.FollowerAnalytics{
+		From:           fromDay,
+		To:             toDay,
+		FollowersStart: byDay[from.AddDate(0, 0, -1).Format(analyticsDateLayout)].Followers,
+		Series:         []model.FollowerGrowthPoint{},
+	}
+	// A day without stats had no followers and no follow events
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		stat := byDay[day.Format(analyticsDateLayout)]
+		analytics.Series = append(analytics.Series, model.FollowerGrowthPoint{
+			Day:               day.Format(analyticsDateLayout),
+			Follows:           stat.Follows,
+			Unfollows:         stat.Unfollows,
+			Net:               stat.Follows - stat.Unfollows,
+			Followers:         stat.Followers,
+			VerifiedFollowers: stat.VerifiedFollowers,
+		})
+		analytics.Follows += stat.Follows
+		analytics.Unfollows += stat.Unfollows
+	}
+	last := analytics.Series[len(analytics.Series)-1]
+	analytics.FollowersEnd = last.Followers
+	analytics.VerifiedFollowers = last.VerifiedFollowers
+	analytics.NetGrowth = analytics.Follows - analytics.Unfollows
+	analytics.ChurnRate = ratio(analytics.Unfollows, analytics.FollowersStart+analytics.Follows)
+	analytics.VerifiedShare = ratio(analytics.VerifiedFollowers, analytics.FollowersEnd)
+
+	analytics.TopFollowers, err = s.analyticsRepo.GetTopFollowers(userID, fromDay, toDay, topFollowersLimit)
+	if err != nil {
+		return nil, err
+	}
+	return analytics, nil
+}
+
+// ratio returns part/whole rounded to 4 decimals, 0 when whole is 0
+func ratio(part, whole int) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
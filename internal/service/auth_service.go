@@ -4,8 +4,10 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
+	"vietick-backend/internal/config"
 	"vietick-backend/internal/model"
 	"vietick-backend/internal/repository"
 	"vietick-backend/internal/utils"
@@ -19,15 +21,42 @@ type AuthService struct {
 	authRepo    *repository.AuthRepository
 	jwtManager  *jwt.JWTManager
 	emailService *email.EmailService
+	statusCache  *accountStatusCache
+	searchService *SearchService
+	accountConfig *config.AccountConfig
+}
+
+// accountStatusCache keeps recently checked account statuses so that the auth
+// middleware does not hit the database on every request. Each instance has its own
+// cache and only the instance handling a status change drops its entry, so the
+// others pick the change up once their entry expires (ACCOUNT_STATUS_CACHE_SECONDS).
+// Expired entries are swept at most once per ttl.
+type accountStatusCache struct {
+	mu        sync.RWMutex
+	entries   map[string]accountStatusEntry
+	ttl       time.Duration
+	lastSweep time.Time
+}
+
+type accountStatusEntry struct {
+	user      *model.User
+	fetchedAt time.Time
 }
 
 func NewAuthService(userRepo *repository.UserRepository, authRepo *repository.AuthRepository, 
-	jwtManager *jwt.JWTManager, emailService *email.EmailService) *AuthService {
+	jwtManager *jwt.JWTManager, emailService *email.EmailService, searchService *SearchService,
+	accountConfig *config.AccountConfig) *AuthService {
 	return &AuthService{
 		userRepo:    userRepo,
 		authRepo:    authRepo,
 		jwtManager:  jwtManager,
 		emailService: emailService,
+		searchService: searchService,
+		accountConfig: accountConfig,
+		statusCache: &accountStatusCache{
+			entries: make(map[string]accountStatusEntry),
+			ttl:     time.Duration(accountConfig.StatusCacheSeconds) * time.Second,
+		},
 	}
 }
 
@@ -59,6 +88,7 @@ func (s *AuthService) Register(req *model.RegisterRequest) (*model.AuthResponse,
 	user := &model.User{
 		ID:                        uuid.New().String(),
 		Username:                  req.Username,
+		AccountType:               model.AccountTypePersonal,
 		Email:                     req.Email,
 		PasswordHash:              passwordHash,
 		FullName:                  req.FullName,
@@ -66,12 +96,14 @@ func (s *AuthService) Register(req *model.RegisterRequest) (*model.AuthResponse,
 		EmailVerificationToken:    &verificationToken,
 		EmailVerificationExpiresAt: timePtr(time.Now().Add(24 * time.Hour)),
 		IdentityVerificationStatus: model.IdentityVerificationNone,
+		AccountStatus:             model.AccountStatusActive,
 	}
 
 	err = s.userRepo.Create(user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	s.searchService.IndexUser(user)
 
 	// Send verification email
 	err = s.emailService.SendEmailVerification(user.Email, user.FullName, verificationToken)
@@ -118,11 +150,36 @@ func (s *AuthService) Login(req *model.LoginRequest) (*model.AuthResponse, error
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
+	// Organizations have no password, their members act on their behalf
+	if user.IsOrganization() {
+		return nil, fmt.Errorf("invalid email or password")
+	}
+
 	// Check password
 	if !utils.CheckPassword(req.Password, user.PasswordHash) {
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
+	// Logging back in reopens a deactivated account until it is deleted
+	if user.AccountStatus == model.AccountStatusDeactivated {
+		since := time.Now().AddDate(0, 0, -s.accountConfig.ReactivationDays)
+		reactivated, err := s.userRepo.Reactivate(user.ID, since)
+		if err != nil {
+			return nil, err
+		}
+		if reactivated {
+			user.AccountStatus = model.AccountStatusActive
+			user.DeactivatedAt = nil
+			s.InvalidateAccountStatus(user.ID)
+			s.searchService.IndexUser(user)
+		}
+	}
+
+	// Refuse suspended, banned and deleted accounts
+	if err := checkAccountAccess(user); err != nil {
+		return nil, err
+	}
+
 	// Generate tokens
 	accessToken, err := s.jwtManager.GenerateAccessToken(user)
 	if err != nil {
@@ -174,6 +231,10 @@ func (s *AuthService) RefreshToken(req *model.RefreshTokenRequest) (*model.AuthR
 		return nil, fmt.Errorf("user not found")
 	}
 
+	if err := checkAccountAccess(user); err != nil {
+		return nil, err
+	}
+
 	// Generate new tokens
 	accessToken, err := s.jwtManager.GenerateAccessToken(user)
 	if err != nil {
@@ -307,6 +368,82 @@ func (s *AuthService) ValidateAccessToken(tokenString string) (*jwt.Claims, erro
 	return s.jwtManager.ValidateAccessToken(tokenString)
 }
 
+// CheckAccountStatus rejects tokens of suspended or banned users, and tokens issued
+// before the user's watermark (set whenever the account is suspended)
+func (s *AuthService) CheckAccountStatus(userID string, issuedAt time.Time) error {
+	user, err := s.getCachedUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkAccountAccess(user); err != nil {
+		return err
+	}
+
+	// A token issued in the same microsecond as the watermark is revoked too
+	if user.TokensValidAfter != nil && !issuedAt.After(*user.TokensValidAfter) {
+		return fmt.Errorf("invalid token: token has been revoked")
+	}
+
+	return nil
+}
+
+// InvalidateAccountStatus drops the cached status so the next request re-reads it
+func (s *AuthService) InvalidateAccountStatus(userID string) {
+	s.statusCache.mu.Lock()
+	delete(s.statusCache.entries, userID)
+	s.statusCache.mu.Unlock()
+}
+
+func (s *AuthService) getCachedUser(userID string) (*model.User, error) {
+	s.statusCache.mu.RLock()
+	entry, ok := s.statusCache.entries[userID]
+	s.statusCache.mu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < s.statusCache.ttl {
+		return entry.user, nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("unauthorized: user not found")
+	}
+
+	if s.statusCache.ttl <= 0 {
+		return user, nil
+	}
+
+	now := time.Now()
+	s.statusCache.mu.Lock()
+	if now.Sub(s.statusCache.lastSweep) >= s.statusCache.ttl {
+		for id, cached := range s.statusCache.entries {
+			if now.Sub(cached.fetchedAt) >= s.statusCache.ttl {
+				delete(s.statusCache.entries, id)
+			}
+		}
+		s.statusCache.lastSweep = now
+	}
+	s.statusCache.entries[userID] = accountStatusEntry{user: user, fetchedAt: now}
+	s.statusCache.mu.Unlock()
+
+	return user, nil
+}
+
+func checkAccountAccess(user *model.User) error {
+	switch user.EffectiveStatus() {
+	case model.AccountStatusBanned:
+		return fmt.Errorf("access denied: account has been banned")
+	case model.AccountStatusDeactivated, model.AccountStatusDeleted:
+		// Closed accounts look like they no longer exist
+		return fmt.Errorf("unauthorized: user not found")
+	case model.AccountStatusSuspended:
+		if user.SuspendedUntil != nil {
+			return fmt.Errorf("access denied: account suspended until %s", user.SuspendedUntil.UTC().Format(time.RFC3339))
+		}
+		return fmt.Errorf("access denied: account suspended")
+	}
+	return nil
+}
+
 func (s *AuthService) storeRefreshToken(userID string, refreshToken string) error {
 	// Check if user has too many refresh tokens (limit to 5 devices)
 	count, err := s.authRepo.GetUserRefreshTokenCount(userID)
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/utils"
+)
+
+// maxBookmarkCollections is how many collections a user can create
+const maxBookmarkCollections = 100
+
+// BookmarkService lets users save posts privately, optionally in named collections
+type BookmarkService struct {
+	bookmarkRepo       *repository.BookmarkRepository
+	postRepo           *repository.PostRepository
+	mediaService       *MediaService
+	pollService        *PollService
+	linkPreviewService *LinkPreviewService
+}
+
+func NewBookmarkService(bookmarkRepo *repository.BookmarkRepository, postRepo *repository.PostRepository,
+	mediaService *MediaService, pollService *PollService, linkPreviewService *LinkPreviewService) *BookmarkService {
+	return &BookmarkService{
+		bookmarkRepo:       bookmarkRepo,
+		postRepo:           postRepo,
+		mediaService:       mediaService,
+		pollService:        pollService,
+		linkPreviewService: linkPreviewService,
+	}
+}
+
+// BookmarkPost saves a post the user can see. Saving it again moves it to another collection.
+func (s *BookmarkService) BookmarkPost(userID, postID string, req *model.BookmarkPostRequest) (*model.Bookmark, error) {
+	if _, err := s.postRepo.GetByID(postID, &userID); err != nil {
+		return nil, fmt.Errorf("post not found")
+	}
+	if req.CollectionID != nil {
+		if _, err := s.getCollection(userID, *req.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.bookmarkRepo.Save(userID, postID, req.CollectionID); err != nil {
+		return nil, err
+	}
+	return s.bookmarkRepo.Get(userID, postID)
+}
+
+func (s *BookmarkService) RemoveBookmark(userID, postID string) error {
+	return s.bookmarkRepo.Remove(userID, postID)
+}
+
+// GetBookmarks lists the user's bookmarks, newest first, with the saved posts
+func (s *BookmarkService) GetBookmarks(userID string, filter *model.BookmarksFilter, pagination *utils.PaginationParams) (*model.BookmarksResponse, error) {
+	if filter.CollectionID != nil {
+		if _, err := s.getCollection(userID, *filter.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+	paginationResult := pagination.Calculate()
+
+	bookmarks, totalCount, err := s.bookmarkRepo.GetByUserID(userID, filter.CollectionID, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+
+	postIDs := make([]string, len(bookmarks))
+	for i := range bookmarks {
+		postIDs[i] = bookmarks[i].PostID
+	}
+	posts, err := s.postRepo.GetByIDs(postIDs)
+	if err != nil {
+		return nil, err
+	}
+	s.mediaService.PopulatePosts(posts)
+	s.pollService.PopulatePosts(posts, &userID)
+	s.linkPreviewService.PopulatePosts(posts)
+	byID := make(map[string]*model.Post, len(posts))
+	for i := range posts {
+		posts[i].IsBookmarked = true
+		byID[posts[i].ID] = &posts[i]
+	}
+	for i := range bookmarks {
+		bookmarks[i].Post = byID[bookmarks[i].PostID]
+	}
+
+	return &model.BookmarksResponse{
+		Bookmarks:  bookmarks,
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+func (s *BookmarkService) GetCollections(userID string) ([]model.BookmarkCollection, error) {
+	return s.bookmarkRepo.GetCollections(userID)
+}
+
+func (s *BookmarkService) CreateCollection(userID string, req *model.SaveBookmarkCollectionRequest) (*model.BookmarkCollection, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("invalid request: collection name is required")
+	}
+	count, err := s.bookmarkRepo.CountCollections(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxBookmarkCollections {
+		return nil, fmt.Errorf("invalid request: you can have at most %d collections", maxBookmarkCollections)
+	}
+	if err := s.checkNameFree(userID, name, ""); err != nil {
+		return nil, err
+	}
+
+	collection := &model.BookmarkCollection{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Name:   name,
+	}
+	if err := s.bookmarkRepo.CreateCollection(collection); err != nil {
+		return nil, err
+	}
+	return s.bookmarkRepo.GetCollection(collection.ID)
+}
+
+func (s *BookmarkService) RenameCollection(userID, collectionID string, req *model.SaveBookmarkCollectionRequest) (*model.BookmarkCollection, error) {
+	if _, err := s.getCollection(userID, collectionID); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("invalid request: collection name is required")
+	}
+	if err := s.checkNameFree(userID, name, collectionID); err != nil {
+		return nil, err
+	}
+
+	if err := s.bookmarkRepo.RenameCollection(collectionID, name); err != nil {
+		return nil, err
+	}
+	return s.bookmarkRepo.GetCollection(collectionID)
+}
+
+// DeleteCollection deletes a collection and keeps its bookmarks as unsorted
+func (s *BookmarkService) DeleteCollection(userID, collectionID string) error {
+	if _, err := s.getCollection(userID, collectionID); err != nil {
+		return err
+	}
+	return s.bookmarkRepo.DeleteCollection(collectionID)
+}
+
+func (s *BookmarkService) checkNameFree(userID, name, excludeID string) error {
+	taken, err := s.bookmarkRepo.CollectionNameTaken(userID, name, excludeID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("collection already exists: %s", name)
+	}
+	return nil
+}
+
+// getCollection returns a collection of the user; other users' collections are not found
+func (s *BookmarkService) getCollection(userID, collectionID string) (*model.BookmarkCollection, error) {
+	collection, err := s.bookmarkRepo.GetCollection(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if collection.UserID != userID {
+		return nil, fmt.Errorf("collection not found")
+	}
+	return collection, nil
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"fmt"
+	"time"
 
+	"vietick-backend/internal/config"
 	"vietick-backend/internal/model"
 	"vietick-backend/internal/repository"
 	"vietick-backend/internal/utils"
@@ -10,27 +12,44 @@ import (
 )
 
 type CommentService struct {
-	commentRepo *repository.CommentRepository
+	commentRepo   *repository.CommentRepository
+	contentPolicy *ContentPolicyService
+	editConfig    *config.EditConfig
+	retention     *config.RetentionConfig
 }
 
-func NewCommentService(commentRepo *repository.CommentRepository) *CommentService {
+func NewCommentService(commentRepo *repository.CommentRepository, contentPolicy *ContentPolicyService,
+	editConfig *config.EditConfig, retention *config.RetentionConfig) *CommentService {
 	return &CommentService{
-		commentRepo: commentRepo,
+		commentRepo:   commentRepo,
+		contentPolicy: contentPolicy,
+		editConfig:    editConfig,
+		retention:     retention,
 	}
 }
 
-func (s *CommentService) CreateComment(userID, postID string, req *model.CreateCommentRequest) (*model.Comment, error) {
+// CreateComment comments as userID; actingUserID is the member writing on behalf of an
+// organization (nil otherwise)
+func (s *CommentService) CreateComment(userID, postID string, actingUserID *string, req *model.CreateCommentRequest) (*model.Comment, error) {
+	decision, err := s.contentPolicy.Check(userID, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
 	comment := &model.Comment{
 		ID: uuid.New().String(),
 		PostID: postID,
 		UserID: userID,
+		ActingUserID: actingUserID,
 		Content: req.Content,
+		IsHidden: decision != nil,
 	}
 
-	err := s.commentRepo.Create(comment)
+	err = s.commentRepo.Create(comment)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create comment: %w", err)
 	}
+	s.contentPolicy.AfterSave(model.ReportTargetComment, comment.ID, userID, req.Content, decision)
 
 	// Get the complete comment with user information
 	return s.commentRepo.GetByID(comment.ID, &userID)
@@ -52,6 +71,7 @@ func (s *CommentService) GetPostComments(postID string, userID *string, paginati
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comments: %w", err)
 	}
+	s.populateReactions(comments, userID)
 
 	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
 
@@ -74,24 +94,78 @@ func (s *CommentService) UpdateComment(commentID, userID string, req *model.Crea
 	if existingComment.UserID != userID {
 		return nil, fmt.Errorf("you can only edit your own comments")
 	}
+	window := s.editConfig.CommentWindowMinutes
+	if window > 0 && time.Since(existingComment.CreatedAt) > time.Duration(window)*time.Minute {
+		return nil, fmt.Errorf("forbidden: comments can only be edited within %d minutes of posting", window)
+	}
+	if existingComment.Content == req.Content {
+		return existingComment, nil
+	}
 
+	decision, err := s.contentPolicy.Check(userID, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
 	comment := &model.Comment{
-		ID:      commentID,
-		UserID:  userID,
-		Content: req.Content,
+		ID:       commentID,
+		UserID:   userID,
+		Content:  req.Content,
+		IsHidden: existingComment.IsHidden || decision != nil,
+		EditedAt: &now,
+	}
+	revision := &model.CommentRevision{
+		ID:        uuid.New().String(),
+		CommentID: commentID,
+		Revision:  existingComment.RevisionCount,
+		Content:   existingComment.Content,
+		CreatedAt: versionTime(existingComment.CreatedAt, existingComment.EditedAt),
 	}
 
-	err = s.commentRepo.Update(comment)
+	err = s.commentRepo.UpdateWithRevision(comment, revision)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update comment: %w", err)
+		return nil, err
 	}
+	s.contentPolicy.AfterSave(model.ReportTargetComment, commentID, userID, req.Content, decision)
 
 	// Get the updated comment
 	return s.commentRepo.GetByID(commentID, &userID)
 }
 
-func (s *CommentService) DeleteComment(commentID, userID string) error {
-	err := s.commentRepo.Delete(commentID, userID)
+// GetCommentHistory trả về mọi phiên bản của bình luận, phiên bản hiện tại trước
+func (s *CommentService) GetCommentHistory(commentID string, viewerID *string) (*model.CommentHistoryResponse, error) {
+	comment, err := s.commentRepo.GetByID(commentID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("comment not found")
+	}
+	previous, err := s.commentRepo.GetRevisions(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := append([]model.CommentRevision{{
+		CommentID: commentID,
+		Revision:  comment.RevisionCount,
+		Content:   comment.Content,
+		CreatedAt: versionTime(comment.CreatedAt, comment.EditedAt),
+	}}, previous...)
+
+	return &model.CommentHistoryResponse{
+		CommentID:     commentID,
+		RevisionCount: comment.RevisionCount,
+		EditedAt:      comment.EditedAt,
+		Revisions:     revisions,
+	}, nil
+}
+
+// DeleteComment xoá mềm bình luận; actingUserID là thành viên xoá thay cho tổ chức
+func (s *CommentService) DeleteComment(commentID, userID string, actingUserID *string) error {
+	deletedBy := userID
+	if actingUserID != nil {
+		deletedBy = *actingUserID
+	}
+	err := s.commentRepo.Delete(commentID, userID, deletedBy)
 	if err != nil {
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
@@ -99,6 +173,59 @@ func (s *CommentService) DeleteComment(commentID, userID string) error {
 	return nil
 }
 
+// GetDeletedComments lists the comments the user deleted and can still restore
+func (s *CommentService) GetDeletedComments(userID string, pagination *utils.PaginationParams) (*model.DeletedCommentsResponse, error) {
+	paginationResult := pagination.Calculate()
+	source := model.DeletionByAuthor
+	since := time.Now().Add(-restoreWindow(s.retention))
+
+	comments, totalCount, err := s.commentRepo.GetDeleted(&userID, &source, &since, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeletedCommentsResponse{
+		Comments:   deletedComments(comments, s.retention),
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+// RestoreComment brings back a comment its author deleted, as long as its post still exists
+func (s *CommentService) RestoreComment(commentID, userID string) (*model.Comment, error) {
+	deleted, err := s.commentRepo.GetDeletedByID(commentID)
+	if err != nil || deleted.UserID != userID {
+		return nil, fmt.Errorf("deleted comment not found")
+	}
+	if deleted.DeletionSource != nil && *deleted.DeletionSource == model.DeletionByModeration {
+		return nil, fmt.Errorf("forbidden: the comment was removed by a moderator")
+	}
+	since := time.Now().Add(-restoreWindow(s.retention))
+	if deleted.DeletedAt.Time.Before(since) {
+		return nil, fmt.Errorf("invalid request: comments can only be restored within %d days of deletion", s.retention.RestoreWindowDays)
+	}
+
+	if err := s.commentRepo.Restore(commentID, userID, since); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.GetByID(commentID, &userID)
+}
+
+// PurgeDeleted xoá hẳn bình luận đã hết thời hạn lưu giữ
+func (s *CommentService) PurgeDeleted() (int, error) {
+	before := time.Now().Add(-retentionPeriod(s.retention))
+	purged := 0
+	for {
+		n, err := s.commentRepo.PurgeDeleted(before, purgeBatchSize)
+		purged += n
+		if err != nil || n < purgeBatchSize {
+			return purged, err
+		}
+	}
+}
+
 func (s *CommentService) LikeComment(commentID, userID string) error {
 	err := s.commentRepo.LikeComment(commentID, userID)
 	if err != nil {
@@ -143,6 +270,80 @@ func (s *CommentService) IsCommentLikedByUser(commentID, userID string) (bool, e
 	return s.commentRepo.IsCommentLikedByUser(commentID, userID)
 }
 
+// React sets the user's reaction to a comment they can see, replacing their previous reaction
+func (s *CommentService) React(commentID, userID string, req *model.ReactRequest) (*model.ReactionResponse, error) {
+	if _, err := s.commentRepo.GetByID(commentID, &userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.commentRepo.React(commentID, userID, req.Reaction); err != nil {
+		return nil, err
+	}
+	return s.reactionResponse(commentID, userID)
+}
+
+func (s *CommentService) RemoveReaction(commentID, userID string) (*model.ReactionResponse, error) {
+	if err := s.commentRepo.Unreact(commentID, userID, nil); err != nil {
+		return nil, err
+	}
+	return s.reactionResponse(commentID, userID)
+}
+
+func (s *CommentService) reactionResponse(commentID, userID string) (*model.ReactionResponse, error) {
+	comment, err := s.commentRepo.GetByID(commentID, &userID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ReactionResponse{
+		Reaction:       comment.Reaction,
+		LikeCount:      comment.LikeCount,
+		ReactionCounts: comment.ReactionCounts,
+	}, nil
+}
+
+// GetReactions lists who reacted to a comment, newest first
+func (s *CommentService) GetReactions(commentID string, viewerID *string, filter *model.ReactionsFilter, pagination *utils.PaginationParams) (*model.ReactionsResponse, error) {
+	comment, err := s.commentRepo.GetByID(commentID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	paginationResult := pagination.Calculate()
+
+	reactions, totalCount, err := s.commentRepo.GetReactions(commentID, filter.Type, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ReactionsResponse{
+		Reactions:      reactions,
+		ReactionCounts: comment.ReactionCounts,
+		TotalCount:     totalCount,
+		Page:           paginationResult.Page,
+		PageSize:       paginationResult.PageSize,
+		HasMore:        utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+// populateReactions sets the viewer's own reaction on each comment
+func (s *CommentService) populateReactions(comments []model.Comment, viewerID *string) {
+	if viewerID == nil || len(comments) == 0 {
+		return
+	}
+	commentIDs := make([]string, len(comments))
+	for i := range comments {
+		commentIDs[i] = comments[i].ID
+	}
+	reactions, err := s.commentRepo.GetViewerReactions(*viewerID, commentIDs)
+	if err != nil {
+		return
+	}
+	for i := range comments {
+		if reaction, ok := reactions[comments[i].ID]; ok {
+			comments[i].Reaction = &reaction
+			comments[i].IsLiked = reaction == model.ReactionLike
+		}
+	}
+}
+
 func (s *CommentService) GetCommentStats(commentID string) (map[string]interface{}, error) {
 	comment, err := s.commentRepo.GetByID(commentID, nil)
 	if err != nil {
@@ -150,9 +351,11 @@ func (s *CommentService) GetCommentStats(commentID string) (map[string]interface
 	}
 
 	stats := map[string]interface{}{
-		"like_count": comment.LikeCount,
-		"created_at": comment.CreatedAt,
-		"updated_at": comment.UpdatedAt,
+		"like_count":      comment.LikeCount,
+		"reaction_counts": comment.ReactionCounts,
+		"reaction_count":  comment.ReactionCounts.Total(),
+		"created_at":      comment.CreatedAt,
+		"updated_at":      comment.UpdatedAt,
 	}
 
 	return stats, nil
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/contentpolicy"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+)
+
+// ContentPolicyService runs posts and comments through the admin-managed rules before they are saved.
+// The compiled pipeline is cached in memory and rebuilt whenever a rule changes (and periodically,
+// so that every instance picks up edits made through another one).
+type ContentPolicyService struct {
+	ruleRepo   *repository.ContentRuleRepository
+	reportRepo *repository.ReportRepository
+	history    *contentpolicy.History
+
+	mu       sync.RWMutex
+	pipeline *contentpolicy.Pipeline
+}
+
+func NewContentPolicyService(ruleRepo *repository.ContentRuleRepository, reportRepo *repository.ReportRepository) *ContentPolicyService {
+	history := contentpolicy.NewHistory(ruleRepo)
+	s := &ContentPolicyService{
+		ruleRepo:   ruleRepo,
+		reportRepo: reportRepo,
+		history:    history,
+	}
+	s.pipeline, _ = contentpolicy.NewPipeline(nil, history)
+	if err := s.Reload(); err != nil {
+		fmt.Printf("Failed to load content rules: %v\n", err)
+	}
+	return s
+}
+
+// Reload rebuilds the cached pipeline from the database
+func (s *ContentPolicyService) Reload() error {
+	rules, err := s.ruleRepo.GetActive()
+	if err != nil {
+		return err
+	}
+
+	pipeline, errs := contentpolicy.NewPipeline(rules, s.history)
+	for _, err := range errs {
+		fmt.Printf("Skipping invalid content rule: %v\n", err)
+	}
+
+	s.mu.Lock()
+	s.pipeline = pipeline
+	s.mu.Unlock()
+	s.history.Prune()
+	return nil
+}
+
+func (s *ContentPolicyService) getPipeline() *contentpolicy.Pipeline {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pipeline
+}
+
+// Check evaluates content before it is persisted. Rejected content returns an error;
+// otherwise the decision (nil when nothing matched) tells the caller whether to hide or hold it.
+func (s *ContentPolicyService) Check(authorID, text string) (*model.ContentDecision, error) {
+	decision := s.getPipeline().Evaluate(authorID, text)
+	if decision != nil && decision.Action == model.ContentRuleReject {
+		return nil, fmt.Errorf("invalid content: rejected by content policy (%s)", decision.Reason)
+	}
+	return decision, nil
+}
+
+// AfterSave remembers saved content for the repeated-content heuristic and queues held content for review
+func (s *ContentPolicyService) AfterSave(targetType model.ReportTarget, targetID, authorID, text string, decision *model.ContentDecision) {
+	s.getPipeline().Record(authorID, text)
+	if decision != nil && decision.Action == model.ContentRuleHold {
+		s.holdForReview(targetType, targetID, authorID, decision)
+	}
+}
+
+// holdForReview files a system report so held content shows up in the moderation queue
+func (s *ContentPolicyService) holdForReview(targetType model.ReportTarget, targetID, ownerID string, decision *model.ContentDecision) {
+	details := fmt.Sprintf("Held automatically by content rule %s (%s): %s", decision.RuleID, decision.RuleType, decision.Reason)
+	report := &model.Report{
+		ID:          uuid.New().String(),
+		Source:      model.ReportSourceAuto,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		TargetOwner: &ownerID,
+		Reason:      model.ReportReasonSpam,
+		Details:     &details,
+		Status:      model.ReportStatusPending,
+		CreatedAt:   time.Now(),
+	}
+	if decision.RuleType == model.ContentRuleKeyword || decision.RuleType == model.ContentRuleRegex {
+		report.Reason = model.ReportReasonOther
+	}
+	if err := s.reportRepo.Create(report); err != nil {
+		fmt.Printf("Failed to queue held content for review: %v\n", err)
+	}
+}
+
+// TestContent shows what the current rules would do with the given text, without recording it
+func (s *ContentPolicyService) TestContent(authorID, text string) *model.ContentDecision {
+	return s.getPipeline().Evaluate(authorID, text)
+}
+
+func (s *ContentPolicyService) GetRules() ([]model.ContentRule, error) {
+	rules, err := s.ruleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []model.ContentRule{}
+	}
+	return rules, nil
+}
+
+func (s *ContentPolicyService) GetRule(ruleID string) (*model.ContentRule, error) {
+	return s.ruleRepo.GetByID(ruleID)
+}
+
+func (s *ContentPolicyService) CreateRule(adminID string, req *model.CreateContentRuleRequest) (*model.ContentRule, error) {
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	rule := &model.ContentRule{
+		ID:            uuid.New().String(),
+		Type:          req.Type,
+		Pattern:       req.Pattern,
+		Threshold:     req.Threshold,
+		WindowSeconds: req.WindowSeconds,
+		Action:        req.Action,
+		Description:   req.Description,
+		IsActive:      isActive,
+		CreatedBy:     adminID,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if _, err := contentpolicy.Compile(rule, s.history); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+
+	s.reloadAfterChange()
+	return rule, nil
+}
+
+func (s *ContentPolicyService) UpdateRule(ruleID string, req *model.UpdateContentRuleRequest) (*model.ContentRule, error) {
+	rule, err := s.ruleRepo.GetByID(ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Pattern != nil {
+		rule.Pattern = *req.Pattern
+	}
+	if req.Threshold != nil {
+		rule.Threshold = req.Threshold
+	}
+	if req.WindowSeconds != nil {
+		rule.WindowSeconds = req.WindowSeconds
+	}
+	if req.Action != nil {
+		rule.Action = *req.Action
+	}
+	if req.Description != nil {
+		rule.Description = req.Description
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	rule.UpdatedAt = time.Now()
+
+	if _, err := contentpolicy.Compile(rule, s.history); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+
+	s.reloadAfterChange()
+	return rule, nil
+}
+
+func (s *ContentPolicyService) DeleteRule(ruleID string) error {
+	if err := s.ruleRepo.Delete(ruleID); err != nil {
+		return err
+	}
+
+	s.reloadAfterChange()
+	return nil
+}
+
+func (s *ContentPolicyService) reloadAfterChange() {
+	if err := s.Reload(); err != nil {
+		fmt.Printf("Failed to reload content rules: %v\n", err)
+	}
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// A publisher that holds a draft longer than this is assumed dead and the draft is
+	// picked up again
+	draftPublishLease = 2 * time.Minute
+	// Scheduled drafts are tried this many times before they are marked failed
+	maxDraftPublishAttempts = 3
+	// How far ahead a post can be scheduled
+	maxScheduleAhead = 365 * 24 * time.Hour
+	draftBatchSize   = 100
+)
+
+type DraftService struct {
+	draftRepo           *repository.DraftRepository
+	userRepo            *repository.UserRepository
+	organizationRepo    *repository.OrganizationRepository
+	postService         *PostService
+	contentPolicy       *ContentPolicyService
+	mediaService        *MediaService
+	notificationService *NotificationService
+}
+
+func NewDraftService(draftRepo *repository.DraftRepository, userRepo *repository.UserRepository,
+	organizationRepo *repository.OrganizationRepository, postService *PostService, contentPolicy *ContentPolicyService,
+	mediaService *MediaService, notificationService *NotificationService) *DraftService {
+	return &DraftService{
+		draftRepo:           draftRepo,
+		userRepo:            userRepo,
+		organizationRepo:    organizationRepo,
+		postService:         postService,
+		contentPolicy:       contentPolicy,
+		mediaService:        mediaService,
+		notificationService: notificationService,
+	}
+}
+
+// CreateDraft saves a draft, scheduled when PublishAt is set
+func (s *DraftService) CreateDraft(userID string, actingUserID *string, req *model.SaveDraftRequest) (*model.PostDraft, error) {
+	draft := &model.PostDraft{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		ActingUserID: actingUserID,
+		Status:       model.PostDraftDraft,
+	}
+	if err := s.apply(draft, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Create(draft); err != nil {
+		return nil, err
+	}
+	return s.GetDraft(draft.ID, userID)
+}
+
+func (s *DraftService) GetDraft(draftID, userID string) (*model.PostDraft, error) {
+	draft, err := s.draftRepo.GetByID(draftID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.UserID != userID {
+		return nil, fmt.Errorf("draft not found")
+	}
+	s.mediaService.PopulateDraft(draft)
+	return draft, nil
+}
+
+func (s *DraftService) GetDrafts(userID string, filter *model.DraftsFilter, pagination *utils.PaginationParams) (*model.PostDraftsResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	drafts, totalCount, err := s.draftRepo.GetByUserID(userID, filter.Status, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+	for i := range drafts {
+		s.mediaService.PopulateDraft(&drafts[i])
+	}
+
+	return &model.PostDraftsResponse{
+		Drafts:     drafts,
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+// UpdateDraft replaces the content of a draft and schedules or unschedules it
+func (s *DraftService) UpdateDraft(draftID, userID string, actingUserID *string, req *model.SaveDraftRequest) (*model.PostDraft, error) {
+	draft, err := s.getEditable(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+	draft.ActingUserID = actingUserID
+	if err := s.apply(draft, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Update(draft); err != nil {
+		return nil, err
+	}
+	return s.GetDraft(draftID, userID)
+}
+
+// ScheduleDraft schedules a draft or moves a scheduled (or failed) one to a new time
+func (s *DraftService) ScheduleDraft(draftID, userID string, req *model.ScheduleDraftRequest) (*model.PostDraft, error) {
+	draft, err := s.getEditable(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.schedule(draft, &req.PublishAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Update(draft); err != nil {
+		return nil, err
+	}
+	return s.GetDraft(draftID, userID)
+}
+
+// CancelSchedule turns a scheduled draft back into a plain draft
+func (s *DraftService) CancelSchedule(draftID, userID string) (*model.PostDraft, error) {
+	draft, err := s.getEditable(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status == model.PostDraftDraft {
+		return nil, fmt.Errorf("invalid request: the draft is not scheduled")
+	}
+	if err := s.schedule(draft, nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Update(draft); err != nil {
+		return nil, err
+	}
+	return s.GetDraft(draftID, userID)
+}
+
+func (s *DraftService) DeleteDraft(draftID, userID string) error {
+	if _, err := s.getEditable(draftID, userID); err != nil {
+		return err
+	}
+	return s.draftRepo.Delete(draftID)
+}
+
+// PublishDraft publishes a draft right away. On failure the draft is left as it was.
+func (s *DraftService) PublishDraft(draftID, userID string) (*model.Post, error) {
+	draft, err := s.getEditable(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token := uuid.New().String()
+	claimed, err := s.draftRepo.Claim(draftID, userID, token, time.Now().Add(draftPublishLease))
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("invalid request: the draft is being published")
+	}
+
+	post, err := s.publish(draft, token)
+	if err != nil {
+		message := err.Error()
+		if releaseErr := s.draftRepo.Release(draftID, token, draft.Status, draft.PublishAttempts, &message); releaseErr != nil {
+			log.Printf("Failed to release draft %s: %v", draftID, releaseErr)
+		}
+		return nil, err
+	}
+	return post, nil
+}
+
+// PublishDue publishes every scheduled draft whose time has come, including those missed
+// while no instance was running. Several instances can run it at the same time: each
+// draft is locked by one of them and published at most once.
+func (s *DraftService) PublishDue() (int, error) {
+	published := 0
+	// Drafts that failed go back to the queue; they are retried on the next run
+	seen := make(map[string]bool)
+	for {
+		ids, err := s.draftRepo.GetDueIDs(time.Now(), draftBatchSize)
+		if err != nil {
+			return published, err
+		}
+
+		fresh := 0
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			fresh++
+
+			token := uuid.New().String()
+			now := time.Now()
+			claimed, err := s.draftRepo.ClaimDue(id, token, now, now.Add(draftPublishLease))
+			if err != nil {
+				return published, err
+			}
+			if !claimed {
+				continue
+			}
+			if s.publishScheduled(id, token) {
+				published++
+			}
+		}
+
+		if len(ids) < draftBatchSize || fresh == 0 {
+			return published, nil
+		}
+	}
+}
+
+// publishScheduled publishes a draft locked by the scheduler, retrying later on failure
+func (s *DraftService) publishScheduled(draftID, token string) bool {
+	draft, err := s.draftRepo.GetByID(draftID)
+	if err != nil {
+		log.Printf("Failed to load scheduled draft %s: %v", draftID, err)
+		return false
+	}
+
+	post, err := s.publish(draft, token)
+	if err == nil {
+		s.notificationService.Notify(draft.UserID, model.NotificationPostPublished,
+			"Scheduled post published", "Your scheduled post has been published.", &post.ID)
+		return true
+	}
+
+	attempts := draft.PublishAttempts + 1
+	message := err.Error()
+	if len(message) > 500 {
+		message = message[:500]
+	}
+	status := model.PostDraftScheduled
+	if attempts >= maxDraftPublishAttempts {
+		status = model.PostDraftFailed
+		log.Printf("Scheduled draft %s failed after %d attempts: %v", draftID, attempts, err)
+		s.notificationService.Notify(draft.UserID, model.NotificationPostPublishFailed,
+			"Scheduled post not published", "Your scheduled post could not be published: "+message, &draft.ID)
+	}
+	if err := s.draftRepo.Release(draftID, token, status, attempts, &message); err != nil {
+		log.Printf("Failed to release draft %s: %v", draftID, err)
+	}
+	return false
+}
+
+// publish turns a locked draft into a post with the draft's ID and runs the usual side
+// effects of a new post
+func (s *DraftService) publish(draft *model.PostDraft, token string) (*model.Post, error) {
+	// The author may have been suspended, or the member removed from the organization,
+	// since the post was scheduled
+	author, err := s.userRepo.GetByID(draft.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err := checkAccountAccess(author); err != nil {
+		return nil, err
+	}
+	if draft.ActingUserID != nil {
+		isMember, err := s.organizationRepo.IsMember(draft.UserID, *draft.ActingUserID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, fmt.Errorf("forbidden: the author is no longer a member of the organization")
+		}
+	}
+
+	post := &model.Post{
+		ID:           draft.ID,
+		UserID:       draft.UserID,
+		ActingUserID: draft.ActingUserID,
+		Content:      draft.Content,
+		MediaIDs:     draft.MediaIDs,
+	}
+	decision, err := s.postService.preparePost(post)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.draftRepo.Publish(draft.ID, token, post); err != nil {
+		return nil, err
+	}
+	return s.postService.afterCreate(post, decision)
+}
+
+// apply copies the request onto the draft after the checks a post gets when it is created
+func (s *DraftService) apply(draft *model.PostDraft, req *model.SaveDraftRequest) error {
+	// Content the rules reject outright is refused now rather than at publication
+	if _, err := s.contentPolicy.Check(draft.UserID, req.Content); err != nil {
+		return err
+	}
+	if _, err := s.mediaService.Attach(draft.UserID, model.MediaPurposePost, req.MediaIDs); err != nil {
+		return err
+	}
+
+	draft.Content = req.Content
+	draft.MediaIDs = model.ImageURLs(req.MediaIDs)
+	return s.schedule(draft, req.PublishAt)
+}
+
+// schedule sets the publication time of a draft, or unschedules it when publishAt is nil
+func (s *DraftService) schedule(draft *model.PostDraft, publishAt *time.Time) error {
+	draft.PublishAttempts = 0
+	draft.LastError = nil
+	if publishAt == nil {
+		draft.Status = model.PostDraftDraft
+		draft.PublishAt = nil
+		return nil
+	}
+
+	now := time.Now()
+	if !publishAt.After(now) {
+		return fmt.Errorf("invalid request: publish_at must be in the future")
+	}
+	if publishAt.After(now.Add(maxScheduleAhead)) {
+		return fmt.Errorf("invalid request: posts can be scheduled at most %d days ahead", int(maxScheduleAhead.Hours()/24))
+	}
+	at := publishAt.UTC()
+	draft.Status = model.PostDraftScheduled
+	draft.PublishAt = &at
+	return nil
+}
+
+func (s *DraftService) getEditable(draftID, userID string) (*model.PostDraft, error) {
+	draft, err := s.draftRepo.GetByID(draftID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.UserID != userID {
+		return nil, fmt.Errorf("draft not found")
+	}
+	if !draft.IsEditable() {
+		if draft.Status == model.PostDraftPublished {
+			return nil, fmt.Errorf("invalid request: the draft has already been published")
+		}
+		return nil, fmt.Errorf("invalid request: the draft is being published")
+	}
+	return draft, nil
+}
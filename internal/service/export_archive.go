@@ -0,0 +1,660 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/utils"
+)
+
+// Sections are saved under exports/<id>/parts/ until the archive is assembled
+func exportPartKey(exportID, file string) string {
+	return fmt.Sprintf("exports/%s/parts/%s", exportID, file)
+}
+
+func exportArchiveKey(exportID string) string {
+	return fmt.Sprintf("exports/%s/archive.zip", exportID)
+}
+
+// exportMediaDir is the folder of uploaded files inside the archive
+const exportMediaDir = "media/"
+
+// exportPage is the HTML rendering of a section: one or more tables
+type exportPage struct {
+	Title  string
+	Tables []exportTable
+}
+
+type exportTable struct {
+	Caption string
+	Columns []string
+	Rows    [][]exportCell
+}
+
+type exportCell struct {
+	Text  string
+	Links []exportLink
+}
+
+type exportLink struct {
+	Href string
+	Text string
+}
+
+func textCell(text string) exportCell {
+	return exportCell{Text: text}
+}
+
+func timeCell(t *time.Time) exportCell {
+	if t == nil {
+		return exportCell{}
+	}
+	return exportCell{Text: t.UTC().Format("2006-01-02 15:04:05")}
+}
+
+func fileLinks(files []string) exportCell {
+	cell := exportCell{}
+	for _, file := range files {
+		cell.Links = append(cell.Links, exportLink{Href: file, Text: file})
+	}
+	return cell
+}
+
+// exportProfile is profile.json: the account, with the archive path of the avatar
+type exportProfile struct {
+	*model.User
+	AvatarFile string `json:"avatar_file,omitempty"`
+}
+
+// exportPost is an entry of posts.json, with the archive paths of its images
+type exportPost struct {
+	model.Post
+	Files []string `json:"files"`
+}
+
+// exportLikes is likes.json, with every reaction and not only likes
+type exportLikes struct {
+	Posts    []model.PostReaction    `json:"posts"`
+	Comments []model.CommentReaction `json:"comments"`
+}
+
+// exportVote is an entry of votes.json, with the text of the chosen option
+type exportVote struct {
+	model.PollVote
+	Option string `json:"option"`
+}
+
+// exportSession is an entry of sessions.json. Token hashes are never exported.
+type exportSession struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// exportMediaFile is an entry of media.json
+type exportMediaFile struct {
+	ID          string             `json:"id"`
+	File        string             `json:"file"`
+	Purpose     model.MediaPurpose `json:"purpose"`
+	ContentType string             `json:"content_type"`
+	Size        int64              `json:"size"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// exportManifest is manifest.json, written last and listing every other file
+type exportManifest struct {
+	FormatVersion int                  `json:"format_version"`
+	ExportID      string               `json:"export_id"`
+	UserID        string               `json:"user_id"`
+	Username      string               `json:"username"`
+	RequestedAt   time.Time            `json:"requested_at"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+	Sections      []string             `json:"sections"`
+	Files         []exportManifestFile `json:"files"`
+}
+
+type exportManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// writeSection reads one section of the user's data and saves it as JSON and HTML parts
+func (s *ExportService) writeSection(user *model.User, export *model.DataExport, section string) error {
+	var data interface{}
+	var page exportPage
+	var err error
+	switch section {
+	case model.DataExportSectionProfile:
+		data, page, err = s.profileSection(user)
+	case model.DataExportSectionPosts:
+		data, page, err = s.postsSection(user.ID)
+	case model.DataExportSectionComments:
+		data, page, err = s.commentsSection(user.ID)
+	case model.DataExportSectionLikes:
+		data, page, err = s.likesSection(user.ID)
+	case model.DataExportSectionVotes:
+		data, page, err = s.votesSection(user.ID)
+	case model.DataExportSectionFollowers:
+		data, page, err = s.followsSection(user.ID, true)
+	case model.DataExportSectionFollowing:
+		data, page, err = s.followsSection(user.ID, false)
+	case model.DataExportSectionSessions:
+		data, page, err = s.sessionsSection(user.ID)
+	case model.DataExportSectionVerification:
+		data, page, err = s.verificationSection(user.ID)
+	case model.DataExportSectionMedia:
+		data, page, err = s.mediaSection(user.ID)
+	default:
+		return fmt.Errorf("unknown export section: %s", section)
+	}
+	if err != nil {
+		return err
+	}
+
+	body, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode section: %w", err)
+	}
+	if err := s.store.Put(exportPartKey(export.ID, section+".json"), bytes.NewReader(body), int64(len(body)), "application/json"); err != nil {
+		return fmt.Errorf("failed to save section: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := exportPageTemplate.Execute(&rendered, page); err != nil {
+		return fmt.Errorf("failed to render section: %w", err)
+	}
+	if err := s.store.Put(exportPartKey(export.ID, section+".html"), &rendered, int64(rendered.Len()), "text/html; charset=utf-8"); err != nil {
+		return fmt.Errorf("failed to save section: %w", err)
+	}
+	return nil
+}
+
+func (s *ExportService) profileSection(user *model.User) (interface{}, exportPage, error) {
+	profile := exportProfile{User: user}
+	if user.AvatarMediaID != nil {
+		media, err := s.mediaService.GetByIDs([]string{*user.AvatarMediaID})
+		if err != nil {
+			return nil, exportPage{}, err
+		}
+		if len(media) > 0 {
+			profile.AvatarFile = exportMediaDir + mediaFileName(media[0].ID, media[0].ContentType)
+		}
+	}
+
+	bio := ""
+	if user.Bio != nil {
+		bio = *user.Bio
+	}
+	avatar := exportCell{}
+	if profile.AvatarFile != "" {
+		avatar = fileLinks([]string{profile.AvatarFile})
+	}
+	rows := [][]exportCell{
+		{textCell("Username"), textCell(user.Username)},
+		{textCell("Full name"), textCell(user.FullName)},
+		{textCell("Email"), textCell(user.Email)},
+		{textCell("Bio"), textCell(bio)},
+		{textCell("Avatar"), avatar},
+		{textCell("Account type"), textCell(string(user.AccountType))},
+		{textCell("Email verified"), textCell(strconv.FormatBool(user.IsEmailVerified))},
+		{textCell("Verified"), textCell(strconv.FormatBool(user.IsVerified))},
+		{textCell("Account status"), textCell(string(user.AccountStatus))},
+		{textCell("Joined"), timeCell(&user.CreatedAt)},
+	}
+	return profile, exportPage{
+		Title:  "Profile",
+		Tables: []exportTable{{Columns: []string{"Field", "Value"}, Rows: rows}},
+	}, nil
+}
+
+func (s *ExportService) postsSection(userID string) (interface{}, exportPage, error) {
+	posts := []exportPost{}
+	for offset := 0; ; offset += exportPageSize {
+		batch, _, err := s.postRepo.GetUserPosts(userID, &userID, utils.PaginationResult{Offset: offset, Limit: exportPageSize})
+		if err != nil {
+			return nil, exportPage{}, err
+		}
+		s.mediaService.PopulatePosts(batch)
+		s.pollService.PopulatePosts(batch, &userID)
+		for _, post := range batch {
+			files := []string{}
+			for _, m := range post.Media {
+				files = append(files, exportMediaDir+mediaFileName(m.ID, m.ContentType))
+			}
+			posts = append(posts, exportPost{Post: post, Files: files})
+		}
+		if len(batch) < exportPageSize {
+			break
+		}
+	}
+
+	table := exportTable{Columns: []string{"Posted", "Content", "Images", "Likes", "Comments", "Edited"}}
+	for _, post := range posts {
+		table.Rows = append(table.Rows, []exportCell{
+			timeCell(&post.CreatedAt),
+			textCell(post.Content),
+			fileLinks(post.Files),
+			textCell(strconv.Itoa(post.LikeCount)),
+			textCell(strconv.Itoa(post.CommentCount)),
+			timeCell(post.EditedAt),
+		})
+	}
+	return posts, exportPage{Title: "Posts", Tables: []exportTable{table}}, nil
+}
+
+func (s *ExportService) commentsSection(userID string) (interface{}, exportPage, error) {
+	comments := []model.Comment{}
+	for offset := 0; ; offset += exportPageSize {
+		batch, err := s.commentRepo.GetUserComments(userID, utils.PaginationResult{Offset: offset, Limit: exportPageSize})
+		if err != nil {
+			return nil, exportPage{}, err
+		}
+		comments = append(comments, batch...)
+		if len(batch) < exportPageSize {
+			break
+		}
+	}
+
+	table := exportTable{Columns: []string{"Posted", "Post", "Content", "Likes", "Edited"}}
+	for _, comment := range comments {
+		table.Rows = append(table.Rows, []exportCell{
+			timeCell(&comment.CreatedAt),
+			textCell(comment.PostID),
+			textCell(comment.Content),
+			textCell(strconv.Itoa(comment.LikeCount)),
+			timeCell(comment.EditedAt),
+		})
+	}
+	return comments, exportPage{Title: "Comments", Tables: []exportTable{table}}, nil
+}
+
+func (s *ExportService) likesSection(userID string) (interface{}, exportPage, error) {
+	likes := exportLikes{Posts: []model.PostReaction{}, Comments: []model.CommentReaction{}}
+	for offset := 0; ; offset += exportPageSize {
+		batch, err := s.postRepo.GetUserReactions(userID, utils.PaginationResult{Offset: offset, Limit: exportPageSize})
+		if err != nil {
+			return nil, exportPage{}, err
+		}
+		likes.Posts = append(likes.Posts, batch...)
+		if len(batch) < exportPageSize {
+			break
+		}
+	}
+	for offset := 0; ; offset += exportPageSize {
+		batch, err := s.commentRepo.GetUserReactions(userID, utils.PaginationResult{Offset: offset, Limit: exportPageSize})
+		if err != nil {
+			return nil, exportPage{}, err
+		}
+		likes.Comments = append(likes.Comments, batch...)
+		if len(batch) < exportPageSize {
+			break
+		}
+	}
+
+	postTable := exportTable{Caption: "Posts", Columns: []string{"Reacted", "Reaction", "Post"}}
+	for _, like := range likes.Posts {
+		postTable.Rows = append(postTable.Rows, []exportCell{timeCell(&like.CreatedAt), textCell(string(like.Reaction)), textCell(like.PostID)})
+	}
+	commentTable := exportTable{Caption: "Comments", Columns: []string{"Reacted", "Reaction", "Comment"}}
+	for _, like := range likes.Comments {
+		commentTable.Rows = append(commentTable.Rows, []exportCell{timeCell(&like.CreatedAt), textCell(string(like.Reaction)), textCell(like.CommentID)})
+	}
+	return likes, exportPage{Title: "Likes and reactions", Tables: []exportTable{postTable, commentTable}}, nil
+}
+
+func (s *ExportService) votesSection(userID string) (interface{}, exportPage, error) {
+	votes := []exportVote{}
+	for offset := 0; ; offset += exportPageSize {
+		batch, err := s.pollRepo.GetUserVotes(userID, utils.PaginationResult{Offset: offset, Limit: exportPageSize})
+		if err != nil {
+			return nil, exportPage{}, err
+		}
+		optionIDs := make([]string, len(batch))
+		for i, vote := range batch {
+			optionIDs[i] = vote.OptionID
+		}
+		options, err := s.pollRepo.GetOptions(optionIDs)
+		if err != nil {
+			return nil, exportPage{}, err
+		}
+		for _, vote := range batch {
+			votes = append(votes, exportVote{PollVote: vote, Option: options[vote.OptionID].Text})
+		}
+		if len(batch) < exportPageSize {
+			break
+		}
+	}
+
+	table := exportTable{Columns: []string{"Voted", "Poll", "Option"}}
+	for _, vote := range votes {
+		table.Rows = append(table.Rows, []exportCell{timeCell(&vote.CreatedAt), textCell(vote.PollID), textCell(vote.Option)})
+	}
+	return votes, exportPage{Title: "Poll votes", Tables: []exportTable{table}}, nil
+}
+
+func (s *ExportService) followsSection(userID string, followers bool) (interface{}, exportPage, error) {
+	users := []model.UserProfile{}
+	for offset := 0; ; offset += exportPageSize {
+		pagination := utils.PaginationResult{Offset: offset, Limit: exportPageSize}
+		var batch []model.UserProfile
+		var err error
+		if followers {
+			batch, _, err = s.followRepo.GetFollowers(userID, nil, pagination)
+		} else {
+			batch, _, err = s.followRepo.GetFollowing(userID, nil, pagination)
+		}
+		if err != nil {
+			return nil, exportPage{}, err
+		}
+		users = append(users, batch...)
+		if len(batch) < exportPageSize {
+			break
+		}
+	}
+
+	title := "Following"
+	if followers {
+		title = "Followers"
+	}
+	table := exportTable{Columns: []string{"Username", "Full name", "User ID"}}
+	for _, u := range users {
+		table.Rows = append(table.Rows, []exportCell{textCell("@" + u.Username), textCell(u.FullName), textCell(u.ID)})
+	}
+	return users, exportPage{Title: title, Tables: []exportTable{table}}, nil
+}
+
+func (s *ExportService) sessionsSection(userID string) (interface{}, exportPage, error) {
+	tokens, err := s.authRepo.GetUserRefreshTokens(userID)
+	if err != nil {
+		return nil, exportPage{}, err
+	}
+
+	sessions := []exportSession{}
+	table := exportTable{Columns: []string{"Signed in", "Expires", "Session ID"}}
+	for _, token := range tokens {
+		sessions = append(sessions, exportSession{ID: token.ID, CreatedAt: token.CreatedAt, ExpiresAt: token.ExpiresAt})
+		table.Rows = append(table.Rows, []exportCell{timeCell(&token.CreatedAt), timeCell(&token.ExpiresAt), textCell(token.ID)})
+	}
+	return sessions, exportPage{Title: "Sessions", Tables: []exportTable{table}}, nil
+}
+
+func (s *ExportService) verificationSection(userID string) (interface{}, exportPage, error) {
+	history, err := s.verificationService.GetUserHistory(userID)
+	if err != nil {
+		return nil, exportPage{}, err
+	}
+
+	requests := exportTable{Caption: "Requests", Columns: []string{"Submitted", "Document", "Status", "Reviewed", "Expires"}}
+	for _, v := range history.Verifications {
+		requests.Rows = append(requests.Rows, []exportCell{
+			timeCell(&v.SubmittedAt),
+			textCell(string(v.IDType)),
+			textCell(string(v.Status)),
+			timeCell(v.ReviewedAt),
+			timeCell(v.ExpiresAt),
+		})
+	}
+	events := exportTable{Caption: "Status changes", Columns: []string{"Date", "Request", "Status", "Reason"}}
+	for _, e := range history.Events {
+		reason := ""
+		if e.Reason != nil {
+			reason = *e.Reason
+		}
+		events.Rows = append(events.Rows, []exportCell{
+			timeCell(&e.CreatedAt),
+			textCell(e.VerificationID),
+			textCell(string(e.ToStatus)),
+			textCell(reason),
+		})
+	}
+	return history, exportPage{Title: "Verification history", Tables: []exportTable{requests, events}}, nil
+}
+
+func (s *ExportService) mediaSection(userID string) (interface{}, exportPage, error) {
+	media, err := s.mediaService.GetExportable(userID)
+	if err != nil {
+		return nil, exportPage{}, err
+	}
+
+	files := []exportMediaFile{}
+	table := exportTable{Columns: []string{"Uploaded", "File", "Used for", "Type", "Size"}}
+	for _, m := range media {
+		file := exportMediaFile{
+			ID:          m.ID,
+			File:        exportMediaDir + mediaFileName(m.ID, m.ContentType),
+			Purpose:     m.Purpose,
+			ContentType: m.ContentType,
+			Size:        m.Size,
+			CreatedAt:   m.CreatedAt,
+		}
+		files = append(files, file)
+		table.Rows = append(table.Rows, []exportCell{
+			timeCell(&file.CreatedAt),
+			fileLinks([]string{file.File}),
+			textCell(string(file.Purpose)),
+			textCell(file.ContentType),
+			textCell(strconv.FormatInt(file.Size, 10)),
+		})
+	}
+	return files, exportPage{Title: "Media", Tables: []exportTable{table}}, nil
+}
+
+// assemble zips the saved sections, the uploaded files, an index page and the manifest,
+// and saves the archive to private storage. It only reads the parts, so it can run again
+// after a failure.
+func (s *ExportService) assemble(user *model.User, export *model.DataExport) (string, int64, error) {
+	tmp, err := os.CreateTemp("", "vietick-export-*.zip")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	now := time.Now()
+	zw := zip.NewWriter(tmp)
+	var files []exportManifestFile
+	add := func(path string, body io.Reader) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		hash := sha256.New()
+		size, err := io.Copy(io.MultiWriter(w, hash), body)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		files = append(files, exportManifestFile{Path: path, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))})
+		return nil
+	}
+	addPart := func(file string) error {
+		reader, err := s.store.Get(exportPartKey(export.ID, file))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		defer reader.Close()
+		return add(file, reader)
+	}
+
+	for _, section := range model.DataExportSections {
+		if err := addPart(section + ".json"); err != nil {
+			return "", 0, err
+		}
+		if err := addPart(section + ".html"); err != nil {
+			return "", 0, err
+		}
+	}
+
+	// Uploaded files listed by the media section
+	reader, err := s.store.Get(exportPartKey(export.ID, model.DataExportSectionMedia+".json"))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read media list: %w", err)
+	}
+	var mediaFiles []exportMediaFile
+	err = json.NewDecoder(reader).Decode(&mediaFiles)
+	reader.Close()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read media list: %w", err)
+	}
+	if err := s.addMedia(mediaFiles, add); err != nil {
+		return "", 0, err
+	}
+
+	var index bytes.Buffer
+	err = exportIndexTemplate.Execute(&index, map[string]interface{}{
+		"Username":      user.Username,
+		"RequestedAt":   export.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+		"GeneratedAt":   now.UTC().Format("2006-01-02 15:04:05"),
+		"FormatVersion": model.DataExportFormatVersion,
+		"Sections":      model.DataExportSections,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to render index: %w", err)
+	}
+	if err := add("index.html", &index); err != nil {
+		return "", 0, err
+	}
+
+	manifest, err := json.MarshalIndent(exportManifest{
+		FormatVersion: model.DataExportFormatVersion,
+		ExportID:      export.ID,
+		UserID:        user.ID,
+		Username:      user.Username,
+		RequestedAt:   export.CreatedAt,
+		GeneratedAt:   now,
+		Sections:      model.DataExportSections,
+		Files:         files,
+	}, "", "  ")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := add("manifest.json", bytes.NewReader(manifest)); err != nil {
+		return "", 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	key := exportArchiveKey(export.ID)
+	if err := s.store.Put(key, tmp, size, "application/zip"); err != nil {
+		return "", 0, fmt.Errorf("failed to save archive: %w", err)
+	}
+	return key, size, nil
+}
+
+// addMedia copies the uploaded files into the archive. Files deleted since the media
+// section was written are skipped.
+func (s *ExportService) addMedia(files []exportMediaFile, add func(string, io.Reader) error) error {
+	ids := make([]string, 0, len(files))
+	for _, file := range files {
+		ids = append(ids, file.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	media, err := s.mediaService.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*model.Media, len(media))
+	for i := range media {
+		byID[media[i].ID] = &media[i]
+	}
+
+	for _, file := range files {
+		m, ok := byID[file.ID]
+		if !ok {
+			continue
+		}
+		reader, err := s.mediaService.Open(m)
+		if err != nil {
+			log.Printf("Failed to add media %s to export: %v", m.ID, err)
+			continue
+		}
+		err = add(file.File, reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteParts removes the saved sections of an export
+func (s *ExportService) deleteParts(exportID string) {
+	for _, section := range model.DataExportSections {
+		for _, file := range []string{section + ".json", section + ".html"} {
+			if err := s.store.Delete(exportPartKey(exportID, file)); err != nil {
+				log.Printf("Failed to delete export part %s of %s: %v", file, exportID, err)
+			}
+		}
+	}
+}
+
+const exportStyle = `body { font-family: Arial, sans-serif; line-height: 1.5; color: #333; margin: 24px; }
+h1 { color: #1DA1F2; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 24px; }
+th, td { border: 1px solid #ddd; padding: 6px 8px; text-align: left; vertical-align: top; white-space: pre-wrap; }
+th { background: #f5f8fa; }`
+
+var exportPageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}} - VietTick data</title>
+    <style>` + exportStyle + `</style>
+</head>
+<body>
+    <p><a href="index.html">&larr; Back</a></p>
+    <h1>{{.Title}}</h1>
+    {{range .Tables}}
+    {{if .Caption}}<h2>{{.Caption}}</h2>{{end}}
+    {{if .Rows}}
+    <table>
+        <tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+        {{range .Rows}}<tr>{{range .}}<td>{{.Text}}{{range .Links}}<a href="{{.Href}}">{{.Text}}</a><br>{{end}}</td>{{end}}</tr>
+        {{end}}
+    </table>
+    {{else}}
+    <p>Nothing here.</p>
+    {{end}}
+    {{end}}
+</body>
+</html>
+`))
+
+var exportIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>VietTick data of @{{.Username}}</title>
+    <style>` + exportStyle + `</style>
+</head>
+<body>
+    <h1>VietTick data of @{{.Username}}</h1>
+    <p>Requested {{.RequestedAt}} UTC, generated {{.GeneratedAt}} UTC. Archive format version {{.FormatVersion}}, described in manifest.json.</p>
+    <ul>
+        {{range .Sections}}<li><a href="{{.}}.html">{{.}}</a> (<a href="{{.}}.json">JSON</a>)</li>
+        {{end}}
+    </ul>
+</body>
+</html>
+`))
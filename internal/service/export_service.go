@@ -0,0 +1,331 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/config"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/utils"
+	"vietick-backend/pkg/email"
+	"vietick-backend/pkg/storage"
+)
+
+const (
+	// How long a worker keeps an export locked; extended after every section
+	exportLease = 15 * time.Minute
+	// Exports that fail this many times are marked failed
+	maxExportAttempts = 3
+	// Rows read per query while writing a section
+	exportPageSize = 500
+)
+
+// ExportService builds archives of a user's data. Archives are written by a background
+// worker one section at a time, each saved to private storage before the next one starts,
+// so an export interrupted by a crash or restart resumes where it stopped.
+type ExportService struct {
+	exportRepo          *repository.ExportRepository
+	userRepo            *repository.UserRepository
+	postRepo            *repository.PostRepository
+	commentRepo         *repository.CommentRepository
+	followRepo          *repository.FollowRepository
+	pollRepo            *repository.PollRepository
+	authRepo            *repository.AuthRepository
+	verificationService *VerificationService
+	mediaService        *MediaService
+	pollService         *PollService
+	emailService        *email.EmailService
+	store               storage.Storage
+	signingSecret       string
+	config              *config.ExportConfig
+}
+
+func NewExportService(exportRepo *repository.ExportRepository, userRepo *repository.UserRepository,
+	postRepo *repository.PostRepository, commentRepo *repository.CommentRepository, followRepo *repository.FollowRepository,
+	pollRepo *repository.PollRepository, authRepo *repository.AuthRepository, verificationService *VerificationService,
+	mediaService *MediaService, pollService *PollService, emailService *email.EmailService, privateStore storage.Storage, signingSecret string, cfg *config.ExportConfig) *ExportService {
+	return &ExportService{
+		exportRepo:          exportRepo,
+		userRepo:            userRepo,
+		postRepo:            postRepo,
+		commentRepo:         commentRepo,
+		followRepo:          followRepo,
+		pollRepo:            pollRepo,
+		authRepo:            authRepo,
+		verificationService: verificationService,
+		mediaService:        mediaService,
+		pollService:         pollService,
+		emailService:        emailService,
+		store:               privateStore,
+		signingSecret:       signingSecret,
+		config:              cfg,
+	}
+}
+
+// RequestExport queues a new archive of the user's data. A user has at most one export in
+// progress and has to wait for the cooldown between requests; failed exports don't count.
+func (s *ExportService) RequestExport(userID string) (*model.DataExport, error) {
+	latest, err := s.exportRepo.GetLatest(userID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		if latest.Status == model.DataExportPending || latest.Status == model.DataExportProcessing {
+			return nil, fmt.Errorf("export already exists: your previous export is still being prepared")
+		}
+		nextAllowed := latest.CreatedAt.Add(time.Duration(s.config.CooldownHours) * time.Hour)
+		if time.Now().Before(nextAllowed) {
+			return nil, fmt.Errorf("rate limit: you can request a new export after %s", nextAllowed.UTC().Format(time.RFC3339))
+		}
+	}
+
+	export := &model.DataExport{
+		ID:                uuid.New().String(),
+		UserID:            userID,
+		Status:            model.DataExportPending,
+		FormatVersion:     model.DataExportFormatVersion,
+		CompletedSections: model.ImageURLs{},
+	}
+	if err := s.exportRepo.Create(export); err != nil {
+		return nil, err
+	}
+	return s.exportRepo.GetByID(export.ID)
+}
+
+func (s *ExportService) GetExports(userID string, pagination *utils.PaginationParams) (*model.DataExportsResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	exports, totalCount, err := s.exportRepo.GetByUserID(userID, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+	for i := range exports {
+		s.populateDownloadURL(&exports[i])
+	}
+
+	return &model.DataExportsResponse{
+		Exports:    exports,
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+func (s *ExportService) GetExport(userID, exportID string) (*model.DataExport, error) {
+	export, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		return nil, err
+	}
+	if export.UserID != userID {
+		return nil, fmt.Errorf("export not found")
+	}
+	s.populateDownloadURL(export)
+	return export, nil
+}
+
+func (s *ExportService) populateDownloadURL(export *model.DataExport) {
+	if export.Status == model.DataExportReady && export.ExpiresAt != nil {
+		export.DownloadURL = s.downloadPath(export)
+	}
+}
+
+// downloadPath is the signed download link of a ready export, valid until the archive expires
+func (s *ExportService) downloadPath(export *model.DataExport) string {
+	expires := strconv.FormatInt(export.ExpiresAt.Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {s.downloadSignature(export.ID, expires)},
+	}
+	return fmt.Sprintf("/api/v1/exports/%s/download?%s", export.ID, query.Encode())
+}
+
+func (s *ExportService) downloadSignature(exportID, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.signingSecret))
+	mac.Write([]byte("export\n" + exportID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// OpenDownload checks a link made by downloadPath and opens the archive
+func (s *ExportService) OpenDownload(exportID, expires, signature string) (*model.DataExport, io.ReadCloser, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(s.downloadSignature(exportID, expires))) {
+		return nil, nil, fmt.Errorf("access denied: invalid signature")
+	}
+	if time.Now().Unix() > expiresAt {
+		return nil, nil, fmt.Errorf("access denied: link has expired")
+	}
+
+	export, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != model.DataExportReady || export.StorageKey == nil || export.SizeBytes == nil {
+		return nil, nil, fmt.Errorf("export not found")
+	}
+	reader, err := s.store.Get(*export.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, fmt.Errorf("export not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	return export, reader, nil
+}
+
+// ProcessDue builds the oldest waiting export, or takes over one left by a worker that
+// stopped. Archives are heavy to build, so each instance builds one export per run.
+func (s *ExportService) ProcessDue() (int, error) {
+	ids, err := s.exportRepo.GetDueIDs(time.Now(), 10)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		token := uuid.New().String()
+		now := time.Now()
+		claimed, err := s.exportRepo.Claim(id, token, now, now.Add(exportLease))
+		if err != nil {
+			return 0, err
+		}
+		if !claimed {
+			continue
+		}
+		if s.build(id, token) {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, nil
+}
+
+// build writes the export locked by this worker, retrying later on failure
+func (s *ExportService) build(exportID, token string) bool {
+	export, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		log.Printf("Failed to load export %s: %v", exportID, err)
+		return false
+	}
+
+	err = s.run(export, token)
+	if err == nil {
+		return true
+	}
+
+	attempts := export.Attempts + 1
+	message := err.Error()
+	if len(message) > 500 {
+		message = message[:500]
+	}
+	status := model.DataExportPending
+	if attempts >= maxExportAttempts {
+		status = model.DataExportFailed
+	}
+	log.Printf("Failed to build export %s (attempt %d): %v", exportID, attempts, err)
+	if err := s.exportRepo.Release(exportID, token, status, attempts, &message); err != nil {
+		log.Printf("Failed to release export %s: %v", exportID, err)
+	}
+	if status == model.DataExportFailed {
+		s.deleteParts(exportID)
+	}
+	return false
+}
+
+// run writes the sections not written yet, then assembles the archive and emails the link
+func (s *ExportService) run(export *model.DataExport, token string) error {
+	user, err := s.userRepo.GetByID(export.UserID)
+	if err != nil {
+		return err
+	}
+	if user.AccountStatus == model.AccountStatusDeleted {
+		return fmt.Errorf("user not found")
+	}
+
+	// Sections written before an upgrade of the archive format are written again
+	if export.FormatVersion != model.DataExportFormatVersion {
+		export.FormatVersion = model.DataExportFormatVersion
+		export.CompletedSections = nil
+	}
+	for _, section := range model.DataExportSections {
+		if export.HasSection(section) {
+			continue
+		}
+		if err := s.writeSection(user, export, section); err != nil {
+			return fmt.Errorf("failed to write %s: %w", section, err)
+		}
+		export.CompletedSections = append(export.CompletedSections, section)
+		if err := s.exportRepo.SaveProgress(export.ID, token, export.FormatVersion, export.CompletedSections, time.Now().Add(exportLease)); err != nil {
+			return err
+		}
+	}
+
+	key, size, err := s.assemble(user, export)
+	if err != nil {
+		return fmt.Errorf("failed to assemble archive: %w", err)
+	}
+	expiresAt := time.Now().Add(time.Duration(s.config.LinkTTLHours) * time.Hour)
+	if err := s.exportRepo.Complete(export.ID, token, key, size, expiresAt); err != nil {
+		return err
+	}
+	s.deleteParts(export.ID)
+
+	export.ExpiresAt = &expiresAt
+	downloadURL := s.config.PublicBaseURL + s.downloadPath(export)
+	if err := s.emailService.SendDataExportReady(user.Email, user.FullName, downloadURL, expiresAt); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Failed to send data export email: %v\n", err)
+	}
+	return nil
+}
+
+// CleanupExpired deletes archives whose download period is over
+func (s *ExportService) CleanupExpired() (int, error) {
+	exports, err := s.exportRepo.GetExpired(time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, export := range exports {
+		if export.StorageKey != nil {
+			if err := s.store.Delete(*export.StorageKey); err != nil {
+				log.Printf("Failed to delete export archive %s: %v", export.ID, err)
+				continue
+			}
+		}
+		if err := s.exportRepo.MarkExpired(export.ID); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// DeleteUserExports removes every export of userID and its files, used when the account
+// is deleted
+func (s *ExportService) DeleteUserExports(userID string) error {
+	exports, err := s.exportRepo.GetAllByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		s.deleteParts(export.ID)
+		if export.StorageKey != nil {
+			if err := s.store.Delete(*export.StorageKey); err != nil {
+				return fmt.Errorf("failed to delete export archive: %w", err)
+			}
+		}
+		if err := s.exportRepo.Delete(export.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
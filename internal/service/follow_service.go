@@ -9,12 +9,16 @@ import (
 )
 
 type FollowService struct {
-	followRepo *repository.FollowRepository
+	followRepo       *repository.FollowRepository
+	searchService    *SearchService
+	analyticsService *AnalyticsService
 }
 
-func NewFollowService(followRepo *repository.FollowRepository) *FollowService {
+func NewFollowService(followRepo *repository.FollowRepository, searchService *SearchService, analyticsService *AnalyticsService) *FollowService {
 	return &FollowService{
-		followRepo: followRepo,
+		followRepo:       followRepo,
+		searchService:    searchService,
+		analyticsService: analyticsService,
 	}
 }
 
@@ -23,6 +27,7 @@ func (s *FollowService) Follow(followerID, followingID string) error {
 	if err != nil {
 		return fmt.Errorf("failed to follow user: %w", err)
 	}
+	s.searchService.FollowChanged(followerID, followingID, true)
 	return nil
 }
 
@@ -31,6 +36,7 @@ func (s *FollowService) Unfollow(followerID, followingID string) error {
 	if err != nil {
 		return fmt.Errorf("failed to unfollow user: %w", err)
 	}
+	s.searchService.FollowChanged(followerID, followingID, false)
 
 	return nil
 }
@@ -147,6 +153,16 @@ func (s *FollowService) GetFollowStats(userID string) (map[string]interface{}, e
 	return stats, nil
 }
 
+// GetFollowerAnalytics trả về tăng trưởng người theo dõi theo ngày, tỉ lệ bỏ theo dõi, tỉ lệ
+// người theo dõi đã xác minh và những người tương tác nhiều nhất, từ thống kê tính mỗi đêm
+func (s *FollowService) GetFollowerAnalytics(userID string, filter *model.FollowerAnalyticsFilter) (*model.FollowerAnalytics, error) {
+	analytics, err := s.analyticsService.GetFollowerAnalytics(userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follower analytics: %w", err)
+	}
+	return analytics, nil
+}
+
 func calculateFollowRatio(followers, following int64) float64 {
 	if following == 0 {
 		if followers == 0 {
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/config"
+	"vietick-backend/internal/contentpolicy"
+	"vietick-backend/internal/linkpreview"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+)
+
+const (
+	// Links of a post that get a preview, in the order they appear
+	maxLinkPreviewsPerPost = 3
+	maxLinkPreviewAttempts = 3
+	// Longer links are not previewed (size of link_previews.url)
+	maxLinkPreviewURLLength = 2048
+	// A worker that has not finished within this time is assumed to have crashed
+	linkPreviewStaleAfter = 5 * time.Minute
+	// Links that did not fit in the queue are picked up by the sweep after this delay
+	linkPreviewSweepDelay = time.Minute
+	// Links that cannot be previewed are tried again after this time
+	linkPreviewFailedTTL = 24 * time.Hour
+	linkPreviewUserAgent = "VietTickBot/1.0 (+https://vietick.com)"
+)
+
+// LinkPreviewService unfurls links in posts into preview cards. Pages are fetched in the
+// background by a fixed number of workers through an SSRF-safe fetcher, and the cards are
+// cached per canonical URL for every post linking to it.
+type LinkPreviewService struct {
+	linkPreviewRepo *repository.LinkPreviewRepository
+	fetcher         *linkpreview.Fetcher
+	config          *config.LinkPreviewConfig
+	queue           chan string
+}
+
+func NewLinkPreviewService(linkPreviewRepo *repository.LinkPreviewRepository, cfg *config.LinkPreviewConfig, queueSize int) *LinkPreviewService {
+	return &LinkPreviewService{
+		linkPreviewRepo: linkPreviewRepo,
+		fetcher: linkpreview.NewFetcher(linkpreview.Options{
+			Timeout:      time.Duration(cfg.TimeoutSeconds) * time.Second,
+			MaxRedirects: cfg.MaxRedirects,
+			MaxBodyBytes: cfg.MaxBodyBytes,
+			UserAgent:    linkPreviewUserAgent,
+		}),
+		config: cfg,
+		queue:  make(chan string, queueSize),
+	}
+}
+
+func (s *LinkPreviewService) Start() {
+	workers := s.config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for previewID := range s.queue {
+				s.handle(previewID)
+			}
+		}()
+	}
+}
+
+// AttachToPost records the links in the content of a post and queues the ones without a
+// fresh preview. Links that are never fetched (private addresses, other ports) are skipped.
+// Failures are logged: a post is never refused for its previews.
+func (s *LinkPreviewService) AttachToPost(postID, content string) {
+	now := time.Now()
+	var links []model.LinkPreview
+	seen := make(map[string]bool)
+	for _, found := range contentpolicy.ExtractURLs(content) {
+		canonical, err := linkpreview.Canonicalize(found)
+		if err != nil || len(canonical) > maxLinkPreviewURLLength {
+			continue
+		}
+		hash := linkpreview.Hash(canonical)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		links = append(links, model.LinkPreview{
+			ID:      uuid.New().String(),
+			URLHash: hash,
+			URL:     canonical,
+			Status:  model.LinkPreviewPending,
+		})
+		if len(links) == maxLinkPreviewsPerPost {
+			break
+		}
+	}
+
+	previews, err := s.linkPreviewRepo.GetOrCreate(links)
+	if err != nil {
+		log.Printf("Failed to save links of post %s: %v", postID, err)
+		return
+	}
+	previewIDs := make([]string, len(previews))
+	var expired, pending []string
+	for i, preview := range previews {
+		previewIDs[i] = preview.ID
+		switch {
+		case preview.Status == model.LinkPreviewPending:
+			pending = append(pending, preview.ID)
+		case preview.Status != model.LinkPreviewProcessing && (preview.ExpiresAt == nil || preview.ExpiresAt.Before(now)):
+			expired = append(expired, preview.ID)
+		}
+	}
+	if err := s.linkPreviewRepo.SetPostLinks(postID, previewIDs); err != nil {
+		log.Printf("Failed to save links of post %s: %v", postID, err)
+		return
+	}
+	if err := s.linkPreviewRepo.MarkPending(expired); err != nil {
+		log.Printf("Failed to refresh link previews: %v", err)
+		return
+	}
+	for _, previewID := range append(pending, expired...) {
+		s.Enqueue(previewID)
+	}
+}
+
+// Enqueue schedules a fetch without blocking. When the queue is full the preview stays
+// pending in the database and Sweep picks it up later.
+func (s *LinkPreviewService) Enqueue(previewID string) {
+	select {
+	case s.queue <- previewID:
+	default:
+		log.Printf("Link preview queue is full, preview %s will be fetched later", previewID)
+	}
+}
+
+// Sweep re-queues previews that were never fetched (full queue, restart, crashed worker)
+// and deletes expired previews no post links to anymore
+func (s *LinkPreviewService) Sweep() (int, error) {
+	now := time.Now()
+	ids, err := s.linkPreviewRepo.GetUnfetchedIDs(now.Add(-linkPreviewSweepDelay), now.Add(-linkPreviewStaleAfter), cap(s.queue))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		s.Enqueue(id)
+	}
+	if _, err := s.linkPreviewRepo.DeleteUnused(now, 500); err != nil {
+		return len(ids), err
+	}
+	return len(ids), nil
+}
+
+func (s *LinkPreviewService) handle(previewID string) {
+	claimed, err := s.linkPreviewRepo.ClaimForFetch(previewID, time.Now().Add(-linkPreviewStaleAfter))
+	if err != nil {
+		log.Printf("Failed to claim link preview %s: %v", previewID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	preview, err := s.linkPreviewRepo.GetByID(previewID)
+	if err != nil {
+		return
+	}
+
+	err = s.fetch(preview)
+	if err == nil {
+		return
+	}
+
+	attempts := preview.Attempts + 1
+	message := err.Error()
+	if len(message) > 500 {
+		message = message[:500]
+	}
+	// Links that turned out to point inside the network lose their card, whatever it was
+	blocked := errors.Is(err, linkpreview.ErrBlocked)
+	if attempts >= maxLinkPreviewAttempts || blocked || errors.Is(err, linkpreview.ErrNotHTML) {
+		retryAt := time.Now().Add(linkPreviewFailedTTL)
+		if err := s.linkPreviewRepo.SetFetchStatus(previewID, model.LinkPreviewFailed, attempts, &message, &retryAt, blocked); err != nil {
+			log.Printf("Failed to update link preview %s: %v", previewID, err)
+		}
+		return
+	}
+
+	if err := s.linkPreviewRepo.SetFetchStatus(previewID, model.LinkPreviewPending, attempts, &message, preview.ExpiresAt, false); err != nil {
+		log.Printf("Failed to reschedule link preview %s: %v", previewID, err)
+		return
+	}
+	backoff := time.Duration(1<<attempts) * time.Second
+	time.AfterFunc(backoff, func() { s.Enqueue(previewID) })
+}
+
+func (s *LinkPreviewService) fetch(preview *model.LinkPreview) error {
+	page, err := s.fetcher.Fetch(context.Background(), preview.URL)
+	if err != nil {
+		return err
+	}
+	card := linkpreview.Parse(page)
+
+	// A page without metadata is cached too, and shows no card
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(s.config.CacheTTLHours) * time.Hour)
+	preview.Title = card.Title
+	preview.Description = card.Description
+	preview.ImageURL = card.ImageURL
+	preview.SiteName = card.SiteName
+	preview.Attempts++
+	preview.FetchedAt = &now
+	preview.ExpiresAt = &expiresAt
+	return s.linkPreviewRepo.SaveFetched(preview)
+}
+
+// PopulatePosts attaches the preview cards of each post
+func (s *LinkPreviewService) PopulatePosts(posts []model.Post) {
+	if len(posts) == 0 {
+		return
+	}
+	postIDs := make([]string, len(posts))
+	for i := range posts {
+		postIDs[i] = posts[i].ID
+	}
+	previews, err := s.linkPreviewRepo.GetByPostIDs(postIDs)
+	if err != nil {
+		log.Printf("Failed to load link previews: %v", err)
+		return
+	}
+	for i := range posts {
+		posts[i].LinkPreviews = previews[posts[i].ID]
+	}
+}
+
+// PopulatePost attaches the preview cards of a post
+func (s *LinkPreviewService) PopulatePost(post *model.Post) {
+	if post == nil {
+		return
+	}
+	posts := []model.Post{*post}
+	s.PopulatePosts(posts)
+	post.LinkPreviews = posts[0].LinkPreviews
+}
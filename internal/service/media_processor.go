@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"vietick-backend/internal/imaging"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/pkg/storage"
+)
+
+const (
+	maxProcessingAttempts = 4
+	// A worker that has not finished within this time is assumed to have crashed
+	processingStaleAfter = 10 * time.Minute
+	// Uploads that did not fit in the queue are picked up by the sweep after this delay
+	processingSweepDelay = time.Minute
+)
+
+// Images we decode. GIFs are left alone (animation), PDFs are not images.
+var processableFormats = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+type imageVariantSpec struct {
+	name    string
+	maxSide int
+}
+
+// Resized copies generated for each purpose. Identity documents are only cleaned of metadata.
+var imageVariantSpecs = map[model.MediaPurpose][]imageVariantSpec{
+	model.MediaPurposePost: {
+		{name: "small", maxSide: 320},
+		{name: "medium", maxSide: 640},
+		{name: "large", maxSide: 1280},
+	},
+	model.MediaPurposeAvatar: {
+		{name: "small", maxSide: 48},
+		{name: "medium", maxSide: 128},
+		{name: "large", maxSide: 400},
+	},
+}
+
+// errUnprocessable marks failures that retrying will not fix (corrupt or oversized images)
+var errUnprocessable = errors.New("unprocessable image")
+
+// MediaProcessor runs uploaded images through the pipeline on a fixed number of workers:
+// strip EXIF/GPS metadata from the original, generate resized variants and a blurhash.
+// Failed jobs are retried with exponential backoff.
+type MediaProcessor struct {
+	mediaRepo *repository.MediaRepository
+	storage   storage.Storage
+	private   storage.Storage
+	workers   int
+	queue     chan string
+}
+
+func NewMediaProcessor(mediaRepo *repository.MediaRepository, store, privateStore storage.Storage, workers, queueSize int) *MediaProcessor {
+	if workers < 1 {
+		workers = 1
+	}
+	return &MediaProcessor{
+		mediaRepo: mediaRepo,
+		storage:   store,
+		private:   privateStore,
+		workers:   workers,
+		queue:     make(chan string, queueSize),
+	}
+}
+
+func (p *MediaProcessor) Start() {
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			for mediaID := range p.queue {
+				p.handle(mediaID)
+			}
+		}()
+	}
+}
+
+// Enqueue schedules the media without blocking. When the queue is full the job stays
+// pending in the database and Sweep picks it up later.
+func (p *MediaProcessor) Enqueue(mediaID string) {
+	select {
+	case p.queue <- mediaID:
+	default:
+		log.Printf("Image queue is full, media %s will be processed later", mediaID)
+	}
+}
+
+// Sweep re-queues uploads that never made it through the pipeline (full queue, restart,
+// crashed worker)
+func (p *MediaProcessor) Sweep() (int, error) {
+	now := time.Now()
+	ids, err := p.mediaRepo.GetUnprocessedIDs(now.Add(-processingSweepDelay), now.Add(-processingStaleAfter), cap(p.queue))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		p.Enqueue(id)
+	}
+	return len(ids), nil
+}
+
+func (p *MediaProcessor) handle(mediaID string) {
+	claimed, err := p.mediaRepo.ClaimForProcessing(mediaID, time.Now().Add(-processingStaleAfter))
+	if err != nil {
+		log.Printf("Failed to claim media %s: %v", mediaID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	media, err := p.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return
+	}
+
+	err = p.process(media)
+	if err == nil {
+		return
+	}
+
+	attempts := media.ProcessingAttempts + 1
+	message := err.Error()
+	if len(message) > 500 {
+		message = message[:500]
+	}
+	if attempts >= maxProcessingAttempts || errors.Is(err, errUnprocessable) {
+		log.Printf("Image processing failed for media %s after %d attempts: %v", mediaID, attempts, err)
+		// The original may still carry its metadata: it is never served
+		p.deleteFiles(media)
+		p.mediaRepo.SetProcessingStatus(mediaID, model.MediaProcessingFailed, attempts, &message)
+		return
+	}
+
+	if err := p.mediaRepo.SetProcessingStatus(mediaID, model.MediaProcessingPending, attempts, &message); err != nil {
+		log.Printf("Failed to reschedule media %s: %v", mediaID, err)
+		return
+	}
+	backoff := time.Duration(1<<attempts) * time.Second
+	time.AfterFunc(backoff, func() { p.Enqueue(mediaID) })
+}
+
+func (p *MediaProcessor) process(media *model.Media) error {
+	if !processableFormats[media.ContentType] {
+		return p.mediaRepo.SetProcessingStatus(media.ID, model.MediaProcessingSkipped, media.ProcessingAttempts, nil)
+	}
+
+	store := storageFor(media.Purpose, p.storage, p.private)
+	reader, err := store.Get(media.StorageKey)
+	if err != nil {
+		return fmt.Errorf("failed to read original: %w", err)
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read original: %w", err)
+	}
+
+	// Uploads are already cleaned before they are stored; this only rewrites originals
+	// stored before that, keeping the key so that the URLs handed out still work
+	clean, img, format, err := cleanImage(data)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(clean, data) {
+		if err := store.Put(media.StorageKey, bytes.NewReader(clean), int64(len(clean)), media.ContentType); err != nil {
+			return fmt.Errorf("failed to store original: %w", err)
+		}
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var variants model.MediaVariants
+	for _, spec := range imageVariantSpecs[media.Purpose] {
+		if width <= spec.maxSide && height <= spec.maxSide {
+			// Never upscale: small images use the original for this size
+			variants = append(variants, model.MediaVariant{
+				Name: spec.name, URL: media.URL, Width: width, Height: height,
+			})
+			continue
+		}
+
+		resized := imaging.Fit(img, spec.maxSide)
+		var buf bytes.Buffer
+		contentType, err := imaging.Encode(&buf, resized, format)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s variant: %w", spec.name, err)
+		}
+		key := variantKey(media.StorageKey, spec.name, imaging.Extension(format))
+		if err := store.Put(key, &buf, int64(buf.Len()), contentType); err != nil {
+			return fmt.Errorf("failed to store %s variant: %w", spec.name, err)
+		}
+		variants = append(variants, model.MediaVariant{
+			Name:   spec.name,
+			URL:    store.URL(key),
+			Key:    key,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+		})
+	}
+
+	media.Size = int64(len(clean))
+	media.Width = &width
+	media.Height = &height
+	media.Variants = variants
+	if media.Purpose != model.MediaPurposeIdentityDocument {
+		hash := imaging.Blurhash(img, 4, 3)
+		media.Blurhash = &hash
+	}
+	media.ProcessingStatus = model.MediaProcessingDone
+	return p.mediaRepo.SaveProcessed(media)
+}
+
+// cleanImage xoá EXIF/GPS khỏi ảnh và trả về ảnh đã xoay đúng chiều. Ảnh JPEG bị xoay theo
+// EXIF thì phải encode lại, còn lại cắt metadata không mất chất lượng.
+func cleanImage(data []byte) ([]byte, image.Image, string, error) {
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %v", errUnprocessable, err)
+	}
+	orientation := imaging.Orientation(data, format)
+	img = imaging.Orient(img, orientation)
+
+	if orientation > 1 && format == "jpeg" {
+		var buf bytes.Buffer
+		if _, err := imaging.Encode(&buf, img, format); err != nil {
+			return nil, nil, "", fmt.Errorf("failed to encode original: %w", err)
+		}
+		return buf.Bytes(), img, format, nil
+	}
+	clean, err := imaging.StripMetadata(data, format)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %v", errUnprocessable, err)
+	}
+	return clean, img, format, nil
+}
+
+// deleteFiles removes the original of a media that could not be processed, with any
+// variant written before the failure
+func (p *MediaProcessor) deleteFiles(media *model.Media) {
+	store := storageFor(media.Purpose, p.storage, p.private)
+	keys := []string{media.StorageKey}
+	for _, spec := range imageVariantSpecs[media.Purpose] {
+		for _, ext := range []string{".jpg", ".png"} {
+			keys = append(keys, variantKey(media.StorageKey, spec.name, ext))
+		}
+	}
+	for _, key := range keys {
+		if err := store.Delete(key); err != nil {
+			log.Printf("Failed to delete %s of media %s: %v", key, media.ID, err)
+		}
+	}
+}
+
+// variantKey places variants next to the original: post/2024/05/<id>.jpg -> post/2024/05/<id>_small.jpg
+func variantKey(originalKey, name, ext string) string {
+	base := originalKey
+	if i := strings.LastIndex(base, "."); i > strings.LastIndex(base, "/") {
+		base = base[:i]
+	}
+	return base + "_" + name + ext
+}
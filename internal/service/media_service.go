@@ -0,0 +1,835 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/config"
+	"vietick-backend/internal/imaging"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/pkg/storage"
+)
+
+const (
+	presignTTL = 15 * time.Minute
+	// Presigned uploads that are not completed within this time are removed
+	pendingMediaTTL = 24 * time.Hour
+	// http.DetectContentType looks at most at this many bytes
+	sniffLen = 512
+	// MaxPostMedia is how many media a single post can attach
+	MaxPostMedia = 4
+)
+
+// Content types accepted for each purpose, with the file extension used for the storage key
+var allowedMediaTypes = map[model.MediaPurpose]map[string]string{
+	model.MediaPurposePost: {
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/gif":  ".gif",
+		"image/webp": ".webp",
+	},
+	model.MediaPurposeAvatar: {
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/webp": ".webp",
+	},
+	model.MediaPurposeIdentityDocument: {
+		"image/jpeg":      ".jpg",
+		"image/png":       ".png",
+		"image/webp":      ".webp",
+		"application/pdf": ".pdf",
+	},
+}
+
+// MediaService stores user uploads and hands out media IDs. Uploads either go through the
+// API as multipart forms or straight to storage with a presigned URL followed by Complete.
+// Identity documents go to the private, encrypted storage and have no public URL.
+type MediaService struct {
+	mediaRepo *repository.MediaRepository
+	storage   storage.Storage
+	private   storage.Storage
+	processor *MediaProcessor
+	cfg       *config.StorageConfig
+}
+
+func NewMediaService(mediaRepo *repository.MediaRepository, store, privateStore storage.Storage, processor *MediaProcessor,
+	cfg *config.StorageConfig) *MediaService {
+	return &MediaService{
+		mediaRepo: mediaRepo,
+		storage:   store,
+		private:   privateStore,
+		processor: processor,
+		cfg:       cfg,
+	}
+}
+
+func (s *MediaService) storeFor(purpose model.MediaPurpose) storage.Storage {
+	return storageFor(purpose, s.storage, s.private)
+}
+
+// storageFor picks the private storage for identity documents and the public one otherwise
+func storageFor(purpose model.MediaPurpose, public, private storage.Storage) storage.Storage {
+	if purpose == model.MediaPurposeIdentityDocument {
+		return private
+	}
+	return public
+}
+
+// MaxSize is the upload limit for the purpose
+func (s *MediaService) MaxSize(purpose model.MediaPurpose) int64 {
+	if purpose == model.MediaPurposeIdentityDocument {
+		return s.cfg.MaxDocBytes
+	}
+	return s.cfg.MaxImageBytes
+}
+
+// MaxUploadSize is the largest upload accepted for any purpose
+func (s *MediaService) MaxUploadSize() int64 {
+	if s.cfg.MaxDocBytes > s.cfg.MaxImageBytes {
+		return s.cfg.MaxDocBytes
+	}
+	return s.cfg.MaxImageBytes
+}
+
+func (s *MediaService) checkSize(purpose model.MediaPurpose, size int64) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid media: file is empty")
+	}
+	if size > s.MaxSize(purpose) {
+		return fmt.Errorf("invalid media: file is larger than %d bytes", s.MaxSize(purpose))
+	}
+	return nil
+}
+
+// sniff detects the content type from the first bytes of the file, ignoring whatever
+// the client claimed
+func sniff(purpose model.MediaPurpose, head []byte) (string, string, error) {
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedMediaTypes[purpose][contentType]
+	if !ok {
+		return "", "", fmt.Errorf("invalid media: %s files are not allowed for %s uploads", contentType, purpose)
+	}
+	return contentType, ext, nil
+}
+
+func storageKey(purpose model.MediaPurpose, mediaID, ext string) string {
+	return fmt.Sprintf("%s/%s/%s%s", purpose, time.Now().UTC().Format("2006/01"), mediaID, ext)
+}
+
+// stagingKey is where a presigned upload is received. Complete writes it to the storage
+// key once its metadata has been removed, so the public URL never serves it as uploaded.
+func stagingKey(storageKey string) string {
+	return "staging/" + storageKey
+}
+
+// cleanUpload removes EXIF/GPS metadata from the images we process before they are stored.
+// Other files are returned unchanged.
+func cleanUpload(contentType string, data []byte) ([]byte, error) {
+	if !processableFormats[contentType] {
+		return data, nil
+	}
+	clean, _, _, err := cleanImage(data)
+	if errors.Is(err, errUnprocessable) {
+		return nil, fmt.Errorf("invalid media: image could not be read")
+	}
+	return clean, err
+}
+
+// Upload stores a file sent through the API
+func (s *MediaService) Upload(ownerID string, purpose model.MediaPurpose, file io.Reader, size int64) (*model.Media, error) {
+	if _, ok := allowedMediaTypes[purpose]; !ok {
+		return nil, fmt.Errorf("invalid media purpose: %s", purpose)
+	}
+	if err := s.checkSize(purpose, size); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	head = head[:n]
+
+	contentType, ext, err := sniff(purpose, head)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(io.MultiReader(bytes.NewReader(head), file), size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	// The hash is of the file as uploaded, to match re-submitted documents
+	contentHash := sha256.Sum256(data)
+	clean, err := cleanUpload(contentType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	media := &model.Media{
+		ID:          uuid.New().String(),
+		OwnerID:     ownerID,
+		Purpose:     purpose,
+		ContentType: contentType,
+		Size:        int64(len(clean)),
+		ContentHash: hex.EncodeToString(contentHash[:]),
+		Status:      model.MediaStatusReady,
+
+		ProcessingStatus: model.MediaProcessingPending,
+	}
+	media.StorageKey = storageKey(purpose, media.ID, ext)
+	store := s.storeFor(purpose)
+	media.URL = store.URL(media.StorageKey)
+
+	if err := store.Put(media.StorageKey, bytes.NewReader(clean), media.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+
+	if err := s.mediaRepo.Create(media); err != nil {
+		store.Delete(media.StorageKey)
+		return nil, err
+	}
+	s.processor.Enqueue(media.ID)
+
+	return media, nil
+}
+
+// Presign reserves a media ID and returns a URL the client uploads the file to. The media
+// stays pending until Complete checks what was actually uploaded.
+func (s *MediaService) Presign(ownerID string, req *model.PresignMediaRequest) (*model.PresignMediaResponse, error) {
+	ext, ok := allowedMediaTypes[req.Purpose][req.ContentType]
+	if !ok {
+		return nil, fmt.Errorf("invalid media: %s files are not allowed for %s uploads", req.ContentType, req.Purpose)
+	}
+	if err := s.checkSize(req.Purpose, req.Size); err != nil {
+		return nil, err
+	}
+
+	media := &model.Media{
+		ID:          uuid.New().String(),
+		OwnerID:     ownerID,
+		Purpose:     req.Purpose,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Status:      model.MediaStatusPending,
+
+		ProcessingStatus: model.MediaProcessingPending,
+	}
+	media.StorageKey = storageKey(req.Purpose, media.ID, ext)
+	store := s.storeFor(req.Purpose)
+	media.URL = store.URL(media.StorageKey)
+
+	expiresAt := time.Now().Add(presignTTL)
+	uploadURL, err := store.PresignPut(stagingKey(media.StorageKey), req.ContentType, presignTTL)
+	if errors.Is(err, storage.ErrPresignNotSupported) {
+		// Local disk or encrypted documents: the client uploads through the API with a
+		// signed URL instead
+		expires := strconv.FormatInt(expiresAt.Unix(), 10)
+		uploadURL = fmt.Sprintf("/api/v1/media/%s/upload?expires=%s&signature=%s",
+			media.ID, expires, s.uploadSignature(media.ID, expires))
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to presign upload: %w", err)
+	}
+
+	if err := s.mediaRepo.Create(media); err != nil {
+		return nil, err
+	}
+
+	return &model.PresignMediaResponse{
+		Media:     media,
+		UploadURL: uploadURL,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": req.ContentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *MediaService) uploadSignature(mediaID, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.SigningSecret))
+	mac.Write([]byte(mediaID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ReceiveUpload accepts the body of a presigned upload signed by the API
+func (s *MediaService) ReceiveUpload(mediaID, expires, signature string, body io.Reader, size int64) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(s.uploadSignature(mediaID, expires))) {
+		return fmt.Errorf("access denied: invalid upload signature")
+	}
+	if time.Now().Unix() > expiresAt {
+		return fmt.Errorf("access denied: upload URL has expired")
+	}
+
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return err
+	}
+	if media.Status != model.MediaStatusPending {
+		return fmt.Errorf("invalid upload: media has already been uploaded")
+	}
+	if err := s.checkSize(media.Purpose, size); err != nil {
+		return err
+	}
+
+	if err := s.storeFor(media.Purpose).Put(stagingKey(media.StorageKey), io.LimitReader(body, size), size, media.ContentType); err != nil {
+		return fmt.Errorf("failed to store media: %w", err)
+	}
+	return nil
+}
+
+// Complete checks a presigned upload (size and sniffed type), removes its metadata, moves
+// it from the staging key to the storage key and marks the media ready. Uploads that fail
+// the checks are deleted.
+func (s *MediaService) Complete(ownerID, mediaID string) (*model.Media, error) {
+	media, err := s.getOwned(ownerID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if media.Status == model.MediaStatusReady {
+		return media, nil
+	}
+
+	store := s.storeFor(media.Purpose)
+	staged := stagingKey(media.StorageKey)
+	info, err := store.Stat(staged)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("invalid media: file has not been uploaded yet")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check upload: %w", err)
+	}
+
+	data, err := s.readObject(media.Purpose, staged)
+	if err != nil {
+		return nil, err
+	}
+	head := data[:min(len(data), sniffLen)]
+
+	// The client could have uploaded anything to the presigned URL
+	contentType, _, err := sniff(media.Purpose, head)
+	if err == nil {
+		err = s.checkSize(media.Purpose, info.Size)
+	}
+	var clean []byte
+	if err == nil {
+		clean, err = cleanUpload(contentType, data)
+	}
+	if err != nil {
+		s.discard(media)
+		return nil, err
+	}
+
+	if err := store.Put(media.StorageKey, bytes.NewReader(clean), int64(len(clean)), contentType); err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+	if err := store.Delete(staged); err != nil {
+		log.Printf("Failed to delete staged upload of media %s: %v", media.ID, err)
+	}
+
+	contentHash := sha256.Sum256(data)
+	if err := s.mediaRepo.MarkReady(media.ID, contentType, int64(len(clean)), hex.EncodeToString(contentHash[:])); err != nil {
+		return nil, err
+	}
+	s.processor.Enqueue(media.ID)
+
+	return s.mediaRepo.GetByID(media.ID)
+}
+
+// readObject reads a stored file, up to the size limit of its purpose
+func (s *MediaService) readObject(purpose model.MediaPurpose, key string) ([]byte, error) {
+	reader, err := s.storeFor(purpose).Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, s.MaxSize(purpose)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	return data, nil
+}
+
+func (s *MediaService) GetMedia(ownerID, mediaID string) (*model.Media, error) {
+	return s.getOwned(ownerID, mediaID)
+}
+
+// DeleteMedia removes an upload that is no longer attached to anything
+func (s *MediaService) DeleteMedia(ownerID, mediaID string) error {
+	media, err := s.getOwned(ownerID, mediaID)
+	if err != nil {
+		return err
+	}
+
+	inUse, err := s.mediaRepo.IsReferenced(media)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return fmt.Errorf("invalid request: media is still in use")
+	}
+
+	if err := s.deleteObjects(media); err != nil {
+		return err
+	}
+	return s.mediaRepo.Delete(media.ID)
+}
+
+// Other users' media is reported as missing so IDs cannot be probed
+func (s *MediaService) getOwned(ownerID, mediaID string) (*model.Media, error) {
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if media.OwnerID != ownerID {
+		return nil, fmt.Errorf("media not found")
+	}
+	return media, nil
+}
+
+// Attach resolves media IDs sent with a post, profile or verification request to their
+// URLs, in order (identity documents have none). Every media must belong to the user, be uploaded for the same purpose
+// and be ready, and images must have been through the pipeline.
+func (s *MediaService) Attach(ownerID string, purpose model.MediaPurpose, mediaIDs []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(mediaIDs))
+	for _, id := range mediaIDs {
+		if _, ok := seen[id]; ok {
+			return nil, fmt.Errorf("invalid media: %s is attached more than once", id)
+		}
+		seen[id] = struct{}{}
+	}
+
+	media, err := s.mediaRepo.GetByIDs(mediaIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(media) != len(mediaIDs) {
+		return nil, fmt.Errorf("media not found")
+	}
+
+	urls := make([]string, 0, len(media))
+	for _, m := range media {
+		if m.OwnerID != ownerID {
+			return nil, fmt.Errorf("access denied: you can only attach your own media")
+		}
+		if m.Purpose != purpose {
+			return nil, fmt.Errorf("invalid media: %s was uploaded for %s, not %s", m.ID, m.Purpose, purpose)
+		}
+		if m.Status != model.MediaStatusReady {
+			return nil, fmt.Errorf("invalid media: upload of %s has not been completed", m.ID)
+		}
+		switch m.ProcessingStatus {
+		case model.MediaProcessingDone, model.MediaProcessingSkipped:
+		case model.MediaProcessingFailed:
+			return nil, fmt.Errorf("invalid media: %s could not be processed, upload it again", m.ID)
+		default:
+			return nil, fmt.Errorf("invalid media: %s is still being processed", m.ID)
+		}
+		urls = append(urls, m.URL)
+	}
+	return urls, nil
+}
+
+// AttachOne is Attach for a single media ID
+func (s *MediaService) AttachOne(ownerID string, purpose model.MediaPurpose, mediaID string) (string, error) {
+	urls, err := s.Attach(ownerID, purpose, []string{mediaID})
+	if err != nil {
+		return "", err
+	}
+	return urls[0], nil
+}
+
+// CleanupPending deletes presigned uploads that were never completed
+func (s *MediaService) CleanupPending() (int, error) {
+	stale, err := s.mediaRepo.GetStalePending(time.Now().Add(-pendingMediaTTL), 500)
+	if err != nil {
+		return 0, err
+	}
+	for i := range stale {
+		s.discard(&stale[i])
+	}
+	return len(stale), nil
+}
+
+// deleteObjects removes the original and every resized variant from storage
+func (s *MediaService) deleteObjects(media *model.Media) error {
+	store := s.storeFor(media.Purpose)
+	if media.Status == model.MediaStatusPending {
+		if err := store.Delete(stagingKey(media.StorageKey)); err != nil {
+			return err
+		}
+	}
+	for _, v := range media.Variants {
+		if v.Key == "" {
+			continue
+		}
+		if err := store.Delete(v.Key); err != nil {
+			return err
+		}
+	}
+	return store.Delete(media.StorageKey)
+}
+
+// Purge deletes media and their files whether or not something still references them.
+// Used when documents reach the end of their retention period.
+func (s *MediaService) Purge(mediaIDs []string) error {
+	media, err := s.mediaRepo.GetByIDs(mediaIDs)
+	if err != nil {
+		return err
+	}
+	for i := range media {
+		if err := s.deleteObjects(&media[i]); err != nil {
+			return fmt.Errorf("failed to delete media objects of %s: %w", media[i].ID, err)
+		}
+		if err := s.mediaRepo.Delete(media[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeOwned deletes every upload of ownerID (avatar, post images, identity documents),
+// used when the account is deleted
+func (s *MediaService) PurgeOwned(ownerID string) error {
+	for {
+		ids, err := s.mediaRepo.GetIDsByOwner(ownerID, 100)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := s.Purge(ids); err != nil {
+			return err
+		}
+	}
+}
+
+// PurgeByURLs is Purge for files referenced by URL (verification requests submitted before
+// documents moved to private storage)
+func (s *MediaService) PurgeByURLs(urls []string) error {
+	ids, err := s.mediaRepo.GetIDsByURLs(urls)
+	if err != nil {
+		return err
+	}
+	return s.Purge(ids)
+}
+
+// ImportPublicDocument copies an identity document kept in public storage (requests
+// submitted before documents moved to private storage) to the private storage as a new
+// media of ownerID. The public file is left in place until DeletePublicFile.
+func (s *MediaService) ImportPublicDocument(ownerID, publicURL string) (*model.Media, error) {
+	key, err := s.publicKey(publicURL)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := s.storage.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("media not found: %s", publicURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", publicURL, err)
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", publicURL, err)
+	}
+
+	purpose := model.MediaPurposeIdentityDocument
+	// Documents of that time were not sniffed: keep whatever was uploaded
+	contentType := http.DetectContentType(data[:min(len(data), sniffLen)])
+	contentHash := sha256.Sum256(data)
+	media := &model.Media{
+		ID:          uuid.New().String(),
+		OwnerID:     ownerID,
+		Purpose:     purpose,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		ContentHash: hex.EncodeToString(contentHash[:]),
+		Status:      model.MediaStatusReady,
+
+		ProcessingStatus: model.MediaProcessingPending,
+	}
+	media.StorageKey = storageKey(purpose, media.ID, allowedMediaTypes[purpose][contentType])
+
+	if err := s.private.Put(media.StorageKey, bytes.NewReader(data), media.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+	if err := s.mediaRepo.Create(media); err != nil {
+		s.private.Delete(media.StorageKey)
+		return nil, err
+	}
+	return media, nil
+}
+
+// DeletePublicFile deletes a file of the public storage by URL, with its media and
+// variants when it has one
+func (s *MediaService) DeletePublicFile(publicURL string) error {
+	ids, err := s.mediaRepo.GetIDsByURLs([]string{publicURL})
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		return s.Purge(ids)
+	}
+	key, err := s.publicKey(publicURL)
+	if err != nil {
+		return err
+	}
+	return s.storage.Delete(key)
+}
+
+// publicKey returns the storage key behind a URL of the public storage: the key of its
+// media when it has one, the key derived from the URL otherwise
+func (s *MediaService) publicKey(publicURL string) (string, error) {
+	ids, err := s.mediaRepo.GetIDsByURLs([]string{publicURL})
+	if err != nil {
+		return "", err
+	}
+	if len(ids) > 0 {
+		media, err := s.mediaRepo.GetByID(ids[0])
+		if err != nil {
+			return "", err
+		}
+		return media.StorageKey, nil
+	}
+
+	base := s.storage.URL("")
+	if !strings.HasPrefix(publicURL, base) {
+		return "", fmt.Errorf("invalid media: %s is not in the media storage", publicURL)
+	}
+	key, err := url.PathUnescape(strings.TrimPrefix(publicURL, base))
+	if err != nil || key == "" {
+		return "", fmt.Errorf("invalid media: %s is not in the media storage", publicURL)
+	}
+	return key, nil
+}
+
+// ReleaseUnused deletes the given media that nothing references any more, e.g. the images
+// of purged posts. Media still used elsewhere are kept.
+func (s *MediaService) ReleaseUnused(mediaIDs []string) {
+	if len(mediaIDs) == 0 {
+		return
+	}
+	media, err := s.mediaRepo.GetByIDs(mediaIDs)
+	if err != nil {
+		log.Printf("Failed to load media to release: %v", err)
+		return
+	}
+	for i := range media {
+		inUse, err := s.mediaRepo.IsReferenced(&media[i])
+		if err != nil {
+			log.Printf("Failed to check media %s: %v", media[i].ID, err)
+			continue
+		}
+		if !inUse {
+			s.discard(&media[i])
+		}
+	}
+}
+
+// SignPrivate returns the query string that lets viewerID read a private media until the
+// returned time. The URL is bound to the viewer so that access can be attributed.
+func (s *MediaService) SignPrivate(mediaID, viewerID string, ttl time.Duration) (string, time.Time) {
+	expiresAt := time.Now().Add(ttl)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{
+		"viewer":    {viewerID},
+		"expires":   {expires},
+		"signature": {s.readSignature(mediaID, viewerID, expires)},
+	}
+	return query.Encode(), expiresAt
+}
+
+func (s *MediaService) readSignature(mediaID, viewerID, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.SigningSecret))
+	mac.Write([]byte("read\n" + mediaID + "\n" + viewerID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// OpenPrivate checks a signature made by SignPrivate and opens the decrypted file
+func (s *MediaService) OpenPrivate(mediaID, viewerID, expires, signature string) (*model.Media, io.ReadCloser, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(s.readSignature(mediaID, viewerID, expires))) {
+		return nil, nil, fmt.Errorf("access denied: invalid signature")
+	}
+	if time.Now().Unix() > expiresAt {
+		return nil, nil, fmt.Errorf("access denied: link has expired")
+	}
+
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if media.Purpose != model.MediaPurposeIdentityDocument {
+		return nil, nil, fmt.Errorf("media not found")
+	}
+	reader, err := s.private.Get(media.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, fmt.Errorf("media not found")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read media: %w", err)
+	}
+	return media, reader, nil
+}
+
+// Fingerprint returns the SHA-256 of a stored file as uploaded and, for images, a
+// perceptual hash that survives re-encoding and resizing
+func (s *MediaService) Fingerprint(mediaID string) (string, *uint64, error) {
+	media, err := s.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := s.readObject(media.Purpose, media.StorageKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	contentHash := media.ContentHash
+	if contentHash == "" {
+		sum := sha256.Sum256(data)
+		contentHash = hex.EncodeToString(sum[:])
+	}
+	if !processableFormats[media.ContentType] {
+		return contentHash, nil, nil
+	}
+
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		// Matched on the exact hash only
+		return contentHash, nil, nil
+	}
+	hash := imaging.DHash(imaging.Orient(img, imaging.Orientation(data, format)))
+	return contentHash, &hash, nil
+}
+
+// GetExportable returns the avatars and post images uploaded by ownerID, for the data
+// export. Identity documents are left out.
+func (s *MediaService) GetExportable(ownerID string) ([]model.Media, error) {
+	return s.mediaRepo.GetReadyByOwner(ownerID, []model.MediaPurpose{model.MediaPurposePost, model.MediaPurposeAvatar})
+}
+
+// Open opens the original file of a media as uploaded
+func (s *MediaService) Open(media *model.Media) (io.ReadCloser, error) {
+	reader, err := s.storeFor(media.Purpose).Get(media.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("media not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media: %w", err)
+	}
+	return reader, nil
+}
+
+// mediaFileName is the name of a media in archives: its ID with the extension of its type
+func mediaFileName(mediaID, contentType string) string {
+	for _, types := range allowedMediaTypes {
+		if ext, ok := types[contentType]; ok {
+			return mediaID + ext
+		}
+	}
+	return mediaID
+}
+
+// GetByIDs returns the media with the given IDs, in order
+func (s *MediaService) GetByIDs(mediaIDs []string) ([]model.Media, error) {
+	return s.mediaRepo.GetByIDs(mediaIDs)
+}
+
+func (s *MediaService) discard(media *model.Media) {
+	if err := s.deleteObjects(media); err != nil {
+		log.Printf("Failed to delete media objects of %s: %v", media.ID, err)
+	}
+	if err := s.mediaRepo.Delete(media.ID); err != nil {
+		log.Printf("Failed to delete media %s: %v", media.ID, err)
+	}
+}
+
+// PopulatePosts fills in the attached media (with variants and dimensions) of each post
+func (s *MediaService) PopulatePosts(posts []model.Post) {
+	ptrs := make([]*model.Post, len(posts))
+	for i := range posts {
+		ptrs[i] = &posts[i]
+	}
+	s.populatePosts(ptrs)
+}
+
+func (s *MediaService) PopulatePost(post *model.Post) {
+	s.populatePosts([]*model.Post{post})
+}
+
+// PopulateDraft fills in the images of a draft the same way as for a post
+func (s *MediaService) PopulateDraft(draft *model.PostDraft) {
+	post := &model.Post{MediaIDs: draft.MediaIDs}
+	s.populatePosts([]*model.Post{post})
+	draft.Media = post.Media
+}
+
+// PopulateRevisions fills in the images of earlier versions of a post
+func (s *MediaService) PopulateRevisions(revisions []model.PostRevision) {
+	posts := make([]*model.Post, len(revisions))
+	for i := range revisions {
+		posts[i] = &model.Post{MediaIDs: revisions[i].MediaIDs}
+	}
+	s.populatePosts(posts)
+	for i := range revisions {
+		revisions[i].Media = posts[i].Media
+	}
+}
+
+func (s *MediaService) populatePosts(posts []*model.Post) {
+	var ids []string
+	for _, post := range posts {
+		ids = append(ids, post.MediaIDs...)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	media, err := s.mediaRepo.GetByIDs(ids)
+	if err != nil {
+		log.Printf("Failed to load post media: %v", err)
+		return
+	}
+	byID := make(map[string]*model.Media, len(media))
+	for i := range media {
+		byID[media[i].ID] = &media[i]
+	}
+
+	for _, post := range posts {
+		for _, id := range post.MediaIDs {
+			if m, ok := byID[id]; ok {
+				post.Media = append(post.Media, m.Attachment())
+			}
+		}
+	}
+}
+
+// PopulateProfile fills in the avatar variants of a profile
+func (s *MediaService) PopulateProfile(profile *model.UserProfile) {
+	if profile == nil || profile.AvatarMediaID == nil {
+		return
+	}
+	media, err := s.mediaRepo.GetByID(*profile.AvatarMediaID)
+	if err != nil {
+		return
+	}
+	avatar := media.Attachment()
+	profile.Avatar = &avatar
+}
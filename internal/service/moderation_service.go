@@ -0,0 +1,466 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/config"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/utils"
+)
+
+type ModerationService struct {
+	reportRepo          *repository.ReportRepository
+	postRepo            *repository.PostRepository
+	commentRepo         *repository.CommentRepository
+	userRepo            *repository.UserRepository
+	notificationService *NotificationService
+	suspensionService   *SuspensionService
+	searchService       *SearchService
+	retention           *config.RetentionConfig
+}
+
+func NewModerationService(reportRepo *repository.ReportRepository, postRepo *repository.PostRepository,
+	commentRepo *repository.CommentRepository, userRepo *repository.UserRepository,
+	notificationService *NotificationService, suspensionService *SuspensionService, searchService *SearchService,
+	retention *config.RetentionConfig) *ModerationService {
+	return &ModerationService{
+		reportRepo:          reportRepo,
+		postRepo:            postRepo,
+		commentRepo:         commentRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		suspensionService:   suspensionService,
+		searchService:       searchService,
+		retention:           retention,
+	}
+}
+
+// Outcome messages sent to reporters once their report is resolved
+var reportOutcomeMessages = map[model.ModerationActionType]string{
+	model.ModerationActionHide:    "Thanks for your report. The content you reported has been hidden.",
+	model.ModerationActionDelete:  "Thanks for your report. The content you reported has been removed.",
+	model.ModerationActionWarn:    "Thanks for your report. The account responsible has received a warning.",
+	model.ModerationActionSuspend: "Thanks for your report. The account responsible has been suspended.",
+	model.ModerationActionDismiss: "Thanks for your report. We reviewed it and found no violation of our community guidelines.",
+}
+
+func (s *ModerationService) CreateReport(reporterID string, req *model.CreateReportRequest) (*model.Report, error) {
+	if req.TargetType == model.ReportTargetUser && req.TargetID == reporterID {
+		return nil, fmt.Errorf("invalid report: you cannot report yourself")
+	}
+
+	// Make sure the target exists and find who is responsible for it
+	ownerID, err := s.reportRepo.GetTargetOwner(req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerID != nil && *ownerID == reporterID {
+		return nil, fmt.Errorf("invalid report: you cannot report your own content")
+	}
+
+	hasPending, err := s.reportRepo.HasPendingReport(reporterID, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasPending {
+		return nil, fmt.Errorf("report already exists for this content")
+	}
+
+	report := &model.Report{
+		ID:          uuid.New().String(),
+		ReporterID:  &reporterID,
+		Source:      model.ReportSourceUser,
+		TargetType:  req.TargetType,
+		TargetID:    req.TargetID,
+		TargetOwner: ownerID,
+		Reason:      req.Reason,
+		Details:     req.Details,
+		Status:      model.ReportStatusPending,
+		CreatedAt:   time.Now(),
+	}
+
+	err = s.reportRepo.Create(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit report: %w", err)
+	}
+
+	return report, nil
+}
+
+func (s *ModerationService) GetReport(reportID string) (*model.Report, error) {
+	report, err := s.reportRepo.GetByID(reportID)
+	if err != nil {
+		return nil, fmt.Errorf("report not found")
+	}
+
+	if report.ReporterID != nil {
+		reporter, err := s.userRepo.GetProfile(*report.ReporterID, nil)
+		if err == nil {
+			report.Reporter = reporter
+		}
+	}
+
+	return report, nil
+}
+
+func (s *ModerationService) GetPendingReports(pagination *utils.PaginationParams) (*model.ReportsResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	reports, totalCount, err := s.reportRepo.GetPending(paginationResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending reports: %w", err)
+	}
+
+	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
+
+	return &model.ReportsResponse{
+		Reports:    reports,
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    hasMore,
+	}, nil
+}
+
+func (s *ModerationService) GetAllReports(status *model.ReportStatus, targetType *model.ReportTarget, pagination *utils.PaginationParams) (*model.ReportsResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	reports, totalCount, err := s.reportRepo.GetAll(status, targetType, paginationResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports: %w", err)
+	}
+
+	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
+
+	return &model.ReportsResponse{
+		Reports:    reports,
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    hasMore,
+	}, nil
+}
+
+func (s *ModerationService) GetModerationStats() (map[string]interface{}, error) {
+	stats, err := s.reportRepo.GetStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (s *ModerationService) GetActions(targetType *model.ReportTarget, targetID *string, pagination *utils.PaginationParams) (*model.ModerationActionsResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	actions, totalCount, err := s.reportRepo.GetActions(targetType, targetID, paginationResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation actions: %w", err)
+	}
+
+	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
+
+	return &model.ModerationActionsResponse{
+		Actions:    actions,
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// How long a claim on a report protects it; a claim left by a crashed request expires after it
+const reportClaimTimeout = 10 * time.Minute
+
+// TakeAction applies a moderator decision to the reported content, resolves every
+// pending report against the same target, records the audit entry and notifies
+// the reporters and the content owner. The reports are claimed first, so two
+// moderators cannot act on the same content at once, and the action, the audit entry
+// and the resolution are written in one transaction, so a failed attempt leaves
+// nothing applied and can simply be retried.
+func (s *ModerationService) TakeAction(reportID, moderatorID string, req *model.ModerationActionRequest) (*model.Report, error) {
+	report, err := s.reportRepo.GetByID(reportID)
+	if err != nil {
+		return nil, fmt.Errorf("report not found")
+	}
+
+	if err := s.reportRepo.ClaimTarget(report, moderatorID, time.Now().Add(-reportClaimTimeout)); err != nil {
+		return nil, err
+	}
+
+	action := &model.ModerationAction{
+		ID:            uuid.New().String(),
+		ReportID:      &report.ID,
+		ModeratorID:   moderatorID,
+		Action:        req.Action,
+		TargetType:    report.TargetType,
+		TargetID:      report.TargetID,
+		Notes:         req.Notes,
+		DurationHours: req.DurationHours,
+		CreatedAt:     time.Now(),
+	}
+
+	status := model.ReportStatusActioned
+	if req.Action == model.ModerationActionDismiss {
+		status = model.ReportStatusDismissed
+	}
+
+	var effects []func()
+	resolved, err := s.reportRepo.ResolveTarget(action, status, reportOutcomeMessages[req.Action], func(tx *repository.ModerationTx) error {
+		effects, err = s.applyAction(tx, report, moderatorID, req)
+		return err
+	})
+	if err != nil {
+		if releaseErr := s.reportRepo.ReleaseClaim(report.TargetType, report.TargetID, moderatorID); releaseErr != nil {
+			fmt.Printf("Failed to release report claim: %v\n", releaseErr)
+		}
+		return nil, err
+	}
+	for _, effect := range effects {
+		effect()
+	}
+
+	// Let every reporter know the outcome (once per reporter)
+	notified := map[string]struct{}{}
+	var reporterIDs []string
+	for _, r := range resolved {
+		if r.ReporterID == nil {
+			continue
+		}
+		if _, ok := notified[*r.ReporterID]; ok {
+			continue
+		}
+		notified[*r.ReporterID] = struct{}{}
+		reporterIDs = append(reporterIDs, *r.ReporterID)
+	}
+	s.notificationService.NotifyMany(reporterIDs, model.NotificationReportOutcome,
+		"Update on your report", reportOutcomeMessages[req.Action], &report.ID)
+
+	return s.GetReport(reportID)
+}
+
+// applyAction writes the action through the transaction's repositories and returns what to
+// do once it is committed (notifications, search index, emails)
+func (s *ModerationService) applyAction(tx *repository.ModerationTx, report *model.Report, moderatorID string, req *model.ModerationActionRequest) ([]func(), error) {
+	var effects []func()
+	notifyOwner := func(notificationType model.NotificationType, title, message string) {
+		effects = append(effects, func() { s.notifyOwner(report, notificationType, title, message) })
+	}
+
+	switch req.Action {
+	case model.ModerationActionHide:
+		switch report.TargetType {
+		case model.ReportTargetPost:
+			if err := tx.Posts.SetHidden(report.TargetID, true); err != nil {
+				return nil, err
+			}
+			effects = append(effects, func() { s.searchService.RemovePost(report.TargetID) })
+		case model.ReportTargetComment:
+			if err := tx.Comments.SetHidden(report.TargetID, true); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid action: cannot hide a %s", report.TargetType)
+		}
+		notifyOwner(model.NotificationContentRemoved, "Your content has been hidden",
+			fmt.Sprintf("Your %s was hidden for violating our community guidelines (%s).", report.TargetType, report.Reason))
+
+	case model.ModerationActionDelete:
+		switch report.TargetType {
+		case model.ReportTargetPost:
+			if err := tx.Posts.DeleteByID(report.TargetID, moderatorID); err != nil {
+				return nil, err
+			}
+			effects = append(effects, func() { s.searchService.RemovePost(report.TargetID) })
+		case model.ReportTargetComment:
+			if err := tx.Comments.DeleteByID(report.TargetID, moderatorID); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid action: cannot delete a %s", report.TargetType)
+		}
+		notifyOwner(model.NotificationContentRemoved, "Your content has been removed",
+			fmt.Sprintf("Your %s was removed for violating our community guidelines (%s).", report.TargetType, report.Reason))
+
+	case model.ModerationActionWarn:
+		if report.TargetOwner == nil {
+			return nil, fmt.Errorf("invalid action: reported %s has no known owner", report.TargetType)
+		}
+		message := fmt.Sprintf("You have received a warning for violating our community guidelines (%s).", report.Reason)
+		if req.Notes != nil && *req.Notes != "" {
+			message += " Moderator note: " + *req.Notes
+		}
+		notifyOwner(model.NotificationModerationWarning, "Community guidelines warning", message)
+
+	case model.ModerationActionSuspend:
+		if report.TargetOwner == nil {
+			return nil, fmt.Errorf("invalid action: reported %s has no known owner", report.TargetType)
+		}
+		reason := fmt.Sprintf("Violation of community guidelines (%s)", report.Reason)
+		if req.Notes != nil && *req.Notes != "" {
+			reason += ": " + *req.Notes
+		}
+		user, after, err := s.suspensionService.suspend(tx.Users, tx.Auth, moderatorID, *report.TargetOwner, &model.SuspendUserRequest{
+			Status:        model.AccountStatusSuspended,
+			Reason:        reason,
+			DurationHours: req.DurationHours,
+		})
+		if err != nil {
+			return nil, err
+		}
+		effects = append(effects, after)
+		notifyOwner(model.NotificationAccountSuspended, "Your account has been suspended",
+			fmt.Sprintf("Your account is suspended until %s for violating our community guidelines (%s).",
+				user.SuspendedUntil.UTC().Format("2006-01-02 15:04"), report.Reason))
+
+	case model.ModerationActionDismiss:
+		// Content held by a content rule is published once a moderator clears it
+		held, err := s.reportRepo.HasPendingAutoReport(report.TargetType, report.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		if held {
+			switch report.TargetType {
+			case model.ReportTargetPost:
+				if err := tx.Posts.SetHidden(report.TargetID, false); err != nil {
+					return nil, err
+				}
+				effects = append(effects, func() { s.reindexPost(report.TargetID) })
+			case model.ReportTargetComment:
+				if err := tx.Comments.SetHidden(report.TargetID, false); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid moderation action: %s", req.Action)
+	}
+
+	return effects, nil
+}
+
+// GetDeletedPosts lists deleted posts kept until they are purged, whoever deleted them
+func (s *ModerationService) GetDeletedPosts(filter *model.DeletedContentFilter, pagination *utils.PaginationParams) (*model.DeletedPostsResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	posts, totalCount, err := s.postRepo.GetDeleted(nil, filter.Source, nil, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeletedPostsResponse{
+		Posts:      deletedPosts(posts, s.retention),
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+func (s *ModerationService) GetDeletedPost(postID string) (*model.DeletedPost, error) {
+	post, err := s.postRepo.GetDeletedByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	return &deletedPosts([]model.Post{*post}, s.retention)[0], nil
+}
+
+// GetDeletedComments lists deleted comments kept until they are purged, whoever deleted them
+func (s *ModerationService) GetDeletedComments(filter *model.DeletedContentFilter, pagination *utils.PaginationParams) (*model.DeletedCommentsResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	comments, totalCount, err := s.commentRepo.GetDeleted(nil, filter.Source, nil, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeletedCommentsResponse{
+		Comments:   deletedComments(comments, s.retention),
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+func (s *ModerationService) GetDeletedComment(commentID string) (*model.DeletedComment, error) {
+	comment, err := s.commentRepo.GetDeletedByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	return &deletedComments([]model.Comment{*comment}, s.retention)[0], nil
+}
+
+// RestorePost brings back a deleted post (removed by mistake, or on appeal) and records it in
+// the audit trail
+func (s *ModerationService) RestorePost(postID, moderatorID string) (*model.Post, error) {
+	deleted, err := s.postRepo.GetDeletedByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.postRepo.RestoreByID(postID); err != nil {
+		return nil, err
+	}
+	s.recordRestore(moderatorID, model.ReportTargetPost, postID)
+
+	// Loaded as its author so that a post hidden by moderation is returned too
+	post, err := s.postRepo.GetByID(postID, &deleted.UserID)
+	if err != nil {
+		return nil, err
+	}
+	s.searchService.IndexPost(post)
+	return post, nil
+}
+
+func (s *ModerationService) RestoreComment(commentID, moderatorID string) (*model.Comment, error) {
+	deleted, err := s.commentRepo.GetDeletedByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.commentRepo.RestoreByID(commentID); err != nil {
+		return nil, err
+	}
+	s.recordRestore(moderatorID, model.ReportTargetComment, commentID)
+
+	return s.commentRepo.GetByID(commentID, &deleted.UserID)
+}
+
+func (s *ModerationService) recordRestore(moderatorID string, targetType model.ReportTarget, targetID string) {
+	action := &model.ModerationAction{
+		ID:          uuid.New().String(),
+		ModeratorID: moderatorID,
+		Action:      model.ModerationActionRestore,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.reportRepo.CreateAction(action); err != nil {
+		fmt.Printf("Failed to record moderation action: %v\n", err)
+	}
+}
+
+func (s *ModerationService) notifyOwner(report *model.Report, notificationType model.NotificationType, title, message string) {
+	if report.TargetOwner == nil {
+		return
+	}
+	s.notificationService.Notify(*report.TargetOwner, notificationType, title, message, &report.TargetID)
+}
+
+// reindexPost adds a post released by a moderator to the search index and its hashtag counts
+func (s *ModerationService) reindexPost(postID string) {
+	ownerID, err := s.reportRepo.GetTargetOwner(model.ReportTargetPost, postID)
+	if err != nil || ownerID == nil {
+		return
+	}
+	post, err := s.postRepo.GetByID(postID, ownerID)
+	if err != nil {
+		return
+	}
+	s.searchService.IndexPost(post)
+}
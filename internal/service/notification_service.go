@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/utils"
+)
+
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// Notify creates an in-app notification. Failures are logged rather than returned
+// so that a notification problem never fails the action that triggered it.
+func (s *NotificationService) Notify(userID string, notificationType model.NotificationType, title, message string, entityID *string) {
+	notification := &model.Notification{
+		ID:       uuid.New().String(),
+		UserID:   userID,
+		Type:     notificationType,
+		Title:    title,
+		Message:  message,
+		EntityID: entityID,
+	}
+
+	if err := s.notificationRepo.Create(notification); err != nil {
+		fmt.Printf("Failed to create notification for user %s: %v\n", userID, err)
+	}
+}
+
+// NotifyMany sends the same notification to several users
+func (s *NotificationService) NotifyMany(userIDs []string, notificationType model.NotificationType, title, message string, entityID *string) {
+	notifications := make([]model.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notifications = append(notifications, model.Notification{
+			ID:       uuid.New().String(),
+			UserID:   userID,
+			Type:     notificationType,
+			Title:    title,
+			Message:  message,
+			EntityID: entityID,
+		})
+	}
+
+	if err := s.notificationRepo.CreateBatch(notifications); err != nil {
+		fmt.Printf("Failed to create notifications: %v\n", err)
+	}
+}
+
+func (s *NotificationService) GetNotifications(userID string, unreadOnly bool, pagination *utils.PaginationParams) (*model.NotificationsResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	notifications, totalCount, err := s.notificationRepo.GetUserNotifications(userID, unreadOnly, paginationResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+
+	unreadCount, err := s.notificationRepo.GetUnreadCount(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unread count: %w", err)
+	}
+
+	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
+
+	return &model.NotificationsResponse{
+		Notifications: notifications,
+		TotalCount:    totalCount,
+		UnreadCount:   unreadCount,
+		Page:          paginationResult.Page,
+		PageSize:      paginationResult.PageSize,
+		HasMore:       hasMore,
+	}, nil
+}
+
+func (s *NotificationService) MarkAsRead(notificationID, userID string) error {
+	return s.notificationRepo.MarkAsRead(notificationID, userID)
+}
+
+func (s *NotificationService) MarkAllAsRead(userID string) error {
+	return s.notificationRepo.MarkAllAsRead(userID)
+}
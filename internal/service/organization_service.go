@@ -0,0 +1,297 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/utils"
+	"vietick-backend/pkg/email"
+	"vietick-backend/pkg/jwt"
+
+	"github.com/google/uuid"
+)
+
+type OrganizationService struct {
+	organizationRepo *repository.OrganizationRepository
+	userRepo         *repository.UserRepository
+	jwtManager       *jwt.JWTManager
+	emailService     *email.EmailService
+	searchService    *SearchService
+	mediaService     *MediaService
+}
+
+func NewOrganizationService(organizationRepo *repository.OrganizationRepository, userRepo *repository.UserRepository,
+	jwtManager *jwt.JWTManager, emailService *email.EmailService, searchService *SearchService,
+	mediaService *MediaService) *OrganizationService {
+	return &OrganizationService{
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+		jwtManager:       jwtManager,
+		emailService:     emailService,
+		searchService:    searchService,
+		mediaService:     mediaService,
+	}
+}
+
+// CreateOrganization creates an organization account run by the user, who becomes its owner
+func (s *OrganizationService) CreateOrganization(ownerID string, req *model.CreateOrganizationRequest) (*model.Organization, error) {
+	owner, err := s.userRepo.GetByID(ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if owner.IsOrganization() {
+		return nil, fmt.Errorf("invalid request: organizations cannot create organizations")
+	}
+
+	if existing, _ := s.userRepo.GetByEmail(req.Email); existing != nil {
+		return nil, fmt.Errorf("user with this email already exists")
+	}
+	if existing, _ := s.userRepo.GetByUsername(req.Username); existing != nil {
+		return nil, fmt.Errorf("user with this username already exists")
+	}
+
+	// The contact address is confirmed the same way as a user's email
+	verificationToken, err := utils.GenerateEmailVerificationToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	expiresAt := time.Now().Add(24 * time.Hour)
+
+	// No password: nobody logs in as the organization, members act on its behalf
+	organization := &model.User{
+		ID:                         uuid.New().String(),
+		Username:                   req.Username,
+		AccountType:                model.AccountTypeOrganization,
+		Email:                      req.Email,
+		FullName:                   req.FullName,
+		Bio:                        req.Bio,
+		EmailVerificationToken:     &verificationToken,
+		EmailVerificationExpiresAt: &expiresAt,
+		IdentityVerificationStatus: model.IdentityVerificationNone,
+		AccountStatus:              model.AccountStatusActive,
+	}
+	err = s.organizationRepo.Create(organization, &model.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         ownerID,
+		Role:           model.OrganizationRoleOwner,
+		AddedBy:        &ownerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.searchService.IndexUser(organization)
+
+	if err := s.emailService.SendEmailVerification(organization.Email, organization.FullName, verificationToken); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Failed to send verification email: %v\n", err)
+	}
+
+	profile, err := s.userRepo.GetProfile(organization.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Organization{UserProfile: *profile, Role: model.OrganizationRoleOwner}, nil
+}
+
+// GetUserOrganizations lists the organizations the user can act on behalf of
+func (s *OrganizationService) GetUserOrganizations(userID string) ([]model.Organization, error) {
+	organizations, err := s.organizationRepo.GetOrganizations(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range organizations {
+		s.mediaService.PopulateProfile(&organizations[i].UserProfile)
+	}
+	return organizations, nil
+}
+
+// GetMembers lists the members of the organization; only members can see them
+func (s *OrganizationService) GetMembers(organizationID, viewerID string) (*model.OrganizationMembersResponse, error) {
+	if _, err := s.requireRole(organizationID, viewerID, model.OrganizationRoleEditor); err != nil {
+		return nil, err
+	}
+
+	members, err := s.organizationRepo.GetMembers(organizationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range members {
+		if profile, err := s.userRepo.GetProfile(members[i].UserID, nil); err == nil {
+			s.mediaService.PopulateProfile(profile)
+			members[i].User = profile
+		}
+	}
+	return &model.OrganizationMembersResponse{OrganizationID: organizationID, Members: members}, nil
+}
+
+// AddMember adds a user to the organization. Owners add any role, admins add editors.
+func (s *OrganizationService) AddMember(organizationID, actorID string, req *model.AddOrganizationMemberRequest) (*model.OrganizationMember, error) {
+	actor, err := s.requireRole(organizationID, actorID, model.OrganizationRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Role.CanManage(req.Role) {
+		return nil, fmt.Errorf("forbidden: only owners can add members with the %s role", req.Role)
+	}
+
+	user, err := s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if user.IsOrganization() {
+		return nil, fmt.Errorf("invalid request: an organization cannot be a member of another organization")
+	}
+	if err := checkAccountAccess(user); err != nil {
+		return nil, fmt.Errorf("invalid request: the account cannot be added while it is restricted")
+	}
+
+	isMember, err := s.organizationRepo.IsMember(organizationID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		return nil, fmt.Errorf("organization member already exists")
+	}
+
+	member := &model.OrganizationMember{
+		OrganizationID: organizationID,
+		UserID:         req.UserID,
+		Role:           req.Role,
+		AddedBy:        &actorID,
+	}
+	if err := s.organizationRepo.AddMember(member); err != nil {
+		return nil, err
+	}
+	return s.getMember(organizationID, req.UserID)
+}
+
+// UpdateMemberRole changes the role of a member. The organization always keeps an owner.
+func (s *OrganizationService) UpdateMemberRole(organizationID, actorID, userID string, req *model.UpdateOrganizationMemberRequest) (*model.OrganizationMember, error) {
+	actor, err := s.requireRole(organizationID, actorID, model.OrganizationRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.organizationRepo.GetMember(organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Role.CanManage(member.Role) || !actor.Role.CanManage(req.Role) {
+		return nil, fmt.Errorf("forbidden: only owners can manage admins and owners")
+	}
+	if member.Role == req.Role {
+		return s.getMember(organizationID, userID)
+	}
+	if member.Role == model.OrganizationRoleOwner {
+		if err := s.checkRemainingOwner(organizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.organizationRepo.UpdateRole(organizationID, userID, req.Role); err != nil {
+		return nil, err
+	}
+	return s.getMember(organizationID, userID)
+}
+
+// RemoveMember removes a member from the organization. Members can always leave, except
+// the last owner.
+func (s *OrganizationService) RemoveMember(organizationID, actorID, userID string) error {
+	var actor *model.OrganizationMember
+	if actorID != userID {
+		var err error
+		if actor, err = s.requireRole(organizationID, actorID, model.OrganizationRoleAdmin); err != nil {
+			return err
+		}
+	}
+	member, err := s.organizationRepo.GetMember(organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if actor != nil && !actor.Role.CanManage(member.Role) {
+		return fmt.Errorf("forbidden: only owners can remove admins and owners")
+	}
+	if member.Role == model.OrganizationRoleOwner {
+		if err := s.checkRemainingOwner(organizationID); err != nil {
+			return err
+		}
+	}
+
+	return s.organizationRepo.RemoveMember(organizationID, userID)
+}
+
+// IssueToken returns an access token of the member scoped to the organization
+func (s *OrganizationService) IssueToken(organizationID, userID string) (*model.OrganizationTokenResponse, error) {
+	member, err := s.ResolveActingAs(organizationID, userID, model.OrganizationRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	accessToken, err := s.jwtManager.GenerateOrganizationToken(user, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	return &model.OrganizationTokenResponse{
+		AccessToken:    accessToken,
+		OrganizationID: organizationID,
+		Role:           member.Role,
+		ExpiresIn:      int64(s.jwtManager.GetAccessTokenExpiry().Seconds()),
+	}, nil
+}
+
+// ResolveActingAs checks that the user may act on behalf of the organization with at
+// least the given role, and that the organization is not suspended or banned
+func (s *OrganizationService) ResolveActingAs(organizationID, userID string, minRole model.OrganizationRole) (*model.OrganizationMember, error) {
+	member, err := s.requireRole(organizationID, userID, minRole)
+	if err != nil {
+		return nil, err
+	}
+	organization, err := s.userRepo.GetByID(organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("organization not found")
+	}
+	if err := checkAccountAccess(organization); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// requireRole returns the user's membership if the user has at least the given role
+func (s *OrganizationService) requireRole(organizationID, userID string, minRole model.OrganizationRole) (*model.OrganizationMember, error) {
+	member, err := s.organizationRepo.GetMember(organizationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("forbidden: you are not a member of this organization")
+	}
+	if !member.Role.AtLeast(minRole) {
+		return nil, fmt.Errorf("forbidden: this action requires the %s role in the organization", minRole)
+	}
+	return member, nil
+}
+
+func (s *OrganizationService) checkRemainingOwner(organizationID string) error {
+	owners, err := s.organizationRepo.CountOwners(organizationID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return fmt.Errorf("invalid request: the organization must keep at least one owner")
+	}
+	return nil
+}
+
+func (s *OrganizationService) getMember(organizationID, userID string) (*model.OrganizationMember, error) {
+	member, err := s.organizationRepo.GetMember(organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile, err := s.userRepo.GetProfile(userID, nil); err == nil {
+		s.mediaService.PopulateProfile(profile)
+		member.User = profile
+	}
+	return member, nil
+}
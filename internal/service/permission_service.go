@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+)
+
+type PermissionService struct {
+	permissionRepo *repository.PermissionRepository
+	userRepo       *repository.UserRepository
+}
+
+func NewPermissionService(permissionRepo *repository.PermissionRepository, userRepo *repository.UserRepository) *PermissionService {
+	return &PermissionService{
+		permissionRepo: permissionRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// HasPermission reports whether the user may perform the action. The admin account
+// has every permission.
+func (s *PermissionService) HasPermission(userID string, permission model.Permission) (bool, error) {
+	if userID == model.AdminUserID {
+		return true, nil
+	}
+	return s.permissionRepo.Has(userID, permission)
+}
+
+// GetUsersWithPermission lists the users granted the permission. The admin account is only
+// included if it was granted the permission explicitly.
+func (s *PermissionService) GetUsersWithPermission(permission model.Permission) ([]string, error) {
+	return s.permissionRepo.GetUserIDs(permission)
+}
+
+func (s *PermissionService) GetUserPermissions(userID string) (*model.UserPermissionsResponse, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+	permissions, err := s.permissionRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.UserPermissionsResponse{UserID: userID, Permissions: permissions}, nil
+}
+
+func (s *PermissionService) GrantPermission(adminID, userID string, permission model.Permission) (*model.UserPermissionsResponse, error) {
+	if !permission.IsKnown() {
+		return nil, fmt.Errorf("invalid permission: %s", permission)
+	}
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+	err := s.permissionRepo.Grant(&model.UserPermission{
+		UserID:     userID,
+		Permission: permission,
+		GrantedBy:  adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUserPermissions(userID)
+}
+
+func (s *PermissionService) RevokePermission(userID string, permission model.Permission) error {
+	return s.permissionRepo.Revoke(userID, permission)
+}
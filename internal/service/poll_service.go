@@ -0,0 +1,258 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+)
+
+const (
+	// A poll stays open at least this long and at most pollMaxDuration
+	pollMinDuration = 5 * time.Minute
+	pollMaxDuration = 30 * 24 * time.Hour
+	// Polls whose closing is handled per run of the background job
+	pollCloseBatchSize = 100
+)
+
+// PollService handles polls attached to posts: voting, tallies and closing
+type PollService struct {
+	pollRepo            *repository.PollRepository
+	postRepo            *repository.PostRepository
+	notificationService *NotificationService
+}
+
+func NewPollService(pollRepo *repository.PollRepository, postRepo *repository.PostRepository,
+	notificationService *NotificationService) *PollService {
+	return &PollService{
+		pollRepo:            pollRepo,
+		postRepo:            postRepo,
+		notificationService: notificationService,
+	}
+}
+
+// NewPoll checks a poll request and builds the poll to save with post postID
+func (s *PollService) NewPoll(postID string, req *model.CreatePollRequest) (*model.Poll, error) {
+	now := time.Now()
+	if req.ClosesAt.Before(now.Add(pollMinDuration)) {
+		return nil, fmt.Errorf("invalid request: a poll must stay open at least %d minutes", int(pollMinDuration/time.Minute))
+	}
+	if req.ClosesAt.After(now.Add(pollMaxDuration)) {
+		return nil, fmt.Errorf("invalid request: a poll can stay open at most %d days", int(pollMaxDuration/(24*time.Hour)))
+	}
+	if len(req.Options) < model.PollMinOptions || len(req.Options) > model.PollMaxOptions {
+		return nil, fmt.Errorf("invalid request: a poll has %d to %d options", model.PollMinOptions, model.PollMaxOptions)
+	}
+
+	poll := &model.Poll{
+		ID:             uuid.New().String(),
+		PostID:         postID,
+		MultipleChoice: req.MultipleChoice,
+		HideResults:    req.HideResults,
+		ClosesAt:       req.ClosesAt.UTC(),
+	}
+	seen := make(map[string]bool, len(req.Options))
+	for i, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, fmt.Errorf("invalid request: poll options cannot be empty")
+		}
+		key := strings.ToLower(text)
+		if seen[key] {
+			return nil, fmt.Errorf("invalid request: poll options must be different")
+		}
+		seen[key] = true
+		poll.Options = append(poll.Options, model.PollOption{
+			ID:       uuid.New().String(),
+			PollID:   poll.ID,
+			Position: i + 1,
+			Text:     text,
+		})
+	}
+	return poll, nil
+}
+
+// Vote replaces the user's ballot in the poll of a post they can see
+func (s *PollService) Vote(postID, userID string, req *model.PollVoteRequest) (*model.Poll, error) {
+	poll, err := s.getPoll(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Closed(time.Now()) {
+		return nil, fmt.Errorf("invalid request: the poll is closed")
+	}
+
+	seen := make(map[string]bool, len(req.OptionIDs))
+	for _, optionID := range req.OptionIDs {
+		if seen[optionID] {
+			return nil, fmt.Errorf("invalid request: an option can only be chosen once")
+		}
+		seen[optionID] = true
+	}
+	if !poll.MultipleChoice && len(req.OptionIDs) != 1 {
+		return nil, fmt.Errorf("invalid request: this poll allows a single choice")
+	}
+
+	if err := s.pollRepo.Vote(poll.ID, userID, req.OptionIDs, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.GetPoll(postID, userID)
+}
+
+// RemoveVote withdraws the user's ballot while the poll is open
+func (s *PollService) RemoveVote(postID, userID string) (*model.Poll, error) {
+	poll, err := s.getPoll(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Closed(time.Now()) {
+		return nil, fmt.Errorf("invalid request: the poll is closed")
+	}
+
+	if err := s.pollRepo.RemoveVote(poll.ID, userID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.GetPoll(postID, userID)
+}
+
+// GetPoll returns the poll of a post as userID sees it
+func (s *PollService) GetPoll(postID, userID string) (*model.Poll, error) {
+	poll, err := s.getPoll(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.prepare([]*model.Poll{poll}, &userID)
+	return poll, nil
+}
+
+// getPoll returns the poll of a post userID can see
+func (s *PollService) getPoll(postID, userID string) (*model.Poll, error) {
+	if _, err := s.postRepo.GetByID(postID, &userID); err != nil {
+		return nil, err
+	}
+	return s.pollRepo.GetByPostID(postID)
+}
+
+// PopulatePosts attaches the poll of each post, with the viewer's vote
+func (s *PollService) PopulatePosts(posts []model.Post, viewerID *string) {
+	if len(posts) == 0 {
+		return
+	}
+	postIDs := make([]string, len(posts))
+	for i := range posts {
+		postIDs[i] = posts[i].ID
+	}
+	polls, err := s.pollRepo.GetByPostIDs(postIDs)
+	if err != nil {
+		log.Printf("Failed to load polls: %v", err)
+		return
+	}
+	if len(polls) == 0 {
+		return
+	}
+
+	list := make([]*model.Poll, 0, len(polls))
+	for i := range posts {
+		if poll, ok := polls[posts[i].ID]; ok {
+			posts[i].Poll = poll
+			list = append(list, poll)
+		}
+	}
+	s.prepare(list, viewerID)
+}
+
+// PopulatePost attaches the poll of a post, with the viewer's vote
+func (s *PollService) PopulatePost(post *model.Post, viewerID *string) {
+	if post == nil {
+		return
+	}
+	posts := []model.Post{*post}
+	s.PopulatePosts(posts, viewerID)
+	post.Poll = posts[0].Poll
+}
+
+// prepare sets the fields that depend on the time and the viewer, and hides the tallies of
+// open polls with hidden results
+func (s *PollService) prepare(polls []*model.Poll, viewerID *string) {
+	now := time.Now()
+	pollIDs := make([]string, len(polls))
+	for i, poll := range polls {
+		pollIDs[i] = poll.ID
+		poll.IsClosed = poll.Closed(now)
+		if poll.HideResults && !poll.IsClosed {
+			poll.ResultsHidden = true
+			for j := range poll.Options {
+				poll.Options[j].VoteCount = 0
+			}
+		}
+	}
+
+	if viewerID == nil || *viewerID == "" {
+		return
+	}
+	votes, err := s.pollRepo.GetViewerVotes(*viewerID, pollIDs)
+	if err != nil {
+		log.Printf("Failed to load poll votes: %v", err)
+		return
+	}
+	for _, poll := range polls {
+		poll.MyVote = votes[poll.ID]
+	}
+}
+
+// CloseDue handles polls that have closed: the author and the voters are notified once.
+// Returns how many polls were closed.
+func (s *PollService) CloseDue() (int, error) {
+	now := time.Now()
+	polls, err := s.pollRepo.GetDueToClose(now, pollCloseBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, poll := range polls {
+		claimed, err := s.pollRepo.MarkClosed(poll.ID, now)
+		if err != nil {
+			return closed, err
+		}
+		// Another instance handled it
+		if !claimed {
+			continue
+		}
+		closed++
+		s.notifyClosed(&poll)
+	}
+	return closed, nil
+}
+
+func (s *PollService) notifyClosed(poll *model.Poll) {
+	// Authors of posts hidden by moderation are notified too
+	posts, err := s.postRepo.GetByIDs([]string{poll.PostID})
+	if err != nil || len(posts) == 0 {
+		log.Printf("Failed to load post of poll %s: %v", poll.ID, err)
+		return
+	}
+	post := posts[0]
+	s.notificationService.Notify(post.UserID, model.NotificationPollClosed,
+		"Your poll has closed", "The final results of your poll are available.", &post.ID)
+
+	voterIDs, err := s.pollRepo.GetVoterIDs(poll.ID)
+	if err != nil {
+		log.Printf("Failed to load voters of poll %s: %v", poll.ID, err)
+		return
+	}
+	recipients := make([]string, 0, len(voterIDs))
+	for _, voterID := range voterIDs {
+		if voterID != post.UserID {
+			recipients = append(recipients, voterID)
+		}
+	}
+	if len(recipients) > 0 {
+		s.notificationService.NotifyMany(recipients, model.NotificationPollClosed,
+			"A poll you voted in has closed", "The final results of the poll are available.", &post.ID)
+	}
+}
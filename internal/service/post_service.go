@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
+	"vietick-backend/internal/config"
 	"vietick-backend/internal/model"
 	"vietick-backend/internal/repository"
 	"vietick-backend/internal/utils"
@@ -12,12 +14,30 @@ import (
 )
 
 type PostService struct {
-	postRepo *repository.PostRepository
+	postRepo           *repository.PostRepository
+	contentPolicy      *ContentPolicyService
+	searchService      *SearchService
+	mediaService       *MediaService
+	pollService        *PollService
+	linkPreviewService *LinkPreviewService
+	analyticsService   *AnalyticsService
+	editConfig         *config.EditConfig
+	retention          *config.RetentionConfig
 }
 
-func NewPostService(postRepo *repository.PostRepository) *PostService {
+func NewPostService(postRepo *repository.PostRepository, contentPolicy *ContentPolicyService, searchService *SearchService,
+	mediaService *MediaService, pollService *PollService, linkPreviewService *LinkPreviewService, analyticsService *AnalyticsService,
+	editConfig *config.EditConfig, retention *config.RetentionConfig) *PostService {
 	return &PostService{
-		postRepo: postRepo,
+		postRepo:           postRepo,
+		contentPolicy:      contentPolicy,
+		searchService:      searchService,
+		mediaService:       mediaService,
+		pollService:        pollService,
+		linkPreviewService: linkPreviewService,
+		analyticsService:   analyticsService,
+		editConfig:         editConfig,
+		retention:          retention,
 	}
 }
 
@@ -36,29 +56,81 @@ func extractHashtags(content string) []string {
 	return result
 }
 
-func (s *PostService) CreatePost(userID string, req *model.CreatePostRequest) (*model.Post, error) {
+// CreatePost publishes a post as userID; actingUserID is the member writing on behalf of an
+// organization (nil otherwise)
+func (s *PostService) CreatePost(userID string, actingUserID *string, req *model.CreatePostRequest) (*model.Post, error) {
 	post := &model.Post{
 		ID: uuid.New().String(),
 		UserID: userID,
+		ActingUserID: actingUserID,
 		Content: req.Content,
-		ImageURLs: model.ImageURLs(req.ImageURLs),
+		MediaIDs: model.ImageURLs(req.MediaIDs),
+	}
+	if req.Poll != nil {
+		poll, err := s.pollService.NewPoll(post.ID, req.Poll)
+		if err != nil {
+			return nil, err
+		}
+		post.Poll = poll
+	}
+	decision, err := s.preparePost(post)
+	if err != nil {
+		return nil, err
 	}
 
-	err := s.postRepo.Create(post)
+	err = s.postRepo.Create(post)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
 
+	return s.afterCreate(post, decision)
+}
+
+// preparePost runs the content filter and resolves the images of a post about to be saved
+func (s *PostService) preparePost(post *model.Post) (*model.ContentDecision, error) {
+	// Chạy bộ lọc nội dung trước khi lưu
+	decision, err := s.contentPolicy.Check(post.UserID, post.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ảnh chỉ lấy từ media của chính người đăng
+	imageURLs, err := s.mediaService.Attach(post.UserID, model.MediaPurposePost, post.MediaIDs)
+	if err != nil {
+		return nil, err
+	}
+	post.ImageURLs = model.ImageURLs(imageURLs)
+	post.IsHidden = decision != nil
+
+	return decision, nil
+}
+
+// afterCreate runs the side effects of a newly published post (moderation queue, hashtags,
+// search index) and returns it as the author sees it
+func (s *PostService) afterCreate(post *model.Post, decision *model.ContentDecision) (*model.Post, error) {
+	s.contentPolicy.AfterSave(model.ReportTargetPost, post.ID, post.UserID, post.Content, decision)
+
 	// Xử lý hashtag
-	hashtags := extractHashtags(req.Content)
+	hashtags := extractHashtags(post.Content)
 	if len(hashtags) > 0 {
-		err = s.postRepo.AddHashtagsToPost(post.ID, hashtags)
+		err := s.postRepo.AddHashtagsToPost(post.ID, hashtags)
 		if err != nil {
 			return nil, fmt.Errorf("failed to add hashtags: %w", err)
 		}
 	}
 
-	return s.postRepo.GetByID(post.ID, &userID)
+	s.linkPreviewService.AttachToPost(post.ID, post.Content)
+
+	created, err := s.postRepo.GetByID(post.ID, &post.UserID)
+	if err != nil {
+		return nil, err
+	}
+	s.searchService.IndexPost(created)
+	s.mediaService.PopulatePost(created)
+	s.pollService.PopulatePost(created, &post.UserID)
+	s.linkPreviewService.PopulatePost(created)
+
+	return created, nil
 }
 
 func (s *PostService) GetPost(postID string, userID *string) (*model.Post, error) {
@@ -66,10 +138,21 @@ func (s *PostService) GetPost(postID string, userID *string) (*model.Post, error
 	if err != nil {
 		return nil, fmt.Errorf("post not found")
 	}
+	s.populatePost(post, userID)
+	if userID != nil {
+		s.analyticsService.RecordView(post, *userID)
+	}
 
 	return post, nil
 }
 
+// populatePost gắn media, bình chọn và link preview vào bài viết
+func (s *PostService) populatePost(post *model.Post, viewerID *string) {
+	s.mediaService.PopulatePost(post)
+	s.pollService.PopulatePost(post, viewerID)
+	s.linkPreviewService.PopulatePost(post)
+}
+
 func (s *PostService) UpdatePost(postID, userID string, req *model.UpdatePostRequest) (*model.Post, error) {
 	// First check if the post exists and belongs to the user
 	existingPost, err := s.postRepo.GetByID(postID, &userID)
@@ -80,18 +163,57 @@ func (s *PostService) UpdatePost(postID, userID string, req *model.UpdatePostReq
 	if existingPost.UserID != userID {
 		return nil, fmt.Errorf("you can only edit your own posts")
 	}
+	if err := s.checkEditWindow(existingPost); err != nil {
+		return nil, err
+	}
+	// Lưu lại mà không thay đổi gì thì không tạo phiên bản mới
+	if existingPost.Content == req.Content && sameStrings(existingPost.MediaIDs, req.MediaIDs) {
+		// Tác giả lưu bài của mình không tính là một lượt xem
+		s.populatePost(existingPost, &userID)
+		return existingPost, nil
+	}
+
+	decision, err := s.contentPolicy.Check(userID, req.Content)
+	if err != nil {
+		return nil, err
+	}
 
+	imageURLs, err := s.mediaService.Attach(userID, model.MediaPurposePost, req.MediaIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	oldHashtags, err := s.postRepo.GetHashtagsByPost(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hashtags: %w", err)
+	}
+	revision := &model.PostRevision{
+		ID:        uuid.New().String(),
+		PostID:    postID,
+		Revision:  existingPost.RevisionCount,
+		Content:   existingPost.Content,
+		ImageURLs: existingPost.ImageURLs,
+		MediaIDs:  existingPost.MediaIDs,
+		Hashtags:  hashtagNames(oldHashtags),
+		CreatedAt: versionTime(existingPost.CreatedAt, existingPost.EditedAt),
+	}
+
+	now := time.Now()
 	post := &model.Post{
 		ID:        postID,
 		UserID:    userID,
 		Content:   req.Content,
-		ImageURLs: model.ImageURLs(req.ImageURLs),
+		ImageURLs: model.ImageURLs(imageURLs),
+		MediaIDs:  model.ImageURLs(req.MediaIDs),
+		IsHidden:  existingPost.IsHidden || decision != nil,
+		EditedAt:  &now,
 	}
 
-	err = s.postRepo.Update(post)
+	err = s.postRepo.UpdateWithRevision(post, revision)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update post: %w", err)
+		return nil, err
 	}
+	s.contentPolicy.AfterSave(model.ReportTargetPost, postID, userID, req.Content, decision)
 
 	// Xử lý hashtag (cập nhật lại toàn bộ hashtag cho post)
 	hashtags := extractHashtags(req.Content)
@@ -111,18 +233,154 @@ func (s *PostService) UpdatePost(postID, userID string, req *model.UpdatePostReq
 		}
 	}
 
-	return s.postRepo.GetByID(postID, &userID)
+	s.linkPreviewService.AttachToPost(postID, req.Content)
+
+	updated, err := s.postRepo.GetByID(postID, &userID)
+	if err != nil {
+		return nil, err
+	}
+	s.searchService.IndexPost(updated)
+	s.populatePost(updated, &userID)
+
+	return updated, nil
 }
 
-func (s *PostService) DeletePost(postID, userID string) error {
-	err := s.postRepo.Delete(postID, userID)
+// GetPostHistory trả về mọi phiên bản của bài viết, phiên bản hiện tại trước
+func (s *PostService) GetPostHistory(postID string, viewerID *string) (*model.PostHistoryResponse, error) {
+	post, err := s.postRepo.GetByID(postID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("post not found")
+	}
+	hashtags, err := s.postRepo.GetHashtagsByPost(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hashtags: %w", err)
+	}
+	previous, err := s.postRepo.GetRevisions(postID)
 	if err != nil {
-		return fmt.Errorf("failed to delete post: %w", err)
+		return nil, err
 	}
 
+	revisions := append([]model.PostRevision{{
+		PostID:    postID,
+		Revision:  post.RevisionCount,
+		Content:   post.Content,
+		ImageURLs: post.ImageURLs,
+		MediaIDs:  post.MediaIDs,
+		Hashtags:  hashtagNames(hashtags),
+		CreatedAt: versionTime(post.CreatedAt, post.EditedAt),
+	}}, previous...)
+	s.mediaService.PopulateRevisions(revisions)
+
+	return &model.PostHistoryResponse{
+		PostID:        postID,
+		RevisionCount: post.RevisionCount,
+		EditedAt:      post.EditedAt,
+		Revisions:     revisions,
+	}, nil
+}
+
+// checkEditWindow: bài viết chỉ được sửa trong một khoảng thời gian sau khi đăng, ngắn hơn
+// khi bài viết đã có nhiều tương tác
+func (s *PostService) checkEditWindow(post *model.Post) error {
+	age := time.Since(post.CreatedAt)
+	window := s.editConfig.PostWindowMinutes
+	if window > 0 && age > time.Duration(window)*time.Minute {
+		return fmt.Errorf("forbidden: posts can only be edited within %d minutes of publishing", window)
+	}
+
+	threshold := s.editConfig.EngagementThreshold
+	engaged := s.editConfig.EngagedPostWindowMinutes
+	if threshold > 0 && post.ReactionCounts.Total()+post.CommentCount >= threshold && age > time.Duration(engaged)*time.Minute {
+		return fmt.Errorf("forbidden: posts with %d or more reactions and comments can only be edited within %d minutes of publishing",
+			threshold, engaged)
+	}
 	return nil
 }
 
+// DeletePost xoá mềm bài viết; tác giả có thể khôi phục trong thời hạn cho phép.
+// actingUserID là thành viên xoá thay cho tổ chức (nil nếu không có).
+func (s *PostService) DeletePost(postID, userID string, actingUserID *string) error {
+	deletedBy := userID
+	if actingUserID != nil {
+		deletedBy = *actingUserID
+	}
+	err := s.postRepo.Delete(postID, userID, deletedBy)
+	if err != nil {
+		return err
+	}
+	s.searchService.RemovePost(postID)
+
+	return nil
+}
+
+// GetDeletedPosts lists the posts the user deleted and can still restore
+func (s *PostService) GetDeletedPosts(userID string, pagination *utils.PaginationParams) (*model.DeletedPostsResponse, error) {
+	paginationResult := pagination.Calculate()
+	source := model.DeletionByAuthor
+	since := time.Now().Add(-restoreWindow(s.retention))
+
+	posts, totalCount, err := s.postRepo.GetDeleted(&userID, &source, &since, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+	s.mediaService.PopulatePosts(posts)
+	s.pollService.PopulatePosts(posts, &userID)
+	s.linkPreviewService.PopulatePosts(posts)
+
+	return &model.DeletedPostsResponse{
+		Posts:      deletedPosts(posts, s.retention),
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+// RestorePost brings back a post its author deleted, with its likes, comments and hashtags
+func (s *PostService) RestorePost(postID, userID string) (*model.Post, error) {
+	deleted, err := s.postRepo.GetDeletedByID(postID)
+	if err != nil || deleted.UserID != userID {
+		return nil, fmt.Errorf("deleted post not found")
+	}
+	if deleted.DeletionSource != nil && *deleted.DeletionSource == model.DeletionByModeration {
+		return nil, fmt.Errorf("forbidden: the post was removed by a moderator")
+	}
+	since := time.Now().Add(-restoreWindow(s.retention))
+	if deleted.DeletedAt.Time.Before(since) {
+		return nil, fmt.Errorf("invalid request: posts can only be restored within %d days of deletion", s.retention.RestoreWindowDays)
+	}
+
+	if err := s.postRepo.Restore(postID, userID, since); err != nil {
+		return nil, err
+	}
+	post, err := s.postRepo.GetByID(postID, &userID)
+	if err != nil {
+		return nil, err
+	}
+	s.searchService.IndexPost(post)
+	s.mediaService.PopulatePost(post)
+	s.pollService.PopulatePost(post, &userID)
+	s.linkPreviewService.PopulatePost(post)
+	return post, nil
+}
+
+// PurgeDeleted xoá hẳn bài viết đã hết thời hạn lưu giữ, cùng ảnh không còn được dùng
+func (s *PostService) PurgeDeleted() (int, error) {
+	before := time.Now().Add(-retentionPeriod(s.retention))
+	purged := 0
+	for {
+		postIDs, mediaIDs, err := s.postRepo.PurgeDeleted(before, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged += len(postIDs)
+		s.mediaService.ReleaseUnused(mediaIDs)
+		if len(postIDs) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
 func (s *PostService) GetFeed(userID string, pagination *utils.PaginationParams) (*model.PostsResponse, error) {
 	paginationResult := pagination.Calculate()
 
@@ -130,6 +388,11 @@ func (s *PostService) GetFeed(userID string, pagination *utils.PaginationParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feed: %w", err)
 	}
+	s.mediaService.PopulatePosts(posts)
+	s.pollService.PopulatePosts(posts, &userID)
+	s.linkPreviewService.PopulatePosts(posts)
+	s.populateViewerState(posts, &userID)
+	s.analyticsService.RecordImpressions(posts, userID)
 
 	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
 
@@ -149,6 +412,13 @@ func (s *PostService) GetUserPosts(userID string, viewerID *string, pagination *
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user posts: %w", err)
 	}
+	s.mediaService.PopulatePosts(posts)
+	s.pollService.PopulatePosts(posts, viewerID)
+	s.linkPreviewService.PopulatePosts(posts)
+	s.populateViewerState(posts, viewerID)
+	if viewerID != nil {
+		s.analyticsService.RecordImpressions(posts, *viewerID)
+	}
 
 	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
 
@@ -195,12 +465,96 @@ func (s *PostService) IsPostLikedByUser(postID, userID string) (bool, error) {
 	return s.postRepo.IsPostLikedByUser(postID, userID)
 }
 
+// React sets the user's reaction to a post they can see, replacing their previous reaction
+func (s *PostService) React(postID, userID string, req *model.ReactRequest) (*model.ReactionResponse, error) {
+	if _, err := s.postRepo.GetByID(postID, &userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.postRepo.React(postID, userID, req.Reaction); err != nil {
+		return nil, err
+	}
+	return s.reactionResponse(postID, userID)
+}
+
+func (s *PostService) RemoveReaction(postID, userID string) (*model.ReactionResponse, error) {
+	if err := s.postRepo.Unreact(postID, userID, nil); err != nil {
+		return nil, err
+	}
+	return s.reactionResponse(postID, userID)
+}
+
+func (s *PostService) reactionResponse(postID, userID string) (*model.ReactionResponse, error) {
+	post, err := s.postRepo.GetByID(postID, &userID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ReactionResponse{
+		Reaction:       post.Reaction,
+		LikeCount:      post.LikeCount,
+		ReactionCounts: post.ReactionCounts,
+	}, nil
+}
+
+// GetReactions lists who reacted to a post, newest first
+func (s *PostService) GetReactions(postID string, viewerID *string, filter *model.ReactionsFilter, pagination *utils.PaginationParams) (*model.ReactionsResponse, error) {
+	post, err := s.postRepo.GetByID(postID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	paginationResult := pagination.Calculate()
+
+	reactions, totalCount, err := s.postRepo.GetReactions(postID, filter.Type, paginationResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ReactionsResponse{
+		Reactions:      reactions,
+		ReactionCounts: post.ReactionCounts,
+		TotalCount:     totalCount,
+		Page:           paginationResult.Page,
+		PageSize:       paginationResult.PageSize,
+		HasMore:        utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize),
+	}, nil
+}
+
+// populateViewerState sets the viewer's own reaction and bookmark on each post
+func (s *PostService) populateViewerState(posts []model.Post, viewerID *string) {
+	if viewerID == nil || *viewerID == "" || len(posts) == 0 {
+		return
+	}
+	postIDs := make([]string, len(posts))
+	for i := range posts {
+		postIDs[i] = posts[i].ID
+	}
+	if reactions, err := s.postRepo.GetViewerReactions(*viewerID, postIDs); err == nil {
+		for i := range posts {
+			if reaction, ok := reactions[posts[i].ID]; ok {
+				posts[i].Reaction = &reaction
+				posts[i].IsLiked = reaction == model.ReactionLike
+			}
+		}
+	}
+	if bookmarked, err := s.postRepo.GetViewerBookmarks(*viewerID, postIDs); err == nil {
+		for i := range posts {
+			posts[i].IsBookmarked = bookmarked[posts[i].ID]
+		}
+	}
+}
+
 func (s *PostService) GetExplorePosts(userID *string, pagination *utils.PaginationParams) (*model.PostsResponse, error) {
 	paginationResult := pagination.Calculate()
 	posts, totalCount, err := s.postRepo.GetUserPosts("", userID, paginationResult) // userID rỗng để lấy tất cả
 	if err != nil {
 		return nil, fmt.Errorf("failed to get explore posts: %w", err)
 	}
+	s.mediaService.PopulatePosts(posts)
+	s.pollService.PopulatePosts(posts, userID)
+	s.linkPreviewService.PopulatePosts(posts)
+	s.populateViewerState(posts, userID)
+	if userID != nil {
+		s.analyticsService.RecordImpressions(posts, *userID)
+	}
 
 	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
 
@@ -220,29 +574,43 @@ func (s *PostService) GetPostStats(postID string) (map[string]interface{}, error
 	}
 
 	stats := map[string]interface{}{
-		"like_count":    post.LikeCount,
-		"comment_count": post.CommentCount,
-		"created_at":    post.CreatedAt,
-		"has_images":    len(post.ImageURLs) > 0,
-		"image_count":   len(post.ImageURLs),
+		"like_count":       post.LikeCount,
+		"reaction_counts":  post.ReactionCounts,
+		"reaction_count":   post.ReactionCounts.Total(),
+		"comment_count":    post.CommentCount,
+		"impression_count": post.ImpressionCount,
+		"view_count":       post.ViewCount,
+		"created_at":       post.CreatedAt,
+		"has_images":       len(post.ImageURLs) > 0,
+		"image_count":      len(post.ImageURLs),
 	}
 
 	return stats, nil
 }
 
+// GetPostAnalytics trả về lượt hiển thị, lượt xem, reaction và bình luận theo giờ hoặc theo ngày;
+// chỉ tác giả mới xem được
+func (s *PostService) GetPostAnalytics(postID, userID string, filter *model.PostAnalyticsFilter) (*model.PostAnalytics, error) {
+	post, err := s.postRepo.GetByID(postID, &userID)
+	if err != nil {
+		return nil, fmt.Errorf("post not found")
+	}
+	if post.UserID != userID {
+		return nil, fmt.Errorf("forbidden: only the author can see the analytics of a post")
+	}
+	return s.analyticsService.GetPostAnalytics(post, filter)
+}
+
+// SearchPosts tìm kiếm qua chỉ mục toàn văn, hỗ trợ "cụm từ", #hashtag, from:, since:, until:, has:image
 func (s *PostService) SearchPosts(query string, page, pageSize int) (*model.PostsResponse, error) {
-	posts, totalCount, err := s.postRepo.SearchPosts(query, page, pageSize)
+	response, err := s.searchService.SearchPosts(query, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
-	hasMore := utils.CalculateHasMore(totalCount, page, pageSize)
-	return &model.PostsResponse{
-		Posts:      posts,
-		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
-		HasMore:    hasMore,
-	}, nil
+	s.mediaService.PopulatePosts(response.Posts)
+	s.pollService.PopulatePosts(response.Posts, nil)
+	s.linkPreviewService.PopulatePosts(response.Posts)
+	return response, nil
 }
 
 // SearchPostsByContent chỉ theo content
@@ -251,6 +619,9 @@ func (s *PostService) SearchPostsByContent(query string, page, pageSize int) (*m
 	if err != nil {
 		return nil, err
 	}
+	s.mediaService.PopulatePosts(posts)
+	s.pollService.PopulatePosts(posts, nil)
+	s.linkPreviewService.PopulatePosts(posts)
 	hasMore := utils.CalculateHasMore(totalCount, page, pageSize)
 	return &model.PostsResponse{
 		Posts:      posts,
@@ -268,14 +639,100 @@ func (s *PostService) GetHashtagsByPost(postID string) ([]model.Hashtag, error)
 
 // Lấy danh sách post theo hashtag
 func (s *PostService) GetPostsByHashtag(hashtag string, limit, offset int) ([]model.Post, error) {
-	return s.postRepo.GetPostsByHashtag(hashtag, limit, offset)
+	posts, err := s.postRepo.GetPostsByHashtag(hashtag, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	s.mediaService.PopulatePosts(posts)
+	s.pollService.PopulatePosts(posts, nil)
+	s.linkPreviewService.PopulatePosts(posts)
+	return posts, nil
 }
 
 func (s *PostService) SearchHashtags(query string, page, pageSize int) ([]model.Hashtag, int64, bool, error) {
-	hashtags, totalCount, err := s.postRepo.SearchHashtags(query, page, pageSize)
-	if err != nil {
-		return nil, 0, false, err
+	return s.searchService.SearchHashtags(query, page, pageSize)
+}
+
+func hashtagNames(hashtags []model.Hashtag) model.ImageURLs {
+	names := make(model.ImageURLs, len(hashtags))
+	for i, h := range hashtags {
+		names[i] = h.Name
 	}
-	hasMore := utils.CalculateHasMore(totalCount, page, pageSize)
-	return hashtags, totalCount, hasMore, nil
+	return names
+}
+
+// versionTime là thời điểm phiên bản hiện tại được đăng: lần sửa gần nhất, hoặc lúc tạo
+func versionTime(createdAt time.Time, editedAt *time.Time) time.Time {
+	if editedAt != nil {
+		return *editedAt
+	}
+	return createdAt
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+const purgeBatchSize = 100
+
+// restoreWindow is how long after deleting it an author can restore a post or comment
+func restoreWindow(cfg *config.RetentionConfig) time.Duration {
+	return time.Duration(cfg.RestoreWindowDays) * 24 * time.Hour
+}
+
+// retentionPeriod is how long deleted posts and comments are kept; never shorter than the
+// restore window
+func retentionPeriod(cfg *config.RetentionConfig) time.Duration {
+	days := cfg.DeletedContentDays
+	if days < cfg.RestoreWindowDays {
+		days = cfg.RestoreWindowDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func describeDeletion(deletedAt time.Time, deletedBy *string, source *model.DeletionSource, cfg *config.RetentionConfig) model.Deletion {
+	deletion := model.Deletion{
+		DeletedAt: deletedAt,
+		DeletedBy: deletedBy,
+		Source:    model.DeletionByAuthor,
+		PurgeAt:   deletedAt.Add(retentionPeriod(cfg)),
+	}
+	if source != nil {
+		deletion.Source = *source
+	}
+	if deletion.Source == model.DeletionByAuthor {
+		until := deletedAt.Add(restoreWindow(cfg))
+		deletion.RestorableUntil = &until
+	}
+	return deletion
+}
+
+func deletedPosts(posts []model.Post, cfg *config.RetentionConfig) []model.DeletedPost {
+	result := make([]model.DeletedPost, len(posts))
+	for i, p := range posts {
+		result[i] = model.DeletedPost{
+			Post:     p,
+			Deletion: describeDeletion(p.DeletedAt.Time, p.DeletedBy, p.DeletionSource, cfg),
+		}
+	}
+	return result
+}
+
+func deletedComments(comments []model.Comment, cfg *config.RetentionConfig) []model.DeletedComment {
+	result := make([]model.DeletedComment, len(comments))
+	for i, c := range comments {
+		result[i] = model.DeletedComment{
+			Comment:  c,
+			Deletion: describeDeletion(c.DeletedAt.Time, c.DeletedBy, c.DeletionSource, cfg),
+		}
+	}
+	return result
 }
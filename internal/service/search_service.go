@@ -0,0 +1,412 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/search"
+	"vietick-backend/internal/utils"
+)
+
+// At most this many ranked matches are considered for a single query
+const maxSearchResults = 1000
+
+const indexBatchSize = 500
+
+// Ranking boosts for typeahead from the viewer's social graph
+const (
+	affinityFollowing  = 5.0
+	affinityFollowedBy = 2.0
+	affinityCacheTTL   = time.Minute
+)
+
+// SearchService answers post, user and hashtag searches from the search engine and keeps
+// the engine in step with the database. Until the first rebuild finishes it falls back
+// to the old LIKE queries.
+//
+// Each instance has its own index: changes made here are applied at once, changes made
+// through other instances show up at the next periodic rebuild. A rebuild fills a new
+// index and swaps it in; changes made here while it runs are replayed on the new index
+// first, so a post deleted during a rebuild is not brought back by it.
+type SearchService struct {
+	newEngine  func() search.Engine
+	postRepo   *repository.PostRepository
+	userRepo   *repository.UserRepository
+	followRepo *repository.FollowRepository
+	ready      atomic.Bool
+
+	rebuildMu  sync.Mutex
+	engineMu   sync.RWMutex
+	engine     search.Engine
+	rebuilding bool
+	pending    []indexChange
+
+	affinityMu    sync.Mutex
+	affinityCache map[string]affinityEntry
+}
+
+type affinityEntry struct {
+	boosts    map[string]float64
+	expiresAt time.Time
+}
+
+// indexChange is a change made while a rebuild runs, replayed on the new index
+type indexChange struct {
+	followers bool
+	apply     func(search.Engine)
+}
+
+// NewSearchService takes a constructor so that every rebuild starts from an empty index
+func NewSearchService(newEngine func() search.Engine, postRepo *repository.PostRepository, userRepo *repository.UserRepository,
+	followRepo *repository.FollowRepository) *SearchService {
+	return &SearchService{
+		newEngine:     newEngine,
+		engine:        newEngine(),
+		postRepo:      postRepo,
+		userRepo:      userRepo,
+		followRepo:    followRepo,
+		affinityCache: make(map[string]affinityEntry),
+	}
+}
+
+// Rebuild indexes every user and post in the database into a new index and swaps it in
+func (s *SearchService) Rebuild() error {
+	s.rebuildMu.Lock()
+	defer s.rebuildMu.Unlock()
+	start := time.Now()
+
+	s.engineMu.Lock()
+	s.rebuilding = true
+	s.pending = nil
+	s.engineMu.Unlock()
+	defer func() {
+		s.engineMu.Lock()
+		s.rebuilding = false
+		s.pending = nil
+		s.engineMu.Unlock()
+	}()
+
+	engine := s.newEngine()
+	err := s.userRepo.ForEachUser(indexBatchSize, func(users []model.User) error {
+		for i := range users {
+			engine.IndexUser(userDocument(&users[i]))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Follows logged before the counts are read are already in them. The log is held
+	// while they are read, so only follows logged afterwards are replayed on top
+	s.engineMu.Lock()
+	followers, err := s.followRepo.GetAllFollowerCounts()
+	if err == nil {
+		kept := s.pending[:0]
+		for _, change := range s.pending {
+			if !change.followers {
+				kept = append(kept, change)
+			}
+		}
+		s.pending = kept
+	}
+	s.engineMu.Unlock()
+	if err != nil {
+		return err
+	}
+	for userID, count := range followers {
+		engine.SetFollowers(userID, count)
+	}
+
+	err = s.postRepo.ForEachPost(indexBatchSize, func(posts []model.Post) error {
+		for i := range posts {
+			if !posts[i].IsHidden {
+				engine.IndexPost(postDocument(&posts[i]))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.engineMu.Lock()
+	for _, change := range s.pending {
+		change.apply(engine)
+	}
+	s.engine = engine
+	s.engineMu.Unlock()
+
+	s.ready.Store(true)
+	stats := engine.Stats()
+	log.Printf("Search index built in %s (%d posts, %d users, %d hashtags)",
+		time.Since(start).Round(time.Millisecond), stats["posts"], stats["users"], stats["hashtags"])
+	return nil
+}
+
+// current returns the index searches are answered from
+func (s *SearchService) current() search.Engine {
+	s.engineMu.RLock()
+	defer s.engineMu.RUnlock()
+	return s.engine
+}
+
+// update applies a change to the live index and, while a rebuild runs, records it for the new one
+func (s *SearchService) update(followers bool, apply func(search.Engine)) {
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+	apply(s.engine)
+	if s.rebuilding {
+		s.pending = append(s.pending, indexChange{followers: followers, apply: apply})
+	}
+}
+
+// IndexPost indexes a visible post. Held and hidden posts are kept out of the index, and out
+// of the hashtag counts, until a moderator releases them.
+func (s *SearchService) IndexPost(post *model.Post) {
+	if post.IsHidden {
+		s.RemovePost(post.ID)
+		return
+	}
+	doc := postDocument(post)
+	s.update(false, func(engine search.Engine) { engine.IndexPost(doc) })
+}
+
+func postDocument(post *model.Post) search.PostDocument {
+	return search.PostDocument{
+		ID:        post.ID,
+		AuthorID:  post.UserID,
+		Content:   post.Content,
+		Hashtags:  extractHashtags(post.Content),
+		HasImage:  len(post.ImageURLs) > 0,
+		CreatedAt: post.CreatedAt,
+	}
+}
+
+func (s *SearchService) RemovePost(postID string) {
+	s.update(false, func(engine search.Engine) { engine.RemovePost(postID) })
+}
+
+func (s *SearchService) IndexUser(user *model.User) {
+	doc := userDocument(user)
+	s.update(false, func(engine search.Engine) { engine.IndexUser(doc) })
+}
+
+func userDocument(user *model.User) search.UserDocument {
+	doc := search.UserDocument{
+		ID:         user.ID,
+		Username:   user.Username,
+		FullName:   user.FullName,
+		AvatarURL:  user.AvatarURL,
+		IsVerified: user.IsVerified,
+		Hidden:     user.AccountStatus.IsHidden(),
+	}
+	if user.Bio != nil {
+		doc.Bio = *user.Bio
+	}
+	return doc
+}
+
+// ReindexUser reloads the user after a change made elsewhere (verification, ban, deactivation)
+func (s *SearchService) ReindexUser(userID string) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return
+	}
+	s.IndexUser(user)
+}
+
+func (s *SearchService) RemoveUser(userID string) {
+	s.update(false, func(engine search.Engine) { engine.RemoveUser(userID) })
+}
+
+// FollowChanged keeps typeahead follower counts and the follower's social graph current
+func (s *SearchService) FollowChanged(followerID, followingID string, followed bool) {
+	delta := -1
+	if followed {
+		delta = 1
+	}
+	s.update(true, func(engine search.Engine) { engine.AdjustFollowers(followingID, delta) })
+
+	s.affinityMu.Lock()
+	delete(s.affinityCache, followerID)
+	delete(s.affinityCache, followingID)
+	s.affinityMu.Unlock()
+}
+
+// Autocomplete returns typeahead suggestions for users, hashtags or both (kind "all").
+// Users are matched on handle and name only, never on email.
+func (s *SearchService) Autocomplete(prefix, kind string, viewerID *string, limit int) *model.AutocompleteResponse {
+	if limit < 1 || limit > 20 {
+		limit = 10
+	}
+
+	response := &model.AutocompleteResponse{
+		Users:    []model.UserSuggestion{},
+		Hashtags: []model.HashtagSuggestion{},
+	}
+
+	if kind == "all" || kind == "users" {
+		affinity := s.getAffinity(viewerID)
+		users := s.current().AutocompleteUsers(prefix, affinity, limit)
+		for i := range users {
+			users[i].IsFollowing = affinity[users[i].ID] >= affinityFollowing
+		}
+		response.Users = users
+	}
+
+	if kind == "all" || kind == "hashtags" {
+		response.Hashtags = s.current().AutocompleteHashtags(prefix, limit)
+	}
+
+	return response
+}
+
+// getAffinity returns per-user ranking boosts from the viewer's follow graph, cached briefly
+// so that each keystroke does not hit the database
+func (s *SearchService) getAffinity(viewerID *string) map[string]float64 {
+	if viewerID == nil {
+		return nil
+	}
+
+	s.affinityMu.Lock()
+	entry, ok := s.affinityCache[*viewerID]
+	s.affinityMu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.boosts
+	}
+
+	boosts := make(map[string]float64)
+	if followers, err := s.followRepo.GetFollowerIDs(*viewerID); err == nil {
+		for _, id := range followers {
+			boosts[id] = affinityFollowedBy
+		}
+	}
+	if following, err := s.followRepo.GetFollowingIDs(*viewerID); err == nil {
+		for _, id := range following {
+			boosts[id] += affinityFollowing
+		}
+	}
+
+	now := time.Now()
+	s.affinityMu.Lock()
+	if len(s.affinityCache) > 10000 {
+		for id, e := range s.affinityCache {
+			if now.After(e.expiresAt) {
+				delete(s.affinityCache, id)
+			}
+		}
+	}
+	s.affinityCache[*viewerID] = affinityEntry{boosts: boosts, expiresAt: now.Add(affinityCacheTTL)}
+	s.affinityMu.Unlock()
+
+	return boosts
+}
+
+// SearchPosts supports phrases and filters, see search.Query
+func (s *SearchService) SearchPosts(query string, page, pageSize int) (*model.PostsResponse, error) {
+	page, pageSize = normalizePage(page, pageSize)
+
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []model.Post
+	var totalCount int64
+	if !s.ready.Load() {
+		posts, totalCount, err = s.postRepo.SearchPosts(query, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ids := s.current().SearchPosts(q, maxSearchResults)
+
+		// The index does not track moderation, so drop hidden posts and banned authors here
+		visible, err := s.postRepo.FilterVisibleIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		var visibleIDs []string
+		for _, id := range ids {
+			if visible[id] {
+				visibleIDs = append(visibleIDs, id)
+			}
+		}
+
+		totalCount = int64(len(visibleIDs))
+		posts, err = s.postRepo.GetByIDs(pageOf(visibleIDs, page, pageSize))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.PostsResponse{
+		Posts:      posts,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+		HasMore:    utils.CalculateHasMore(totalCount, page, pageSize),
+	}, nil
+}
+
+func (s *SearchService) SearchUsers(query string, page, pageSize int) ([]model.UserProfile, int64, error) {
+	page, pageSize = normalizePage(page, pageSize)
+
+	if !s.ready.Load() {
+		return s.userRepo.SearchUsers(query, page, pageSize)
+	}
+
+	ids := s.current().SearchUsers(query, maxSearchResults)
+	users, err := s.userRepo.GetProfilesByIDs(pageOf(ids, page, pageSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, int64(len(ids)), nil
+}
+
+func (s *SearchService) SearchHashtags(query string, page, pageSize int) ([]model.Hashtag, int64, bool, error) {
+	page, pageSize = normalizePage(page, pageSize)
+
+	var hashtags []model.Hashtag
+	var totalCount int64
+	var err error
+	if !s.ready.Load() {
+		hashtags, totalCount, err = s.postRepo.SearchHashtags(query, page, pageSize)
+	} else {
+		names := s.current().SearchHashtags(query, maxSearchResults)
+		totalCount = int64(len(names))
+		hashtags, err = s.postRepo.GetHashtagsByNames(pageOf(names, page, pageSize))
+	}
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	return hashtags, totalCount, utils.CalculateHasMore(totalCount, page, pageSize), nil
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+func pageOf(ids []string, page, pageSize int) []string {
+	start := (page - 1) * pageSize
+	if start >= len(ids) {
+		return nil
+	}
+	end := start + pageSize
+	if end > len(ids) {
+		end = len(ids)
+	}
+	return ids[start:end]
+}
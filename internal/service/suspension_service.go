@@ -0,0 +1,173 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"vietick-backend/internal/model"
+	"vietick-backend/internal/repository"
+	"vietick-backend/internal/utils"
+	"vietick-backend/pkg/email"
+)
+
+type SuspensionService struct {
+	userRepo      *repository.UserRepository
+	authRepo      *repository.AuthRepository
+	reportRepo    *repository.ReportRepository
+	authService   *AuthService
+	emailService  *email.EmailService
+	searchService *SearchService
+}
+
+func NewSuspensionService(userRepo *repository.UserRepository, authRepo *repository.AuthRepository,
+	reportRepo *repository.ReportRepository, authService *AuthService, emailService *email.EmailService,
+	searchService *SearchService) *SuspensionService {
+	return &SuspensionService{
+		userRepo:      userRepo,
+		authRepo:      authRepo,
+		reportRepo:    reportRepo,
+		authService:   authService,
+		emailService:  emailService,
+		searchService: searchService,
+	}
+}
+
+// SuspendUser is the admin endpoint entry point; it also records the audit entry
+func (s *SuspensionService) SuspendUser(adminID, userID string, req *model.SuspendUserRequest) (*model.User, error) {
+	user, err := s.ApplySuspension(adminID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	actionType := model.ModerationActionSuspend
+	if req.Status == model.AccountStatusBanned {
+		actionType = model.ModerationActionBan
+	}
+	s.recordAction(adminID, userID, actionType, &req.Reason, req.DurationHours)
+
+	return user, nil
+}
+
+// ApplySuspension suspends or bans the account, revokes every session and emails the user.
+// Callers that keep their own audit trail (the moderation queue) use this directly.
+func (s *SuspensionService) ApplySuspension(adminID, userID string, req *model.SuspendUserRequest) (*model.User, error) {
+	user, after, err := s.suspend(s.userRepo, s.authRepo, adminID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	after()
+	return user, nil
+}
+
+// suspend writes the suspension through the given repositories, so that the moderation queue
+// can make it part of its own transaction. The returned function drops the cached status,
+// reindexes the user and sends the email; it must only run once the change is committed.
+func (s *SuspensionService) suspend(userRepo *repository.UserRepository, authRepo *repository.AuthRepository,
+	adminID, userID string, req *model.SuspendUserRequest) (*model.User, func(), error) {
+	if adminID == userID {
+		return nil, nil, fmt.Errorf("invalid request: you cannot suspend your own account")
+	}
+
+	var until *time.Time
+	if req.Status == model.AccountStatusSuspended {
+		if req.DurationHours == nil {
+			return nil, nil, fmt.Errorf("validation failed: duration_hours is required for a suspension")
+		}
+		t := time.Now().Add(time.Duration(*req.DurationHours) * time.Hour)
+		until = &t
+	}
+
+	user, err := userRepo.GetByID(userID)
+	if err != nil || user.AccountStatus == model.AccountStatusDeleted {
+		return nil, nil, fmt.Errorf("user not found")
+	}
+
+	// Every access token issued before now stops working immediately
+	now := time.Now()
+	reason := req.Reason
+	if err := userRepo.UpdateAccountStatus(userID, req.Status, &reason, until, &now); err != nil {
+		return nil, nil, err
+	}
+
+	if err := authRepo.DeleteUserRefreshTokens(userID); err != nil {
+		return nil, nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	updated, err := userRepo.GetByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	after := func() {
+		s.authService.InvalidateAccountStatus(userID)
+		s.searchService.ReindexUser(userID)
+
+		if err := s.emailService.SendAccountSuspended(user.Email, user.FullName, reason, until); err != nil {
+			// Log error but don't fail the operation
+			fmt.Printf("Failed to send suspension email: %v\n", err)
+		}
+	}
+	return updated, after, nil
+}
+
+func (s *SuspensionService) UnsuspendUser(adminID, userID string) (*model.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	// Deactivated accounts are reopened by their owner logging in, not by an admin
+	if status := user.EffectiveStatus(); status != model.AccountStatusSuspended && status != model.AccountStatusBanned {
+		return nil, fmt.Errorf("invalid request: user is not suspended")
+	}
+
+	if err := s.userRepo.UpdateAccountStatus(userID, model.AccountStatusActive, nil, nil, nil); err != nil {
+		return nil, err
+	}
+	s.authService.InvalidateAccountStatus(userID)
+	s.searchService.ReindexUser(userID)
+	s.recordAction(adminID, userID, model.ModerationActionUnsuspend, nil, nil)
+
+	if err := s.emailService.SendAccountReinstated(user.Email, user.FullName); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Failed to send reinstatement email: %v\n", err)
+	}
+
+	return s.userRepo.GetByID(userID)
+}
+
+func (s *SuspensionService) GetSuspendedUsers(pagination *utils.PaginationParams) (*model.SuspendedUsersResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	users, totalCount, err := s.userRepo.GetSuspendedUsers(paginationResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suspended users: %w", err)
+	}
+
+	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
+
+	return &model.SuspendedUsersResponse{
+		Users:      users,
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    hasMore,
+	}, nil
+}
+
+func (s *SuspensionService) recordAction(adminID, userID string, actionType model.ModerationActionType, notes *string, durationHours *int) {
+	action := &model.ModerationAction{
+		ID:            uuid.New().String(),
+		ModeratorID:   adminID,
+		Action:        actionType,
+		TargetType:    model.ReportTargetUser,
+		TargetID:      userID,
+		Notes:         notes,
+		DurationHours: durationHours,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.reportRepo.CreateAction(action); err != nil {
+		fmt.Printf("Failed to record moderation action: %v\n", err)
+	}
+}
@@ -10,14 +10,21 @@ import (
 )
 
 type UserService struct {
-	userRepo   *repository.UserRepository
-	followRepo *repository.FollowRepository
+	userRepo         *repository.UserRepository
+	followRepo       *repository.FollowRepository
+	searchService    *SearchService
+	mediaService     *MediaService
+	analyticsService *AnalyticsService
 }
 
-func NewUserService(userRepo *repository.UserRepository, followRepo *repository.FollowRepository) *UserService {
+func NewUserService(userRepo *repository.UserRepository, followRepo *repository.FollowRepository, searchService *SearchService,
+	mediaService *MediaService, analyticsService *AnalyticsService) *UserService {
 	return &UserService{
-		userRepo:   userRepo,
-		followRepo: followRepo,
+		userRepo:         userRepo,
+		followRepo:       followRepo,
+		searchService:    searchService,
+		mediaService:     mediaService,
+		analyticsService: analyticsService,
 	}
 }
 
@@ -26,6 +33,7 @@ func (s *UserService) GetProfile(userID string, viewerID *string) (*model.UserPr
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user profile: %w", err)
 	}
+	s.mediaService.PopulateProfile(profile)
 
 	return profile, nil
 }
@@ -52,20 +60,31 @@ func (s *UserService) UpdateProfile(userID string, req *model.UpdateProfileReque
 	if req.Bio != nil {
 		user.Bio = req.Bio
 	}
-	if req.AvatarURL != nil {
-		user.AvatarURL = req.AvatarURL
+	if req.AvatarMediaID != nil {
+		if *req.AvatarMediaID == "" {
+			user.AvatarURL = nil
+			user.AvatarMediaID = nil
+		} else {
+			avatarURL, err := s.mediaService.AttachOne(userID, model.MediaPurposeAvatar, *req.AvatarMediaID)
+			if err != nil {
+				return nil, err
+			}
+			user.AvatarURL = &avatarURL
+			user.AvatarMediaID = req.AvatarMediaID
+		}
 	}
 
 	err = s.userRepo.Update(user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update profile: %w", err)
 	}
+	s.searchService.IndexUser(user)
 
 	return s.GetProfile(userID, nil)
 }
 
-func (s *UserService) SearchUsers(query string, page, pageSize int) ([]model.User, int64, error) {
-	return s.userRepo.SearchUsers(query, page, pageSize)
+func (s *UserService) SearchUsers(query string, page, pageSize int) ([]model.UserProfile, int64, error) {
+	return s.searchService.SearchUsers(query, page, pageSize)
 }
 
 func (s *UserService) GetUserByID(userID string) (*model.User, error) {
@@ -138,6 +157,7 @@ func (s *UserService) UpdateUsername(userID string, newUsername string) error {
 	if err != nil {
 		return fmt.Errorf("failed to update username: %w", err)
 	}
+	s.searchService.IndexUser(user)
 
 	return nil
 }
@@ -178,7 +198,7 @@ func (s *UserService) UpdateEmail(userID string, newEmail string) error {
 	return nil
 }
 
-func (s *UserService) GetRecommendedUsers(userID string, limit int) ([]model.User, error) {
+func (s *UserService) GetRecommendedUsers(userID string, limit int) ([]model.UserProfile, error) {
 	users, _, err := s.userRepo.SearchUsers("", 1, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recommended users: %w", err)
@@ -209,3 +229,19 @@ func (s *UserService) GetUserStats(userID string) (map[string]interface{}, error
 
 	return stats, nil
 }
+
+// GetDashboard bổ sung vào GetUserStats số liệu tiếp cận của các bài viết trong days ngày gần nhất
+func (s *UserService) GetDashboard(userID string, days int) (map[string]interface{}, error) {
+	stats, err := s.GetUserStats(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics, err := s.analyticsService.GetAccountAnalytics(userID, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analytics: %w", err)
+	}
+	stats["analytics"] = analytics
+
+	return stats, nil
+}
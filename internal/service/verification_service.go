@@ -2,30 +2,75 @@ package service
 
 import (
 	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 
+	"vietick-backend/internal/config"
 	"vietick-backend/internal/model"
 	"vietick-backend/internal/repository"
 	"vietick-backend/internal/utils"
 	"vietick-backend/pkg/email"
+	"vietick-backend/pkg/encryption"
 	"github.com/google/uuid"
 )
 
+const (
+	// Signed document URLs are only valid for a few minutes
+	documentLinkTTL = 5 * time.Minute
+	// Images whose perceptual hashes differ in at most this many of 64 bits are flagged as
+	// the same picture
+	similarImageMaxDistance = 5
+)
+
 type VerificationService struct {
-	verificationRepo *repository.VerificationRepository
-	userRepo         *repository.UserRepository
-	emailService     *email.EmailService
+	verificationRepo  *repository.VerificationRepository
+	userRepo          *repository.UserRepository
+	organizationRepo  *repository.OrganizationRepository
+	emailService      *email.EmailService
+	searchService     *SearchService
+	mediaService      *MediaService
+	permissionService *PermissionService
+	keyring           *encryption.Keyring
+	cfg               *config.VerificationConfig
 }
 
 func NewVerificationService(verificationRepo *repository.VerificationRepository, 
-	userRepo *repository.UserRepository, emailService *email.EmailService) *VerificationService {
+	userRepo *repository.UserRepository, organizationRepo *repository.OrganizationRepository,
+	emailService *email.EmailService, searchService *SearchService,
+	mediaService *MediaService, permissionService *PermissionService, keyring *encryption.Keyring,
+	cfg *config.VerificationConfig) *VerificationService {
 	return &VerificationService{
-		verificationRepo: verificationRepo,
-		userRepo:         userRepo,
-		emailService:     emailService,
+		verificationRepo:  verificationRepo,
+		userRepo:          userRepo,
+		organizationRepo:  organizationRepo,
+		emailService:      emailService,
+		searchService:     searchService,
+		mediaService:      mediaService,
+		permissionService: permissionService,
+		keyring:           keyring,
+		cfg:               cfg,
+	}
+}
+
+// IDNumberIndex is the blind index of an ID number. Separators people type differently
+// ("012-345 678" and "012345678" are the same document) are stripped before hashing.
+func IDNumberIndex(keyring *encryption.Keyring, idNumber string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(idNumber) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
 	}
+	return keyring.BlindIndex(b.String())
 }
 
-func (s *VerificationService) SubmitIdentityVerification(userID string, req *model.SubmitIdentityVerificationRequest) (*model.IdentityVerification, error) {
+// SubmitIdentityVerification submits a request for userID. submittedBy is the member
+// submitting on behalf of an organization, or userID itself.
+func (s *VerificationService) SubmitIdentityVerification(userID, submittedBy string, req *model.SubmitIdentityVerificationRequest) (*model.IdentityVerification, error) {
 	// Check if user already has a pending verification
 	hasPending, err := s.verificationRepo.HasPendingVerification(userID)
 	if err != nil {
@@ -43,32 +88,115 @@ func (s *VerificationService) SubmitIdentityVerification(userID string, req *mod
 	}
 
 	if user.IsVerified {
-		return nil, fmt.Errorf("user is already verified")
+		// Verified users may only submit again to renew a verification that is about to expire
+		renewalDue, err := s.renewalDue(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !renewalDue {
+			return nil, fmt.Errorf("user is already verified")
+		}
+	}
+
+	// Organizations prove who they are with their business registration, people with an ID
+	if user.IsOrganization() != (req.IDType == model.IdentityDocumentBusinessRegistration) {
+		if user.IsOrganization() {
+			return nil, fmt.Errorf("invalid request: organizations verify with a business registration")
+		}
+		return nil, fmt.Errorf("invalid request: business registrations can only verify organizations")
+	}
+
+	resubmitAfter, err := s.resubmitAfter(userID)
+	if err != nil {
+		return nil, err
+	}
+	if resubmitAfter != nil {
+		return nil, fmt.Errorf("invalid request: a new verification request can be submitted after %s",
+			resubmitAfter.UTC().Format(time.RFC3339))
+	}
+
+	// Documents must be the user's own identity_document uploads
+	documentIDs := []string{req.FrontImageMediaID, req.SelfieImageMediaID}
+	if req.BackImageMediaID != nil {
+		documentIDs = append(documentIDs, *req.BackImageMediaID)
+	}
+	if _, err := s.mediaService.Attach(userID, model.MediaPurposeIdentityDocument, documentIDs); err != nil {
+		return nil, err
+	}
+
+	verificationID := uuid.New().String()
+	fingerprints, err := s.fingerprintDocuments(verificationID, userID, map[string]*string{
+		model.VerificationDocumentFront:  &req.FrontImageMediaID,
+		model.VerificationDocumentBack:   req.BackImageMediaID,
+		model.VerificationDocumentSelfie: &req.SelfieImageMediaID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var submitter *string
+	if submittedBy != userID {
+		submitter = &submittedBy
 	}
 
 	// Create verification request
 	verification := &model.IdentityVerification{
-		ID: uuid.New().String(),
+		ID: verificationID,
 		UserID: userID,
-		FullName: req.FullName,
-		IDNumber: req.IDNumber,
+		FullName: model.EncryptedString(req.FullName),
+		IDNumber: model.EncryptedString(req.IDNumber),
+		IDNumberHash: IDNumberIndex(s.keyring, req.IDNumber),
 		IDType: req.IDType,
-		FrontImageURL: req.FrontImageURL,
-		BackImageURL: req.BackImageURL,
-		SelfieImageURL: req.SelfieImageURL,
+		Category: req.Category,
+		SubmittedBy: submitter,
+		FrontMediaID: &req.FrontImageMediaID,
+		BackMediaID: req.BackImageMediaID,
+		SelfieMediaID: &req.SelfieImageMediaID,
 		Status: model.IdentityVerificationPending,
 	}
 
-	err = s.verificationRepo.Create(verification)
+	err = s.verificationRepo.Create(verification, &model.VerificationEvent{
+		ID:             uuid.New().String(),
+		VerificationID: verification.ID,
+		UserID:         userID,
+		ToStatus:       model.IdentityVerificationPending,
+		ActorID:        &submittedBy,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit verification: %w", err)
 	}
+	if err := s.verificationRepo.CreateFingerprints(fingerprints); err != nil {
+		log.Printf("Failed to save fingerprints of verification %s: %v", verification.ID, err)
+	}
 
-	// Update user verification status
-	user.IdentityVerificationStatus = model.IdentityVerificationPending
-	err = s.userRepo.Update(user)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update user verification status: %w", err)
+	// Update user verification status; a renewing user stays approved until the review
+	if !user.IsVerified {
+		user.IdentityVerificationStatus = model.IdentityVerificationPending
+		err = s.userRepo.Update(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update user verification status: %w", err)
+		}
+	}
+
+	// Flag other accounts using the same ID number or document images for the reviewer
+	matches := s.detectDuplicates(verification, fingerprints)
+	if len(matches) > 0 {
+		// Requests sharing an identity with another account need two approvals
+		verification.HighRisk = true
+		if err := s.verificationRepo.SetHighRisk(verification.ID); err != nil {
+			log.Printf("Failed to flag verification %s as high risk: %v", verification.ID, err)
+		}
+	}
+	if reason := autoRejectReason(matches); reason != "" && s.cfg.AutoRejectDuplicates {
+		if err := s.reject(verification, user, nil, &reason); err != nil {
+			log.Printf("Failed to auto-reject verification %s: %v", verification.ID, err)
+		}
+	} else {
+		s.assignReviewer(verification)
+		if err := s.emailService.SendVerificationSubmitted(user.Email, user.FullName); err != nil {
+			// Log error but don't fail the operation
+			fmt.Printf("Failed to send verification submitted email: %v\n", err)
+		}
 	}
 
 	// Get the complete verification with user information
@@ -89,14 +217,159 @@ func (s *VerificationService) GetVerification(verificationID string) (*model.Ide
 	if err != nil {
 		return nil, fmt.Errorf("verification not found")
 	}
+	s.populateDuplicateMatches(verification)
+	if events, err := s.verificationRepo.GetEvents(verification.ID); err == nil {
+		verification.Events = events
+	} else {
+		log.Printf("Failed to load events of verification %s: %v", verification.ID, err)
+	}
+	if reviews, err := s.verificationRepo.GetReviews(verification.ID); err == nil {
+		for i := range reviews {
+			if profile, err := s.userRepo.GetProfile(reviews[i].ReviewerID, nil); err == nil {
+				reviews[i].Reviewer = profile
+			}
+		}
+		verification.Reviews = reviews
+	} else {
+		log.Printf("Failed to load reviews of verification %s: %v", verification.ID, err)
+	}
 
 	return verification, nil
 }
 
-func (s *VerificationService) GetPendingVerifications(pagination *utils.PaginationParams) (*model.IdentityVerificationsResponse, error) {
+// GetUserHistory returns every verification request of the user and each status change
+func (s *VerificationService) GetUserHistory(userID string) (*model.VerificationHistoryResponse, error) {
+	verifications, events, err := s.verificationRepo.GetHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+	resubmitAfter, err := s.resubmitAfter(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.VerificationHistoryResponse{
+		Verifications: verifications,
+		Events:        events,
+		ResubmitAfter: resubmitAfter,
+	}, nil
+}
+
+// fingerprintDocuments hashes each submitted document so re-use by other accounts can be
+// detected, now and after the files are purged
+func (s *VerificationService) fingerprintDocuments(verificationID, userID string, documents map[string]*string) ([]model.VerificationDocumentFingerprint, error) {
+	var fingerprints []model.VerificationDocumentFingerprint
+	for _, side := range []string{model.VerificationDocumentFront, model.VerificationDocumentBack, model.VerificationDocumentSelfie} {
+		mediaID := documents[side]
+		if mediaID == nil {
+			continue
+		}
+		contentHash, perceptualHash, err := s.mediaService.Fingerprint(*mediaID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s document: %w", side, err)
+		}
+		fingerprints = append(fingerprints, model.VerificationDocumentFingerprint{
+			VerificationID: verificationID,
+			UserID:         userID,
+			Side:           side,
+			ContentHash:    contentHash,
+			PerceptualHash: perceptualHash,
+		})
+	}
+	return fingerprints, nil
+}
+
+// detectDuplicates records requests of other accounts with the same ID number or the same
+// (or visually similar) document images
+func (s *VerificationService) detectDuplicates(verification *model.IdentityVerification, fingerprints []model.VerificationDocumentFingerprint) []model.VerificationDuplicateMatch {
+	var matches []model.VerificationDuplicateMatch
+	newMatch := func(matchedVerificationID, matchedUserID string, reason model.VerificationMatchReason, exact bool,
+		detail string, status model.IdentityVerificationStatus) {
+		matches = append(matches, model.VerificationDuplicateMatch{
+			ID:                    uuid.New().String(),
+			VerificationID:        verification.ID,
+			MatchedVerificationID: matchedVerificationID,
+			MatchedUserID:         matchedUserID,
+			Reason:                reason,
+			Exact:                 exact,
+			Detail:                detail,
+			MatchedStatus:         status,
+		})
+	}
+
+	sameIDNumber, err := s.verificationRepo.FindByIDNumberHash(verification.IDNumberHash, verification.UserID)
+	if err != nil {
+		log.Printf("Failed to check ID number of verification %s: %v", verification.ID, err)
+	}
+	for _, other := range sameIDNumber {
+		newMatch(other.ID, other.UserID, model.VerificationMatchIDNumber, true,
+			"same ID number", other.Status)
+	}
+
+	for i := range fingerprints {
+		fingerprint := &fingerprints[i]
+		found, err := s.verificationRepo.FindFingerprintMatches(fingerprint, similarImageMaxDistance)
+		if err != nil {
+			log.Printf("Failed to check documents of verification %s: %v", verification.ID, err)
+			continue
+		}
+		reason := model.VerificationMatchDocumentImage
+		if fingerprint.Side == model.VerificationDocumentSelfie {
+			reason = model.VerificationMatchSelfieImage
+		}
+		for _, other := range found {
+			exact := other.ContentHash == fingerprint.ContentHash
+			detail := fmt.Sprintf("%s image is identical to their %s image", fingerprint.Side, other.Side)
+			if !exact {
+				detail = fmt.Sprintf("%s image looks like their %s image (%d of 64 bits differ)",
+					fingerprint.Side, other.Side, other.Distance)
+			}
+			newMatch(other.VerificationID, other.UserID, reason, exact, detail, other.Status)
+		}
+	}
+
+	if err := s.verificationRepo.CreateDuplicateMatches(matches); err != nil {
+		log.Printf("Failed to save duplicate matches of verification %s: %v", verification.ID, err)
+	}
+	return matches
+}
+
+// autoRejectReason returns the admin note of a request that should be rejected without
+// review: an exact match (ID number or identical file) with an already verified account.
+// Similar looking images are only flagged, ID cards of one type all look alike. The note is
+// shown to the user, so it does not name the other account; reviewers see the matches.
+func autoRejectReason(matches []model.VerificationDuplicateMatch) string {
+	for _, match := range matches {
+		if match.Exact && match.MatchedStatus == model.IdentityVerificationApproved {
+			if match.Reason == model.VerificationMatchIDNumber {
+				return "Automatically rejected: this ID number is already used by a verified account"
+			}
+			return "Automatically rejected: these documents are already used by a verified account"
+		}
+	}
+	return ""
+}
+
+func (s *VerificationService) populateDuplicateMatches(verification *model.IdentityVerification) {
+	matches, err := s.verificationRepo.GetDuplicateMatches(verification.ID)
+	if err != nil {
+		log.Printf("Failed to load duplicate matches of verification %s: %v", verification.ID, err)
+		return
+	}
+	for i := range matches {
+		if profile, err := s.userRepo.GetProfile(matches[i].MatchedUserID, nil); err == nil {
+			matches[i].MatchedUser = profile
+		}
+	}
+	verification.DuplicateMatches = matches
+}
+
+// GetPendingVerifications returns the review queue, only the requests assigned to one
+// reviewer if assignedTo is set
+func (s *VerificationService) GetPendingVerifications(assignedTo *string, pagination *utils.PaginationParams) (*model.IdentityVerificationsResponse, error) {
 	paginationResult := pagination.Calculate()
 
-	verifications, totalCount, err := s.verificationRepo.GetPending(paginationResult)
+	verifications, totalCount, err := s.verificationRepo.GetPending(assignedTo, paginationResult)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pending verifications: %w", err)
 	}
@@ -142,67 +415,588 @@ func (s *VerificationService) ReviewVerification(verificationID string, reviewed
 		return nil, fmt.Errorf("verification has already been reviewed")
 	}
 
-	// Review verification
-	err = s.verificationRepo.Review(verificationID, req.Status, req.AdminNotes, reviewedBy)
+	if err := s.checkReviewer(verification, reviewedBy); err != nil {
+		return nil, err
+	}
+
+	// Deciding takes (or renews) the lease, so two reviewers cannot decide at the same time
+	if err := s.claim(verification, reviewedBy); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(verification.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to review verification: %w", err)
+		return nil, fmt.Errorf("user not found")
+	}
+
+	reviews, err := s.verificationRepo.GetReviews(verificationID)
+	if err != nil {
+		return nil, err
+	}
+	review := &model.VerificationReview{
+		ID:             uuid.New().String(),
+		VerificationID: verificationID,
+		ReviewerID:     reviewedBy,
+		Decision:       req.Status,
+		Notes:          req.AdminNotes,
 	}
 
-	// If approved, send approval email
 	if req.Status == model.IdentityVerificationApproved {
-		user, err := s.userRepo.GetByID(verification.UserID)
-		if err == nil { // Don't fail the review if email fails
-			err = s.emailService.SendVerificationApproval(user.Email, user.FullName)
-			if err != nil {
-				// Log error but don't fail the operation
-				fmt.Printf("Failed to send verification approval email: %v\n", err)
+		approvals := 1
+		for _, previous := range reviews {
+			if previous.Decision != model.IdentityVerificationApproved {
+				continue
+			}
+			if previous.ReviewerID == reviewedBy {
+				return nil, fmt.Errorf("invalid request: this verification needs the approval of another reviewer")
+			}
+			approvals++
+		}
+		if approvals < verification.RequiredApprovals() {
+			// First approval of a high-risk request: hand it over to a second reviewer
+			if err := s.verificationRepo.CreateReview(review); err != nil {
+				return nil, fmt.Errorf("failed to review verification: %w", err)
+			}
+			if err := s.verificationRepo.ReleaseClaim(verificationID, reviewedBy); err != nil {
+				log.Printf("Failed to release verification %s: %v", verificationID, err)
 			}
+			s.assignReviewer(verification, reviewedBy)
+			return s.GetVerification(verificationID)
+		}
+		category := req.Category
+		if category == nil {
+			category = verification.Category
 		}
+		err = s.approve(verification, user, reviewedBy, req.AdminNotes, category)
+	} else {
+		err = s.reject(verification, user, &reviewedBy, req.AdminNotes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review verification: %w", err)
+	}
+	if err := s.verificationRepo.CreateReview(review); err != nil {
+		log.Printf("Failed to record review of verification %s: %v", verificationID, err)
 	}
 
 	// Get updated verification
-	return s.verificationRepo.GetByID(verificationID)
+	return s.GetVerification(verificationID)
 }
 
-func (s *VerificationService) GetVerificationStats() (map[string]interface{}, error) {
-	// Get counts for different statuses
-	pendingStatus := model.IdentityVerificationPending
-	approvedStatus := model.IdentityVerificationApproved
-	rejectedStatus := model.IdentityVerificationRejected
+// checkReviewer refuses reviewers deciding on their own request or on the request of an
+// organization they are a member of
+func (s *VerificationService) checkReviewer(verification *model.IdentityVerification, reviewerID string) error {
+	if verification.UserID == reviewerID || (verification.SubmittedBy != nil && *verification.SubmittedBy == reviewerID) {
+		return fmt.Errorf("forbidden: you cannot review your own verification request")
+	}
+	isMember, err := s.organizationRepo.IsMember(verification.UserID, reviewerID)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return fmt.Errorf("forbidden: you cannot review the verification request of your organization")
+	}
+	return nil
+}
+
+// ClaimVerification gives the reviewer a lease on a pending request for ClaimLeaseMinutes.
+// Claiming again renews the lease.
+func (s *VerificationService) ClaimVerification(verificationID, reviewerID string) (*model.IdentityVerification, error) {
+	verification, err := s.verificationRepo.GetByID(verificationID)
+	if err != nil {
+		return nil, fmt.Errorf("verification not found")
+	}
+	if verification.Status != model.IdentityVerificationPending {
+		return nil, fmt.Errorf("verification has already been reviewed")
+	}
+	if err := s.checkReviewer(verification, reviewerID); err != nil {
+		return nil, err
+	}
+	if err := s.claim(verification, reviewerID); err != nil {
+		return nil, err
+	}
+	return s.GetVerification(verificationID)
+}
+
+// ReleaseVerification gives up the reviewer's lease so that someone else can take the request
+func (s *VerificationService) ReleaseVerification(verificationID, reviewerID string) error {
+	return s.verificationRepo.ReleaseClaim(verificationID, reviewerID)
+}
+
+// AssignPending assigns requests without a reviewer, e.g. submitted while there were no
+// reviewers or assigned to a user who is no longer one
+func (s *VerificationService) AssignPending() (int, error) {
+	reviewers, err := s.permissionService.GetUsersWithPermission(model.PermissionReviewVerifications)
+	if err != nil {
+		return 0, err
+	}
+	if len(reviewers) == 0 {
+		return 0, nil
+	}
+	verifications, err := s.verificationRepo.GetUnassigned(reviewers, 100)
+	if err != nil {
+		return 0, err
+	}
+
+	assigned := 0
+	for i := range verifications {
+		// Whoever approved a high-risk request already cannot be its second reviewer
+		var exclude []string
+		if reviews, err := s.verificationRepo.GetReviews(verifications[i].ID); err == nil {
+			for _, review := range reviews {
+				exclude = append(exclude, review.ReviewerID)
+			}
+		}
+		if s.assignReviewer(&verifications[i], exclude...) {
+			assigned++
+		}
+	}
+	return assigned, nil
+}
+
+// claim takes or renews the reviewer's lease on a pending request
+func (s *VerificationService) claim(verification *model.IdentityVerification, reviewerID string) error {
+	until := time.Now().Add(time.Duration(s.cfg.ClaimLeaseMinutes) * time.Minute)
+	claimed, err := s.verificationRepo.Claim(verification.ID, reviewerID, until)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		if current, err := s.verificationRepo.GetByID(verification.ID); err == nil && current.Status != model.IdentityVerificationPending {
+			return fmt.Errorf("verification has already been reviewed")
+		}
+		return fmt.Errorf("forbidden: verification is claimed by another reviewer")
+	}
+	verification.ClaimedBy = &reviewerID
+	verification.ClaimExpiresAt = &until
+	return nil
+}
+
+// assignReviewer assigns the request to the next reviewer, never to the requester or the
+// excluded users. Without reviewers the queue is left to the admin.
+func (s *VerificationService) assignReviewer(verification *model.IdentityVerification, exclude ...string) bool {
+	reviewerID, err := s.nextReviewer(append(exclude, verification.UserID))
+	if err != nil {
+		log.Printf("Failed to pick a reviewer for verification %s: %v", verification.ID, err)
+		return false
+	}
+	if reviewerID == "" {
+		return false
+	}
+	if err := s.verificationRepo.Assign(verification.ID, reviewerID); err != nil {
+		log.Printf("Failed to assign verification %s: %v", verification.ID, err)
+		return false
+	}
+	return true
+}
+
+// nextReviewer picks reviewers round-robin: the one whose last assignment is the oldest,
+// reviewers never assigned before first
+func (s *VerificationService) nextReviewer(exclude []string) (string, error) {
+	reviewers, err := s.permissionService.GetUsersWithPermission(model.PermissionReviewVerifications)
+	if err != nil {
+		return "", err
+	}
+	excluded := make(map[string]bool, len(exclude))
+	for _, id := range exclude {
+		excluded[id] = true
+	}
+	var candidates []string
+	for _, id := range reviewers {
+		if !excluded[id] {
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	lastAssignedAt, err := s.verificationRepo.GetLastAssignedAt(candidates)
+	if err != nil {
+		return "", err
+	}
+	next := ""
+	var nextAt time.Time
+	for _, id := range candidates {
+		at, ok := lastAssignedAt[id]
+		if !ok {
+			return id, nil
+		}
+		if next == "" || at.Before(nextAt) {
+			next, nextAt = id, at
+		}
+	}
+	return next, nil
+}
+
+// RevokeVerification removes the blue tick granted by an approved request
+func (s *VerificationService) RevokeVerification(verificationID, adminID string, req *model.RevokeIdentityVerificationRequest) (*model.IdentityVerification, error) {
+	verification, err := s.verificationRepo.GetByID(verificationID)
+	if err != nil {
+		return nil, fmt.Errorf("verification not found")
+	}
+	user, err := s.userRepo.GetByID(verification.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	now := time.Now()
+	err = s.transition(verification, model.IdentityVerificationRevoked, &adminID, &req.Reason,
+		map[string]interface{}{
+			"revoked_at":        now,
+			"revoked_by":        adminID,
+			"revocation_reason": req.Reason,
+		},
+		map[string]interface{}{
+			"is_verified":                  false,
+			"identity_verification_status": model.IdentityVerificationRevoked,
+		})
+	if err != nil {
+		return nil, err
+	}
+	s.searchService.ReindexUser(user.ID)
+
+	if err := s.emailService.SendVerificationRevoked(user.Email, user.FullName, req.Reason, s.cooldownEnd(now)); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Failed to send verification revoked email: %v\n", err)
+	}
+
+	return s.GetVerification(verificationID)
+}
+
+// ExpireVerifications reminds users whose verification is about to expire to renew it and
+// removes the blue tick of verifications past their validity period
+func (s *VerificationService) ExpireVerifications() (int, error) {
+	now := time.Now()
+
+	expiring, err := s.verificationRepo.GetExpiring(now.AddDate(0, 0, s.cfg.ExpiryReminderDays), 100)
+	if err != nil {
+		return 0, err
+	}
+	for i := range expiring {
+		verification := &expiring[i]
+		if verification.ExpiresAt.After(now) {
+			if user, err := s.userRepo.GetByID(verification.UserID); err == nil {
+				if err := s.emailService.SendVerificationExpiring(user.Email, user.FullName, *verification.ExpiresAt); err != nil {
+					fmt.Printf("Failed to send verification expiring email: %v\n", err)
+				}
+			}
+		}
+		if err := s.verificationRepo.MarkExpiryReminded(verification.ID); err != nil {
+			log.Printf("Failed to mark verification %s reminded: %v", verification.ID, err)
+		}
+	}
+
+	expired, err := s.verificationRepo.GetExpired(now, 100)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	reason := "validity period of the document type ended"
+	for i := range expired {
+		verification := &expired[i]
+		err := s.transition(verification, model.IdentityVerificationExpired, nil, &reason, nil,
+			map[string]interface{}{
+				"is_verified":                  false,
+				"identity_verification_status": model.IdentityVerificationExpired,
+			})
+		if err != nil {
+			log.Printf("Failed to expire verification %s: %v", verification.ID, err)
+			continue
+		}
+		count++
+		s.searchService.ReindexUser(verification.UserID)
+
+		if user, err := s.userRepo.GetByID(verification.UserID); err == nil {
+			if err := s.emailService.SendVerificationExpired(user.Email, user.FullName); err != nil {
+				fmt.Printf("Failed to send verification expired email: %v\n", err)
+			}
+		}
+	}
+	return count, nil
+}
+
+// transition applies a status change allowed by the state machine and records it in the
+// history of the request
+func (s *VerificationService) transition(verification *model.IdentityVerification, to model.IdentityVerificationStatus,
+	actorID, reason *string, updates, userUpdates map[string]interface{}) error {
+	from := verification.Status
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("invalid status transition: a %s verification cannot become %s", from, to)
+	}
+	return s.verificationRepo.Transition(&model.VerificationEvent{
+		ID:             uuid.New().String(),
+		VerificationID: verification.ID,
+		UserID:         verification.UserID,
+		FromStatus:     &from,
+		ToStatus:       to,
+		Reason:         reason,
+		ActorID:        actorID,
+	}, updates, userUpdates)
+}
+
+// approve grants the blue tick; category (if any) becomes the user's directory category
+func (s *VerificationService) approve(verification *model.IdentityVerification, user *model.User, reviewedBy string,
+	notes *string, category *model.VerificationCategory) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"admin_notes": notes,
+		"reviewed_at": now,
+		"reviewed_by": reviewedBy,
+		"expires_at":  s.expiresAt(verification.IDType, now),
+		"category":    category,
+
+		"claimed_by":       nil,
+		"claim_expires_at": nil,
+	}
+	userUpdates := map[string]interface{}{
+		"is_verified":                  true,
+		"identity_verification_status": model.IdentityVerificationApproved,
+		"verified_at":                  now,
+	}
+	if category != nil {
+		userUpdates["verification_category"] = *category
+	}
+	err := s.transition(verification, model.IdentityVerificationApproved, &reviewedBy, notes, updates, userUpdates)
+	if err != nil {
+		return err
+	}
+
+	// A renewal replaces the verification it renews
+	previous, err := s.verificationRepo.GetByUserIDAndStatus(user.ID, model.IdentityVerificationApproved)
+	if err != nil {
+		log.Printf("Failed to get previous verifications of user %s: %v", user.ID, err)
+	}
+	reason := "replaced by a newer verification"
+	for i := range previous {
+		if previous[i].ID == verification.ID {
+			continue
+		}
+		if err := s.transition(&previous[i], model.IdentityVerificationExpired, nil, &reason, nil, nil); err != nil {
+			log.Printf("Failed to expire verification %s: %v", previous[i].ID, err)
+		}
+	}
+
+	s.searchService.ReindexUser(user.ID)
+	if err := s.emailService.SendVerificationApproval(user.Email, user.FullName); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Failed to send verification approval email: %v\n", err)
+	}
+	return nil
+}
+
+// reject rejects a pending request; actorID is nil for automatic rejections
+func (s *VerificationService) reject(verification *model.IdentityVerification, user *model.User, actorID *string, notes *string) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"admin_notes": notes,
+		"reviewed_at": now,
+
+		"claimed_by":       nil,
+		"claim_expires_at": nil,
+	}
+	if actorID != nil {
+		updates["reviewed_by"] = *actorID
+	}
+	// A verified user whose renewal is rejected keeps the tick until it expires
+	var userUpdates map[string]interface{}
+	if !user.IsVerified {
+		userUpdates = map[string]interface{}{"identity_verification_status": model.IdentityVerificationRejected}
+	}
+	if err := s.transition(verification, model.IdentityVerificationRejected, actorID, notes, updates, userUpdates); err != nil {
+		return err
+	}
+
+	reason := "Your documents could not be verified."
+	if notes != nil && *notes != "" {
+		reason = *notes
+	}
+	if err := s.emailService.SendVerificationRejected(user.Email, user.FullName, reason, s.cooldownEnd(now)); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Failed to send verification rejected email: %v\n", err)
+	}
+	return nil
+}
+
+// expiresAt is when a verification approved at approvedAt lapses, nil for document types
+// without a validity period
+func (s *VerificationService) expiresAt(idType model.IdentityDocumentType, approvedAt time.Time) *time.Time {
+	months, ok := s.cfg.ValidityMonths[string(idType)]
+	if !ok {
+		return nil
+	}
+	t := approvedAt.AddDate(0, months, 0)
+	return &t
+}
 
-	pendingPagination := utils.PaginationResult{Offset: 0, Limit: 1, Page: 1, PageSize: 1}
+func (s *VerificationService) cooldownEnd(decidedAt time.Time) time.Time {
+	return decidedAt.AddDate(0, 0, s.cfg.ResubmitCooldownDays)
+}
 
-	pendingVerifications, pendingCount, err := s.verificationRepo.GetAll(&pendingStatus, pendingPagination)
+// resubmitAfter returns the end of the cooldown after the user's last rejection or
+// revocation, nil if the user can submit now
+func (s *VerificationService) resubmitAfter(userID string) (*time.Time, error) {
+	event, err := s.verificationRepo.GetLastEvent(userID, []model.IdentityVerificationStatus{
+		model.IdentityVerificationRejected, model.IdentityVerificationRevoked,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pending count: %w", err)
+		return nil, err
+	}
+	if event == nil {
+		return nil, nil
+	}
+	end := s.cooldownEnd(event.CreatedAt)
+	if !end.After(time.Now()) {
+		return nil, nil
 	}
-	_ = pendingVerifications // We only need the count
+	return &end, nil
+}
 
-	approvedVerifications, approvedCount, err := s.verificationRepo.GetAll(&approvedStatus, pendingPagination)
+// renewalDue reports whether the user's current verification expires within the reminder
+// window, so that a new request can be submitted before the tick lapses
+func (s *VerificationService) renewalDue(userID string) (bool, error) {
+	approved, err := s.verificationRepo.GetByUserIDAndStatus(userID, model.IdentityVerificationApproved)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get approved count: %w", err)
+		return false, err
+	}
+	renewFrom := time.Now().AddDate(0, 0, s.cfg.ExpiryReminderDays)
+	for _, verification := range approved {
+		if verification.ExpiresAt != nil && verification.ExpiresAt.Before(renewFrom) {
+			return true, nil
+		}
 	}
-	_ = approvedVerifications // We only need the count
+	return false, nil
+}
 
-	rejectedVerifications, rejectedCount, err := s.verificationRepo.GetAll(&rejectedStatus, pendingPagination)
+func (s *VerificationService) GetVerificationStats() (map[string]interface{}, error) {
+	counts, err := s.verificationRepo.CountByStatus()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rejected count: %w", err)
+		return nil, fmt.Errorf("failed to get verification counts: %w", err)
 	}
-	_ = rejectedVerifications // We only need the count
 
-	totalCount := pendingCount + approvedCount + rejectedCount
+	var totalCount int64
+	for _, count := range counts {
+		totalCount += count
+	}
+	// Revoked and expired requests were approved at first
+	everApproved := counts[model.IdentityVerificationApproved] + counts[model.IdentityVerificationRevoked] +
+		counts[model.IdentityVerificationExpired]
 
 	stats := map[string]interface{}{
 		"total_submissions":    totalCount,
-		"pending_submissions":  pendingCount,
-		"approved_submissions": approvedCount,
-		"rejected_submissions": rejectedCount,
-		"approval_rate":        calculateApprovalRate(approvedCount, totalCount),
+		"pending_submissions":  counts[model.IdentityVerificationPending],
+		"approved_submissions": counts[model.IdentityVerificationApproved],
+		"rejected_submissions": counts[model.IdentityVerificationRejected],
+		"revoked_submissions":  counts[model.IdentityVerificationRevoked],
+		"expired_submissions":  counts[model.IdentityVerificationExpired],
+		"approval_rate":        calculateApprovalRate(everApproved, totalCount),
+	}
+
+	sla, err := s.slaStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLA metrics: %w", err)
 	}
+	stats["sla"] = sla
 
 	return stats, nil
 }
 
+// slaStats measures the review queue: time from submission to the first review over the
+// last 30 days, age of the requests waiting now and the throughput of each reviewer
+func (s *VerificationService) slaStats() (*model.VerificationSLAStats, error) {
+	now := time.Now()
+
+	delays, err := s.verificationRepo.GetFirstReviewDelays(now.AddDate(0, 0, -30))
+	if err != nil {
+		return nil, err
+	}
+	submittedAt, err := s.verificationRepo.GetPendingSubmittedAt()
+	if err != nil {
+		return nil, err
+	}
+	ages := make([]int64, len(submittedAt))
+	for i, t := range submittedAt {
+		ages[i] = int64(now.Sub(t).Seconds())
+	}
+
+	throughput, err := s.verificationRepo.GetReviewerThroughput(now)
+	if err != nil {
+		return nil, err
+	}
+	assigned, claimed, err := s.verificationRepo.GetQueueLoad(now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reviewers without decisions in the period are listed too
+	listed := make(map[string]bool, len(throughput))
+	for _, t := range throughput {
+		listed[t.ReviewerID] = true
+	}
+	reviewers, err := s.permissionService.GetUsersWithPermission(model.PermissionReviewVerifications)
+	if err != nil {
+		return nil, err
+	}
+	for _, ids := range [][]string{reviewers, mapKeys(assigned), mapKeys(claimed)} {
+		for _, id := range ids {
+			if !listed[id] {
+				listed[id] = true
+				throughput = append(throughput, model.ReviewerThroughput{ReviewerID: id})
+			}
+		}
+	}
+	for i := range throughput {
+		throughput[i].Assigned = assigned[throughput[i].ReviewerID]
+		throughput[i].ActiveClaims = claimed[throughput[i].ReviewerID]
+		if profile, err := s.userRepo.GetProfile(throughput[i].ReviewerID, nil); err == nil {
+			throughput[i].Reviewer = profile
+		}
+	}
+
+	return &model.VerificationSLAStats{
+		TimeToFirstReview: durationStats(delays),
+		BacklogAge:        durationStats(ages),
+		Reviewers:         throughput,
+	}, nil
+}
+
+// durationStats summarizes durations (seconds) with nearest-rank percentiles
+func durationStats(values []int64) model.VerificationDurationStats {
+	stats := model.VerificationDurationStats{Count: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum int64
+	for _, v := range sorted {
+		sum += v
+	}
+	stats.Average = float64(sum) / float64(len(sorted))
+	stats.P50 = percentile(sorted, 50)
+	stats.P90 = percentile(sorted, 90)
+	stats.P99 = percentile(sorted, 99)
+	stats.Max = sorted[len(sorted)-1]
+	return stats
+}
+
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func mapKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func calculateApprovalRate(approved, total int64) float64 {
 	if total == 0 {
 		return 0.0
@@ -217,9 +1011,15 @@ func (s *VerificationService) CanSubmitVerification(userID string) (bool, string
 		return false, "User not found", err
 	}
 
-	// Check if already verified
+	// Check if already verified (renewals are allowed shortly before the verification expires)
 	if user.IsVerified {
-		return false, "User is already verified", nil
+		renewalDue, err := s.renewalDue(userID)
+		if err != nil {
+			return false, "Failed to check verification expiry", err
+		}
+		if !renewalDue {
+			return false, "User is already verified", nil
+		}
 	}
 
 	// Check if email is verified
@@ -237,10 +1037,25 @@ func (s *VerificationService) CanSubmitVerification(userID string) (bool, string
 		return false, "A verification request is already pending", nil
 	}
 
+	resubmitAfter, err := s.resubmitAfter(userID)
+	if err != nil {
+		return false, "Failed to check verification history", err
+	}
+	if resubmitAfter != nil {
+		return false, fmt.Sprintf("A new verification request can be submitted after %s",
+			resubmitAfter.UTC().Format("2006-01-02 15:04 UTC")), nil
+	}
+
 	return true, "Can submit verification", nil
 }
 
 func (s *VerificationService) DeleteVerification(verificationID string) error {
+	if verification, err := s.verificationRepo.GetByID(verificationID); err == nil {
+		if err := s.purgeFiles(verification); err != nil {
+			return fmt.Errorf("failed to delete verification documents: %w", err)
+		}
+	}
+
 	err := s.verificationRepo.Delete(verificationID)
 	if err != nil {
 		return fmt.Errorf("failed to delete verification: %w", err)
@@ -249,14 +1064,199 @@ func (s *VerificationService) DeleteVerification(verificationID string) error {
 	return nil
 }
 
-func (s *VerificationService) GetVerifiedUsers(pagination *utils.PaginationParams) (*model.FollowersResponse, error) {
-	// This would need a custom repository method to get verified users
-	// For now, return empty response as placeholder
+// DeleteUserVerifications deletes every verification request of the user with its documents,
+// used when the account is deleted
+func (s *VerificationService) DeleteUserVerifications(userID string) error {
+	verifications, err := s.verificationRepo.GetAllByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for i := range verifications {
+		if err := s.DeleteVerification(verifications[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetVerifiedUsers is the directory of verified accounts, with the viewer's follow status
+func (s *VerificationService) GetVerifiedUsers(filter *model.VerifiedUsersFilter, viewerID *string, pagination *utils.PaginationParams) (*model.FollowersResponse, error) {
+	paginationResult := pagination.Calculate()
+
+	users, totalCount, err := s.userRepo.GetVerifiedUsers(filter, viewerID, paginationResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verified users: %w", err)
+	}
+
+	hasMore := utils.CalculateHasMore(totalCount, paginationResult.Page, paginationResult.PageSize)
+
 	return &model.FollowersResponse{
-		Users:      []model.UserProfile{},
-		TotalCount: 0,
-		Page:       pagination.Calculate().Page,
-		PageSize:   pagination.Calculate().PageSize,
-		HasMore:    false,
+		Users:      users,
+		TotalCount: totalCount,
+		Page:       paginationResult.Page,
+		PageSize:   paginationResult.PageSize,
+		HasMore:    hasMore,
 	}, nil
 }
+
+// SetVerificationCategory changes the directory category of a verified user
+func (s *VerificationService) SetVerificationCategory(userID string, category *model.VerificationCategory) (*model.UserProfile, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if !user.IsVerified {
+		return nil, fmt.Errorf("invalid request: user is not verified")
+	}
+	if err := s.userRepo.UpdateVerificationCategory(userID, category); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetProfile(userID, nil)
+}
+
+// GetDocumentLinks hands a reviewer short-lived URLs to the documents of a request. Every
+// link handed out is recorded in the access log.
+func (s *VerificationService) GetDocumentLinks(verificationID, viewerID, ipAddress, userAgent string) (*model.VerificationDocumentsResponse, error) {
+	verification, err := s.verificationRepo.GetByID(verificationID)
+	if err != nil {
+		return nil, fmt.Errorf("verification not found")
+	}
+	if verification.DocumentsPurgedAt != nil {
+		return nil, fmt.Errorf("documents not found: they were deleted after the retention period")
+	}
+
+	response := &model.VerificationDocumentsResponse{
+		VerificationID: verification.ID,
+		Documents:      []model.VerificationDocumentLink{},
+	}
+
+	sideByID := make(map[string]string)
+	var ids []string
+	for side, id := range verification.DocumentMediaIDs() {
+		sideByID[id] = side
+		ids = append(ids, id)
+	}
+	media, err := s.mediaService.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range media {
+		query, expiresAt := s.mediaService.SignPrivate(m.ID, viewerID, documentLinkTTL)
+		response.Documents = append(response.Documents, model.VerificationDocumentLink{
+			Side:        sideByID[m.ID],
+			ContentType: m.ContentType,
+			URL:         fmt.Sprintf("/api/v1/verification/documents/%s?%s", m.ID, query),
+			ExpiresAt:   expiresAt,
+		})
+		s.recordDocumentAccess(verification.ID, m.ID, sideByID[m.ID], viewerID,
+			model.VerificationDocumentLinkIssued, ipAddress, userAgent)
+	}
+
+	return response, nil
+}
+
+// OpenDocument serves a document through a signed URL from GetDocumentLinks and records the view
+func (s *VerificationService) OpenDocument(mediaID, viewerID, expires, signature, ipAddress, userAgent string) (*model.Media, io.ReadCloser, error) {
+	media, reader, err := s.mediaService.OpenPrivate(mediaID, viewerID, expires, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verification, err := s.verificationRepo.GetByDocumentMediaID(mediaID)
+	if err != nil {
+		reader.Close()
+		return nil, nil, fmt.Errorf("media not found")
+	}
+	var side string
+	for documentSide, id := range verification.DocumentMediaIDs() {
+		if id == mediaID {
+			side = documentSide
+		}
+	}
+	s.recordDocumentAccess(verification.ID, mediaID, side, viewerID,
+		model.VerificationDocumentViewed, ipAddress, userAgent)
+
+	return media, reader, nil
+}
+
+func (s *VerificationService) recordDocumentAccess(verificationID, mediaID, side, viewerID string,
+	action model.VerificationDocumentAccessAction, ipAddress, userAgent string) {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	err := s.verificationRepo.CreateDocumentAccess(&model.VerificationDocumentAccess{
+		ID:             uuid.New().String(),
+		VerificationID: verificationID,
+		MediaID:        mediaID,
+		Side:           side,
+		ViewerID:       viewerID,
+		Action:         action,
+		IPAddress:      ipAddress,
+		UserAgent:      userAgent,
+	})
+	if err != nil {
+		log.Printf("Failed to record access to verification %s documents: %v", verificationID, err)
+	}
+}
+
+// GetDocumentAccessLog lists who requested or opened the documents of a request
+func (s *VerificationService) GetDocumentAccessLog(verificationID string) ([]model.VerificationDocumentAccess, error) {
+	if _, err := s.verificationRepo.GetByID(verificationID); err != nil {
+		return nil, fmt.Errorf("verification not found")
+	}
+	accesses, err := s.verificationRepo.GetDocumentAccess(verificationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range accesses {
+		if profile, err := s.userRepo.GetProfile(accesses[i].ViewerID, nil); err == nil {
+			accesses[i].Viewer = profile
+		}
+	}
+	return accesses, nil
+}
+
+// PurgeDocuments deletes the documents of requests decided more than retentionDays ago.
+// The request itself (status, notes, reviewer) is kept.
+func (s *VerificationService) PurgeDocuments(retentionDays int) (int, error) {
+	decidedBefore := time.Now().AddDate(0, 0, -retentionDays)
+	verifications, err := s.verificationRepo.GetPurgeable(decidedBefore, 100)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range verifications {
+		if err := s.purgeFiles(&verifications[i]); err != nil {
+			log.Printf("Failed to purge documents of verification %s: %v", verifications[i].ID, err)
+			continue
+		}
+		if err := s.verificationRepo.MarkDocumentsPurged(verifications[i].ID); err != nil {
+			log.Printf("Failed to mark documents of verification %s purged: %v", verifications[i].ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (s *VerificationService) purgeFiles(verification *model.IdentityVerification) error {
+	var ids []string
+	for _, id := range verification.DocumentMediaIDs() {
+		ids = append(ids, id)
+	}
+	if err := s.mediaService.Purge(ids); err != nil {
+		return err
+	}
+
+	var urls []string
+	for _, url := range []string{verification.FrontImageURL, verification.SelfieImageURL} {
+		if url != "" {
+			urls = append(urls, url)
+		}
+	}
+	if verification.BackImageURL != nil && *verification.BackImageURL != "" {
+		urls = append(urls, *verification.BackImageURL)
+	}
+	return s.mediaService.PurgeByURLs(urls)
+}
@@ -0,0 +1,62 @@
+-- VietTick Moderation Schema
+-- Content reports, moderator audit trail and in-app notifications
+
+ALTER TABLE posts ADD COLUMN is_hidden BOOLEAN DEFAULT FALSE AFTER comment_count;
+ALTER TABLE comments ADD COLUMN is_hidden BOOLEAN DEFAULT FALSE AFTER like_count;
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP NULL AFTER identity_documents;
+
+-- User reports against posts, comments, users and messages
+CREATE TABLE reports (
+    id CHAR(36) PRIMARY KEY,
+    reporter_id CHAR(36) NOT NULL,
+    target_type ENUM('post', 'comment', 'user', 'message') NOT NULL,
+    target_id CHAR(36) NOT NULL,
+    target_owner_id CHAR(36) NULL,
+    reason ENUM('spam', 'harassment', 'hate_speech', 'violence', 'nudity', 'misinformation', 'impersonation', 'self_harm', 'other') NOT NULL,
+    details TEXT,
+    status ENUM('pending', 'resolving', 'actioned', 'dismissed') DEFAULT 'pending',
+    resolution TEXT,
+    claimed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL,
+    resolved_by CHAR(36) NULL,
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_reporter_id (reporter_id),
+    INDEX idx_target (target_type, target_id),
+    INDEX idx_status (status),
+    INDEX idx_created_at (created_at)
+);
+
+-- Audit trail of moderator actions (never updated or deleted)
+CREATE TABLE moderation_actions (
+    id CHAR(36) PRIMARY KEY,
+    report_id CHAR(36) NULL,
+    moderator_id CHAR(36) NOT NULL,
+    action ENUM('hide', 'delete', 'warn', 'suspend', 'dismiss') NOT NULL,
+    target_type ENUM('post', 'comment', 'user', 'message') NOT NULL,
+    target_id CHAR(36) NOT NULL,
+    notes TEXT,
+    duration_hours INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (report_id) REFERENCES reports(id) ON DELETE SET NULL,
+    INDEX idx_moderator_id (moderator_id),
+    INDEX idx_target (target_type, target_id),
+    INDEX idx_created_at (created_at)
+);
+
+-- In-app notifications
+CREATE TABLE notifications (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    entity_id CHAR(36) NULL,
+    is_read BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id),
+    INDEX idx_user_unread (user_id, is_read),
+    INDEX idx_created_at (created_at)
+);
@@ -0,0 +1,14 @@
+-- VietTick Account Status
+-- Suspensions, bans and access token watermark
+
+ALTER TABLE users
+    ADD COLUMN account_status ENUM('active', 'suspended', 'banned') DEFAULT 'active' AFTER identity_documents,
+    ADD COLUMN status_reason TEXT AFTER account_status,
+    ADD COLUMN tokens_valid_after TIMESTAMP(6) NULL AFTER suspended_until,
+    ADD INDEX idx_account_status (account_status);
+
+-- Accounts suspended through the moderation queue before this migration
+UPDATE users SET account_status = 'suspended' WHERE suspended_until IS NOT NULL AND suspended_until > NOW();
+
+-- Direct admin suspensions are recorded in the moderation audit trail too
+ALTER TABLE moderation_actions MODIFY COLUMN action ENUM('hide', 'delete', 'warn', 'suspend', 'ban', 'unsuspend', 'dismiss') NOT NULL;
@@ -0,0 +1,35 @@
+-- VietTick Content Policy
+-- Admin-managed rules checked before posts and comments are saved
+
+CREATE TABLE content_rules (
+    id CHAR(36) PRIMARY KEY,
+    type ENUM('keyword', 'regex', 'domain', 'repeated_content', 'link_density') NOT NULL,
+    pattern TEXT,
+    threshold DOUBLE NULL,
+    window_seconds INT NULL,
+    action ENUM('reject', 'hold', 'shadow_hide') NOT NULL,
+    description VARCHAR(255),
+    is_active BOOLEAN DEFAULT TRUE,
+    created_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_is_active (is_active)
+);
+
+-- Content held by a rule goes into the moderation queue as a system report
+ALTER TABLE reports
+    MODIFY COLUMN reporter_id CHAR(36) NULL,
+    ADD COLUMN source ENUM('user', 'auto') DEFAULT 'user' AFTER reporter_id,
+    ADD INDEX idx_source (source);
+
+-- Recent posts and comments of each author, fingerprinted, for the repeated-content rule.
+-- Kept in the database so every instance sees the same history; rows older than a day are pruned.
+CREATE TABLE content_submissions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    author_id CHAR(36) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_author_fingerprint (author_id, fingerprint, created_at),
+    INDEX idx_created (created_at)
+);
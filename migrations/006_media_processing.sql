@@ -0,0 +1,19 @@
+-- VietTick Image Pipeline
+-- Dimensions, blurhash and resized variants of uploaded images
+
+ALTER TABLE media
+    ADD COLUMN width INT NULL,
+    ADD COLUMN height INT NULL,
+    ADD COLUMN blurhash VARCHAR(64) NULL,
+    ADD COLUMN variants JSON NULL,
+    ADD COLUMN processing_status ENUM('pending', 'processing', 'done', 'failed', 'skipped') DEFAULT 'pending',
+    ADD COLUMN processing_attempts INT DEFAULT 0,
+    ADD COLUMN processing_error VARCHAR(500) NULL,
+    ADD INDEX idx_processing_status (processing_status, updated_at);
+
+-- Posts and profiles keep the media ID so responses can include the variants
+ALTER TABLE posts
+    ADD COLUMN media_ids JSON NULL AFTER image_urls;
+
+ALTER TABLE users
+    ADD COLUMN avatar_media_id CHAR(36) NULL AFTER avatar_url;
@@ -0,0 +1,42 @@
+-- VietTick Private Identity Documents
+-- Documents are referenced by media ID (files live in encrypted private storage),
+-- views are audited and files are purged some time after the decision
+
+ALTER TABLE identity_verifications
+    MODIFY COLUMN front_image_url VARCHAR(255) NOT NULL DEFAULT '',
+    MODIFY COLUMN selfie_image_url VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN front_media_id CHAR(36) NULL AFTER id_type,
+    ADD COLUMN back_media_id CHAR(36) NULL AFTER front_media_id,
+    ADD COLUMN selfie_media_id CHAR(36) NULL AFTER back_media_id,
+    ADD COLUMN documents_purged_at TIMESTAMP NULL AFTER reviewed_by,
+    ADD INDEX idx_front_media_id (front_media_id),
+    ADD INDEX idx_back_media_id (back_media_id),
+    ADD INDEX idx_selfie_media_id (selfie_media_id),
+    ADD INDEX idx_status_reviewed_at (status, reviewed_at);
+
+-- Media of private documents have no public URL
+ALTER TABLE media
+    MODIFY COLUMN url VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE TABLE user_permissions (
+    user_id CHAR(36) NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    granted_by CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, permission),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE verification_document_access_logs (
+    id CHAR(36) PRIMARY KEY,
+    verification_id CHAR(36) NOT NULL,
+    media_id CHAR(36) NOT NULL DEFAULT '',
+    side ENUM('front', 'back', 'selfie') NOT NULL,
+    viewer_id CHAR(36) NOT NULL,
+    action ENUM('link_issued', 'viewed') NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_verification_id (verification_id, created_at),
+    INDEX idx_viewer_id (viewer_id)
+);
@@ -0,0 +1,10 @@
+-- VietTick PII Encryption
+-- ID numbers and names on identity documents are stored encrypted ("enc:" + base64),
+-- which needs wider columns, plus an HMAC blind index to match ID numbers without
+-- decrypting. Existing rows are encrypted by running: go run ./cmd/rotate-pii-keys
+
+ALTER TABLE identity_verifications
+    MODIFY COLUMN full_name VARCHAR(1024) NOT NULL,
+    MODIFY COLUMN id_number VARCHAR(512) NOT NULL,
+    ADD COLUMN id_number_hash CHAR(64) NULL AFTER id_number,
+    ADD INDEX idx_id_number_hash (id_number_hash);
@@ -0,0 +1,33 @@
+-- VietTick Duplicate Identity Detection
+-- Fingerprints of submitted documents (kept after the files are purged) and the matches
+-- against other accounts shown to reviewers
+
+ALTER TABLE media
+    ADD COLUMN content_hash CHAR(64) NULL AFTER url;
+
+CREATE TABLE verification_document_fingerprints (
+    verification_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    side ENUM('front', 'back', 'selfie') NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    perceptual_hash BIGINT UNSIGNED NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (verification_id, side),
+    FOREIGN KEY (verification_id) REFERENCES identity_verifications(id) ON DELETE CASCADE,
+    INDEX idx_content_hash (content_hash),
+    INDEX idx_user_id (user_id)
+);
+
+CREATE TABLE verification_duplicate_matches (
+    id CHAR(36) PRIMARY KEY,
+    verification_id CHAR(36) NOT NULL,
+    matched_verification_id CHAR(36) NOT NULL,
+    matched_user_id CHAR(36) NOT NULL,
+    reason ENUM('id_number', 'document_image', 'selfie_image') NOT NULL,
+    exact BOOLEAN NOT NULL DEFAULT TRUE,
+    detail VARCHAR(255) NOT NULL,
+    matched_status ENUM('pending', 'approved', 'rejected') NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (verification_id) REFERENCES identity_verifications(id) ON DELETE CASCADE,
+    INDEX idx_verification_id (verification_id)
+);
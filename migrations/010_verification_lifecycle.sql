@@ -0,0 +1,49 @@
+-- VietTick Verification Lifecycle
+-- Approved verifications can be revoked or expire, every status change is recorded
+
+ALTER TABLE identity_verifications
+    MODIFY COLUMN status ENUM('pending', 'approved', 'rejected', 'revoked', 'expired') DEFAULT 'pending',
+    ADD COLUMN expires_at TIMESTAMP NULL AFTER reviewed_by,
+    ADD COLUMN expiry_reminded_at TIMESTAMP NULL AFTER expires_at,
+    ADD COLUMN revoked_at TIMESTAMP NULL AFTER expiry_reminded_at,
+    ADD COLUMN revoked_by CHAR(36) NULL AFTER revoked_at,
+    ADD COLUMN revocation_reason TEXT AFTER revoked_by,
+    ADD FOREIGN KEY (revoked_by) REFERENCES users(id) ON DELETE SET NULL,
+    ADD INDEX idx_expires_at (status, expires_at);
+
+ALTER TABLE users
+    MODIFY COLUMN identity_verification_status ENUM('pending', 'approved', 'rejected', 'revoked', 'expired', 'none') DEFAULT 'none';
+
+ALTER TABLE verification_duplicate_matches
+    MODIFY COLUMN matched_status ENUM('pending', 'approved', 'rejected', 'revoked', 'expired') NOT NULL;
+
+CREATE TABLE verification_events (
+    id CHAR(36) PRIMARY KEY,
+    verification_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    from_status ENUM('pending', 'approved', 'rejected', 'revoked', 'expired') NULL,
+    to_status ENUM('pending', 'approved', 'rejected', 'revoked', 'expired') NOT NULL,
+    reason TEXT,
+    actor_id CHAR(36) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (verification_id) REFERENCES identity_verifications(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_verification_id (verification_id),
+    INDEX idx_user_created (user_id, created_at)
+);
+
+-- History of requests submitted before this migration
+INSERT INTO verification_events (id, verification_id, user_id, from_status, to_status, actor_id, created_at)
+SELECT UUID(), id, user_id, NULL, 'pending', user_id, submitted_at FROM identity_verifications;
+
+INSERT INTO verification_events (id, verification_id, user_id, from_status, to_status, reason, actor_id, created_at)
+SELECT UUID(), id, user_id, 'pending', status, admin_notes, reviewed_by, reviewed_at
+FROM identity_verifications WHERE status IN ('approved', 'rejected') AND reviewed_at IS NOT NULL;
+
+-- Rejections used to leave the user's status at pending
+UPDATE users u SET u.identity_verification_status = 'rejected'
+WHERE u.identity_verification_status = 'pending' AND u.is_verified = FALSE
+  AND NOT EXISTS (SELECT 1 FROM identity_verifications v WHERE v.user_id = u.id AND v.status = 'pending');
+
+-- Approvals before this migration never expire (expires_at stays NULL)
@@ -0,0 +1,38 @@
+-- VietTick Verification Review Queue
+-- Reviewer assignment, claim leases, two-reviewer approval of high-risk requests and the
+-- decisions of each reviewer (used for SLA metrics)
+
+ALTER TABLE identity_verifications
+    ADD COLUMN assigned_to CHAR(36) NULL AFTER revocation_reason,
+    ADD COLUMN assigned_at TIMESTAMP NULL AFTER assigned_to,
+    ADD COLUMN claimed_by CHAR(36) NULL AFTER assigned_at,
+    ADD COLUMN claim_expires_at TIMESTAMP NULL AFTER claimed_by,
+    ADD COLUMN high_risk BOOLEAN NOT NULL DEFAULT FALSE AFTER claim_expires_at,
+    ADD FOREIGN KEY (assigned_to) REFERENCES users(id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (claimed_by) REFERENCES users(id) ON DELETE SET NULL,
+    ADD INDEX idx_assigned_to (assigned_to, status),
+    ADD INDEX idx_claimed_by (claimed_by, status);
+
+CREATE TABLE verification_reviews (
+    id CHAR(36) PRIMARY KEY,
+    verification_id CHAR(36) NOT NULL,
+    reviewer_id CHAR(36) NOT NULL,
+    decision ENUM('approved', 'rejected') NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (verification_id) REFERENCES identity_verifications(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_verification_id (verification_id),
+    INDEX idx_reviewer_created (reviewer_id, created_at),
+    INDEX idx_created_at (created_at)
+);
+
+-- Requests already flagged as sharing an identity with another account
+UPDATE identity_verifications SET high_risk = TRUE
+WHERE status = 'pending' AND id IN (SELECT verification_id FROM verification_duplicate_matches);
+
+-- Decisions made before this migration
+INSERT INTO verification_reviews (id, verification_id, reviewer_id, decision, notes, created_at)
+SELECT UUID(), id, reviewed_by, status, admin_notes, reviewed_at
+FROM identity_verifications
+WHERE status IN ('approved', 'rejected') AND reviewed_by IS NOT NULL AND reviewed_at IS NOT NULL;
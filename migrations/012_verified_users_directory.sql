@@ -0,0 +1,22 @@
+-- VietTick Verified Users Directory
+-- Verification categories and the date the blue tick was granted
+
+ALTER TABLE identity_verifications
+    ADD COLUMN category ENUM('journalist', 'government', 'brand', 'creator') NULL AFTER id_type;
+
+ALTER TABLE users
+    ADD COLUMN verification_category ENUM('journalist', 'government', 'brand', 'creator') NULL AFTER identity_verification_status,
+    ADD COLUMN verified_at TIMESTAMP NULL AFTER verification_category,
+    ADD INDEX idx_verified_category (is_verified, verification_category),
+    ADD INDEX idx_verified_at (is_verified, verified_at);
+
+-- Verified accounts from before this migration: date of their latest approval
+UPDATE users u
+JOIN (
+    SELECT user_id, MAX(reviewed_at) AS approved_at
+    FROM identity_verifications
+    WHERE status = 'approved'
+    GROUP BY user_id
+) v ON v.user_id = u.id
+SET u.verified_at = v.approved_at
+WHERE u.is_verified = TRUE;
@@ -0,0 +1,38 @@
+-- VietTick Organization Accounts
+-- Shared accounts for companies and news outlets, run by their members (owner, admin, editor).
+-- Posts and comments made on behalf of an organization keep the member who wrote them.
+
+ALTER TABLE users
+    ADD COLUMN account_type ENUM('personal', 'organization') NOT NULL DEFAULT 'personal' AFTER username,
+    ADD INDEX idx_account_type (account_type);
+
+CREATE TABLE organization_members (
+    organization_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    role ENUM('owner', 'admin', 'editor') NOT NULL,
+    added_by CHAR(36) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id),
+    FOREIGN KEY (organization_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (added_by) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_user_id (user_id),
+    INDEX idx_organization_role (organization_id, role)
+);
+
+ALTER TABLE posts
+    ADD COLUMN acting_user_id CHAR(36) NULL AFTER user_id,
+    ADD FOREIGN KEY (acting_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    ADD INDEX idx_acting_user_id (acting_user_id);
+
+ALTER TABLE comments
+    ADD COLUMN acting_user_id CHAR(36) NULL AFTER user_id,
+    ADD FOREIGN KEY (acting_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    ADD INDEX idx_acting_user_id (acting_user_id);
+
+-- Organizations verify with their business registration, submitted by one of their members
+ALTER TABLE identity_verifications
+    MODIFY COLUMN id_type ENUM('national_id', 'passport', 'driver_license', 'business_registration') NOT NULL,
+    ADD COLUMN submitted_by CHAR(36) NULL AFTER user_id,
+    ADD FOREIGN KEY (submitted_by) REFERENCES users(id) ON DELETE SET NULL;
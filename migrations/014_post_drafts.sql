@@ -0,0 +1,27 @@
+-- VietTick Drafts and Scheduled Posts
+-- Drafts are kept apart from posts so they never show up in listings. A scheduled draft is
+-- published by the scheduler as a post with the draft's ID.
+
+CREATE TABLE post_drafts (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    acting_user_id CHAR(36) NULL,
+    content TEXT NOT NULL,
+    media_ids JSON,
+    status ENUM('draft', 'scheduled', 'publishing', 'published', 'failed') NOT NULL DEFAULT 'draft',
+    publish_at TIMESTAMP NULL,
+    published_at TIMESTAMP NULL,
+    post_id CHAR(36) NULL,
+    publish_attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    lock_token CHAR(36) NULL,
+    locked_until TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (acting_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE SET NULL,
+    INDEX idx_user_status (user_id, status, updated_at),
+    INDEX idx_due (status, publish_at),
+    INDEX idx_locked_until (status, locked_until)
+);
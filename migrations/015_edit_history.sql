@@ -0,0 +1,36 @@
+-- VietTick Edit History
+-- Every edit of a post or comment keeps the version it replaced, so readers can see what changed.
+-- Revisions are never updated; they go away with their post or comment.
+
+ALTER TABLE posts
+    ADD COLUMN edited_at TIMESTAMP NULL AFTER is_hidden,
+    ADD COLUMN revision_count INT NOT NULL DEFAULT 0 AFTER edited_at;
+
+ALTER TABLE comments
+    ADD COLUMN edited_at TIMESTAMP NULL AFTER is_hidden,
+    ADD COLUMN revision_count INT NOT NULL DEFAULT 0 AFTER edited_at;
+
+-- revision 0 is the original post; the current version is posts.revision_count
+CREATE TABLE post_revisions (
+    id CHAR(36) PRIMARY KEY,
+    post_id CHAR(36) NOT NULL,
+    revision INT NOT NULL,
+    content TEXT NOT NULL,
+    image_urls JSON NULL,
+    media_ids JSON NULL,
+    hashtags JSON NULL,
+    -- when this version was published
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    UNIQUE KEY uniq_post_revision (post_id, revision)
+);
+
+CREATE TABLE comment_revisions (
+    id CHAR(36) PRIMARY KEY,
+    comment_id CHAR(36) NOT NULL,
+    revision INT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    UNIQUE KEY uniq_comment_revision (comment_id, revision)
+);
@@ -0,0 +1,18 @@
+-- VietTick Soft Delete
+-- Deleted posts and comments are kept for a retention period: authors can restore them for a while,
+-- moderators keep them as evidence, then a background job deletes them for good.
+
+ALTER TABLE posts
+    ADD COLUMN deleted_at TIMESTAMP NULL AFTER updated_at,
+    ADD COLUMN deleted_by CHAR(36) NULL AFTER deleted_at,
+    ADD COLUMN deletion_source ENUM('author', 'moderation') NULL AFTER deleted_by,
+    ADD INDEX idx_deleted_at (deleted_at);
+
+ALTER TABLE comments
+    ADD COLUMN deleted_at TIMESTAMP NULL AFTER updated_at,
+    ADD COLUMN deleted_by CHAR(36) NULL AFTER deleted_at,
+    ADD COLUMN deletion_source ENUM('author', 'moderation') NULL AFTER deleted_by,
+    ADD INDEX idx_deleted_at (deleted_at);
+
+-- Moderators restoring deleted content is recorded in the audit trail
+ALTER TABLE moderation_actions MODIFY COLUMN action ENUM('hide', 'delete', 'warn', 'suspend', 'ban', 'unsuspend', 'dismiss', 'restore') NOT NULL;
@@ -0,0 +1,10 @@
+-- VietTick Account Deactivation & Deletion
+-- Deactivated accounts are hidden until the user logs back in. Deleted accounts are
+-- erased in the background and keep their username for a cooldown period.
+
+ALTER TABLE users
+    MODIFY COLUMN account_status ENUM('active', 'suspended', 'banned', 'deactivated', 'deleted') DEFAULT 'active',
+    ADD COLUMN deactivated_at TIMESTAMP NULL AFTER tokens_valid_after,
+    ADD COLUMN deletion_requested_at TIMESTAMP NULL AFTER deactivated_at,
+    ADD COLUMN erased_at TIMESTAMP NULL AFTER deletion_requested_at,
+    ADD COLUMN username_released_at TIMESTAMP NULL AFTER erased_at;
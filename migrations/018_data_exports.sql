@@ -0,0 +1,25 @@
+-- VietTick Personal Data Export
+-- Users download an archive of their data. Archives are built section by section by a
+-- background worker, so an interrupted export resumes where it stopped.
+
+CREATE TABLE data_exports (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    status ENUM('pending', 'processing', 'ready', 'failed', 'expired') NOT NULL DEFAULT 'pending',
+    format_version INT NOT NULL,
+    completed_sections JSON,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    lock_token CHAR(36) NULL,
+    locked_until TIMESTAMP NULL,
+    storage_key VARCHAR(255) NULL,
+    size_bytes BIGINT NULL,
+    expires_at TIMESTAMP NULL,
+    completed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_created (user_id, created_at),
+    INDEX idx_status_locked_until (status, locked_until),
+    INDEX idx_status_expires (status, expires_at)
+);
@@ -0,0 +1,28 @@
+-- VietTick Bookmarks
+-- Users save posts for later, optionally sorted into named collections. Bookmarks are only
+-- visible to their owner and do not notify the author.
+
+CREATE TABLE bookmark_collections (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_user_name (user_id, name)
+);
+
+-- A post is saved once per user; deleting its collection moves it back to unsorted bookmarks
+CREATE TABLE bookmarks (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    post_id CHAR(36) NOT NULL,
+    collection_id CHAR(36) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (collection_id) REFERENCES bookmark_collections(id) ON DELETE SET NULL,
+    UNIQUE KEY unique_user_post (user_id, post_id),
+    INDEX idx_user_created (user_id, created_at),
+    INDEX idx_collection_created (collection_id, created_at)
+);
@@ -0,0 +1,58 @@
+-- VietTick Reactions
+-- Likes become one of a fixed set of reactions (like, love, haha, wow, sad, angry), one per user per
+-- post or comment. Existing likes are converted to "like" reactions and the like tables are dropped.
+
+CREATE TABLE post_reactions (
+    id CHAR(36) PRIMARY KEY,
+    post_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    reaction ENUM('like', 'love', 'haha', 'wow', 'sad', 'angry') NOT NULL DEFAULT 'like',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_post_user (post_id, user_id),
+    INDEX idx_post_reaction (post_id, reaction, created_at),
+    INDEX idx_post_created (post_id, created_at),
+    INDEX idx_user_created (user_id, created_at)
+);
+
+CREATE TABLE comment_reactions (
+    id CHAR(36) PRIMARY KEY,
+    comment_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    reaction ENUM('like', 'love', 'haha', 'wow', 'sad', 'angry') NOT NULL DEFAULT 'like',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_comment_user (comment_id, user_id),
+    INDEX idx_comment_reaction (comment_id, reaction, created_at),
+    INDEX idx_comment_created (comment_id, created_at),
+    INDEX idx_user_created (user_id, created_at)
+);
+
+-- Number of reactions of each type, e.g. {"like": 3, "love": 1}; like_count stays the number of "like" reactions
+ALTER TABLE posts ADD COLUMN reaction_counts JSON NULL AFTER like_count;
+ALTER TABLE comments ADD COLUMN reaction_counts JSON NULL AFTER like_count;
+
+-- Convert existing likes. Old rows may share an empty id, so every reaction gets a new one.
+INSERT INTO post_reactions (id, post_id, user_id, reaction, created_at, updated_at)
+SELECT UUID(), post_id, user_id, 'like', created_at, created_at FROM post_likes;
+
+INSERT INTO comment_reactions (id, comment_id, user_id, reaction, created_at, updated_at)
+SELECT UUID(), comment_id, user_id, 'like', created_at, created_at FROM comment_likes;
+
+-- Recount from the converted rows, leaving updated_at untouched
+UPDATE posts p
+SET p.like_count = (SELECT COUNT(*) FROM post_reactions r WHERE r.post_id = p.id),
+    p.reaction_counts = IF(p.like_count > 0, JSON_OBJECT('like', p.like_count), NULL),
+    p.updated_at = p.updated_at;
+
+UPDATE comments c
+SET c.like_count = (SELECT COUNT(*) FROM comment_reactions r WHERE r.comment_id = c.id),
+    c.reaction_counts = IF(c.like_count > 0, JSON_OBJECT('like', c.like_count), NULL),
+    c.updated_at = c.updated_at;
+
+DROP TABLE post_likes;
+DROP TABLE comment_likes;
@@ -0,0 +1,43 @@
+-- VietTick Polls
+-- A post can carry a poll with 2-4 options, single or multiple choice, open until closes_at.
+-- Each user casts one ballot per poll and can change it until the poll closes.
+
+CREATE TABLE polls (
+    id CHAR(36) PRIMARY KEY,
+    post_id CHAR(36) NOT NULL,
+    multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    hide_results BOOLEAN NOT NULL DEFAULT FALSE,
+    closes_at TIMESTAMP NOT NULL,
+    -- Set by the background job once the author and voters have been notified
+    closed_at TIMESTAMP NULL,
+    voter_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_post (post_id),
+    INDEX idx_closing (closed_at, closes_at)
+);
+
+CREATE TABLE poll_options (
+    id CHAR(36) PRIMARY KEY,
+    poll_id CHAR(36) NOT NULL,
+    position TINYINT NOT NULL,
+    text VARCHAR(100) NOT NULL,
+    vote_count INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_poll_position (poll_id, position)
+);
+
+-- A ballot is every row of a user for a poll: one for single choice polls, one or more otherwise
+CREATE TABLE poll_votes (
+    id CHAR(36) PRIMARY KEY,
+    poll_id CHAR(36) NOT NULL,
+    option_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+    FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_option_user (option_id, user_id),
+    INDEX idx_poll_user (poll_id, user_id),
+    INDEX idx_user_created (user_id, created_at)
+);
@@ -0,0 +1,36 @@
+-- VietTick Link Previews
+-- Links in posts are unfurled in the background into preview cards (OpenGraph / Twitter card
+-- metadata). Previews are cached per canonical URL and shared by every post linking to it.
+
+CREATE TABLE link_previews (
+    id CHAR(36) PRIMARY KEY,
+    -- SHA-256 of the canonical URL
+    url_hash CHAR(64) NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    status ENUM('pending', 'processing', 'ready', 'failed') NOT NULL DEFAULT 'pending',
+    title VARCHAR(300) NOT NULL DEFAULT '',
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    image_url VARCHAR(2048) NOT NULL DEFAULT '',
+    site_name VARCHAR(200) NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(500) NULL,
+    fetched_at TIMESTAMP NULL,
+    -- The page is fetched again when a post links to it after this time
+    expires_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_url_hash (url_hash),
+    INDEX idx_status_updated (status, updated_at),
+    INDEX idx_expires (expires_at)
+);
+
+-- Links of a post, in the order they appear in its content
+CREATE TABLE post_links (
+    post_id CHAR(36) NOT NULL,
+    link_preview_id CHAR(36) NOT NULL,
+    position TINYINT NOT NULL,
+    PRIMARY KEY (post_id, position),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (link_preview_id) REFERENCES link_previews(id) ON DELETE CASCADE,
+    INDEX idx_link_preview (link_preview_id)
+);
@@ -0,0 +1,24 @@
+-- VietTick Post Analytics
+-- Impressions (a post shown in a feed or list) and views (a post opened) are counted once per
+-- viewer and window, buffered in memory and added here in batches, per post and hour.
+-- Reactions and comments over time are counted from their own tables.
+
+ALTER TABLE posts
+    ADD COLUMN impression_count INT NOT NULL DEFAULT 0 AFTER comment_count,
+    ADD COLUMN view_count INT NOT NULL DEFAULT 0 AFTER impression_count;
+
+CREATE TABLE post_view_stats (
+    post_id CHAR(36) NOT NULL,
+    -- Author of the post, for the account dashboard
+    user_id CHAR(36) NOT NULL,
+    -- Start of the hour, UTC
+    hour TIMESTAMP NOT NULL,
+    impressions INT NOT NULL DEFAULT 0,
+    -- Part of the above by followers of the author at the time
+    follower_impressions INT NOT NULL DEFAULT 0,
+    views INT NOT NULL DEFAULT 0,
+    follower_views INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, hour),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    INDEX idx_user_hour (user_id, hour)
+);
@@ -0,0 +1,48 @@
+-- VietTick Follower Analytics
+-- Every follow and unfollow is logged, so the history survives the follows row being deleted.
+-- A nightly job sums each day per account into follower_daily_stats and
+-- follower_engagement_daily; the follower analytics endpoint only reads those.
+
+CREATE TABLE follow_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    follower_id CHAR(36) NOT NULL,
+    following_id CHAR(36) NOT NULL,
+    event ENUM('follow', 'unfollow') NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (following_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_following_created (following_id, created_at),
+    INDEX idx_created (created_at),
+    INDEX idx_follower (follower_id)
+);
+
+-- Follows made before this migration
+INSERT INTO follow_events (follower_id, following_id, event, created_at)
+SELECT follower_id, following_id, 'follow', created_at FROM follows;
+
+-- One row per account and day (UTC)
+CREATE TABLE follower_daily_stats (
+    user_id CHAR(36) NOT NULL,
+    day DATE NOT NULL,
+    follows INT NOT NULL DEFAULT 0,
+    unfollows INT NOT NULL DEFAULT 0,
+    -- Followers and verified followers when the day was computed, i.e. shortly after it ended
+    followers INT NOT NULL DEFAULT 0,
+    verified_followers INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_day (day)
+);
+
+-- Reactions and comments of followers on the posts of an account, per day
+CREATE TABLE follower_engagement_daily (
+    user_id CHAR(36) NOT NULL,
+    day DATE NOT NULL,
+    follower_id CHAR(36) NOT NULL,
+    reactions INT NOT NULL DEFAULT 0,
+    comments INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day, follower_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_follower (follower_id)
+);
@@ -3,6 +3,8 @@ package email
 import (
 	"crypto/tls"
 	"fmt"
+	"html"
+	"time"
 
 	"gopkg.in/gomail.v2"
 	"vietick-backend/internal/config"
@@ -116,6 +118,256 @@ func (e *EmailService) SendVerificationApproval(toEmail, toName string) error {
 	return e.sendEmail(toEmail, toName, subject, body)
 }
 
+func (e *EmailService) SendVerificationSubmitted(toEmail, toName string) error {
+	subject := "We Received Your VietTick Verification Request"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Verification Submitted</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #1DA1F2;">We received your verification request</h1>
+        <p>Hi %s,</p>
+        <p>Thanks for submitting your identity verification. Our team will review your documents, which usually takes 3-5 business days.</p>
+        <p>We will email you as soon as a decision has been made. You can also follow the status of your request in the app.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName)
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
+// SendVerificationRejected tells the user why the request was rejected and when they can
+// submit a new one
+func (e *EmailService) SendVerificationRejected(toEmail, toName, reason string, resubmitAfter time.Time) error {
+	subject := "Your VietTick Verification Request Was Not Approved"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Verification Rejected</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #E0245E;">Your verification request was not approved</h1>
+        <p>Hi %s,</p>
+        <p>We reviewed your identity verification request and unfortunately could not approve it.</p>
+        <div style="background-color: #fff5f5; padding: 15px; border-radius: 10px; margin: 20px 0;">
+            <p style="margin: 0;"><strong>Reason:</strong> %s</p>
+        </div>
+        <p>You can submit a new request from %s (UTC). Please make sure your documents are clear, valid and match your profile.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName, html.EscapeString(reason), resubmitAfter.UTC().Format("2006-01-02 15:04"))
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
+func (e *EmailService) SendVerificationRevoked(toEmail, toName, reason string, resubmitAfter time.Time) error {
+	subject := "Your VietTick Verification Has Been Revoked"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Verification Revoked</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #E0245E;">Your verified status has been revoked</h1>
+        <p>Hi %s,</p>
+        <p>The blue checkmark has been removed from your VietTick profile.</p>
+        <div style="background-color: #fff5f5; padding: 15px; border-radius: 10px; margin: 20px 0;">
+            <p style="margin: 0;"><strong>Reason:</strong> %s</p>
+        </div>
+        <p>You can apply for verification again from %s (UTC). If you believe this was a mistake, please contact our support channel.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName, html.EscapeString(reason), resubmitAfter.UTC().Format("2006-01-02 15:04"))
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
+func (e *EmailService) SendVerificationExpiring(toEmail, toName string, expiresAt time.Time) error {
+	subject := "Your VietTick Verification Is About to Expire"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Verification Expiring</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #1DA1F2;">Time to verify again</h1>
+        <p>Hi %s,</p>
+        <p>Your verified status is based on a document that has to be checked again regularly. It expires on %s (UTC).</p>
+        <p>To keep your blue checkmark without interruption, please submit a new verification request with a valid document before that date.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName, expiresAt.UTC().Format("2006-01-02"))
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
+func (e *EmailService) SendVerificationExpired(toEmail, toName string) error {
+	subject := "Your VietTick Verification Has Expired"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Verification Expired</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #E0245E;">Your verification has expired</h1>
+        <p>Hi %s,</p>
+        <p>Your verified status has expired and the blue checkmark has been removed from your profile.</p>
+        <p>You can get it back at any time by submitting a new verification request with a valid document.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName)
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
+func (e *EmailService) SendAccountSuspended(toEmail, toName, reason string, until *time.Time) error {
+	subject := "Your VietTick Account Has Been Suspended"
+	duration := "permanently"
+	// Only banned accounts have their posts hidden; a temporary suspension leaves them up
+	visibility := "your posts are hidden from other users"
+	if until != nil {
+		duration = fmt.Sprintf("until %s (UTC)", until.UTC().Format("2006-01-02 15:04"))
+		visibility = "your existing posts stay visible"
+	}
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Account Suspended</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #E0245E;">Your account has been suspended</h1>
+        <p>Hi %s,</p>
+        <p>Your VietTick account has been suspended %s for violating our community guidelines.</p>
+        <div style="background-color: #fff5f5; padding: 15px; border-radius: 10px; margin: 20px 0;">
+            <p style="margin: 0;"><strong>Reason:</strong> %s</p>
+        </div>
+        <p>While suspended you cannot sign in, and %s.</p>
+        <p>If you believe this was a mistake, please reply through our support channel to appeal.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName, duration, html.EscapeString(reason), visibility)
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
+func (e *EmailService) SendAccountReinstated(toEmail, toName string) error {
+	subject := "Your VietTick Account Has Been Reinstated"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Account Reinstated</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #1DA1F2;">Welcome back!</h1>
+        <p>Hi %s,</p>
+        <p>The suspension on your VietTick account has been lifted. You can sign in again and your posts are visible once more.</p>
+        <p>Please keep our community guidelines in mind to keep VietTick a safe place for everyone.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName)
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
+func (e *EmailService) SendAccountDeleted(toEmail, toName string) error {
+	subject := "Your VietTick Account Has Been Deleted"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Account Deleted</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #1DA1F2;">Your account has been deleted</h1>
+        <p>Hi %s,</p>
+        <p>As requested, your VietTick account has been permanently deleted. Your posts, comments, likes, follows and identity documents have been removed, and this email address is no longer linked to any account.</p>
+        <p>We're sorry to see you go. You are welcome to create a new account at any time.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName)
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
+func (e *EmailService) SendDataExportReady(toEmail, toName, downloadURL string, expiresAt time.Time) error {
+	subject := "Your VietTick Data Archive Is Ready"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Data Archive Ready</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h1 style="color: #1DA1F2;">Your data archive is ready</h1>
+        <p>Hi %s,</p>
+        <p>The copy of your VietTick data you asked for is ready. It contains your profile, posts with their images, comments, likes, followers, sessions and verification history.</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="%s" style="background-color: #1DA1F2; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; display: inline-block;">Download Archive</a>
+        </div>
+        <p>If the button doesn't work, you can also copy and paste the following link into your browser:</p>
+        <p style="word-break: break-all; color: #1DA1F2;">%s</p>
+        <p>This link will expire on %s (UTC). Keep the archive somewhere safe, it contains personal information.</p>
+        <p>If you didn't request your data, please change your password right away.</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+        <p style="font-size: 12px; color: #666;">This is an automated message, please do not reply to this email.</p>
+    </div>
+</body>
+</html>
+	`, toName, downloadURL, downloadURL, expiresAt.UTC().Format("2006-01-02 15:04"))
+
+	return e.sendEmail(toEmail, toName, subject, body)
+}
+
 func (e *EmailService) sendEmail(toEmail, toName, subject, body string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", m.FormatAddress(e.config.FromEmail, e.config.FromName))
@@ -0,0 +1,83 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of an AES-256 key
+const KeySize = 32
+
+// defaultKeyVersion is used by NewAEAD; Keyring gives each key its own version
+const defaultKeyVersion byte = 1
+
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// AEAD encrypts with AES-256-GCM. Output layout: key version (1 byte) | nonce (12 bytes) | sealed data.
+type AEAD struct {
+	gcm     cipher.AEAD
+	version byte
+}
+
+func NewAEAD(key []byte) (*AEAD, error) {
+	return newVersionedAEAD(key, defaultKeyVersion)
+}
+
+func newVersionedAEAD(key []byte, version byte) (*AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AEAD{gcm: gcm, version: version}, nil
+}
+
+// ParseKey decodes a base64 (standard or URL alphabet) encoded 32-byte key
+func ParseKey(encoded string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(encoded); err == nil {
+			if len(key) != KeySize {
+				return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("encryption key is not valid base64")
+}
+
+// Overhead is how many bytes Seal adds to the plaintext
+func (a *AEAD) Overhead() int {
+	return 1 + a.gcm.NonceSize() + a.gcm.Overhead()
+}
+
+func (a *AEAD) Seal(plaintext []byte) ([]byte, error) {
+	out := make([]byte, 1+a.gcm.NonceSize(), a.Overhead()+len(plaintext))
+	out[0] = a.version
+	if _, err := rand.Read(out[1:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	nonce := out[1:]
+	return a.gcm.Seal(out, nonce, plaintext, []byte{a.version}), nil
+}
+
+func (a *AEAD) Open(ciphertext []byte) ([]byte, error) {
+	headerLen := 1 + a.gcm.NonceSize()
+	if len(ciphertext) < a.Overhead() || ciphertext[0] != a.version {
+		return nil, ErrInvalidCiphertext
+	}
+	plaintext, err := a.gcm.Open(nil, ciphertext[1:headerLen], ciphertext[headerLen:], ciphertext[:1])
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,78 @@
+package encryption
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+
+	"vietick-backend/internal/config"
+)
+
+// developmentKey returns the fixed key used for name when the variable is not set. It is
+// derived from a string in the source code, so it is refused unless development keys are
+// explicitly allowed.
+func developmentKey(cfg *config.EncryptionConfig, variable, name string) ([]byte, error) {
+	if !cfg.AllowDevelopmentKeys {
+		return nil, fmt.Errorf("%s is not set (set ALLOW_DEVELOPMENT_KEYS=true to use the development key locally)", variable)
+	}
+	log.Printf("%s is not set, using the development key", variable)
+	key := sha256.Sum256([]byte("vietick-development-" + name + "-key"))
+	return key[:], nil
+}
+
+// LoadKeyring builds the PII keyring from the configuration. Missing keys are an error
+// unless development keys are allowed.
+func LoadKeyring(cfg *config.EncryptionConfig) (*Keyring, error) {
+	var keys map[int][]byte
+	if cfg.PIIKeys == "" {
+		devKey, err := developmentKey(cfg, "PII_ENCRYPTION_KEYS", "pii")
+		if err != nil {
+			return nil, err
+		}
+		keys = map[int][]byte{1: devKey}
+	} else {
+		var err error
+		if keys, err = ParseKeys(cfg.PIIKeys); err != nil {
+			return nil, err
+		}
+	}
+
+	current := cfg.PIICurrentKeyVersion
+	if current == 0 {
+		for version := range keys {
+			if version > current {
+				current = version
+			}
+		}
+	}
+
+	var indexKey []byte
+	var err error
+	if cfg.BlindIndexKey == "" {
+		indexKey, err = developmentKey(cfg, "PII_BLIND_INDEX_KEY", "blind-index")
+	} else {
+		indexKey, err = ParseKey(cfg.BlindIndexKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyring(keys, current, indexKey)
+}
+
+// LoadDocumentAEAD builds the cipher of the private document storage from the configured
+// key. A missing key is an error unless development keys are allowed.
+func LoadDocumentAEAD(storageCfg *config.StorageConfig, cfg *config.EncryptionConfig) (*AEAD, error) {
+	if storageCfg.DocumentEncryptionKey == "" {
+		key, err := developmentKey(cfg, "DOCUMENT_ENCRYPTION_KEY", "document")
+		if err != nil {
+			return nil, err
+		}
+		return NewAEAD(key)
+	}
+	key, err := ParseKey(storageCfg.DocumentEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DOCUMENT_ENCRYPTION_KEY: %w", err)
+	}
+	return NewAEAD(key)
+}
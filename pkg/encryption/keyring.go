@@ -0,0 +1,218 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// encryptedPrefix marks values written by Keyring; anything else is legacy plaintext
+const encryptedPrefix = "enc:"
+
+// dataKeyVersion tags values sealed with a per-value data key
+const dataKeyVersion byte = 0
+
+// Keyring does envelope encryption of database fields: every value is encrypted with its
+// own random data key, and the data key is encrypted (wrapped) with a versioned master key.
+// Rotating master keys only re-wraps the data keys. Values are stored as
+// "enc:" + base64(wrapped data key | sealed value).
+type Keyring struct {
+	keys     map[byte]*AEAD
+	current  byte
+	indexKey []byte
+}
+
+// NewKeyring takes the master keys by version (1-255), the version new values are written
+// with, and the HMAC key of blind indexes
+func NewKeyring(keys map[int][]byte, current int, indexKey []byte) (*Keyring, error) {
+	if len(indexKey) < 16 {
+		return nil, fmt.Errorf("blind index key must be at least 16 bytes")
+	}
+	k := &Keyring{keys: make(map[byte]*AEAD, len(keys)), current: byte(current), indexKey: indexKey}
+	for version, key := range keys {
+		if version < 1 || version > 255 {
+			return nil, fmt.Errorf("key version must be between 1 and 255, got %d", version)
+		}
+		aead, err := newVersionedAEAD(key, byte(version))
+		if err != nil {
+			return nil, fmt.Errorf("key version %d: %w", version, err)
+		}
+		k.keys[byte(version)] = aead
+	}
+	if _, ok := k.keys[k.current]; !ok || current < 1 || current > 255 {
+		return nil, fmt.Errorf("current key version %d is not configured", current)
+	}
+	return k, nil
+}
+
+// ParseKeys reads "version:base64key" pairs separated by commas, e.g. "1:AbC...,2:XyZ..."
+func ParseKeys(spec string) (map[int][]byte, error) {
+	keys := make(map[int][]byte)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		versionStr, encoded, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("key %q must be written as version:base64key", part)
+		}
+		var version int
+		if _, err := fmt.Sscanf(versionStr, "%d", &version); err != nil {
+			return nil, fmt.Errorf("invalid key version %q", versionStr)
+		}
+		if _, exists := keys[version]; exists {
+			return nil, fmt.Errorf("key version %d is configured twice", version)
+		}
+		key, err := ParseKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key version %d: %w", version, err)
+		}
+		keys[version] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no encryption keys configured")
+	}
+	return keys, nil
+}
+
+// CurrentVersion is the master key version new values are written with
+func (k *Keyring) CurrentVersion() int {
+	return int(k.current)
+}
+
+// IsEncrypted reports whether a stored value was written by a Keyring
+func IsEncrypted(stored string) bool {
+	return strings.HasPrefix(stored, encryptedPrefix)
+}
+
+func (k *Keyring) EncryptString(plaintext string) (string, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	dataAEAD, err := newVersionedAEAD(dataKey, dataKeyVersion)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := dataAEAD.Seal([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := k.keys[k.current].Seal(dataKey)
+	if err != nil {
+		return "", err
+	}
+	return encode(wrapped, sealed), nil
+}
+
+// DecryptString returns legacy plaintext values unchanged
+func (k *Keyring) DecryptString(stored string) (string, error) {
+	if !IsEncrypted(stored) {
+		return stored, nil
+	}
+	wrapped, sealed, err := k.decode(stored)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := k.unwrap(wrapped)
+	if err != nil {
+		return "", err
+	}
+	dataAEAD, err := newVersionedAEAD(dataKey, dataKeyVersion)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := dataAEAD.Open(sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// KeyVersion returns the master key version a value is wrapped with, 0 for plaintext
+func (k *Keyring) KeyVersion(stored string) (int, error) {
+	if !IsEncrypted(stored) {
+		return 0, nil
+	}
+	wrapped, _, err := k.decode(stored)
+	if err != nil {
+		return 0, err
+	}
+	return int(wrapped[0]), nil
+}
+
+// Rotate re-wraps the data key of a value with the current master key, or encrypts a
+// legacy plaintext value. It reports false when the value is already up to date.
+func (k *Keyring) Rotate(stored string) (string, bool, error) {
+	if !IsEncrypted(stored) {
+		encrypted, err := k.EncryptString(stored)
+		return encrypted, err == nil, err
+	}
+	wrapped, sealed, err := k.decode(stored)
+	if err != nil {
+		return "", false, err
+	}
+	if wrapped[0] == k.current {
+		return stored, false, nil
+	}
+	dataKey, err := k.unwrap(wrapped)
+	if err != nil {
+		return "", false, err
+	}
+	rewrapped, err := k.keys[k.current].Seal(dataKey)
+	if err != nil {
+		return "", false, err
+	}
+	return encode(rewrapped, sealed), true, nil
+}
+
+// BlindIndex is a keyed hash of the value, used to find equal values without decrypting
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (k *Keyring) wrappedLen() int {
+	return k.keys[k.current].Overhead() + KeySize
+}
+
+func (k *Keyring) decode(stored string) ([]byte, []byte, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedPrefix))
+	if err != nil || len(raw) <= k.wrappedLen() {
+		return nil, nil, ErrInvalidCiphertext
+	}
+	n := k.wrappedLen()
+	return raw[:n], raw[n:], nil
+}
+
+func (k *Keyring) unwrap(wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[wrapped[0]]
+	if !ok {
+		return nil, fmt.Errorf("encryption key version %d is not configured", wrapped[0])
+	}
+	return key.Open(wrapped)
+}
+
+func encode(wrapped, sealed []byte) string {
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(bytes.Join([][]byte{wrapped, sealed}, nil))
+}
+
+var defaultKeyring atomic.Pointer[Keyring]
+
+// SetDefault installs the keyring used by database field types (see model.EncryptedString)
+func SetDefault(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+// Default returns the keyring installed with SetDefault, or nil
+func Default() *Keyring {
+	return defaultKeyring.Load()
+}
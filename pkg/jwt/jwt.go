@@ -19,6 +19,11 @@ type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	// Set on tokens scoped to an organization: the user acts on its behalf
+	OrganizationID string `json:"organization_id,omitempty"`
+	// Issue time in microseconds; iat only has second precision, which is too coarse to
+	// compare with the account's token watermark
+	IssuedAtMicro int64 `json:"iat_us,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -33,9 +38,32 @@ func NewJWTManager(accessSecret, refreshSecret string, accessExpiryHour, refresh
 
 func (j *JWTManager) GenerateAccessToken(user *model.User) (string, error) {
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
+		UserID:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		IssuedAtMicro: time.Now().UnixMicro(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(j.accessExpiryHour))),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "vietick",
+			Subject:   user.ID,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.accessSecret))
+}
+
+// GenerateOrganizationToken issues an access token of a member acting on behalf of an
+// organization. The membership is checked again on every request.
+func (j *JWTManager) GenerateOrganizationToken(user *model.User, organizationID string) (string, error) {
+	claims := &Claims{
+		UserID:         user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		OrganizationID: organizationID,
+		IssuedAtMicro:  time.Now().UnixMicro(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(j.accessExpiryHour))),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"vietick-backend/pkg/encryption"
+)
+
+// EncryptedStorage encrypts objects before handing them to the underlying storage. Objects
+// have no public URL and cannot be uploaded directly; they are only readable through Get.
+type EncryptedStorage struct {
+	inner Storage
+	aead  *encryption.AEAD
+}
+
+func NewEncryptedStorage(inner Storage, aead *encryption.AEAD) *EncryptedStorage {
+	return &EncryptedStorage{inner: inner, aead: aead}
+}
+
+func (s *EncryptedStorage) Put(key string, body io.Reader, size int64, contentType string) error {
+	plaintext, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+	sealed, err := s.aead.Seal(plaintext)
+	if err != nil {
+		return err
+	}
+	return s.inner.Put(key, bytes.NewReader(sealed), int64(len(sealed)), "application/octet-stream")
+}
+
+func (s *EncryptedStorage) Get(key string) (io.ReadCloser, error) {
+	reader, err := s.inner.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	sealed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	plaintext, err := s.aead.Open(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", key, err)
+	}
+	return io.NopCloser(bytes.NewReader(plaintext)), nil
+}
+
+// Stat reports the plaintext size
+func (s *EncryptedStorage) Stat(key string) (*ObjectInfo, error) {
+	info, err := s.inner.Stat(key)
+	if err != nil {
+		return nil, err
+	}
+	info.Size -= int64(s.aead.Overhead())
+	info.ContentType = ""
+	return info, nil
+}
+
+func (s *EncryptedStorage) Delete(key string) error {
+	return s.inner.Delete(key)
+}
+
+func (s *EncryptedStorage) URL(key string) string {
+	return ""
+}
+
+func (s *EncryptedStorage) PresignPut(key, contentType string, expires time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
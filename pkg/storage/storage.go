@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"vietick-backend/internal/config"
+	"vietick-backend/pkg/encryption"
+)
+
+// ErrNotFound is returned when the object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ErrPresignNotSupported is returned by backends that cannot hand out direct upload URLs.
+// Callers then sign an upload URL against the API themselves.
+var ErrPresignNotSupported = errors.New("presigned uploads not supported by this storage backend")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Storage is a flat key/value blob store. Keys use "/" as separator and never start with one.
+type Storage interface {
+	Put(key string, body io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Stat(key string) (*ObjectInfo, error)
+	Delete(key string) error
+	// URL is the public address of the object
+	URL(key string) string
+	// PresignPut returns a URL the client can PUT the object body to directly
+	PresignPut(key, contentType string, expires time.Duration) (string, error)
+}
+
+// New creates the backend selected by cfg.Driver ("local" or "s3")
+func New(cfg *config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalPath, cfg.PublicBaseURL)
+	case "s3":
+		return NewS3Storage(S3Options{
+			Endpoint:      cfg.S3Endpoint,
+			Region:        cfg.S3Region,
+			Bucket:        cfg.S3Bucket,
+			AccessKey:     cfg.S3AccessKey,
+			SecretKey:     cfg.S3SecretKey,
+			UsePathStyle:  cfg.S3UsePathStyle,
+			PublicBaseURL: cfg.PublicBaseURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+}
+
+// NewPrivate creates the backend for private files (identity documents). Objects are kept
+// apart from public uploads (another directory or bucket) and encrypted with aead.
+func NewPrivate(cfg *config.StorageConfig, aead *encryption.AEAD) (Storage, error) {
+	var inner Storage
+	var err error
+	switch cfg.Driver {
+	case "", "local":
+		inner, err = NewLocalStorage(cfg.PrivateLocalPath, "")
+	case "s3":
+		inner, err = NewS3Storage(S3Options{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3PrivateBucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewEncryptedStorage(inner, aead), nil
+}